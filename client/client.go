@@ -0,0 +1,143 @@
+// Package client is a Go client of the vehicle api, for the tools that keep a local copy of a fleet.
+//
+//	c := client.NewClient("http://localhost:8080", &client.ConfigClient{APIKey: key})
+//	rp := client.NewReplica()
+//	err := rp.Sync(ctx, c) // the whole fleet the first time, only the changes afterwards
+//
+// The base url of a named fleet is the one of the api followed by /fleets/{fleet}.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrStatus is an error that represents a response that is not successful
+	ErrStatus = errors.New("client: unexpected status")
+)
+
+// Vehicle is a struct that represents a vehicle as returned by the api, with the quantities in the units of the client
+type Vehicle struct {
+	Id              int
+	Brand           string
+	Model           string
+	Registration    string
+	Color           string
+	FabricationYear int
+	Capacity        int
+	MaxSpeed        float64
+	FuelType        string
+	Transmission    string
+	Weight          float64
+	Height          float64
+	Length          float64
+	Width           float64
+}
+
+// Changes is a struct that represents the changes of the vehicles of a fleet since a position of its sequence
+type Changes struct {
+	// Seq is the position of the last change included, the since of the next request
+	Seq uint64 `json:"seq"`
+	// Resync is true if the upserts are the whole fleet: the local copy must be replaced, not patched
+	Resync bool `json:"resync"`
+	// Upserts are the vehicles created, updated or restored
+	Upserts []Vehicle `json:"upserts"`
+	// Tombstones are the ids of the vehicles deleted
+	Tombstones []int `json:"tombstones"`
+}
+
+// ConfigClient is a struct that represents the configuration for Client
+type ConfigClient struct {
+	// HTTPClient is the client that sends the requests
+	HTTPClient *http.Client
+	// APIKey is the api key sent in the X-API-Key header (empty: no authentication)
+	APIKey string
+	// Units is the system of units of the quantities (empty: metric)
+	Units string
+}
+
+// NewClient is a function that returns a new instance of Client
+// - baseURL: url of the api, or of a fleet (/fleets/{fleet})
+func NewClient(baseURL string, cfg *ConfigClient) *Client {
+	// default values
+	defaultConfig := &ConfigClient{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	if cfg != nil {
+		if cfg.HTTPClient != nil {
+			defaultConfig.HTTPClient = cfg.HTTPClient
+		}
+		defaultConfig.APIKey = cfg.APIKey
+		defaultConfig.Units = cfg.Units
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		hc:      defaultConfig.HTTPClient,
+		apiKey:  defaultConfig.APIKey,
+		units:   defaultConfig.Units,
+	}
+}
+
+// Client is a struct that represents a client of the vehicle api
+type Client struct {
+	// baseURL is the url of the api, without trailing slash
+	baseURL string
+	// hc is the client that sends the requests
+	hc *http.Client
+	// apiKey is the api key of the requests
+	apiKey string
+	// units is the system of units of the quantities
+	units string
+}
+
+// Changes is a method that returns the changes of the vehicles after the position (0: the whole fleet)
+func (c *Client) Changes(ctx context.Context, since uint64) (ch Changes, err error) {
+	query := url.Values{"since": {strconv.FormatUint(since, 10)}}
+	err = c.get(ctx, "/vehicles/changes", query, &ch)
+	return
+}
+
+// get is a method that sends a GET request and decodes the data of the response
+func (c *Client) get(ctx context.Context, path string, query url.Values, data any) (err error) {
+	if c.units != "" {
+		query.Set("units", c.units)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	res, err := c.hc.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	// error: the message of the body, if any
+	if res.StatusCode != http.StatusOK {
+		var body struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		err = fmt.Errorf("%w: %s %s: %d %s", ErrStatus, http.MethodGet, path, res.StatusCode, body.Message)
+		return
+	}
+
+	body := struct {
+		Data any `json:"data"`
+	}{Data: data}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	return
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// NewReplica is a function that returns a new instance of Replica, empty until its first sync
+func NewReplica() *Replica {
+	return &Replica{vehicles: make(map[int]Vehicle)}
+}
+
+// Replica is a struct that represents a local copy of the vehicles of a fleet, kept up to date with the change feed
+// - it is safe for concurrent use: reads can run while it syncs
+type Replica struct {
+	// mu is the lock that guards the position and the vehicles
+	mu sync.RWMutex
+	// seq is the position of the last change applied (0: never synced)
+	seq uint64
+	// vehicles are the vehicles by id
+	vehicles map[int]Vehicle
+}
+
+// Seq is a method that returns the position of the last change applied
+func (r *Replica) Seq() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.seq
+}
+
+// Vehicles is a method that returns a copy of the vehicles by id
+func (r *Replica) Vehicles() (v map[int]Vehicle) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]Vehicle, len(r.vehicles))
+	for key, value := range r.vehicles {
+		v[key] = value
+	}
+	return
+}
+
+// Apply is a method that applies the changes: a resync replaces the vehicles, otherwise they are patched
+func (r *Replica) Apply(ch Changes) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ch.Resync {
+		r.vehicles = make(map[int]Vehicle, len(ch.Upserts))
+	}
+	for _, v := range ch.Upserts {
+		r.vehicles[v.Id] = v
+	}
+	for _, id := range ch.Tombstones {
+		delete(r.vehicles, id)
+	}
+	r.seq = ch.Seq
+}
+
+// Sync is a method that fetches the changes after the position of the replica and applies them
+// - the replica is left untouched on error
+func (r *Replica) Sync(ctx context.Context, c *Client) (err error) {
+	ch, err := c.Changes(ctx, r.Seq())
+	if err != nil {
+		return
+	}
+
+	r.Apply(ch)
+	return
+}
@@ -0,0 +1,92 @@
+package client_test
+
+import (
+	"app/client"
+	"app/internal/application"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Replica
+func TestReplica(t *testing.T) {
+	server := func(t *testing.T) *httptest.Server {
+		rt := chi.NewRouter()
+		app := application.NewApplicationDefault(&application.ConfigApplicationDefault{
+			Router:           rt,
+			AuthDisabled:     true,
+			LoaderFilePath:   "../docs/db/vehicles_100.json",
+			MaintenanceDir:   t.TempDir(),
+			AuditFilePath:    filepath.Join(t.TempDir(), "audit.jsonl"),
+			WebhooksFilePath: filepath.Join(t.TempDir(), "webhooks.json"),
+		})
+		require.NoError(t, app.SetUp())
+		srv := httptest.NewServer(rt)
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	send := func(t *testing.T, method string, url string) {
+		req, err := http.NewRequest(method, url, nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Less(t, res.StatusCode, 300)
+	}
+
+	t.Run("case 01: the first sync copies the fleet, the next ones only apply the changes", func(t *testing.T) {
+		// arrange
+		srv := server(t)
+		c := client.NewClient(srv.URL, nil)
+		rp := client.NewReplica()
+		require.NoError(t, rp.Sync(context.Background(), c))
+		first := rp.Seq()
+		send(t, http.MethodDelete, srv.URL+"/vehicles/1")
+		send(t, http.MethodDelete, srv.URL+"/vehicles/2")
+		send(t, http.MethodPost, srv.URL+"/vehicles/2/restore")
+
+		// act
+		changes, errChanges := c.Changes(context.Background(), first)
+		errSync := rp.Sync(context.Background(), c)
+
+		// assert
+		require.NotZero(t, first)
+		require.NoError(t, errChanges)
+		require.False(t, changes.Resync)
+		require.Len(t, changes.Upserts, 1)
+		require.Equal(t, 2, changes.Upserts[0].Id)
+		require.Equal(t, []int{1}, changes.Tombstones)
+		require.NoError(t, errSync)
+		require.Equal(t, first+3, rp.Seq())
+		vehicles := rp.Vehicles()
+		require.Len(t, vehicles, 99)
+		require.NotContains(t, vehicles, 1)
+		require.Contains(t, vehicles, 2)
+	})
+
+	t.Run("case 02: an unknown position resyncs and an error leaves the replica untouched", func(t *testing.T) {
+		// arrange
+		srv := server(t)
+		rp := client.NewReplica()
+		rp.Apply(client.Changes{Seq: 1, Upserts: []client.Vehicle{{Id: 999}}})
+
+		// act
+		errUnits := rp.Sync(context.Background(), client.NewClient(srv.URL, &client.ConfigClient{Units: "furlongs"}))
+		vehiclesUnits := rp.Vehicles()
+		errSync := rp.Sync(context.Background(), client.NewClient(srv.URL+"/", nil))
+
+		// assert
+		require.ErrorIs(t, errUnits, client.ErrStatus)
+		require.ErrorContains(t, errUnits, "invalid units")
+		require.Len(t, vehiclesUnits, 1)
+		require.NoError(t, errSync)
+		vehicles := rp.Vehicles()
+		require.Len(t, vehicles, 100)
+		require.NotContains(t, vehicles, 999)
+	})
+}
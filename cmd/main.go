@@ -1,31 +1,80 @@
-package main
-
-import (
-	"app/internal/application"
-	"fmt"
-)
-
-func main() {
-	// env
-	// ...
-
-	// app
-	// - config
-	cfg := &application.ConfigApplicationDefault{
-		ServerAddress: ":8080",
-		LoaderFilePath: "docs/db/vehicles_100.json",
-	}
-	app := application.NewApplicationDefault(cfg)
-	// - setup
-	err := app.SetUp()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	// - run
-	err = app.Run()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+package main
+
+import (
+	"app/internal/application"
+	"app/internal/auth"
+	"app/platform/web/deprecation"
+	"fmt"
+	"os"
+)
+
+func main() {
+	// env
+	// - AUTH_API_KEYS: comma separated list of key:role[:subject[:fleet]]
+	apiKeys, err := auth.ParseAPIKeys(os.Getenv("AUTH_API_KEYS"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// - AUTH_JWT_SECRET: secret used to verify HS256 bearer tokens
+	jwtSecret := os.Getenv("AUTH_JWT_SECRET")
+	// - AUTH_DISABLED: serve every request without credentials when "true" (the server refuses to start without credentials otherwise)
+	authDisabled := os.Getenv("AUTH_DISABLED") == "true"
+	// - FLEETS: comma separated list of name=path of the datasets served under /fleets/{name}
+	fleets, err := application.ParseFleets(os.Getenv("FLEETS"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// - FLEETS_DIR: directory of datasets, each one a fleet named after the file
+	fleetsDir := os.Getenv("FLEETS_DIR")
+	// - REGISTRATION_JURISDICTION: jurisdiction of docs/db/registrations.json that normalizes and validates the registrations
+	registrationJurisdiction := os.Getenv("REGISTRATION_JURISDICTION")
+	// - OPENAPI_VALIDATION: validate the requests against the OpenAPI document when "true"
+	openAPIValidation := os.Getenv("OPENAPI_VALIDATION") == "true"
+	// - WEBHOOKS_ALLOW_PRIVATE_TARGETS: let the webhooks target loopback, private and link-local addresses when "true"
+	webhooksAllowPrivateTargets := os.Getenv("WEBHOOKS_ALLOW_PRIVATE_TARGETS") == "true"
+	// - API_V1_DEPRECATED: RFC 3339 instant from which v1, and the routes without a version prefix, are deprecated (empty: not deprecated)
+	// - API_V1_SUNSET: RFC 3339 instant after which v1 may stop being served (optional)
+	// - API_V1_DEPRECATION_LINK: url of the migration guide to v2 (optional)
+	deprecations := make(map[string]deprecation.Policy)
+	if os.Getenv("API_V1_DEPRECATED") != "" {
+		deprecations["v1"], err = application.ParseDeprecation(os.Getenv("API_V1_DEPRECATED"), os.Getenv("API_V1_SUNSET"), os.Getenv("API_V1_DEPRECATION_LINK"))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	// app
+	// - config
+	cfg := &application.ConfigApplicationDefault{
+		ServerAddress: ":8080",
+		LoaderFilePath: "docs/db/vehicles_100.json",
+		LoaderUnits: "metric",
+		AliasesFilePath: "docs/db/aliases.json",
+		RegistrationRulesFilePath: "docs/db/registrations.json",
+		RegistrationJurisdiction: registrationJurisdiction,
+		Fleets: fleets,
+		FleetsDir: fleetsDir,
+		AuthAPIKeys: apiKeys,
+		AuthJWTSecret: jwtSecret,
+		AuthDisabled: authDisabled,
+		OpenAPIValidation: openAPIValidation,
+		WebhooksAllowPrivateTargets: webhooksAllowPrivateTargets,
+		Deprecations: deprecations,
+	}
+	app := application.NewApplicationDefault(cfg)
+	// - setup
+	err = app.SetUp()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// - run
+	err = app.Run()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 }
\ No newline at end of file
@@ -0,0 +1,16 @@
+// Command vehiclectl runs the queries of the vehicle service against a dataset file, without starting the server.
+//
+//	vehiclectl find --color Blue --year 2008
+//	vehiclectl avg-speed --brand Ford --output json
+//	vehiclectl weight --min 100 --max 200 --output csv
+//
+// Run vehiclectl without arguments for the list of commands and the exit codes.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// printer is an interface that represents an output format
+type printer interface {
+	// vehicles is a method that prints vehicles ordered by id, with the quantities in the units
+	vehicles(v map[int]internal.Vehicle, u internal.Units) (err error)
+	// value is a method that prints a single record, e.g. an average
+	value(columns []string, values []any) (err error)
+}
+
+// newPrinter is a function that returns the printer of the format
+func newPrinter(format string, w io.Writer) (p printer, err error) {
+	switch format {
+	case "table":
+		p = &printerTable{w: w}
+	case "json":
+		p = &printerJSON{w: w}
+	case "csv":
+		p = &printerCSV{w: w}
+	default:
+		err = fmt.Errorf("invalid output %q (allowed: table, json, csv)", format)
+	}
+	return
+}
+
+// vehicleColumns are the columns of the vehicles in the table and csv formats
+var vehicleColumns = []string{"id", "brand", "model", "registration", "color", "year", "passengers", "max_speed", "fuel_type", "transmission", "weight", "height", "length", "width"}
+
+// vehicleRows is a function that returns the vehicles as rows ordered by id
+func vehicleRows(v map[int]internal.Vehicle, u internal.Units) (rows [][]string) {
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	number := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	for _, id := range ids {
+		vh := v[id]
+		rows = append(rows, []string{
+			strconv.Itoa(vh.Id), vh.Brand, vh.Model, vh.Registration, vh.Color,
+			strconv.Itoa(vh.FabricationYear), strconv.Itoa(vh.Capacity), number(vh.MaxSpeed.In(u.Speed)),
+			string(vh.FuelType), string(vh.Transmission), number(vh.Weight.In(u.Mass)),
+			number(vh.Height.In(u.Length)), number(vh.Length.In(u.Length)), number(vh.Width.In(u.Length)),
+		})
+	}
+	return
+}
+
+// printerTable is a struct that prints aligned columns
+type printerTable struct {
+	w io.Writer
+}
+
+func (p *printerTable) vehicles(v map[int]internal.Vehicle, u internal.Units) (err error) {
+	return p.table(vehicleColumns, vehicleRows(v, u))
+}
+
+func (p *printerTable) value(columns []string, values []any) (err error) {
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = fmt.Sprint(value)
+	}
+	return p.table(columns, [][]string{row})
+}
+
+// table is a method that prints the header and the rows
+func (p *printerTable) table(columns []string, rows [][]string) (err error) {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{columns}, rows...) {
+		for i, cell := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// printerJSON is a struct that prints the same JSON as the API: vehicles as an array ordered by id
+type printerJSON struct {
+	w io.Writer
+}
+
+func (p *printerJSON) vehicles(v map[int]internal.Vehicle, u internal.Units) (err error) {
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	data := make([]handler.VehicleResponseJSON, 0, len(ids))
+	for _, id := range ids {
+		data = append(data, handler.NewVehicleResponseJSON(v[id], u))
+	}
+	return p.encode(data)
+}
+
+func (p *printerJSON) value(columns []string, values []any) (err error) {
+	data := make(map[string]any, len(columns))
+	for i, column := range columns {
+		data[column] = values[i]
+	}
+	return p.encode(data)
+}
+
+// encode is a method that prints an indented JSON document
+func (p *printerJSON) encode(data any) (err error) {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// printerCSV is a struct that prints comma separated values with a header
+type printerCSV struct {
+	w io.Writer
+}
+
+func (p *printerCSV) vehicles(v map[int]internal.Vehicle, u internal.Units) (err error) {
+	return p.write(vehicleColumns, vehicleRows(v, u))
+}
+
+func (p *printerCSV) value(columns []string, values []any) (err error) {
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = fmt.Sprint(value)
+	}
+	return p.write(columns, [][]string{row})
+}
+
+// write is a method that prints the header and the records
+func (p *printerCSV) write(columns []string, rows [][]string) (err error) {
+	w := csv.NewWriter(p.w)
+	if err = w.Write(columns); err != nil {
+		return
+	}
+	if err = w.WriteAll(rows); err != nil {
+		return
+	}
+	return w.Error()
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/normalizer"
+	"app/internal/repository"
+	"app/internal/service"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// exitOK is the exit code of a successful query
+	exitOK = 0
+	// exitError is the exit code of a query that failed, e.g. the dataset could not be loaded
+	exitError = 1
+	// exitUsage is the exit code of an invalid command line
+	exitUsage = 2
+	// exitNotFound is the exit code of a query without results
+	exitNotFound = 3
+)
+
+// usage is the help of the command
+const usage = `usage: vehiclectl <command> [flags]
+
+commands:
+  get          --id ID                          vehicle by id
+  find         --color COLOR --year YEAR        vehicles by color and fabrication year
+  find         --brand BRAND --from YEAR --to YEAR
+                                                vehicles by brand between fabrication years
+  avg-speed    --brand BRAND                    average max speed of a brand
+  avg-capacity --brand BRAND                    average capacity of a brand
+  weight       [--min WEIGHT --max WEIGHT]      vehicles by weight range (every vehicle without range)
+
+run "vehiclectl <command> -h" for the flags of a command
+exit codes: 0 success, 1 error, 2 invalid usage, 3 nothing found
+`
+
+// options is a struct that represents the flags shared by every command
+type options struct {
+	// file is the path to the dataset
+	file string
+	// fileUnits is the system of units of the dataset
+	fileUnits string
+	// aliases is the path to the aliases of the string attributes (optional)
+	aliases string
+	// units is the system of units of the filters and the output
+	units string
+	// output is the format of the output: table, json or csv
+	output string
+}
+
+// register is a method that adds the shared flags to a flag set
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.file, "file", "docs/db/vehicles_100.json", "path to the dataset `file`")
+	fs.StringVar(&o.fileUnits, "file-units", "metric", "`system` of units of the dataset: metric or imperial")
+	fs.StringVar(&o.aliases, "aliases", "docs/db/aliases.json", "path to the aliases `file` of brands and colors (empty: none)")
+	fs.StringVar(&o.units, "units", "metric", "`system` of units of the filters and the output: metric or imperial")
+	fs.StringVar(&o.output, "output", "table", "output `format`: table, json or csv")
+}
+
+// errUsage is an error that represents an invalid command line, already reported
+var errUsage = errors.New("vehiclectl: invalid usage")
+
+// run is a function that runs a command and returns its exit code
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	// flags
+	var o options
+	fs := flag.NewFlagSet("vehiclectl "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	o.register(fs)
+
+	var cmd func(sv internal.ServiceVehicle, u internal.Units, p printer) error
+	switch args[0] {
+	case "get":
+		id := fs.Int("id", 0, "`id` of the vehicle")
+		cmd = func(sv internal.ServiceVehicle, u internal.Units, p printer) (err error) {
+			if err = required(fs, "id"); err != nil {
+				return
+			}
+			v, err := sv.FindById(*id)
+			if err != nil {
+				return
+			}
+			return p.vehicles(map[int]internal.Vehicle{v.Id: v}, u)
+		}
+	case "find":
+		color := fs.String("color", "", "`color` of the vehicles, with --year")
+		year := fs.Int("year", 0, "fabrication `year` of the vehicles, with --color")
+		brand := fs.String("brand", "", "`brand` of the vehicles, with --from and --to")
+		from := fs.Int("from", math.MinInt, "first fabrication `year`, with --brand (default: no limit)")
+		to := fs.Int("to", math.MaxInt, "last fabrication `year`, with --brand (default: no limit)")
+		cmd = func(sv internal.ServiceVehicle, u internal.Units, p printer) (err error) {
+			var v map[int]internal.Vehicle
+			switch {
+			case isSet(fs, "color") && !isSet(fs, "brand"):
+				if err = required(fs, "year"); err != nil {
+					return
+				}
+				v, err = sv.FindByColorAndYear(*color, *year)
+			case isSet(fs, "brand") && !isSet(fs, "color"):
+				v, err = sv.FindByBrandAndYearRange(*brand, *from, *to)
+			default:
+				fmt.Fprintln(stderr, "find requires either --color and --year or --brand")
+				return errUsage
+			}
+			if err != nil {
+				return
+			}
+			if len(v) == 0 {
+				return internal.ErrServiceNoVehicles
+			}
+			return p.vehicles(v, u)
+		}
+	case "avg-speed":
+		brand := fs.String("brand", "", "`brand` of the vehicles")
+		cmd = func(sv internal.ServiceVehicle, u internal.Units, p printer) (err error) {
+			if err = required(fs, "brand"); err != nil {
+				return
+			}
+			average, err := sv.AverageMaxSpeedByBrand(*brand)
+			if err != nil {
+				return
+			}
+			return p.value([]string{"brand", "average_max_speed", "unit"}, []any{*brand, average.In(u.Speed), string(u.Speed)})
+		}
+	case "avg-capacity":
+		brand := fs.String("brand", "", "`brand` of the vehicles")
+		cmd = func(sv internal.ServiceVehicle, u internal.Units, p printer) (err error) {
+			if err = required(fs, "brand"); err != nil {
+				return
+			}
+			average, err := sv.AverageCapacityByBrand(*brand)
+			if err != nil {
+				return
+			}
+			return p.value([]string{"brand", "average_capacity"}, []any{*brand, average})
+		}
+	case "weight":
+		minWeight := fs.Float64("min", 0, "minimum `weight`, with --max")
+		maxWeight := fs.Float64("max", 0, "maximum `weight`, with --min")
+		cmd = func(sv internal.ServiceVehicle, u internal.Units, p printer) (err error) {
+			ok := isSet(fs, "min") || isSet(fs, "max")
+			if ok && !(isSet(fs, "min") && isSet(fs, "max")) {
+				fmt.Fprintln(stderr, "weight requires both --min and --max, or none of them")
+				return errUsage
+			}
+			query := internal.SearchQuery{
+				FromWeight: internal.NewMass(*minWeight, u.Mass),
+				ToWeight:   internal.NewMass(*maxWeight, u.Mass),
+			}
+			v, err := sv.SearchByWeightRange(query, ok)
+			if err != nil {
+				return
+			}
+			if len(v) == 0 {
+				return internal.ErrServiceNoVehicles
+			}
+			return p.vehicles(v, u)
+		}
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments %v\n", fs.Args())
+		return exitUsage
+	}
+	u, err := internal.ParseUnits(o.units)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	p, err := newPrinter(o.output, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	// service
+	sv, err := o.service()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	// command
+	err = cmd(sv, u, p)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, internal.ErrServiceVehicleNotFound), errors.Is(err, internal.ErrServiceNoVehicles):
+		fmt.Fprintln(stderr, err)
+		return exitNotFound
+	default:
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+}
+
+// service is a method that loads the dataset and returns the vehicle service over it
+func (o *options) service() (sv internal.ServiceVehicle, err error) {
+	var aliases normalizer.Aliases
+	if o.aliases != "" {
+		aliases, err = normalizer.LoadAliasesJSON(o.aliases)
+		if err != nil {
+			return
+		}
+	}
+	nz := normalizer.NewNormalizerAlias(aliases)
+	units, err := internal.ParseUnits(o.fileUnits)
+	if err != nil {
+		return
+	}
+
+	db, err := loader.NewLoaderVehicleNormalized(loader.NewLoaderVehicleJSON(o.file, units), nz).Load()
+	if err != nil {
+		return
+	}
+	sv = service.NewServiceVehicleDefault(repository.NewRepositoryReadVehicleMap(db, nz))
+	return
+}
+
+// isSet is a function that returns true if the flag was given on the command line
+func isSet(fs *flag.FlagSet, name string) (set bool) {
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return
+}
+
+// required is a function that returns errUsage, after reporting it, if the flag was not given
+func required(fs *flag.FlagSet, name string) (err error) {
+	if !isSet(fs, name) {
+		fmt.Fprintf(fs.Output(), "%s requires --%s\n", fs.Name(), name)
+		err = errUsage
+	}
+	return
+}
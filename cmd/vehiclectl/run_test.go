@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for run
+func TestRun(t *testing.T) {
+	dataset := []string{"--file", "../../docs/db/vehicles_100.json", "--aliases", "../../docs/db/aliases.json"}
+	runArgs := func(args ...string) (code int, stdout string, stderr string) {
+		var out, errOut bytes.Buffer
+		code = run(append(args, dataset...), &out, &errOut)
+		return code, out.String(), errOut.String()
+	}
+
+	t.Run("case 01: find by color and year prints a table ordered by id", func(t *testing.T) {
+		// act
+		code, stdout, _ := runArgs("find", "--color", "orange", "--year", "2008")
+
+		// assert
+		require.Equal(t, exitOK, code)
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 2)
+		require.True(t, strings.HasPrefix(lines[0], "id  brand"))
+		require.True(t, strings.HasPrefix(lines[1], "1   Hummer"))
+	})
+
+	t.Run("case 02: average max speed in json and imperial units", func(t *testing.T) {
+		// act
+		code, stdout, _ := runArgs("avg-speed", "--brand", "Chevy", "--output", "json", "--units", "imperial")
+
+		// assert
+		require.Equal(t, exitOK, code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal([]byte(stdout), &body))
+		require.Equal(t, "mph", body["unit"])
+		require.InDelta(t, 102.2156, body["average_max_speed"], 0.0001)
+	})
+
+	t.Run("case 03: weight range in csv", func(t *testing.T) {
+		// act
+		code, stdout, _ := runArgs("weight", "--min", "71", "--max", "72", "--output", "csv")
+
+		// assert
+		require.Equal(t, exitOK, code)
+		require.Equal(t, "id,brand,model,registration,color,year,passengers,max_speed,fuel_type,transmission,weight,height,length,width\n"+
+			"32,Chevrolet,Impala,55,Crimson,2009,2,183,gasoline,automatic,71.22,254.99,0,116.76\n", stdout)
+	})
+
+	t.Run("case 04: exit codes", func(t *testing.T) {
+		// act
+		notFound, _, _ := runArgs("avg-speed", "--brand", "Unknown")
+		missing, _, stderr := runArgs("find", "--color", "Blue")
+		unknown, _, _ := runArgs("frobnicate")
+		output, _, _ := runArgs("get", "--id", "1", "--output", "xml")
+		code := run([]string{"get", "--id", "1", "--file", "missing.json"}, &bytes.Buffer{}, &bytes.Buffer{})
+
+		// assert
+		require.Equal(t, exitNotFound, notFound)
+		require.Equal(t, exitUsage, missing)
+		require.Contains(t, stderr, "requires --year")
+		require.Equal(t, exitUsage, unknown)
+		require.Equal(t, exitUsage, output)
+		require.Equal(t, exitError, code)
+	})
+}
@@ -0,0 +1,15 @@
+// Command vehiclegen writes a synthetic fleet in the schema of docs/db/vehicles_100.json, e.g. for performance or validation tests.
+//
+//	vehiclegen -n 1000000 -seed 42 -out docs/db/vehicles_1m.json
+//	vehiclegen -n 5000 -duplicates 0.01 -invalid 0.05 -format csv
+//
+// The same seed and flags always produce the same file. A summary of the written rows is printed to stderr.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
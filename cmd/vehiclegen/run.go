@@ -0,0 +1,100 @@
+package main
+
+import (
+	"app/internal"
+	"app/internal/generator"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// exitOK is the exit code of a successful generation
+	exitOK = 0
+	// exitError is the exit code of a generation that failed, e.g. the output could not be written
+	exitError = 1
+	// exitUsage is the exit code of an invalid command line
+	exitUsage = 2
+)
+
+// run is a function that generates the dataset and returns the exit code
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	// flags
+	fs := flag.NewFlagSet("vehiclegen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	n := fs.Int("n", 1000, "number of `rows`")
+	seed := fs.Int64("seed", 1, "`seed` of the random source")
+	format := fs.String("format", "json", "output `format`: json or csv")
+	out := fs.String("out", "", "path to the output `file` (default: stdout)")
+	units := fs.String("units", "metric", "`system` of units of the quantities: metric or imperial")
+	config := fs.String("config", "", "path to a JSON `file` with the brand, model, color and year distributions (default: built-in)")
+	duplicates := fs.Float64("duplicates", -1, "`rate` of rows that repeat a previous vehicle (default: the config's)")
+	invalid := fs.Float64("invalid", -1, "`rate` of rows that break a validation rule (default: the config's)")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 || *n < 0 {
+		fmt.Fprintln(stderr, "vehiclegen: unexpected arguments or negative -n")
+		return exitUsage
+	}
+
+	u, err := internal.ParseUnits(*units)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	var write func(w io.Writer, g internal.GeneratorVehicle, n int, units internal.Units) (generator.Stats, error)
+	switch *format {
+	case "json":
+		write = generator.WriteJSON
+	case "csv":
+		write = generator.WriteCSV
+	default:
+		fmt.Fprintf(stderr, "vehiclegen: invalid format %q (allowed: json, csv)\n", *format)
+		return exitUsage
+	}
+
+	// generator
+	cfg := generator.DefaultConfig()
+	if *config != "" {
+		cfg, err = generator.LoadConfigJSON(*config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if *duplicates >= 0 {
+		cfg.DuplicateRate = *duplicates
+	}
+	if *invalid >= 0 {
+		cfg.InvalidRate = *invalid
+	}
+	g, err := generator.NewGeneratorVehicleRandom(cfg, *seed)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	// output
+	w := stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer file.Close()
+		w = file
+	}
+	s, err := write(w, g, *n, u)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	fmt.Fprintf(stderr, "vehiclegen: %d rows (%d valid, %d duplicate, %d invalid)\n", s.Total, s.Valid, s.Duplicate, s.Invalid)
+	return exitOK
+}
@@ -1,102 +1,780 @@
-package application
-
-import (
-	"app/internal/handler"
-	"app/internal/loader"
-	"app/internal/repository"
-	"app/internal/service"
-	"net/http"
-
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-)
-
-// ConfigApplicationDefault is a struct that represents the configuration for ApplicationDefault
-type ConfigApplicationDefault struct {
-	// Router is the router / multiplexer that will be used by the application
-	Router *chi.Mux
-	// ServerAddress is the address where the server will be listening
-	ServerAddress string
-	// LoaderFilePath is the path to the file that contains the vehicles
-	LoaderFilePath string
-}
-
-// NewApplicationDefault is a function that returns a new instance of ApplicationDefault
-func NewApplicationDefault(cfg *ConfigApplicationDefault) *ApplicationDefault {
-	// default values
-	defaultConfig := &ConfigApplicationDefault{
-		Router: chi.NewRouter(),
-		ServerAddress: ":8080",
-	}
-	if cfg != nil {
-		if cfg.Router != nil {
-			defaultConfig.Router = cfg.Router
-		}
-		if cfg.ServerAddress != "" {
-			defaultConfig.ServerAddress = cfg.ServerAddress
-		}
-		if cfg.LoaderFilePath != "" {
-			defaultConfig.LoaderFilePath = cfg.LoaderFilePath
-		}
-	}
-
-	return &ApplicationDefault{
-		router: defaultConfig.Router,
-		serverAddress: defaultConfig.ServerAddress,
-		loaderFilePath: defaultConfig.LoaderFilePath,
-	}
-}
-
-// ApplicationDefault is a struct that implements the Application interface
-type ApplicationDefault struct {
-	// router is the router / multiplexer that will be used by the application
-	router *chi.Mux
-	// serverAddress is the address where the server will be listening
-	serverAddress string
-	// loaderFilePath is the path to the file that contains the vehicles
-	loaderFilePath string
-}
-
-// SetUp is a method that sets up the application
-func (a *ApplicationDefault) SetUp() (err error) {
-	// dependencies
-	// - loader: loader for vehicles
-	ld := loader.NewLoaderVehicleJSON(a.loaderFilePath)
-	// - db: map of vehicles
-	db, err := ld.Load()
-	if err != nil {
-		return
-	}
-	// - repository: repository for vehicles
-	rp := repository.NewRepositoryReadVehicleMap(db)
-	// - service: service for vehicles
-	sv := service.NewServiceVehicleDefault(rp)
-	// - handler: handler for vehicles
-	hd := handler.NewHandlerVehicle(sv)
-
-	// routes
-	// - middlewares
-	a.router.Use(middleware.Logger)
-	a.router.Use(middleware.Recoverer)
-	// - endpoints
-	a.router.Route("/vehicles", func(r chi.Router) {
-		// Get vehicles by color and year
-		r.Get("/color/{color}/year/{year}", hd.FindByColorAndYear())
-		// Get vehicles by brand between years
-		r.Get("/brand/{brand}/between/{start_year}/{end_year}", hd.FindByBrandAndYearRange())
-		// Get average max speed by brand
-		r.Get("/average_speed/brand/{brand}", hd.AverageMaxSpeedByBrand())
-		// Get average capacity by brand
-		r.Get("/average_capacity/brand/{brand}", hd.AverageCapacityByBrand())
-		// Get vehicles by weight range (query)
-		r.Get("/weight", hd.SearchByWeightRange())
-	})
-
-	return
-}
-
-// Run is a method that runs the application
-func (a *ApplicationDefault) Run() (err error) {
-	err = http.ListenAndServe(a.serverAddress, a.router)
-	return
+package application
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"app/internal/handler"
+	"app/internal/index"
+	"app/internal/loader"
+	"app/internal/normalizer"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/platform/web/deprecation"
+	"app/platform/web/metrics"
+	"app/platform/web/openapi"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ConfigApplicationDefault is a struct that represents the configuration for ApplicationDefault
+type ConfigApplicationDefault struct {
+	// Router is the router / multiplexer that will be used by the application
+	Router *chi.Mux
+	// ServerAddress is the address where the server will be listening
+	ServerAddress string
+	// LoaderFilePath is the path to the file that contains the vehicles
+	LoaderFilePath string
+	// LoaderUnits is the system of units of the file that contains the vehicles (metric or imperial)
+	LoaderUnits string
+	// AliasesFilePath is the path to the file that contains the aliases of the string attributes
+	AliasesFilePath string
+	// RegistrationRulesFilePath is the path to the file that contains the rules of the registrations by jurisdiction
+	RegistrationRulesFilePath string
+	// RegistrationJurisdiction is the jurisdiction whose rules normalize and validate the registrations (empty: no rules)
+	RegistrationJurisdiction string
+	// Fleets is a map of fleet name to the file that contains its vehicles, served under /fleets/{fleet}
+	// - the file of LoaderFilePath is the fleet "default", also served by the routes that are not scoped to a fleet
+	Fleets map[string]string
+	// FleetsDir is a directory of files that contain vehicles, each one a fleet named after the file
+	FleetsDir string
+	// MaintenanceDir is the directory where the maintenance records of each fleet are persisted, in <fleet>.jsonl
+	MaintenanceDir string
+	// TelemetryCapacity is the number of readings kept in memory per vehicle
+	TelemetryCapacity int
+	// AlertCapacity is the number of alerts kept in memory per fleet
+	AlertCapacity int
+	// WebhooksFilePath is the path to the file where the webhooks are persisted
+	WebhooksFilePath string
+	// WebhooksAllowPrivateTargets lets the webhooks target loopback, private, link-local and unspecified addresses
+	WebhooksAllowPrivateTargets bool
+	// StreamReplay is the number of events of each fleet kept for the clients that resume a stream
+	StreamReplay int
+	// StreamHeartbeat is the interval of the heartbeats of the streams
+	StreamHeartbeat time.Duration
+	// ChangesTombstones is the number of deleted vehicles of each fleet kept for the change feed
+	ChangesTombstones int
+	// HistoryMaxVersions is the number of versions of a vehicle kept in history, the first one always kept (-1: every version)
+	HistoryMaxVersions int
+	// AuthAPIKeys is a map of static api keys to the principal they authenticate
+	AuthAPIKeys map[string]internal.Principal
+	// AuthJWTSecret is the secret used to verify HS256 bearer tokens
+	AuthJWTSecret string
+	// AuthDisabled lets every request through without credentials, the roles and fleets unchecked
+	// - without it, SetUp fails when neither AuthAPIKeys nor AuthJWTSecret is configured
+	AuthDisabled bool
+	// AuditFilePath is the path to the file where the audit trail is appended
+	AuditFilePath string
+	// AuditMaxBytes is the size after which the audit file is rotated
+	AuditMaxBytes int64
+	// AuditMaxBackups is the number of rotated audit files that are kept
+	AuditMaxBackups int
+	// OpenAPIValidation enables the validation of the requests against the OpenAPI document
+	OpenAPIValidation bool
+	// Deprecations are the policies of the deprecated versions of the api (v1 or v2), whose responses carry the Deprecation and Sunset headers
+	// - the routes without a version prefix are the version v1
+	Deprecations map[string]deprecation.Policy
+}
+
+// NewApplicationDefault is a function that returns a new instance of ApplicationDefault
+func NewApplicationDefault(cfg *ConfigApplicationDefault) *ApplicationDefault {
+	// default values
+	defaultConfig := &ConfigApplicationDefault{
+		Router: chi.NewRouter(),
+		ServerAddress: ":8080",
+		LoaderUnits: "metric",
+		MaintenanceDir: "maintenance",
+		TelemetryCapacity: 10000,
+		AlertCapacity: 1000,
+		WebhooksFilePath: "webhooks.json",
+		StreamReplay: 1000,
+		StreamHeartbeat: 15 * time.Second,
+		ChangesTombstones: 1000,
+		HistoryMaxVersions: 100,
+		AuditFilePath: "audit.jsonl",
+		AuditMaxBytes: 10 << 20,
+		AuditMaxBackups: 5,
+	}
+	if cfg != nil {
+		if cfg.Router != nil {
+			defaultConfig.Router = cfg.Router
+		}
+		if cfg.ServerAddress != "" {
+			defaultConfig.ServerAddress = cfg.ServerAddress
+		}
+		if cfg.LoaderFilePath != "" {
+			defaultConfig.LoaderFilePath = cfg.LoaderFilePath
+		}
+		if cfg.LoaderUnits != "" {
+			defaultConfig.LoaderUnits = cfg.LoaderUnits
+		}
+		if cfg.AliasesFilePath != "" {
+			defaultConfig.AliasesFilePath = cfg.AliasesFilePath
+		}
+		if cfg.RegistrationRulesFilePath != "" {
+			defaultConfig.RegistrationRulesFilePath = cfg.RegistrationRulesFilePath
+		}
+		if cfg.RegistrationJurisdiction != "" {
+			defaultConfig.RegistrationJurisdiction = cfg.RegistrationJurisdiction
+		}
+		if cfg.Fleets != nil {
+			defaultConfig.Fleets = cfg.Fleets
+		}
+		if cfg.FleetsDir != "" {
+			defaultConfig.FleetsDir = cfg.FleetsDir
+		}
+		if cfg.MaintenanceDir != "" {
+			defaultConfig.MaintenanceDir = cfg.MaintenanceDir
+		}
+		if cfg.TelemetryCapacity != 0 {
+			defaultConfig.TelemetryCapacity = cfg.TelemetryCapacity
+		}
+		if cfg.AlertCapacity != 0 {
+			defaultConfig.AlertCapacity = cfg.AlertCapacity
+		}
+		if cfg.WebhooksFilePath != "" {
+			defaultConfig.WebhooksFilePath = cfg.WebhooksFilePath
+		}
+		defaultConfig.WebhooksAllowPrivateTargets = cfg.WebhooksAllowPrivateTargets
+		if cfg.StreamReplay != 0 {
+			defaultConfig.StreamReplay = cfg.StreamReplay
+		}
+		if cfg.StreamHeartbeat != 0 {
+			defaultConfig.StreamHeartbeat = cfg.StreamHeartbeat
+		}
+		if cfg.ChangesTombstones != 0 {
+			defaultConfig.ChangesTombstones = cfg.ChangesTombstones
+		}
+		if cfg.HistoryMaxVersions != 0 {
+			defaultConfig.HistoryMaxVersions = cfg.HistoryMaxVersions
+		}
+		if cfg.AuthAPIKeys != nil {
+			defaultConfig.AuthAPIKeys = cfg.AuthAPIKeys
+		}
+		if cfg.AuthJWTSecret != "" {
+			defaultConfig.AuthJWTSecret = cfg.AuthJWTSecret
+		}
+		defaultConfig.AuthDisabled = cfg.AuthDisabled
+		if cfg.AuditFilePath != "" {
+			defaultConfig.AuditFilePath = cfg.AuditFilePath
+		}
+		if cfg.AuditMaxBytes != 0 {
+			defaultConfig.AuditMaxBytes = cfg.AuditMaxBytes
+		}
+		if cfg.AuditMaxBackups != 0 {
+			defaultConfig.AuditMaxBackups = cfg.AuditMaxBackups
+		}
+		defaultConfig.OpenAPIValidation = cfg.OpenAPIValidation
+		if cfg.Deprecations != nil {
+			defaultConfig.Deprecations = cfg.Deprecations
+		}
+	}
+
+	return &ApplicationDefault{
+		router: defaultConfig.Router,
+		serverAddress: defaultConfig.ServerAddress,
+		loaderFilePath: defaultConfig.LoaderFilePath,
+		loaderUnits: defaultConfig.LoaderUnits,
+		aliasesFilePath: defaultConfig.AliasesFilePath,
+		registrationRulesFilePath: defaultConfig.RegistrationRulesFilePath,
+		registrationJurisdiction: defaultConfig.RegistrationJurisdiction,
+		fleets: defaultConfig.Fleets,
+		fleetsDir: defaultConfig.FleetsDir,
+		maintenanceDir: defaultConfig.MaintenanceDir,
+		telemetryCapacity: defaultConfig.TelemetryCapacity,
+		alertCapacity: defaultConfig.AlertCapacity,
+		webhooksFilePath: defaultConfig.WebhooksFilePath,
+		webhooksAllowPrivateTargets: defaultConfig.WebhooksAllowPrivateTargets,
+		streamReplay: defaultConfig.StreamReplay,
+		streamHeartbeat: defaultConfig.StreamHeartbeat,
+		changesTombstones: defaultConfig.ChangesTombstones,
+		historyMaxVersions: defaultConfig.HistoryMaxVersions,
+		authAPIKeys: defaultConfig.AuthAPIKeys,
+		authJWTSecret: defaultConfig.AuthJWTSecret,
+		authDisabled: defaultConfig.AuthDisabled,
+		auditFilePath: defaultConfig.AuditFilePath,
+		auditMaxBytes: defaultConfig.AuditMaxBytes,
+		auditMaxBackups: defaultConfig.AuditMaxBackups,
+		openAPIValidation: defaultConfig.OpenAPIValidation,
+		deprecations: defaultConfig.Deprecations,
+	}
+}
+
+// ApplicationDefault is a struct that implements the Application interface
+type ApplicationDefault struct {
+	// router is the router / multiplexer that will be used by the application
+	router *chi.Mux
+	// serverAddress is the address where the server will be listening
+	serverAddress string
+	// loaderFilePath is the path to the file that contains the vehicles
+	loaderFilePath string
+	// loaderUnits is the system of units of the file that contains the vehicles
+	loaderUnits string
+	// aliasesFilePath is the path to the file that contains the aliases of the string attributes
+	aliasesFilePath string
+	// registrationRulesFilePath is the path to the file that contains the rules of the registrations by jurisdiction
+	registrationRulesFilePath string
+	// registrationJurisdiction is the jurisdiction whose rules normalize and validate the registrations
+	registrationJurisdiction string
+	// fleets is a map of fleet name to the file that contains its vehicles
+	fleets map[string]string
+	// fleetsDir is a directory of files that contain vehicles, each one a fleet named after the file
+	fleetsDir string
+	// maintenanceDir is the directory where the maintenance records of each fleet are persisted
+	maintenanceDir string
+	// telemetryCapacity is the number of readings kept in memory per vehicle
+	telemetryCapacity int
+	// alertCapacity is the number of alerts kept in memory per fleet
+	alertCapacity int
+	// webhooksFilePath is the path to the file where the webhooks are persisted
+	webhooksFilePath string
+	// webhooksAllowPrivateTargets lets the webhooks target loopback, private, link-local and unspecified addresses
+	webhooksAllowPrivateTargets bool
+	// streamReplay is the number of events of each fleet kept for the clients that resume a stream
+	streamReplay int
+	// streamHeartbeat is the interval of the heartbeats of the streams
+	streamHeartbeat time.Duration
+	// changesTombstones is the number of deleted vehicles of each fleet kept for the change feed
+	changesTombstones int
+	// historyMaxVersions is the number of versions of a vehicle kept in history (-1: every version)
+	historyMaxVersions int
+	// authAPIKeys is a map of static api keys to the principal they authenticate
+	authAPIKeys map[string]internal.Principal
+	// authJWTSecret is the secret used to verify HS256 bearer tokens
+	authJWTSecret string
+	// authDisabled lets every request through without credentials
+	authDisabled bool
+	// auditFilePath is the path to the file where the audit trail is appended
+	auditFilePath string
+	// auditMaxBytes is the size after which the audit file is rotated
+	auditMaxBytes int64
+	// auditMaxBackups is the number of rotated audit files that are kept
+	auditMaxBackups int
+	// openAPIValidation enables the validation of the requests against the OpenAPI document
+	openAPIValidation bool
+	// deprecations are the policies of the deprecated versions of the api
+	deprecations map[string]deprecation.Policy
+	// svWebhook is the service of the webhooks, whose queues are drained on shutdown
+	svWebhook *service.ServiceWebhookDefault
+}
+
+// SetUp is a method that sets up the application
+func (a *ApplicationDefault) SetUp() (err error) {
+	// authentication: credentials are required unless explicitly disabled
+	credentials := len(a.authAPIKeys) > 0 || a.authJWTSecret != ""
+	switch {
+	case !credentials && !a.authDisabled:
+		err = errors.New("application: no credentials configured (api keys or jwt secret), authentication must be explicitly disabled")
+		return
+	case credentials && a.authDisabled:
+		err = errors.New("application: credentials configured with authentication disabled")
+		return
+	}
+	for version := range a.deprecations {
+		if version != "v1" && version != "v2" {
+			err = fmt.Errorf("application: deprecation of unknown api version %q", version)
+			return
+		}
+	}
+
+	// dependencies
+	// - aliases: aliases of the string attributes, shared by the fleets
+	var aliases normalizer.Aliases
+	if a.aliasesFilePath != "" {
+		aliases, err = normalizer.LoadAliasesJSON(a.aliasesFilePath)
+		if err != nil {
+			return
+		}
+	}
+	// - registrations: rules of the registrations of the jurisdiction, shared by the fleets
+	var rr internal.RegistrationRules
+	if a.registrationJurisdiction != "" {
+		var rules normalizer.RegistrationRules
+		rules, err = normalizer.LoadRegistrationRulesJSON(a.registrationRulesFilePath)
+		if err != nil {
+			return
+		}
+		rr, err = normalizer.NewRegistrationRulesRegex(rules, a.registrationJurisdiction)
+		if err != nil {
+			return
+		}
+	}
+	// - units: units of the files that contain the vehicles
+	units, err := internal.ParseUnits(a.loaderUnits)
+	if err != nil {
+		return
+	}
+	// - datasets: the main file and the files of the named fleets
+	datasets, err := a.datasets()
+	if err != nil {
+		return
+	}
+	// - credentials: the fleet of every api key must be one of the datasets
+	for _, p := range a.authAPIKeys {
+		if _, ok := datasets[p.Fleet]; p.Fleet != "" && !ok {
+			err = fmt.Errorf("application: api key of %s for unknown fleet %q", p.Subject, p.Fleet)
+			return
+		}
+	}
+	// - repository: audit trail of the mutations of every fleet
+	rpAudit := repository.NewRepositoryAuditFile(a.auditFilePath, a.auditMaxBytes, a.auditMaxBackups)
+	// - service: service for the audit trail
+	svAudit := service.NewServiceAuditDefault(rpAudit)
+	// - handler: handler for the audit trail
+	hdAudit := handler.NewHandlerAudit(svAudit)
+	// - repository: webhooks, persisted after every mutation, and their deliveries, kept in memory
+	ldWebhook := loader.NewLoaderWebhookJSON(a.webhooksFilePath)
+	dbWebhook, err := ldWebhook.Load()
+	if err != nil {
+		return
+	}
+	rpWebhook := repository.NewRepositoryWebhookMap(dbWebhook, ldWebhook)
+	rpDelivery := repository.NewRepositoryDeliveryMap(1000)
+	// - service: service for the webhooks, the publisher of the events of every fleet
+	svWebhook := service.NewServiceWebhookDefault(rpWebhook, rpDelivery, &service.ConfigServiceWebhookDefault{Encode: handler.EncodeEvent, AllowPrivateTargets: a.webhooksAllowPrivateTargets})
+	a.svWebhook = svWebhook
+	// - fleets: a repository, services and handlers per fleet
+	fleets := make(map[string]*fleet, len(datasets))
+	names := make([]string, 0, len(datasets))
+	for name, path := range datasets {
+		ldMaintenance := loader.NewLoaderMaintenanceJSON(filepath.Join(a.maintenanceDir, name+".jsonl"))
+		fleets[name], err = a.newFleet(name, loader.NewLoaderVehicleJSON(path, units), ldMaintenance, aliases, rr, rpAudit, svWebhook)
+		if err != nil {
+			err = fmt.Errorf("application: fleet %s: %w", name, err)
+			return
+		}
+		names = append(names, name)
+	}
+	// - handler: handler for the fleets
+	hdFleet := handler.NewHandlerFleet(names)
+	// - handler: handler for the webhooks
+	hdWebhook := handler.NewHandlerWebhook(svWebhook, names)
+	// - metrics: usage of the versions of the api
+	requests := metrics.NewCounter("api_requests_total", "Requests to the api by version, method and status code.", "version", "method", "code")
+	// - api: the routes of a version, the routes of each fleet registered by fleetRoutes
+	api := func(rt chi.Router, fleetRoutes func(rt chi.Router, f *fleet)) {
+		// - default fleet: the routes that are not scoped to a fleet
+		rt.Group(func(rt chi.Router) {
+			rt.Use(a.authorizeFleet(func(r *http.Request) string { return internal.DefaultFleet }))
+			fleetRoutes(rt, fleets[internal.DefaultFleet])
+		})
+		rt.Route("/audit", func(r chi.Router) {
+			// Get audit records (query)
+			r.With(a.authorize(internal.RoleAdmin)).Get("/", hdAudit.Find())
+		})
+		rt.Route("/webhooks", func(r chi.Router) {
+			// Get webhooks
+			r.With(a.authorize(internal.RoleAdmin)).Get("/", hdWebhook.List())
+			// Create webhook
+			r.With(a.authorize(internal.RoleAdmin)).Post("/", hdWebhook.Create())
+			// Get webhook by id
+			r.With(a.authorize(internal.RoleAdmin)).Get("/{id}", hdWebhook.FindById())
+			// Delete webhook
+			r.With(a.authorize(internal.RoleAdmin)).Delete("/{id}", hdWebhook.Delete())
+			// Get delivery attempts of webhook
+			r.With(a.authorize(internal.RoleAdmin)).Get("/{id}/deliveries", hdWebhook.Deliveries())
+			// Get undelivered events of webhook
+			r.With(a.authorize(internal.RoleAdmin)).Get("/{id}/dead-letters", hdWebhook.DeadLetters())
+			// Deliver again an undelivered event of webhook
+			r.With(a.authorize(internal.RoleAdmin)).Post("/{id}/dead-letters/{dead_letter_id}/redeliver", hdWebhook.Redeliver())
+		})
+		rt.Route("/fleets", func(rt chi.Router) {
+			// Get the fleets of the caller
+			rt.With(a.authorize(internal.RoleReader)).Get("/", hdFleet.List())
+			// Routes of each fleet: the same as the default fleet, under /fleets/<name>
+			routeFleets(rt, fleets, a.authorizeFleet, fleetRoutes)
+		})
+	}
+
+	// routes
+	// - middlewares
+	a.router.Use(middleware.RequestID)
+	a.router.Use(handler.RequestId)
+	a.router.Use(middleware.Logger)
+	a.router.Use(middleware.Recoverer)
+	// - documentation: public, outside the authenticated group, the operations of the deprecated versions marked
+	deprecated := make([]string, 0, len(a.deprecations))
+	for version := range a.deprecations {
+		deprecated = append(deprecated, version)
+	}
+	doc := handler.OpenAPIDocument(deprecated...)
+	a.router.Get("/openapi.json", openapi.Handler(doc))
+	a.router.Get("/docs", openapi.Viewer(doc.Info.Title, "/openapi.json"))
+	// - endpoints
+	a.router.Group(func(rt chi.Router) {
+		// - authentication: api keys and / or bearer tokens
+		au := a.authenticator()
+		if au != nil {
+			rt.Use(auth.Authenticate(au))
+		}
+		// - validation: requests that do not conform to the document are rejected
+		if a.openAPIValidation {
+			rt.Use(openapi.Validator(doc))
+		}
+		// Get the usage of the versions of the api
+		rt.With(a.authorize(internal.RoleAdmin)).Get("/metrics", metrics.Handler(requests))
+		// - v1: also served without a version prefix, as before the versions, without the deprecation of the version v1
+		rt.Group(func(rt chi.Router) {
+			rt.Use(a.versioned(requests, "unversioned", ""))
+			api(rt, a.fleetRoutes)
+		})
+		rt.Route("/v1", func(rt chi.Router) {
+			rt.Use(a.versioned(requests, "v1", "v1"))
+			api(rt, a.fleetRoutes)
+		})
+		// - v2: the vehicles in the shape of the body, the finders by the filters of the query
+		// the other routes of the vehicles (search, maintenance, reservations, telemetry, ...) are only served by v1
+		rt.Route("/v2", func(rt chi.Router) {
+			rt.Use(a.versioned(requests, "v2", "v2"))
+			api(rt, a.fleetRoutesV2)
+		})
+	})
+
+	return
+}
+
+// fleet is a struct that represents the handlers of a fleet, each fleet with its own repository and services
+type fleet struct {
+	// hd is the handler for vehicles
+	hd *handler.HandlerVehicle
+	// hdV2 is the handler for vehicles in the version 2 of the api
+	hdV2 *handler.HandlerVehicleV2
+	// hdSearch is the handler for the full-text search of vehicles
+	hdSearch *handler.HandlerSearchVehicle
+	// hdRecommend is the handler for the recommendation and the similarity of vehicles
+	hdRecommend *handler.HandlerRecommendVehicle
+	// hdRPC is the JSON-RPC handler for vehicles
+	hdRPC *handler.HandlerRPCVehicle
+	// hdGraphQL is the GraphQL handler for vehicles
+	hdGraphQL *handler.HandlerGraphQLVehicle
+	// hdMaintenance is the handler for the maintenance records
+	hdMaintenance *handler.HandlerMaintenance
+	// hdReservation is the handler for the reservations
+	hdReservation *handler.HandlerReservation
+	// hdTelemetry is the handler for the telemetry and the alerts
+	hdTelemetry *handler.HandlerTelemetry
+	// hdStream is the handler for the live events of the vehicles
+	hdStream *handler.HandlerStreamVehicle
+	// hdChanges is the handler for the feed of the changes of the vehicles
+	hdChanges *handler.HandlerChangesVehicle
+	// hdReload is the handler for the reload of the dataset of the fleet
+	hdReload *handler.HandlerReloadVehicle
+}
+
+// newFleet is a method that loads the vehicles and the maintenance records of a fleet and returns its handlers
+// - ldMaintenance: loader of the maintenance records, also where they are persisted
+// - rr: rules of the registrations (nil: compared as the other string attributes, any format is valid)
+// - pb: publisher of the events of every fleet, besides the stream of the fleet
+func (a *ApplicationDefault) newFleet(name string, ldJSON internal.LoaderVehicle, ldMaintenance *loader.LoaderMaintenanceJSON, aliases normalizer.Aliases, rr internal.RegistrationRules, rpAudit internal.RepositoryAudit, pb internal.PublisherEvent) (f *fleet, err error) {
+	// - normalizer: normalizer of the string attributes, the registrations by their rules
+	var nz internal.Normalizer = normalizer.NewNormalizerAlias(aliases)
+	if rr != nil {
+		nz = normalizer.NewNormalizerRegistration(nz, rr)
+	}
+	// - loader: loader for vehicles, with the registrations in error reported
+	ld := loader.NewLoaderVehicleRegistration(loader.NewLoaderVehicleNormalized(ldJSON, nz), nz, rr, func(err error) {
+		log.Printf("fleet %s: %v", name, err)
+	})
+	// - db: map of vehicles
+	db, err := ld.Load()
+	if err != nil {
+		return
+	}
+	// - index: full-text index over the vehicles
+	ix := index.NewIndexVehicleInverted(nz)
+	// - index: similarity index over the numeric attributes of the vehicles
+	ixSimilar := index.NewIndexVehicleKDTree()
+	// - repository: repository for vehicles, with the indexes kept in sync
+	rpIndexed, err := repository.NewRepositoryVehicleIndexed(repository.NewRepositoryReadVehicleMapWithConfig(db, nz, &repository.ConfigRepositoryReadVehicleMap{MaxVersions: a.historyMaxVersions}), ix, ixSimilar)
+	if err != nil {
+		return
+	}
+	// - service: stream of the events of the fleet, a subscriber can fall 64 events behind
+	svStream := service.NewServiceStreamDefault(nz, a.streamReplay, 64)
+	// - service: feed of the changes of the fleet, reading the whole fleet from the repository on a resync
+	svChanges := service.NewServiceChangesVehicleDefault(rpIndexed, a.changesTombstones)
+	pbFleet := internal.PublishersEvent{pb, svStream, svChanges}
+	// - repository: the mutations audited and published
+	rp := repository.NewRepositoryVehicleEvents(repository.NewRepositoryVehicleAudit(rpIndexed, rpAudit, name), pbFleet, name)
+	// - the load starts the sequence of the feed of changes. It is not a reload: it is not published to the stream nor to the webhooks
+	svChanges.Publish(internal.NewEvent(internal.EventDatasetReloaded, name, time.Now()))
+	// - service: service for vehicles
+	sv := service.NewServiceVehicleDefaultWithConfig(rp, &service.ConfigServiceVehicleDefault{RegistrationRules: rr, Normalizer: nz})
+	// - service: full-text search service for vehicles
+	svSearch := service.NewServiceSearchVehicleDefault(ix, rp)
+	// - service: recommendation and similarity service for vehicles
+	svRecommend := service.NewServiceRecommendVehicleDefault(rp, ixSimilar)
+	// - service: reload of the dataset, with the same loader as the load, audited and published as a single change
+	svReload := service.NewServiceReloadVehicleDefault(ld, rp)
+	// - repository: maintenance records, persisted after every mutation
+	dbMaintenance, err := ldMaintenance.Load()
+	if err != nil {
+		return
+	}
+	rpMaintenance := repository.NewRepositoryMaintenanceMap(dbMaintenance, ldMaintenance)
+	// - service: service for the maintenance records of the vehicles
+	svMaintenance := service.NewServiceMaintenanceDefault(rpMaintenance, rp)
+	// - service: service for the reservations of the vehicles, kept in memory
+	svReservation := service.NewServiceReservationDefault(repository.NewRepositoryReservationMap(nil), rp)
+	// - service: service for the telemetry of the vehicles and its alerts, kept in memory
+	svTelemetry := service.NewServiceTelemetryDefault(repository.NewRepositoryTelemetryRing(a.telemetryCapacity), repository.NewRepositoryAlertMap(a.alertCapacity), rp)
+
+	f = &fleet{
+		// - handler: handler for vehicles
+		hd: handler.NewHandlerVehicle(sv),
+		// - handler: handler for vehicles in the version 2 of the api, over the same service
+		hdV2: handler.NewHandlerVehicleV2(sv),
+		// - handler: handler for the full-text search of vehicles
+		hdSearch: handler.NewHandlerSearchVehicle(svSearch),
+		// - handler: handler for the recommendation and the similarity of vehicles
+		hdRecommend: handler.NewHandlerRecommendVehicle(svRecommend),
+		// - handler: JSON-RPC handler for vehicles, over the same service as the REST handler
+		hdRPC: handler.NewHandlerRPCVehicle(sv),
+		// - handler: GraphQL handler for vehicles, over the same service as the REST handler
+		hdGraphQL: handler.NewHandlerGraphQLVehicle(sv),
+		// - handler: handler for the maintenance records
+		hdMaintenance: handler.NewHandlerMaintenance(svMaintenance),
+		// - handler: handler for the reservations
+		hdReservation: handler.NewHandlerReservation(svReservation),
+		// - handler: handler for the telemetry and the alerts
+		hdTelemetry: handler.NewHandlerTelemetry(svTelemetry),
+		// - handler: handler for the live events of the vehicles, a write can take 10 seconds
+		hdStream: handler.NewHandlerStreamVehicle(svStream, a.streamHeartbeat, 10*time.Second),
+		// - handler: handler for the feed of the changes of the vehicles
+		hdChanges: handler.NewHandlerChangesVehicle(svChanges),
+		// - handler: handler for the reload of the dataset of the fleet
+		hdReload: handler.NewHandlerReloadVehicle(svReload),
+	}
+	return
+}
+
+// fleetRoutes is a method that registers the routes of a fleet in the version 1 of the api
+func (a *ApplicationDefault) fleetRoutes(rt chi.Router, f *fleet) {
+	hd := f.hd
+	rt.Route("/vehicles", func(r chi.Router) {
+		// Get vehicles by color and year
+		r.With(a.authorize(internal.RoleReader)).Get("/color/{color}/year/{year}", hd.FindByColorAndYear())
+		// Get vehicle by registration
+		r.With(a.authorize(internal.RoleReader)).Get("/registration/{registration}", hd.FindByRegistration())
+		// Get vehicles by brand between years
+		r.With(a.authorize(internal.RoleReader)).Get("/brand/{brand}/between/{start_year}/{end_year}", hd.FindByBrandAndYearRange())
+		// Get average max speed by brand
+		r.With(a.authorize(internal.RoleReader)).Get("/average_speed/brand/{brand}", hd.AverageMaxSpeedByBrand())
+		// Get average capacity by brand
+		r.With(a.authorize(internal.RoleReader)).Get("/average_capacity/brand/{brand}", hd.AverageCapacityByBrand())
+		// Get vehicles by weight range (query)
+		r.With(a.authorize(internal.RoleReader)).Get("/weight", hd.SearchByWeightRange())
+		// Get vehicle by id
+		r.With(a.authorize(internal.RoleReader)).Get("/{id}", hd.FindById())
+		// Get versions of vehicle
+		r.With(a.authorize(internal.RoleReader)).Get("/{id}/history", hd.FindHistory())
+		// Create vehicle
+		r.With(a.authorize(internal.RoleEditor)).Post("/", hd.Create())
+		// Update vehicle
+		r.With(a.authorize(internal.RoleEditor)).Put("/{id}", hd.Update())
+		// Restore deleted vehicle
+		r.With(a.authorize(internal.RoleEditor)).Post("/{id}/restore", hd.Restore())
+		a.vehicleRoutes(r, f)
+		a.vehicleRoutesV1(r, f)
+	})
+	a.datasetRoutes(rt, f)
+	a.protocolRoutes(rt, f)
+}
+
+// fleetRoutesV2 is a method that registers the routes of a fleet in the version 2 of the api
+// - the vehicles in the shape of the body, the finders and the averages by the filters of the query instead of path segments
+// - the routes that respond with the vehicles of the version 1 are not served, neither the protocols
+func (a *ApplicationDefault) fleetRoutesV2(rt chi.Router, f *fleet) {
+	hd := f.hdV2
+	rt.Route("/vehicles", func(r chi.Router) {
+		// Get vehicles (query)
+		r.With(a.authorize(internal.RoleReader)).Get("/", hd.Find())
+		// Get average of an attribute (query)
+		r.With(a.authorize(internal.RoleReader)).Get("/average", hd.Average())
+		// Get vehicle by registration
+		r.With(a.authorize(internal.RoleReader)).Get("/registration/{registration}", hd.FindByRegistration())
+		// Get vehicle by id
+		r.With(a.authorize(internal.RoleReader)).Get("/{id}", hd.FindById())
+		// Get versions of vehicle
+		r.With(a.authorize(internal.RoleReader)).Get("/{id}/history", hd.FindHistory())
+		// Create vehicle
+		r.With(a.authorize(internal.RoleEditor)).Post("/", hd.Create())
+		// Update vehicle
+		r.With(a.authorize(internal.RoleEditor)).Put("/{id}", hd.Update())
+		// Restore deleted vehicle
+		r.With(a.authorize(internal.RoleEditor)).Post("/{id}/restore", hd.Restore())
+		a.vehicleRoutes(r, f)
+	})
+	a.datasetRoutes(rt, f)
+}
+
+// datasetRoutes is a method that registers the routes of the dataset of a fleet, the same in every version of the api
+func (a *ApplicationDefault) datasetRoutes(rt chi.Router, f *fleet) {
+	// Reload the vehicles from the dataset
+	rt.With(a.authorize(internal.RoleAdmin)).Post("/dataset/reload", f.hdReload.Reload())
+}
+
+// vehicleRoutes is a method that registers the routes under /vehicles that are the same in every version of the api
+func (a *ApplicationDefault) vehicleRoutes(r chi.Router, f *fleet) {
+	// Get allowed values of the enum attributes
+	r.With(a.authorize(internal.RoleReader)).Get("/enums", f.hd.Enums())
+	// Delete vehicle
+	r.With(a.authorize(internal.RoleEditor)).Delete("/{id}", f.hd.Delete())
+}
+
+// vehicleRoutesV1 is a method that registers the other routes under /vehicles of the version 1 of the api, those that respond with its vehicles
+func (a *ApplicationDefault) vehicleRoutesV1(r chi.Router, f *fleet) {
+	hd, hdSearch, hdRecommend, hdMaintenance, hdReservation, hdTelemetry, hdStream, hdChanges := f.hd, f.hdSearch, f.hdRecommend, f.hdMaintenance, f.hdReservation, f.hdTelemetry, f.hdStream, f.hdChanges
+	// Get vehicles side by side (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/compare", hd.Compare())
+	// Get distribution of an attribute (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/histogram", hd.Histogram())
+	// Get averages of an attribute by fabrication year (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/trends", hd.Trends())
+	// Get vehicles by full-text search (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/search", hdSearch.Search())
+	// Get vehicles that meet requirements, ranked by preferences (body)
+	r.With(a.authorize(internal.RoleReader)).Post("/recommend", hdRecommend.Recommend())
+	// Get vehicles overdue for service (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/maintenance/overdue", hdMaintenance.Overdue())
+	// Get vehicles free for a trip (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/available", hdReservation.Available())
+	// Get live events of the vehicles (server-sent events)
+	r.With(a.authorize(internal.RoleReader)).Get("/events", hdStream.Events())
+	// Get changes of the vehicles since a position (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/changes", hdChanges.Changes())
+	// Get alerts raised by the telemetry (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/alerts", hdTelemetry.FindAlerts())
+	// Acknowledge alert
+	r.With(a.authorize(internal.RoleEditor)).Post("/alerts/{alert_id}/acknowledge", hdTelemetry.Acknowledge())
+	// Get vehicles similar to vehicle (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/{id}/similar", hdRecommend.Similar())
+	// Get maintenance records of vehicle
+	r.With(a.authorize(internal.RoleReader)).Get("/{id}/maintenance", hdMaintenance.FindByVehicleId())
+	// Create maintenance record of vehicle
+	r.With(a.authorize(internal.RoleEditor)).Post("/{id}/maintenance", hdMaintenance.Create())
+	// Get reservations of vehicle
+	r.With(a.authorize(internal.RoleReader)).Get("/{id}/reservations", hdReservation.FindByVehicleId())
+	// Book vehicle
+	r.With(a.authorize(internal.RoleEditor)).Post("/{id}/reservations", hdReservation.Book())
+	// Cancel reservation of vehicle
+	r.With(a.authorize(internal.RoleEditor)).Delete("/{id}/reservations/{reservation_id}", hdReservation.Cancel())
+	// Get telemetry of vehicle (query)
+	r.With(a.authorize(internal.RoleReader)).Get("/{id}/telemetry", hdTelemetry.FindRange())
+	// Ingest telemetry of vehicle (a reading or a NDJSON batch)
+	r.With(a.authorize(internal.RoleEditor)).Post("/{id}/telemetry", hdTelemetry.Ingest())
+}
+
+// protocolRoutes is a method that registers the routes of the other protocols over the vehicles of a fleet in the version 1 of the api
+func (a *ApplicationDefault) protocolRoutes(rt chi.Router, f *fleet) {
+	// JSON-RPC 2.0 methods of the vehicle service (mutations require editor)
+	rt.With(a.authorize(internal.RoleReader)).Post("/rpc", f.hdRPC.RPC())
+	// GraphQL queries over the vehicles (query string or body)
+	rt.With(a.authorize(internal.RoleReader)).Get("/graphql", f.hdGraphQL.GraphQL())
+	rt.With(a.authorize(internal.RoleReader)).Post("/graphql", f.hdGraphQL.GraphQL())
+}
+
+// versioned is a method that returns the middleware of the routes of a version of the api
+// - the requests are counted under the label
+// - the responses of a deprecated version carry the headers of its policy (version empty: none, as the routes without a version prefix)
+func (a *ApplicationDefault) versioned(requests *metrics.Counter, label string, version string) func(http.Handler) http.Handler {
+	count := metrics.Count(requests, label)
+	p, ok := a.deprecations[version]
+	if !ok {
+		return count
+	}
+	headers := deprecation.Headers(p)
+	return func(next http.Handler) http.Handler { return count(headers(next)) }
+}
+
+// datasets is a method that returns the file of each fleet: the main file as the default fleet and the named fleets
+func (a *ApplicationDefault) datasets() (datasets map[string]string, err error) {
+	datasets = map[string]string{internal.DefaultFleet: a.loaderFilePath}
+	if a.fleetsDir != "" {
+		var dir map[string]string
+		dir, err = LoadFleetsDir(a.fleetsDir)
+		if err != nil {
+			return
+		}
+		for name, path := range dir {
+			datasets[name] = path
+		}
+	}
+	for name, path := range a.fleets {
+		datasets[name] = path
+	}
+	if datasets[internal.DefaultFleet] != a.loaderFilePath {
+		err = fmt.Errorf("application: fleet name %q is reserved for the main file", internal.DefaultFleet)
+		return
+	}
+	return
+}
+
+// authenticator is a method that returns the authenticator built from the configuration
+// - nil means that authentication is disabled
+func (a *ApplicationDefault) authenticator() internal.Authenticator {
+	if a.authDisabled {
+		return nil
+	}
+	var authenticators []internal.Authenticator
+	if len(a.authAPIKeys) > 0 {
+		authenticators = append(authenticators, auth.NewAuthenticatorAPIKey(a.authAPIKeys))
+	}
+	if a.authJWTSecret != "" {
+		authenticators = append(authenticators, auth.NewAuthenticatorJWT([]byte(a.authJWTSecret)))
+	}
+	return auth.NewAuthenticatorChain(authenticators...)
+}
+
+// authorize is a method that returns the middleware that requires the given role on a route
+// - when authentication is disabled every request is let through
+func (a *ApplicationDefault) authorize(role internal.Role) func(http.Handler) http.Handler {
+	if a.authDisabled {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return auth.Authorize(role)
+}
+
+// authorizeFleet is a method that returns the middleware that requires access to the fleet of the request
+// - when authentication is disabled every request is let through
+func (a *ApplicationDefault) authorizeFleet(fleet func(r *http.Request) string) func(http.Handler) http.Handler {
+	if a.authDisabled {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return auth.AuthorizeFleet(fleet)
+}
+
+// Run is a method that runs the application
+// - on SIGINT or SIGTERM the server stops accepting requests, lets the ones in progress end and drains the deliveries of the webhooks
+func (a *ApplicationDefault) Run() (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: a.serverAddress, Handler: a.router}
+	errs := make(chan error, 1)
+	go func() { errs <- srv.ListenAndServe() }()
+	select {
+	case err = <-errs:
+		return
+	case <-ctx.Done():
+	}
+
+	// shutdown
+	ctxShutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = srv.Shutdown(ctxShutdown)
+	if a.svWebhook != nil {
+		a.svWebhook.Close()
+	}
+	return
 }
\ No newline at end of file
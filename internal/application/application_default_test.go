@@ -0,0 +1,348 @@
+package application_test
+
+import (
+	"app/internal"
+	"app/internal/application"
+	"app/internal/handler"
+	"app/platform/web/deprecation"
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ApplicationDefault.SetUp
+func TestApplicationDefault_SetUp(t *testing.T) {
+	t.Run("case 01: the OpenAPI document covers exactly the registered routes", func(t *testing.T) {
+		// arrange
+		rt := chi.NewRouter()
+		app := application.NewApplicationDefault(&application.ConfigApplicationDefault{
+			Router:          rt,
+			AuthDisabled:    true,
+			LoaderFilePath:  "../../docs/db/vehicles_100.json",
+			AliasesFilePath: "../../docs/db/aliases.json",
+			MaintenanceDir:  t.TempDir(),
+			AuditFilePath:   filepath.Join(t.TempDir(), "audit.jsonl"),
+		})
+		require.NoError(t, app.SetUp())
+
+		// act
+		var routes []string
+		err := chi.Walk(rt, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			if route != "/" {
+				route = strings.TrimSuffix(route, "/")
+			}
+			// the routes of each fleet are registered under its name, documented under the {fleet} parameter
+			route = strings.Replace(route, "/fleets/"+internal.DefaultFleet, "/fleets/{fleet}", 1)
+			routes = append(routes, method+" "+route)
+			return nil
+		})
+		require.NoError(t, err)
+
+		var documented []string
+		for path, item := range handler.OpenAPIDocument().Paths {
+			for _, method := range item.Methods() {
+				documented = append(documented, method+" "+path)
+			}
+		}
+
+		// assert
+		sort.Strings(routes)
+		sort.Strings(documented)
+		require.Equal(t, documented, routes)
+	})
+
+	t.Run("case 02: a key of a fleet only reaches the routes of its fleet", func(t *testing.T) {
+		// arrange
+		rt := chi.NewRouter()
+		app := application.NewApplicationDefault(&application.ConfigApplicationDefault{
+			Router:          rt,
+			LoaderFilePath:  "../../docs/db/vehicles_100.json",
+			AliasesFilePath: "../../docs/db/aliases.json",
+			Fleets:          map[string]string{"north": "../../docs/db/vehicles_100.json"},
+			AuthAPIKeys: map[string]internal.Principal{
+				"operator": {Subject: "operator", Role: internal.RoleEditor},
+				"tenant":   {Subject: "tenant", Role: internal.RoleEditor, Fleet: "north"},
+			},
+			MaintenanceDir: t.TempDir(),
+			AuditFilePath:  filepath.Join(t.TempDir(), "audit.jsonl"),
+		})
+		require.NoError(t, app.SetUp())
+		status := func(method, key, path string) int {
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set("X-API-Key", key)
+			res := httptest.NewRecorder()
+			rt.ServeHTTP(res, req)
+			return res.Code
+		}
+
+		// act
+		tenantOwn := status(http.MethodDelete, "tenant", "/fleets/north/vehicles/1")
+		tenantOther := status(http.MethodGet, "tenant", "/fleets/default/vehicles/1")
+		tenantUnscoped := status(http.MethodGet, "tenant", "/vehicles/1")
+		operatorDeleted := status(http.MethodGet, "operator", "/fleets/north/vehicles/1")
+		operatorDefault := status(http.MethodGet, "operator", "/vehicles/1")
+		operatorUnknown := status(http.MethodGet, "operator", "/fleets/south/vehicles/1")
+		req := httptest.NewRequest(http.MethodGet, "/fleets/north/trucks", nil)
+		req.Header.Set("X-API-Key", "operator")
+		operatorRoute := httptest.NewRecorder()
+		rt.ServeHTTP(operatorRoute, req)
+
+		// assert
+		require.Equal(t, http.StatusNoContent, tenantOwn)
+		require.Equal(t, http.StatusForbidden, tenantOther)
+		require.Equal(t, http.StatusForbidden, tenantUnscoped)
+		require.Equal(t, http.StatusNotFound, operatorDeleted)
+		require.Equal(t, http.StatusOK, operatorDefault)
+		require.Equal(t, http.StatusNotFound, operatorUnknown)
+		require.Equal(t, http.StatusNotFound, operatorRoute.Code)
+		require.JSONEq(t, `{"type": "about:blank", "title": "Not Found", "status": 404, "detail": "route not found"}`, operatorRoute.Body.String())
+	})
+
+	t.Run("case 03: the event stream replays the changes after the last event id", func(t *testing.T) {
+		// arrange
+		rt := chi.NewRouter()
+		app := application.NewApplicationDefault(&application.ConfigApplicationDefault{
+			Router:           rt,
+			AuthDisabled:     true,
+			LoaderFilePath:   "../../docs/db/vehicles_100.json",
+			AliasesFilePath:  "../../docs/db/aliases.json",
+			MaintenanceDir:   t.TempDir(),
+			AuditFilePath:    filepath.Join(t.TempDir(), "audit.jsonl"),
+			WebhooksFilePath: filepath.Join(t.TempDir(), "webhooks.json"),
+		})
+		require.NoError(t, app.SetUp())
+		srv := httptest.NewServer(rt)
+		defer srv.Close()
+		for _, path := range []string{"/vehicles/1", "/vehicles/2"} {
+			req, err := http.NewRequest(http.MethodDelete, srv.URL+path, nil)
+			require.NoError(t, err)
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+			require.Equal(t, http.StatusNoContent, res.StatusCode)
+		}
+
+		// act
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/vehicles/events", nil)
+		require.NoError(t, err)
+		req.Header.Set("Last-Event-ID", "1")
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		var lines []string
+		sc := bufio.NewScanner(res.Body)
+		for sc.Scan() && sc.Text() != ": connected" {
+			lines = append(lines, sc.Text())
+		}
+
+		// assert
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+		require.Len(t, lines, 4)
+		require.Equal(t, "id: 2", lines[0])
+		require.Equal(t, "event: vehicle.deleted", lines[1])
+		require.True(t, strings.HasPrefix(lines[2], "data: {"))
+		require.Contains(t, lines[2], `"Id":2`)
+	})
+
+	t.Run("case 04: v1 keeps the responses of the routes without prefix, only /v1 deprecated, and v2 serves only the new shapes", func(t *testing.T) {
+		// arrange
+		rt := chi.NewRouter()
+		app := application.NewApplicationDefault(&application.ConfigApplicationDefault{
+			Router:          rt,
+			AuthDisabled:    true,
+			LoaderFilePath:  "../../docs/db/vehicles_100.json",
+			AliasesFilePath: "../../docs/db/aliases.json",
+			MaintenanceDir:  t.TempDir(),
+			AuditFilePath:   filepath.Join(t.TempDir(), "audit.jsonl"),
+			Deprecations: map[string]deprecation.Policy{
+				"v1": {Since: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Sunset: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		})
+		require.NoError(t, app.SetUp())
+		get := func(path string) *httptest.ResponseRecorder {
+			res := httptest.NewRecorder()
+			rt.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+			return res
+		}
+
+		// act
+		unversioned := get("/vehicles/1")
+		v1 := get("/v1/vehicles/1")
+		v2 := get("/v2/vehicles/1")
+		v2PathFinder := get("/v2/vehicles/color/red/year/2008")
+		v2Maintenance := get("/v2/vehicles/1/maintenance")
+		v2Enums := get("/v2/vehicles/enums")
+		v2Filter := get("/v2/fleets/default/vehicles?brand=Hummer&year_from=2008&year_to=2008")
+		metrics := get("/metrics")
+
+		// assert
+		require.Equal(t, http.StatusOK, unversioned.Code)
+		require.Equal(t, unversioned.Body.String(), v1.Body.String())
+		require.Contains(t, v1.Body.String(), `"FabricationYear":2008`)
+		require.Equal(t, "@1767225600", v1.Header().Get("Deprecation"))
+		require.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", v1.Header().Get("Sunset"))
+		require.Empty(t, unversioned.Header().Values("Deprecation"))
+		require.Empty(t, unversioned.Header().Values("Sunset"))
+		require.Equal(t, http.StatusOK, v2.Code)
+		require.Contains(t, v2.Body.String(), `"year":2008`)
+		require.Contains(t, v2.Body.String(), `"units":{"system":"metric","mass":"kg","length":"cm","speed":"km/h","distance":"km"}`)
+		require.Empty(t, v2.Header().Values("Deprecation"))
+		require.Equal(t, http.StatusNotFound, v2PathFinder.Code)
+		require.Equal(t, http.StatusNotFound, v2Maintenance.Code)
+		require.Equal(t, http.StatusOK, v2Enums.Code)
+		require.Equal(t, http.StatusOK, v2Filter.Code)
+		require.True(t, strings.HasPrefix(v2Filter.Body.String(), `{"data":[{"id":1,`))
+		require.Contains(t, metrics.Body.String(), `api_requests_total{version="unversioned",method="GET",code="200"} 1`)
+		require.Contains(t, metrics.Body.String(), `api_requests_total{version="v1",method="GET",code="200"} 1`)
+		require.Contains(t, metrics.Body.String(), `api_requests_total{version="v2",method="GET",code="200"} 3`)
+		require.Contains(t, metrics.Body.String(), `api_requests_total{version="v2",method="GET",code="404"} 2`)
+	})
+
+	t.Run("case 05: the setup fails without credentials unless authentication is explicitly disabled", func(t *testing.T) {
+		// arrange
+		cfg := func(disabled bool, keys map[string]internal.Principal) *application.ConfigApplicationDefault {
+			return &application.ConfigApplicationDefault{
+				Router:         chi.NewRouter(),
+				LoaderFilePath: "../../docs/db/vehicles_100.json",
+				MaintenanceDir: t.TempDir(),
+				AuditFilePath:  filepath.Join(t.TempDir(), "audit.jsonl"),
+				AuthAPIKeys:    keys,
+				AuthDisabled:   disabled,
+			}
+		}
+		keys := map[string]internal.Principal{"k": {Subject: "k", Role: internal.RoleReader}}
+
+		// act
+		errNone := application.NewApplicationDefault(cfg(false, nil)).SetUp()
+		errBoth := application.NewApplicationDefault(cfg(true, keys)).SetUp()
+		errDisabled := application.NewApplicationDefault(cfg(true, nil)).SetUp()
+
+		// assert
+		require.Error(t, errNone)
+		require.Error(t, errBoth)
+		require.NoError(t, errDisabled)
+	})
+
+	t.Run("case 06: the setup fails with an api key of a fleet that is not loaded", func(t *testing.T) {
+		// arrange
+		app := application.NewApplicationDefault(&application.ConfigApplicationDefault{
+			Router:         chi.NewRouter(),
+			LoaderFilePath: "../../docs/db/vehicles_100.json",
+			Fleets:         map[string]string{"north": "../../docs/db/vehicles_100.json"},
+			MaintenanceDir: t.TempDir(),
+			AuditFilePath:  filepath.Join(t.TempDir(), "audit.jsonl"),
+			AuthAPIKeys: map[string]internal.Principal{
+				"tenant": {Subject: "tenant", Role: internal.RoleEditor, Fleet: "south"},
+			},
+		})
+
+		// act
+		err := app.SetUp()
+
+		// assert
+		require.ErrorContains(t, err, `unknown fleet "south"`)
+	})
+
+	t.Run("case 07: a dataset whose registrations are not in the formats of the jurisdiction is loaded, every duplicate found", func(t *testing.T) {
+		// arrange
+		newApp := func(jurisdiction string, rt *chi.Mux) *application.ApplicationDefault {
+			return application.NewApplicationDefault(&application.ConfigApplicationDefault{
+				Router:                    rt,
+				LoaderFilePath:            "../../docs/db/vehicles_100.json",
+				RegistrationRulesFilePath: "../../docs/db/registrations.json",
+				RegistrationJurisdiction:  jurisdiction,
+				MaintenanceDir:            t.TempDir(),
+				AuditFilePath:             filepath.Join(t.TempDir(), "audit.jsonl"),
+				AuthDisabled:              true,
+			})
+		}
+		rt := chi.NewRouter()
+
+		// act
+		errLegacy := newApp("legacy", chi.NewRouter()).SetUp()
+		errAR := newApp("ar", rt).SetUp()
+		res := httptest.NewRecorder()
+		rt.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/vehicles/registration/0", nil))
+
+		// assert
+		require.NoError(t, errLegacy)
+		require.NoError(t, errAR)
+		require.Equal(t, http.StatusOK, res.Code)
+		var body struct {
+			Data map[string]any `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+		require.Len(t, body.Data, 3)
+		for _, id := range []string{"1", "7", "67"} {
+			require.Contains(t, body.Data, id)
+		}
+	})
+	t.Run("case 08: the reload replaces the changes by the dataset and is published to the stream of the fleet", func(t *testing.T) {
+		// arrange
+		dataset, err := os.ReadFile("../../docs/db/vehicles_100.json")
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "vehicles.json")
+		require.NoError(t, os.WriteFile(path, dataset, 0o644))
+		rt := chi.NewRouter()
+		app := application.NewApplicationDefault(&application.ConfigApplicationDefault{
+			Router:           rt,
+			AuthDisabled:     true,
+			LoaderFilePath:   path,
+			AliasesFilePath:  "../../docs/db/aliases.json",
+			MaintenanceDir:   t.TempDir(),
+			AuditFilePath:    filepath.Join(t.TempDir(), "audit.jsonl"),
+			WebhooksFilePath: filepath.Join(t.TempDir(), "webhooks.json"),
+		})
+		require.NoError(t, app.SetUp())
+		srv := httptest.NewServer(rt)
+		defer srv.Close()
+		req, err := http.NewRequest(http.MethodDelete, srv.URL+"/vehicles/1", nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		events, err := http.Get(srv.URL + "/vehicles/events")
+		require.NoError(t, err)
+		defer events.Body.Close()
+		sc := bufio.NewScanner(events.Body)
+		for sc.Scan() && sc.Text() != ": connected" {
+		}
+
+		// act
+		res, err = http.Post(srv.URL+"/dataset/reload", "application/json", nil)
+		require.NoError(t, err)
+		body, errBody := io.ReadAll(res.Body)
+		res.Body.Close()
+		var lines []string
+		for sc.Scan() && (len(lines) == 0 || sc.Text() != "") {
+			if sc.Text() != "" {
+				lines = append(lines, sc.Text())
+			}
+		}
+		restored, err := http.Get(srv.URL + "/vehicles/1")
+		require.NoError(t, err)
+		restored.Body.Close()
+
+		// assert
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, errBody)
+		require.JSONEq(t, `{"message": "dataset reloaded", "data": {"created": 1, "updated": 0, "deleted": 0}}`, string(body))
+		require.Len(t, lines, 3)
+		require.Equal(t, "event: dataset.reloaded", lines[1])
+		require.Contains(t, lines[2], `"vehicle":null`)
+		require.Equal(t, http.StatusOK, restored.StatusCode)
+	})
+}
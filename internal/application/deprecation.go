@@ -0,0 +1,34 @@
+package application
+
+import (
+	"app/platform/web/deprecation"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ParseDeprecation is a function that parses the deprecation policy of a version of the api
+// - since and sunset: RFC 3339 instants, sunset optional and after since
+// - link: absolute url of the documentation of the migration, optional
+func ParseDeprecation(since string, sunset string, link string) (p deprecation.Policy, err error) {
+	p.Since, err = time.Parse(time.RFC3339, since)
+	if err != nil {
+		err = fmt.Errorf("application: invalid deprecation instant %q", since)
+		return
+	}
+	if sunset != "" {
+		p.Sunset, err = time.Parse(time.RFC3339, sunset)
+		if err != nil || !p.Sunset.After(p.Since) {
+			err = fmt.Errorf("application: invalid sunset instant %q", sunset)
+			return
+		}
+	}
+	if link != "" {
+		if u, errURL := url.Parse(link); errURL != nil || !u.IsAbs() {
+			err = fmt.Errorf("application: invalid deprecation link %q", link)
+			return
+		}
+		p.Link = link
+	}
+	return
+}
@@ -0,0 +1,83 @@
+package application
+
+import (
+	"app/platform/web/response"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// fleetName is the pattern of the names of the fleets, usable as a path segment
+var fleetName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ParseFleets is a function that parses a list of named datasets
+// - format: comma separated list of name=path
+func ParseFleets(spec string) (fleets map[string]string, err error) {
+	fleets = make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, path, found := strings.Cut(entry, "=")
+		if !found || path == "" {
+			err = fmt.Errorf("application: invalid fleet entry %q", entry)
+			return
+		}
+		if !fleetName.MatchString(name) {
+			err = fmt.Errorf("application: invalid fleet name %q", name)
+			return
+		}
+		fleets[name] = path
+	}
+	return
+}
+
+// LoadFleetsDir is a function that returns the datasets of a directory: each JSON file is a fleet named after the file
+// - e.g. fleets/north.json is the fleet north
+func LoadFleetsDir(dir string) (fleets map[string]string, err error) {
+	if _, err = os.Stat(dir); err != nil {
+		return
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return
+	}
+
+	fleets = make(map[string]string, len(paths))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if !fleetName.MatchString(name) {
+			err = fmt.Errorf("application: invalid fleet name %q (file %s)", name, path)
+			return
+		}
+		fleets[name] = path
+	}
+	return
+}
+
+// routeFleets is a function that registers the routes of each fleet under /<name>, the same routes for every fleet
+// - authorizeFleet: middleware that requires access to the fleet of the request, the name of the fleet of the routes
+// - the requests to a fleet that does not exist are rejected with 404
+func routeFleets(rt chi.Router, fleets map[string]*fleet, authorizeFleet func(fleet func(r *http.Request) string) func(http.Handler) http.Handler, routes func(rt chi.Router, f *fleet)) {
+	for name, f := range fleets {
+		name, f := name, f
+		rt.Route("/"+name, func(rt chi.Router) {
+			rt.Use(authorizeFleet(func(r *http.Request) string { return name }))
+			routes(rt, f)
+			// - the routes that do not exist in a fleet that does
+			rt.NotFound(func(w http.ResponseWriter, r *http.Request) {
+				response.Problem(w, http.StatusNotFound, "route not found")
+			})
+		})
+	}
+	rt.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		response.Problem(w, http.StatusNotFound, "fleet not found")
+	})
+}
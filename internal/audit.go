@@ -0,0 +1,209 @@
+package internal
+
+import "time"
+
+// AuditAction is a type that represents the kind of mutation recorded in the audit trail
+type AuditAction string
+
+const (
+	// AuditActionCreated is the action of a vehicle that was added
+	AuditActionCreated AuditAction = "created"
+	// AuditActionUpdated is the action of a vehicle that was modified
+	AuditActionUpdated AuditAction = "updated"
+	// AuditActionDeleted is the action of a vehicle that was removed
+	AuditActionDeleted AuditAction = "deleted"
+	// AuditActionRestored is the action of a deleted vehicle that was restored
+	AuditActionRestored AuditAction = "restored"
+	// AuditActionReloaded is the action of a fleet reloaded from its dataset, without vehicle id nor changes
+	AuditActionReloaded AuditAction = "reloaded"
+)
+
+// AuditStatus is a type that represents the outcome of a recorded mutation
+type AuditStatus string
+
+const (
+	// AuditStatusPending is the status of a mutation recorded before it is done, whose outcome is not recorded yet
+	AuditStatusPending AuditStatus = "pending"
+	// AuditStatusCommitted is the status of a mutation that was done
+	AuditStatusCommitted AuditStatus = "committed"
+	// AuditStatusAborted is the status of a mutation that failed
+	AuditStatusAborted AuditStatus = "aborted"
+)
+
+// AuditChange is a struct that represents the change of a single field
+type AuditChange struct {
+	// Field is the name of the field that changed
+	Field string
+	// Before is the value before the mutation (nil for created vehicles)
+	Before any
+	// After is the value after the mutation (nil for deleted vehicles)
+	After any
+}
+
+// AuditRecord is a struct that represents an entry of the audit trail
+type AuditRecord struct {
+	// Id is the id of the mutation, shared by its pending record and the record of its outcome
+	Id string
+	// Status is the outcome of the mutation
+	Status AuditStatus
+	// Timestamp is the instant of the mutation
+	Timestamp time.Time
+	// Actor is the subject of the principal that made the mutation
+	Actor string
+	// Fleet is the fleet of the mutated vehicle
+	Fleet string
+	// RequestId is the id of the request that made the mutation
+	RequestId string
+	// Action is the kind of mutation
+	Action AuditAction
+	// VehicleId is the id of the mutated vehicle
+	VehicleId int
+	// Changes are the fields that changed
+	Changes []AuditChange
+}
+
+// AuditChangeJSON is a struct that represents the change of a field in JSON format
+type AuditChangeJSON struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// AuditRecordJSON is a struct that represents an audit record in JSON format, as stored in the trail and served by the api
+type AuditRecordJSON struct {
+	Id        string            `json:"id"`
+	Status    string            `json:"status"`
+	Timestamp time.Time         `json:"timestamp"`
+	Actor     string            `json:"actor"`
+	Fleet     string            `json:"fleet"`
+	RequestId string            `json:"request_id"`
+	Action    string            `json:"action"`
+	VehicleId int               `json:"vehicle_id"`
+	Changes   []AuditChangeJSON `json:"changes"`
+}
+
+// NewAuditRecordJSON is a function that serializes an audit record
+func NewAuditRecordJSON(rc AuditRecord) AuditRecordJSON {
+	recordJSON := AuditRecordJSON{
+		Id:        rc.Id,
+		Status:    string(rc.Status),
+		Timestamp: rc.Timestamp.UTC(),
+		Actor:     rc.Actor,
+		Fleet:     rc.Fleet,
+		RequestId: rc.RequestId,
+		Action:    string(rc.Action),
+		VehicleId: rc.VehicleId,
+		Changes:   make([]AuditChangeJSON, 0, len(rc.Changes)),
+	}
+	for _, c := range rc.Changes {
+		recordJSON.Changes = append(recordJSON.Changes, AuditChangeJSON{Field: c.Field, Before: c.Before, After: c.After})
+	}
+	return recordJSON
+}
+
+// AuditRecord is a method that deserializes the audit record
+// - the records written before the statuses are committed
+func (j AuditRecordJSON) AuditRecord() (rc AuditRecord) {
+	rc = AuditRecord{
+		Id:        j.Id,
+		Status:    AuditStatus(j.Status),
+		Timestamp: j.Timestamp,
+		Actor:     j.Actor,
+		Fleet:     j.Fleet,
+		RequestId: j.RequestId,
+		Action:    AuditAction(j.Action),
+		VehicleId: j.VehicleId,
+	}
+	if rc.Status == "" {
+		rc.Status = AuditStatusCommitted
+	}
+	for _, c := range j.Changes {
+		rc.Changes = append(rc.Changes, AuditChange{Field: c.Field, Before: c.Before, After: c.After})
+	}
+	return
+}
+
+// AuditQuery is a struct that represents a query over the audit trail
+// - zero values are not used as filters
+type AuditQuery struct {
+	// VehicleId is the id of the mutated vehicle
+	VehicleId int
+	// Actor is the subject that made the mutation
+	Actor string
+	// Fleet is the fleet of the mutated vehicle
+	Fleet string
+	// Since is the instant from which records are returned
+	Since time.Time
+	// Offset is the number of matching records skipped
+	Offset int
+	// Limit is the maximum number of records returned (0: every record)
+	Limit int
+}
+
+// Match is a method that returns true if the record satisfies the query
+func (q AuditQuery) Match(rc AuditRecord) bool {
+	if q.VehicleId != 0 && rc.VehicleId != q.VehicleId {
+		return false
+	}
+	if q.Actor != "" && rc.Actor != q.Actor {
+		return false
+	}
+	if q.Fleet != "" && rc.Fleet != q.Fleet {
+		return false
+	}
+	if !q.Since.IsZero() && rc.Timestamp.Before(q.Since) {
+		return false
+	}
+	return true
+}
+
+// DiffVehicles is a function that returns the fields that differ between two versions of a vehicle
+// - before is nil for created (or restored) vehicles, after is nil for deleted vehicles
+func DiffVehicles(before *Vehicle, after *Vehicle) (c []AuditChange) {
+	fields := func(v *Vehicle) map[string]any {
+		if v == nil {
+			return nil
+		}
+		return VehicleFields(v.VehicleAttributes)
+	}
+	b, a := fields(before), fields(after)
+
+	for _, name := range VehicleFieldNames {
+		var valueBefore, valueAfter any
+		if b != nil {
+			valueBefore = b[name]
+		}
+		if a != nil {
+			valueAfter = a[name]
+		}
+		if valueBefore != valueAfter {
+			c = append(c, AuditChange{Field: name, Before: valueBefore, After: valueAfter})
+		}
+	}
+	return
+}
+
+// VehicleFieldNames are the names of the vehicle attributes, in the same order and with the same names as the JSON dataset
+var VehicleFieldNames = []string{
+	"brand", "model", "registration", "color", "year", "passengers",
+	"max_speed", "fuel_type", "transmission", "weight", "height", "length", "width",
+}
+
+// VehicleFields is a function that returns the attributes of a vehicle by field name
+func VehicleFields(a VehicleAttributes) map[string]any {
+	return map[string]any{
+		"brand":        a.Brand,
+		"model":        a.Model,
+		"registration": a.Registration,
+		"color":        a.Color,
+		"year":         a.FabricationYear,
+		"passengers":   a.Capacity,
+		"max_speed":    a.MaxSpeed,
+		"fuel_type":    string(a.FuelType),
+		"transmission": string(a.Transmission),
+		"weight":       a.Weight,
+		"height":       a.Height,
+		"length":       a.Length,
+		"width":        a.Width,
+	}
+}
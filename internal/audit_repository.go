@@ -0,0 +1,24 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrRepositoryAuditAppend is an error that represents a record that could not be appended
+	ErrRepositoryAuditAppend = errors.New("repository: audit record could not be appended")
+)
+
+// RepositoryAudit is an interface that represents an append-only store of audit records
+type RepositoryAudit interface {
+	// Append is a method that adds a record at the end of the trail
+	Append(rc AuditRecord) (err error)
+
+	// AppendCommit is a method that adds the record of a mutation, commits the mutation and adds the record of its outcome
+	// - the record is written as pending before commit is called; if it cannot be written, commit is not called
+	// - commit returns the record as committed (ids and canonical values are only known after the mutation), written with the id of the pending one
+	// - if commit fails, an aborted record is written and the error of commit is returned. The trail is never rewritten
+	AppendCommit(rc AuditRecord, commit func() (AuditRecord, error)) (err error)
+
+	// Find is a method that returns the records that match the query, oldest first
+	// - a mutation is returned once, as committed or, while its outcome is not recorded, as pending. Aborted mutations are not returned
+	Find(query AuditQuery) (rc []AuditRecord, err error)
+}
@@ -0,0 +1,7 @@
+package internal
+
+// ServiceAudit is an interface that represents an audit service
+type ServiceAudit interface {
+	// Find is a method that returns the records that match the query, oldest first
+	Find(query AuditQuery) (rc []AuditRecord, err error)
+}
@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrAuthenticatorMissingCredentials is an error that represents a request without credentials
+	ErrAuthenticatorMissingCredentials = errors.New("authenticator: missing credentials")
+	// ErrAuthenticatorInvalidCredentials is an error that represents a request with invalid credentials
+	ErrAuthenticatorInvalidCredentials = errors.New("authenticator: invalid credentials")
+	// ErrAuthenticatorInvalidRole is an error that represents an unknown role
+	ErrAuthenticatorInvalidRole = errors.New("authenticator: invalid role")
+)
+
+// Role is a type that represents the role of a principal
+// - roles are hierarchical: admin includes editor, editor includes reader
+type Role string
+
+const (
+	// RoleReader is the role that can read the fleet
+	RoleReader Role = "reader"
+	// RoleEditor is the role that can read and modify the fleet
+	RoleEditor Role = "editor"
+	// RoleAdmin is the role that can do everything
+	RoleAdmin Role = "admin"
+)
+
+// roleLevels is the level of each role in the hierarchy
+var roleLevels = map[Role]int{
+	RoleReader: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// ParseRole is a function that returns the role that matches the given name
+func ParseRole(name string) (r Role, err error) {
+	r = Role(name)
+	if _, ok := roleLevels[r]; !ok {
+		err = fmt.Errorf("%w: %q", ErrAuthenticatorInvalidRole, name)
+		return
+	}
+	return
+}
+
+// Includes is a method that returns true if the role grants the permissions of the other role
+func (r Role) Includes(other Role) bool {
+	level, ok := roleLevels[r]
+	if !ok {
+		return false
+	}
+	return level >= roleLevels[other]
+}
+
+// Principal is a struct that represents an authenticated caller
+type Principal struct {
+	// Subject is the identifier of the caller (e.g. the key name or the token subject)
+	Subject string
+	// Role is the role granted to the caller
+	Role Role
+	// Fleet is the only fleet the caller can access (empty: every fleet)
+	Fleet string
+}
+
+// CanAccessFleet is a method that returns true if the principal can access the fleet
+func (p Principal) CanAccessFleet(fleet string) bool {
+	return p.Fleet == "" || p.Fleet == fleet
+}
+
+// Authenticator is an interface that represents an authentication method
+type Authenticator interface {
+	// Authenticate is a method that returns the principal of the request
+	// - ErrAuthenticatorMissingCredentials: the request does not carry credentials for this method
+	// - ErrAuthenticatorInvalidCredentials: the request carries credentials that are not valid
+	Authenticate(r *http.Request) (p Principal, err error)
+}
+
+// principalContextKey is the key used to store the principal in a context
+type principalContextKey struct{}
+
+// ContextWithPrincipal is a function that returns a copy of ctx that carries the principal
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext is a function that returns the principal stored in ctx
+func PrincipalFromContext(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalContextKey{}).(Principal)
+	return
+}
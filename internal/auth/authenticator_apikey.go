@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"app/internal"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HeaderAPIKey is the header that carries the api key
+const HeaderAPIKey = "X-API-Key"
+
+// NewAuthenticatorAPIKey is a function that returns a new instance of AuthenticatorAPIKey
+// - keys: map of api key to the principal it authenticates
+func NewAuthenticatorAPIKey(keys map[string]internal.Principal) *AuthenticatorAPIKey {
+	// keys are indexed by their hash so lookups do not compare secrets byte by byte
+	hashed := make(map[[sha256.Size]byte]internal.Principal, len(keys))
+	for key, p := range keys {
+		hashed[sha256.Sum256([]byte(key))] = p
+	}
+	return &AuthenticatorAPIKey{keys: hashed}
+}
+
+// AuthenticatorAPIKey is a struct that implements the Authenticator interface with static api keys
+type AuthenticatorAPIKey struct {
+	// keys is a map of api key hashes to principals
+	keys map[[sha256.Size]byte]internal.Principal
+}
+
+// Authenticate is a method that returns the principal of the request
+func (a *AuthenticatorAPIKey) Authenticate(r *http.Request) (p internal.Principal, err error) {
+	key := r.Header.Get(HeaderAPIKey)
+	if key == "" {
+		err = internal.ErrAuthenticatorMissingCredentials
+		return
+	}
+
+	p, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		err = internal.ErrAuthenticatorInvalidCredentials
+		return
+	}
+	return
+}
+
+// ParseAPIKeys is a function that parses a list of api keys
+// - format: comma separated list of key:role[:subject[:fleet]]. The subject defaults to the role
+// - a key with a fleet can only access that fleet, a key without fleet can access every fleet
+func ParseAPIKeys(spec string) (keys map[string]internal.Principal, err error) {
+	keys = make(map[string]internal.Principal)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 4)
+		if len(parts) < 2 || parts[0] == "" {
+			err = fmt.Errorf("auth: invalid api key entry %q", entry)
+			return
+		}
+
+		var role internal.Role
+		role, err = internal.ParseRole(parts[1])
+		if err != nil {
+			return
+		}
+
+		subject := string(role)
+		if len(parts) >= 3 && parts[2] != "" {
+			subject = parts[2]
+		}
+		var fleet string
+		if len(parts) == 4 {
+			fleet = parts[3]
+		}
+		keys[parts[0]] = internal.Principal{Subject: subject, Role: role, Fleet: fleet}
+	}
+	return
+}
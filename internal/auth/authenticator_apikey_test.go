@@ -0,0 +1,111 @@
+package auth_test
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for AuthenticatorAPIKey.Authenticate method
+func TestAuthenticatorAPIKey_Authenticate(t *testing.T) {
+	au := auth.NewAuthenticatorAPIKey(map[string]internal.Principal{
+		"k1": {Subject: "ops", Role: internal.RoleAdmin},
+		"k2": {Subject: "tenant", Role: internal.RoleReader, Fleet: "north"},
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// arrange
+		r := &http.Request{Header: http.Header{}}
+		r.Header.Set(auth.HeaderAPIKey, "k2")
+
+		// act
+		p, err := au.Authenticate(r)
+
+		// assert
+		expectedPrincipal := internal.Principal{Subject: "tenant", Role: internal.RoleReader, Fleet: "north"}
+		require.NoError(t, err)
+		require.Equal(t, expectedPrincipal, p)
+	})
+
+	t.Run("error - missing credentials", func(t *testing.T) {
+		// arrange
+		r := &http.Request{Header: http.Header{}}
+
+		// act
+		_, err := au.Authenticate(r)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorMissingCredentials)
+	})
+
+	t.Run("error - unknown key", func(t *testing.T) {
+		// arrange
+		r := &http.Request{Header: http.Header{}}
+		r.Header.Set(auth.HeaderAPIKey, "k3")
+
+		// act
+		_, err := au.Authenticate(r)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorInvalidCredentials)
+	})
+}
+
+// Tests for ParseAPIKeys function
+func TestParseAPIKeys(t *testing.T) {
+	t.Run("success - subjects default to the role, fleets are optional", func(t *testing.T) {
+		// arrange
+		spec := " k1:admin , k2:editor:john, k3:reader:jane:north,"
+
+		// act
+		keys, err := auth.ParseAPIKeys(spec)
+
+		// assert
+		expectedKeys := map[string]internal.Principal{
+			"k1": {Subject: "admin", Role: internal.RoleAdmin},
+			"k2": {Subject: "john", Role: internal.RoleEditor},
+			"k3": {Subject: "jane", Role: internal.RoleReader, Fleet: "north"},
+		}
+		require.NoError(t, err)
+		require.Equal(t, expectedKeys, keys)
+	})
+
+	t.Run("success - empty spec", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		keys, err := auth.ParseAPIKeys("")
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("error - entry without role or key", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		_, errRole := auth.ParseAPIKeys("k1")
+		_, errKey := auth.ParseAPIKeys(":admin")
+
+		// assert
+		require.Error(t, errRole)
+		require.Error(t, errKey)
+	})
+
+	t.Run("error - unknown role", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		_, err := auth.ParseAPIKeys("k1:owner")
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorInvalidRole)
+	})
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"app/internal"
+	"errors"
+	"net/http"
+)
+
+// NewAuthenticatorChain is a function that returns a new instance of AuthenticatorChain
+func NewAuthenticatorChain(authenticators ...internal.Authenticator) *AuthenticatorChain {
+	return &AuthenticatorChain{authenticators: authenticators}
+}
+
+// AuthenticatorChain is a struct that implements the Authenticator interface by trying several methods in order
+// - the first method that finds credentials in the request decides the result
+type AuthenticatorChain struct {
+	// authenticators are the methods that will be tried
+	authenticators []internal.Authenticator
+}
+
+// Authenticate is a method that returns the principal of the request
+func (a *AuthenticatorChain) Authenticate(r *http.Request) (p internal.Principal, err error) {
+	for _, au := range a.authenticators {
+		p, err = au.Authenticate(r)
+		if errors.Is(err, internal.ErrAuthenticatorMissingCredentials) {
+			continue
+		}
+		return
+	}
+
+	err = internal.ErrAuthenticatorMissingCredentials
+	return
+}
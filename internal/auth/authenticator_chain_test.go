@@ -0,0 +1,74 @@
+package auth_test
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for AuthenticatorChain.Authenticate method
+func TestAuthenticatorChain_Authenticate(t *testing.T) {
+	secret := []byte("secret")
+	au := auth.NewAuthenticatorChain(
+		auth.NewAuthenticatorAPIKey(map[string]internal.Principal{"k1": {Subject: "ops", Role: internal.RoleAdmin}}),
+		auth.NewAuthenticatorJWT(secret),
+	)
+
+	t.Run("success - first method with credentials", func(t *testing.T) {
+		// arrange
+		r := &http.Request{Header: http.Header{}}
+		r.Header.Set(auth.HeaderAPIKey, "k1")
+
+		// act
+		p, err := au.Authenticate(r)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.Principal{Subject: "ops", Role: internal.RoleAdmin}, p)
+	})
+
+	t.Run("success - next method when the first finds no credentials", func(t *testing.T) {
+		// arrange
+		token, err := auth.SignJWT(secret, auth.ClaimsJWT{Subject: "john", Role: "reader", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		require.NoError(t, err)
+		r := &http.Request{Header: http.Header{}}
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		// act
+		p, err := au.Authenticate(r)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.Principal{Subject: "john", Role: internal.RoleReader}, p)
+	})
+
+	t.Run("error - invalid credentials of a method are not retried with the next one", func(t *testing.T) {
+		// arrange
+		token, err := auth.SignJWT(secret, auth.ClaimsJWT{Subject: "john", Role: "reader", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		require.NoError(t, err)
+		r := &http.Request{Header: http.Header{}}
+		r.Header.Set(auth.HeaderAPIKey, "k2")
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		// act
+		_, err = au.Authenticate(r)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorInvalidCredentials)
+	})
+
+	t.Run("error - no method finds credentials", func(t *testing.T) {
+		// arrange
+		r := &http.Request{Header: http.Header{}}
+
+		// act
+		_, err := au.Authenticate(r)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorMissingCredentials)
+	})
+}
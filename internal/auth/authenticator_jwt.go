@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"app/internal"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrJWTMalformed is an error that represents a token that is not a valid JWT
+	ErrJWTMalformed = errors.New("auth: malformed token")
+	// ErrJWTUnsupportedAlgorithm is an error that represents a token signed with an algorithm other than HS256
+	ErrJWTUnsupportedAlgorithm = errors.New("auth: unsupported token algorithm")
+	// ErrJWTInvalidSignature is an error that represents a token with a signature that does not match
+	ErrJWTInvalidSignature = errors.New("auth: invalid token signature")
+	// ErrJWTExpired is an error that represents a token that is expired or not valid yet
+	ErrJWTExpired = errors.New("auth: token expired or not valid yet")
+	// ErrJWTMissingExpiration is an error that represents a token without expiration, that would never expire
+	ErrJWTMissingExpiration = errors.New("auth: token without expiration")
+)
+
+// ClaimsJWT is a struct that represents the claims of a token
+type ClaimsJWT struct {
+	// Subject is the subject of the token
+	Subject string `json:"sub"`
+	// Role is the role granted by the token
+	Role string `json:"role"`
+	// Fleet is the only fleet the token can access (empty: every fleet)
+	Fleet string `json:"fleet,omitempty"`
+	// ExpiresAt is the unix time after which the token is not valid (required)
+	ExpiresAt int64 `json:"exp,omitempty"`
+	// NotBefore is the unix time before which the token is not valid
+	NotBefore int64 `json:"nbf,omitempty"`
+	// IssuedAt is the unix time when the token was issued
+	IssuedAt int64 `json:"iat,omitempty"`
+}
+
+// headerJWT is a struct that represents the header of a token
+type headerJWT struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// NewAuthenticatorJWT is a function that returns a new instance of AuthenticatorJWT
+func NewAuthenticatorJWT(secret []byte) *AuthenticatorJWT {
+	return &AuthenticatorJWT{
+		secret: secret,
+		now:    time.Now,
+		leeway: 30 * time.Second,
+	}
+}
+
+// AuthenticatorJWT is a struct that implements the Authenticator interface with HMAC-SHA256 signed bearer tokens
+type AuthenticatorJWT struct {
+	// secret is the key used to sign the tokens
+	secret []byte
+	// now is the clock used to validate the time claims
+	now func() time.Time
+	// leeway is the clock skew tolerated when validating the time claims
+	leeway time.Duration
+}
+
+// Authenticate is a method that returns the principal of the request
+func (a *AuthenticatorJWT) Authenticate(r *http.Request) (p internal.Principal, err error) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		err = internal.ErrAuthenticatorMissingCredentials
+		return
+	}
+
+	claims, err := a.Verify(strings.TrimSpace(token))
+	if err != nil {
+		err = fmt.Errorf("%w: %v", internal.ErrAuthenticatorInvalidCredentials, err)
+		return
+	}
+
+	role, err := internal.ParseRole(claims.Role)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", internal.ErrAuthenticatorInvalidCredentials, err)
+		return
+	}
+
+	p = internal.Principal{Subject: claims.Subject, Role: role, Fleet: claims.Fleet}
+	return
+}
+
+// Verify is a method that checks the signature and the time claims of a token and returns its claims
+// - the expiration is required: a token without exp is rejected
+func (a *AuthenticatorJWT) Verify(token string) (c ClaimsJWT, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		err = ErrJWTMalformed
+		return
+	}
+
+	// header
+	var h headerJWT
+	if err = decodeSegmentJWT(parts[0], &h); err != nil {
+		return
+	}
+	if h.Algorithm != "HS256" {
+		err = fmt.Errorf("%w: %q", ErrJWTUnsupportedAlgorithm, h.Algorithm)
+		return
+	}
+
+	// signature
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		err = ErrJWTMalformed
+		return
+	}
+	if !hmac.Equal(signature, signJWT(a.secret, parts[0]+"."+parts[1])) {
+		err = ErrJWTInvalidSignature
+		return
+	}
+
+	// claims
+	if err = decodeSegmentJWT(parts[1], &c); err != nil {
+		return
+	}
+	if c.ExpiresAt == 0 {
+		err = ErrJWTMissingExpiration
+		return
+	}
+	now := a.now()
+	if now.After(time.Unix(c.ExpiresAt, 0).Add(a.leeway)) {
+		err = ErrJWTExpired
+		return
+	}
+	if c.NotBefore != 0 && now.Before(time.Unix(c.NotBefore, 0).Add(-a.leeway)) {
+		err = ErrJWTExpired
+		return
+	}
+	return
+}
+
+// SignJWT is a function that returns a HS256 token with the given claims
+func SignJWT(secret []byte, c ClaimsJWT) (token string, err error) {
+	header, err := json.Marshal(headerJWT{Algorithm: "HS256", Type: "JWT"})
+	if err != nil {
+		return
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	token = unsigned + "." + base64.RawURLEncoding.EncodeToString(signJWT(secret, unsigned))
+	return
+}
+
+// signJWT is a function that returns the HMAC-SHA256 of the signing input
+func signJWT(secret []byte, input string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+// decodeSegmentJWT is a function that decodes a base64url JSON segment of a token
+func decodeSegmentJWT(segment string, ptr any) (err error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		err = ErrJWTMalformed
+		return
+	}
+	if err = json.Unmarshal(bytes, ptr); err != nil {
+		err = ErrJWTMalformed
+		return
+	}
+	return
+}
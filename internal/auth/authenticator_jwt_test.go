@@ -0,0 +1,108 @@
+package auth_test
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for AuthenticatorJWT.Authenticate method
+func TestAuthenticatorJWT_Authenticate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// arrange
+		secret := []byte("secret")
+		token, err := auth.SignJWT(secret, auth.ClaimsJWT{Subject: "john", Role: "editor", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		require.NoError(t, err)
+		au := auth.NewAuthenticatorJWT(secret)
+
+		// act
+		r := &http.Request{Header: http.Header{"Authorization": []string{"Bearer " + token}}}
+		p, err := au.Authenticate(r)
+
+		// assert
+		expectedPrincipal := internal.Principal{Subject: "john", Role: internal.RoleEditor}
+		require.NoError(t, err)
+		require.Equal(t, expectedPrincipal, p)
+	})
+
+	t.Run("success - fleet claim", func(t *testing.T) {
+		// arrange
+		secret := []byte("secret")
+		token, err := auth.SignJWT(secret, auth.ClaimsJWT{Subject: "jane", Role: "reader", Fleet: "north", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		require.NoError(t, err)
+		au := auth.NewAuthenticatorJWT(secret)
+
+		// act
+		r := &http.Request{Header: http.Header{"Authorization": []string{"Bearer " + token}}}
+		p, err := au.Authenticate(r)
+
+		// assert
+		expectedPrincipal := internal.Principal{Subject: "jane", Role: internal.RoleReader, Fleet: "north"}
+		require.NoError(t, err)
+		require.Equal(t, expectedPrincipal, p)
+		require.True(t, p.CanAccessFleet("north"))
+		require.False(t, p.CanAccessFleet("south"))
+	})
+
+	t.Run("error - missing credentials", func(t *testing.T) {
+		// arrange
+		au := auth.NewAuthenticatorJWT([]byte("secret"))
+
+		// act
+		r := &http.Request{Header: http.Header{}}
+		_, err := au.Authenticate(r)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorMissingCredentials)
+	})
+
+	t.Run("error - signed with another secret", func(t *testing.T) {
+		// arrange
+		token, err := auth.SignJWT([]byte("other"), auth.ClaimsJWT{Subject: "john", Role: "admin"})
+		require.NoError(t, err)
+		au := auth.NewAuthenticatorJWT([]byte("secret"))
+
+		// act
+		r := &http.Request{Header: http.Header{"Authorization": []string{"Bearer " + token}}}
+		_, err = au.Authenticate(r)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorInvalidCredentials)
+	})
+
+	t.Run("error - expired", func(t *testing.T) {
+		// arrange
+		secret := []byte("secret")
+		token, err := auth.SignJWT(secret, auth.ClaimsJWT{Subject: "john", Role: "reader", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+		require.NoError(t, err)
+		au := auth.NewAuthenticatorJWT(secret)
+
+		// act
+		r := &http.Request{Header: http.Header{"Authorization": []string{"Bearer " + token}}}
+		_, err = au.Authenticate(r)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorInvalidCredentials)
+	})
+
+	t.Run("error - without expiration", func(t *testing.T) {
+		// arrange
+		secret := []byte("secret")
+		token, err := auth.SignJWT(secret, auth.ClaimsJWT{Subject: "john", Role: "admin"})
+		require.NoError(t, err)
+		au := auth.NewAuthenticatorJWT(secret)
+
+		// act
+		r := &http.Request{Header: http.Header{"Authorization": []string{"Bearer " + token}}}
+		_, err = au.Authenticate(r)
+		_, errVerify := au.Verify(token)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrAuthenticatorInvalidCredentials)
+		require.ErrorIs(t, errVerify, auth.ErrJWTMissingExpiration)
+	})
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+)
+
+// Authenticate is a middleware that authenticates every request with the given authenticator
+// - requests without valid credentials are rejected with 401
+// - the principal is stored in the request context for the next handlers
+func Authenticate(au internal.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := au.Authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="vehicles"`)
+				switch {
+				case errors.Is(err, internal.ErrAuthenticatorMissingCredentials):
+					response.Problem(w, http.StatusUnauthorized, "missing credentials")
+				default:
+					response.Problem(w, http.StatusUnauthorized, "invalid credentials")
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(internal.ContextWithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// Authorize is a middleware that only lets through principals whose role includes the given role
+// - requests without principal are rejected with 401, requests with an insufficient role with 403
+func Authorize(role internal.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := internal.PrincipalFromContext(r.Context())
+			if !ok {
+				response.Problem(w, http.StatusUnauthorized, "missing credentials")
+				return
+			}
+			if !p.Role.Includes(role) {
+				response.Problem(w, http.StatusForbidden, "role "+string(role)+" required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+
+// AuthorizeFleet is a middleware that only lets through principals that can access the fleet of the request
+// - fleet: function that returns the fleet addressed by the request
+// - requests without principal are rejected with 401, requests to another fleet with 403
+func AuthorizeFleet(fleet func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := internal.PrincipalFromContext(r.Context())
+			if !ok {
+				response.Problem(w, http.StatusUnauthorized, "missing credentials")
+				return
+			}
+			if name := fleet(r); !p.CanAccessFleet(name) {
+				response.Problem(w, http.StatusForbidden, "fleet "+name+" not allowed")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package auth_test
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Authenticate and Authorize middlewares
+func TestAuthorize(t *testing.T) {
+	au := auth.NewAuthenticatorAPIKey(map[string]internal.Principal{
+		"reader": {Subject: "reader", Role: internal.RoleReader},
+		"admin":  {Subject: "admin", Role: internal.RoleAdmin},
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	hd := auth.Authenticate(au)(auth.Authorize(internal.RoleEditor)(next))
+	serve := func(h http.Handler, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/vehicles/1", nil)
+		if key != "" {
+			req.Header.Set(auth.HeaderAPIKey, key)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("success - role that includes the required one", func(t *testing.T) {
+		// act
+		res := serve(hd, "admin")
+
+		// assert
+		require.Equal(t, http.StatusNoContent, res.Code)
+	})
+
+	t.Run("error - 401 without credentials", func(t *testing.T) {
+		// act
+		res := serve(hd, "")
+
+		// assert
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+		require.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+		require.Contains(t, res.Body.String(), "missing credentials")
+		require.NotEmpty(t, res.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("error - 401 with an unknown key", func(t *testing.T) {
+		// act
+		res := serve(hd, "nobody")
+
+		// assert
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+		require.Contains(t, res.Body.String(), "invalid credentials")
+	})
+
+	t.Run("error - 401 without a principal in the context", func(t *testing.T) {
+		// act
+		res := serve(auth.Authorize(internal.RoleReader)(next), "admin")
+
+		// assert
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("error - 403 with an insufficient role", func(t *testing.T) {
+		// act
+		res := serve(hd, "reader")
+
+		// assert
+		require.Equal(t, http.StatusForbidden, res.Code)
+		require.Contains(t, res.Body.String(), "role editor required")
+	})
+}
+
+// Tests for AuthorizeFleet middleware
+func TestAuthorizeFleet(t *testing.T) {
+	au := auth.NewAuthenticatorAPIKey(map[string]internal.Principal{
+		"operator": {Subject: "operator", Role: internal.RoleEditor},
+		"tenant":   {Subject: "tenant", Role: internal.RoleEditor, Fleet: "north"},
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	fleet := func(r *http.Request) string { return r.URL.Query().Get("fleet") }
+	hd := auth.Authenticate(au)(auth.AuthorizeFleet(fleet)(next))
+	serve := func(h http.Handler, key string, fleet string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/vehicles/1?fleet="+fleet, nil)
+		if key != "" {
+			req.Header.Set(auth.HeaderAPIKey, key)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("success - key of the fleet", func(t *testing.T) {
+		// act
+		res := serve(hd, "tenant", "north")
+
+		// assert
+		require.Equal(t, http.StatusNoContent, res.Code)
+	})
+
+	t.Run("success - key without fleet reaches every fleet", func(t *testing.T) {
+		// act
+		north := serve(hd, "operator", "north")
+		south := serve(hd, "operator", "south")
+
+		// assert
+		require.Equal(t, http.StatusNoContent, north.Code)
+		require.Equal(t, http.StatusNoContent, south.Code)
+	})
+
+	t.Run("error - 401 without a principal in the context", func(t *testing.T) {
+		// act
+		res := serve(auth.AuthorizeFleet(fleet)(next), "tenant", "north")
+
+		// assert
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("error - 403 with the key of another fleet", func(t *testing.T) {
+		// act
+		res := serve(hd, "tenant", "south")
+
+		// assert
+		require.Equal(t, http.StatusForbidden, res.Code)
+		require.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+		require.Contains(t, res.Body.String(), "fleet south not allowed")
+	})
+}
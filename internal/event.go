@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidEventType is an error that represents an unknown event type
+	ErrInvalidEventType = errors.New("invalid event type")
+)
+
+// EventType is a type that represents the kind of change of an event
+type EventType string
+
+const (
+	// EventVehicleCreated is a vehicle that was added (or restored)
+	EventVehicleCreated EventType = "vehicle.created"
+	// EventVehicleUpdated is a vehicle that was replaced
+	EventVehicleUpdated EventType = "vehicle.updated"
+	// EventVehicleDeleted is a vehicle that was removed
+	EventVehicleDeleted EventType = "vehicle.deleted"
+	// EventDatasetReloaded is a fleet whose vehicles were loaded from its file, discarding the changes made since the previous load
+	EventDatasetReloaded EventType = "dataset.reloaded"
+)
+
+// EventTypes are the allowed event types
+var EventTypes = []EventType{EventVehicleCreated, EventVehicleUpdated, EventVehicleDeleted, EventDatasetReloaded}
+
+// ParseEventType is a function that returns the event type of a name
+func ParseEventType(name string) (t EventType, err error) {
+	for _, et := range EventTypes {
+		if string(et) == name {
+			t = et
+			return
+		}
+	}
+	err = fmt.Errorf("%w: %q (allowed: %v)", ErrInvalidEventType, name, EventTypes)
+	return
+}
+
+// Event is a struct that represents a change of a fleet
+type Event struct {
+	// Id is the unique identifier of the event
+	Id string
+	// Type is the kind of change
+	Type EventType
+	// Time is the instant of the change
+	Time time.Time
+	// Fleet is the fleet that changed
+	Fleet string
+	// Actor is the subject of the principal that made the change (empty for the changes made by the application)
+	Actor string
+	// RequestId is the id of the request that made the change
+	RequestId string
+	// Vehicle is the state of the vehicle after the change, or before it for deleted vehicles (nil for dataset events)
+	Vehicle *Vehicle
+}
+
+// NewEvent is a function that returns an event of the fleet with a random id
+func NewEvent(t EventType, fleet string, at time.Time) Event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return Event{Id: hex.EncodeToString(id), Type: t, Time: at, Fleet: fleet}
+}
+
+// PublisherEvent is an interface that represents the destination of the events
+type PublisherEvent interface {
+	// Publish is a method that hands an event over. It does not wait for the event to reach its subscribers
+	Publish(e Event)
+}
+
+// PublishersEvent is a list of publishers that is itself a publisher: it hands every event over to each of them, in order
+type PublishersEvent []PublisherEvent
+
+// Publish is a method that hands the event over to each publisher
+func (p PublishersEvent) Publish(e Event) {
+	for _, pb := range p {
+		pb.Publish(e)
+	}
+}
@@ -0,0 +1,41 @@
+package internal
+
+// StreamQuery is a struct that represents the filters of a subscription to the events of a fleet
+// - zero values are not used as filters
+// - the filters apply to the vehicle of the event: dataset events always match
+type StreamQuery struct {
+	// Brand is the brand of the vehicle, case and accent insensitive
+	Brand string
+	// Color is the color of the vehicle, case and accent insensitive
+	Color string
+}
+
+// StreamedEvent is a struct that represents an event with its position in the stream of its fleet
+type StreamedEvent struct {
+	// Seq is the position of the event in the stream, starting at 1
+	Seq uint64
+	// Event is the event
+	Event Event
+}
+
+// SubscriptionEvent is an interface that represents a subscription to the live events of a fleet
+type SubscriptionEvent interface {
+	// Events is a method that returns the channel of the live events
+	// - it is closed when the subscription is closed, or dropped because the subscriber fell behind
+	Events() <-chan StreamedEvent
+
+	// Close is a method that ends the subscription
+	Close()
+}
+
+// ServiceStream is an interface that represents a stream of the events of a fleet
+// - it is a publisher: the events are kept in a bounded replay buffer and sent to the subscribers without waiting for them
+type ServiceStream interface {
+	PublisherEvent
+
+	// Subscribe is a method that returns a subscription to the events that match the query
+	// - lastSeq: the last event seen by a resuming subscriber (0: only live events)
+	// - replay: the events after lastSeq in the replay buffer that match the query
+	// - gap: true if some events after lastSeq are not in the replay buffer anymore
+	Subscribe(query StreamQuery, lastSeq uint64) (s SubscriptionEvent, replay []StreamedEvent, gap bool, err error)
+}
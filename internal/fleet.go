@@ -0,0 +1,4 @@
+package internal
+
+// DefaultFleet is the name of the fleet of the main dataset, served by the routes that are not scoped to a fleet
+const DefaultFleet = "default"
@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"app/internal"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config is a struct that represents the distributions of the generated vehicles
+type Config struct {
+	// Brands are the brands with their weight and models
+	Brands []Brand `json:"brands"`
+	// Colors are the colors with their weight
+	Colors []Weighted `json:"colors"`
+	// Years is the distribution of the fabrication years
+	Years Years `json:"years"`
+	// DuplicateRate is the rate of rows that repeat a previous vehicle, between 0 and 1
+	DuplicateRate float64 `json:"duplicate_rate"`
+	// InvalidRate is the rate of rows that break a validation rule, between 0 and 1
+	InvalidRate float64 `json:"invalid_rate"`
+}
+
+// Brand is a struct that represents a brand and its models
+type Brand struct {
+	Name string `json:"name"`
+	// Weight is the relative frequency of the brand
+	Weight float64 `json:"weight"`
+	// Models are the models of the brand, equally frequent
+	Models []string `json:"models"`
+}
+
+// Weighted is a struct that represents a value and its relative frequency
+type Weighted struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// Years is a struct that represents a triangular distribution of the fabrication years
+type Years struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+	// Mode is the most frequent year, between Min and Max
+	Mode int `json:"mode"`
+}
+
+// DefaultConfig is a function that returns the distributions of a fleet of common cars, without duplicates nor invalid rows
+func DefaultConfig() Config {
+	return Config{
+		Brands: []Brand{
+			{Name: "Chevrolet", Weight: 12, Models: []string{"Impala", "Malibu", "Silverado", "Cavalier", "Tahoe", "Camaro"}},
+			{Name: "Ford", Weight: 12, Models: []string{"F-150", "Focus", "Mustang", "Explorer", "Escape", "Ranger"}},
+			{Name: "Toyota", Weight: 12, Models: []string{"Corolla", "Camry", "RAV4", "Tacoma", "Prius", "Highlander"}},
+			{Name: "Honda", Weight: 9, Models: []string{"Civic", "Accord", "CR-V", "Pilot", "Fit"}},
+			{Name: "GMC", Weight: 6, Models: []string{"Sierra", "Yukon", "Acadia", "Savana"}},
+			{Name: "Nissan", Weight: 7, Models: []string{"Altima", "Sentra", "Rogue", "Frontier"}},
+			{Name: "Volkswagen", Weight: 6, Models: []string{"Golf", "Jetta", "Passat", "Tiguan"}},
+			{Name: "Hyundai", Weight: 5, Models: []string{"Elantra", "Sonata", "Tucson", "Santa Fe"}},
+			{Name: "Kia", Weight: 4, Models: []string{"Rio", "Sportage", "Sorento", "Soul"}},
+			{Name: "Dodge", Weight: 4, Models: []string{"Ram", "Charger", "Durango", "Caravan"}},
+			{Name: "Mazda", Weight: 3, Models: []string{"Mazda3", "Mazda6", "CX-5", "MX-5"}},
+			{Name: "Subaru", Weight: 3, Models: []string{"Impreza", "Outback", "Forester"}},
+			{Name: "BMW", Weight: 3, Models: []string{"3 Series", "5 Series", "X3", "X5"}},
+			{Name: "Mercedes-Benz", Weight: 3, Models: []string{"C-Class", "E-Class", "GLC", "Sprinter"}},
+			{Name: "Audi", Weight: 2, Models: []string{"A3", "A4", "Q5", "Q7"}},
+			{Name: "Volvo", Weight: 1, Models: []string{"XC60", "XC90", "S60"}},
+			{Name: "Porsche", Weight: 0.5, Models: []string{"911", "Cayenne", "Boxster"}},
+			{Name: "Ferrari", Weight: 0.2, Models: []string{"F430", "California", "Roma"}},
+		},
+		Colors: []Weighted{
+			{Name: "White", Weight: 24}, {Name: "Black", Weight: 20}, {Name: "Gray", Weight: 16}, {Name: "Silver", Weight: 12},
+			{Name: "Blue", Weight: 9}, {Name: "Red", Weight: 9}, {Name: "Green", Weight: 3}, {Name: "Brown", Weight: 2},
+			{Name: "Orange", Weight: 1}, {Name: "Yellow", Weight: 1}, {Name: "Purple", Weight: 1}, {Name: "Maroon", Weight: 1},
+		},
+		Years: Years{Min: 1985, Max: 2024, Mode: 2014},
+	}
+}
+
+// LoadConfigJSON is a function that reads a configuration from a JSON file
+// - the fields that are absent keep the value of DefaultConfig
+func LoadConfigJSON(path string) (cfg Config, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	cfg = DefaultConfig()
+	defaults := cfg
+	cfg.Brands, cfg.Colors = nil, nil
+	if err = json.NewDecoder(file).Decode(&cfg); err != nil {
+		err = fmt.Errorf("%w: %v", internal.ErrGeneratorInvalidConfig, err)
+		return
+	}
+	if cfg.Brands == nil {
+		cfg.Brands = defaults.Brands
+	}
+	if cfg.Colors == nil {
+		cfg.Colors = defaults.Colors
+	}
+	return
+}
+
+// Validate is a method that returns an error if the distributions cannot be sampled
+func (c Config) Validate() (err error) {
+	total := 0.0
+	for _, b := range c.Brands {
+		if b.Name == "" || b.Weight < 0 || len(b.Models) == 0 {
+			return fmt.Errorf("%w: brand %q needs a name, a non negative weight and models", internal.ErrGeneratorInvalidConfig, b.Name)
+		}
+		total += b.Weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("%w: brands need a positive total weight", internal.ErrGeneratorInvalidConfig)
+	}
+
+	total = 0
+	for _, c := range c.Colors {
+		if c.Name == "" || c.Weight < 0 {
+			return fmt.Errorf("%w: color %q needs a name and a non negative weight", internal.ErrGeneratorInvalidConfig, c.Name)
+		}
+		total += c.Weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("%w: colors need a positive total weight", internal.ErrGeneratorInvalidConfig)
+	}
+
+	switch {
+	case c.Years.Min <= 0 || c.Years.Min > c.Years.Max:
+		return fmt.Errorf("%w: years need 0 < min <= max", internal.ErrGeneratorInvalidConfig)
+	case c.Years.Mode < c.Years.Min || c.Years.Mode > c.Years.Max:
+		return fmt.Errorf("%w: years need min <= mode <= max", internal.ErrGeneratorInvalidConfig)
+	case c.DuplicateRate < 0 || c.InvalidRate < 0 || c.DuplicateRate+c.InvalidRate > 1:
+		return fmt.Errorf("%w: rates need to be non negative and add up to 1 at most", internal.ErrGeneratorInvalidConfig)
+	}
+	return
+}
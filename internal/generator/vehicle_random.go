@@ -0,0 +1,219 @@
+package generator
+
+import (
+	"app/internal"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+)
+
+// NewGeneratorVehicleRandom is a function that returns a new instance of GeneratorVehicleRandom
+// - seed: the same seed and config always produce the same sequence of vehicles
+func NewGeneratorVehicleRandom(cfg Config, seed int64) (g *GeneratorVehicleRandom, err error) {
+	if err = cfg.Validate(); err != nil {
+		return
+	}
+
+	g = &GeneratorVehicleRandom{
+		cfg:    cfg,
+		rnd:    rand.New(rand.NewSource(seed)),
+		recent: make([]internal.Vehicle, 0, recentSize),
+	}
+	g.brands = make([]float64, len(cfg.Brands))
+	for i, b := range cfg.Brands {
+		g.brands[i] = b.Weight
+	}
+	g.colors = make([]float64, len(cfg.Colors))
+	for i, c := range cfg.Colors {
+		g.colors[i] = c.Weight
+	}
+	return
+}
+
+// recentSize is the number of generated vehicles a duplicate can be copied from
+const recentSize = 256
+
+// GeneratorVehicleRandom is a struct that implements the GeneratorVehicle interface
+// - ids are sequential starting at 1 and registrations are derived from the id, so valid vehicles never collide
+type GeneratorVehicleRandom struct {
+	// cfg is the distributions of the vehicles
+	cfg Config
+	// rnd is the source of randomness
+	rnd *rand.Rand
+	// brands and colors are the weights of the distributions
+	brands []float64
+	colors []float64
+	// id is the last id
+	id int
+	// recent is a bounded reservoir of vehicles a duplicate can be copied from
+	recent []internal.Vehicle
+}
+
+// class is a struct that represents the physical specification of a body style
+type class struct {
+	capacity [2]int
+	maxSpeed [2]float64 // km/h
+	weight   [2]float64 // kg
+	height   [2]float64 // cm
+	length   [2]float64 // cm
+	width    [2]float64 // cm
+}
+
+// classes are the body styles of the vehicles
+var classes = []class{
+	// - compact
+	{capacity: [2]int{4, 5}, maxSpeed: [2]float64{150, 190}, weight: [2]float64{950, 1300}, height: [2]float64{140, 155}, length: [2]float64{380, 430}, width: [2]float64{165, 180}},
+	// - sedan
+	{capacity: [2]int{5, 5}, maxSpeed: [2]float64{170, 230}, weight: [2]float64{1300, 1700}, height: [2]float64{140, 150}, length: [2]float64{450, 500}, width: [2]float64{175, 190}},
+	// - suv
+	{capacity: [2]int{5, 8}, maxSpeed: [2]float64{160, 210}, weight: [2]float64{1600, 2500}, height: [2]float64{165, 190}, length: [2]float64{440, 520}, width: [2]float64{180, 205}},
+	// - pickup
+	{capacity: [2]int{2, 6}, maxSpeed: [2]float64{150, 190}, weight: [2]float64{1900, 2800}, height: [2]float64{175, 200}, length: [2]float64{520, 600}, width: [2]float64{190, 210}},
+	// - van
+	{capacity: [2]int{7, 15}, maxSpeed: [2]float64{130, 170}, weight: [2]float64{2000, 3200}, height: [2]float64{190, 270}, length: [2]float64{500, 600}, width: [2]float64{195, 210}},
+	// - sports
+	{capacity: [2]int{2, 4}, maxSpeed: [2]float64{240, 330}, weight: [2]float64{1200, 1600}, height: [2]float64{115, 130}, length: [2]float64{420, 470}, width: [2]float64{185, 200}},
+}
+
+// classOf is a function that returns the body style of a model, always the same for the same brand and model
+func classOf(brand, model string) class {
+	h := fnv.New32a()
+	h.Write([]byte(brand + "/" + model))
+	return classes[h.Sum32()%uint32(len(classes))]
+}
+
+// Next is a method that returns the next vehicle and its kind
+func (g *GeneratorVehicleRandom) Next() (v internal.Vehicle, kind internal.GeneratedKind) {
+	p := g.rnd.Float64()
+	switch {
+	case p < g.cfg.DuplicateRate && len(g.recent) > 0:
+		v, kind = g.recent[g.rnd.Intn(len(g.recent))], internal.GeneratedDuplicate
+	case p >= g.cfg.DuplicateRate && p < g.cfg.DuplicateRate+g.cfg.InvalidRate:
+		v, kind = g.invalid(g.valid()), internal.GeneratedInvalid
+	default:
+		v, kind = g.valid(), internal.GeneratedValid
+		g.remember(v)
+	}
+	return
+}
+
+// valid is a method that returns a new vehicle that passes the validation of the service
+func (g *GeneratorVehicleRandom) valid() (v internal.Vehicle) {
+	g.id++
+	brand := g.cfg.Brands[pick(g.rnd, g.brands)]
+	model := brand.Models[g.rnd.Intn(len(brand.Models))]
+	c := classOf(brand.Name, model)
+
+	v = internal.Vehicle{
+		Id: g.id,
+		VehicleAttributes: internal.VehicleAttributes{
+			Brand:           brand.Name,
+			Model:           model,
+			Registration:    registration(g.id),
+			Color:           g.cfg.Colors[pick(g.rnd, g.colors)].Name,
+			FabricationYear: g.year(),
+			Capacity:        c.capacity[0] + g.rnd.Intn(c.capacity[1]-c.capacity[0]+1),
+			MaxSpeed:        internal.Speed(g.between(c.maxSpeed)),
+			FuelType:        g.fuelType(),
+			Transmission:    internal.Transmissions[g.rnd.Intn(len(internal.Transmissions))],
+			Weight:          internal.Mass(g.between(c.weight)),
+			Dimensions: internal.Dimensions{
+				Height: internal.Length(g.between(c.height)),
+				Length: internal.Length(g.between(c.length)),
+				Width:  internal.Length(g.between(c.width)),
+			},
+		},
+	}
+	return
+}
+
+// invalid is a method that breaks exactly one validation rule of the vehicle
+func (g *GeneratorVehicleRandom) invalid(v internal.Vehicle) internal.Vehicle {
+	switch g.rnd.Intn(7) {
+	case 0:
+		v.Brand = ""
+	case 1:
+		v.Model = ""
+	case 2:
+		v.FabricationYear = 0
+	case 3:
+		v.Capacity = -v.Capacity
+	case 4:
+		v.Weight = -v.Weight
+	case 5:
+		v.FuelType = "steam"
+	case 6:
+		v.Transmission = "pedal"
+	}
+	return v
+}
+
+// remember is a method that keeps the vehicle as a candidate for duplicates
+func (g *GeneratorVehicleRandom) remember(v internal.Vehicle) {
+	if len(g.recent) < recentSize {
+		g.recent = append(g.recent, v)
+		return
+	}
+	g.recent[g.rnd.Intn(recentSize)] = v
+}
+
+// year is a method that returns a fabrication year of the triangular distribution
+func (g *GeneratorVehicleRandom) year() int {
+	// - continuous over [min, max+1) with the peak at the middle of the mode, then truncated
+	lo, hi, mode := float64(g.cfg.Years.Min), float64(g.cfg.Years.Max)+1, float64(g.cfg.Years.Mode)+0.5
+	u := g.rnd.Float64()
+	var y float64
+	if u < (mode-lo)/(hi-lo) {
+		y = lo + math.Sqrt(u*(hi-lo)*(mode-lo))
+	} else {
+		y = hi - math.Sqrt((1-u)*(hi-lo)*(hi-mode))
+	}
+	return int(math.Min(math.Floor(y), float64(g.cfg.Years.Max)))
+}
+
+// fuelTypeWeights is a map of fuel type to its relative frequency, mostly gasoline
+// - a fuel type without weight is not generated
+var fuelTypeWeights = map[internal.FuelType]float64{
+	internal.FuelTypeGasoline:  70,
+	internal.FuelTypeDiesel:    15,
+	internal.FuelTypeBiodiesel: 3,
+	internal.FuelTypeElectric:  6,
+	internal.FuelTypeHybrid:    6,
+}
+
+// fuelType is a method that returns a fuel type with the probability of its weight
+func (g *GeneratorVehicleRandom) fuelType() internal.FuelType {
+	weights := make([]float64, len(internal.FuelTypes))
+	for i, f := range internal.FuelTypes {
+		weights[i] = fuelTypeWeights[f]
+	}
+	return internal.FuelTypes[pick(g.rnd, weights)]
+}
+
+// between is a method that returns a value of the range rounded to one decimal
+func (g *GeneratorVehicleRandom) between(r [2]float64) float64 {
+	return math.Round((r[0]+g.rnd.Float64()*(r[1]-r[0]))*10) / 10
+}
+
+// pick is a function that returns an index with a probability proportional to its weight
+func pick(rnd *rand.Rand, weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	x := rnd.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return i
+		}
+		x -= w
+	}
+	return len(weights) - 1
+}
+
+// registration is a function that returns the registration of an id, unique up to 26^3 * 10^4 ids (e.g. 1 -> "AAA-0001")
+func registration(id int) string {
+	letters := id / 10000
+	return fmt.Sprintf("%c%c%c-%04d", 'A'+letters/676%26, 'A'+letters/26%26, 'A'+letters%26, id%10000)
+}
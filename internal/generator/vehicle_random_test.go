@@ -0,0 +1,201 @@
+package generator_test
+
+import (
+	"app/internal"
+	"app/internal/generator"
+	"app/internal/loader"
+	"app/internal/repository"
+	"app/internal/service"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for GeneratorVehicleRandom
+func TestGeneratorVehicleRandom_Next(t *testing.T) {
+	t.Run("case 01: the same seed produces the same vehicles", func(t *testing.T) {
+		// arrange
+		cfg := generator.DefaultConfig()
+		cfg.DuplicateRate, cfg.InvalidRate = 0.1, 0.1
+		g1, err := generator.NewGeneratorVehicleRandom(cfg, 42)
+		require.NoError(t, err)
+		g2, err := generator.NewGeneratorVehicleRandom(cfg, 42)
+		require.NoError(t, err)
+
+		// act
+		var out1, out2 bytes.Buffer
+		_, err1 := generator.WriteJSON(&out1, g1, 500, internal.UnitsMetric)
+		_, err2 := generator.WriteJSON(&out2, g2, 500, internal.UnitsMetric)
+
+		// assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.Equal(t, out1.String(), out2.String())
+	})
+
+	t.Run("case 02: the rows follow the rates and each invalid row is rejected by the service", func(t *testing.T) {
+		// arrange
+		cfg := generator.DefaultConfig()
+		cfg.DuplicateRate, cfg.InvalidRate = 0.05, 0.2
+		g, err := generator.NewGeneratorVehicleRandom(cfg, 7)
+		require.NoError(t, err)
+		sv := service.NewServiceVehicleDefault(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{}, nil))
+
+		// act
+		counts := map[internal.GeneratedKind]int{}
+		for i := 0; i < 10000; i++ {
+			v, kind := g.Next()
+			counts[kind]++
+
+			// assert
+			err := sv.Create(context.Background(), &v)
+			switch kind {
+			case internal.GeneratedValid:
+				require.NoError(t, err)
+			case internal.GeneratedDuplicate:
+				require.ErrorIs(t, err, internal.ErrServiceVehicleAlreadyExists)
+			case internal.GeneratedInvalid:
+				require.ErrorIs(t, err, internal.ErrServiceInvalidVehicle)
+			}
+		}
+		require.InDelta(t, 500, counts[internal.GeneratedDuplicate], 100)
+		require.InDelta(t, 2000, counts[internal.GeneratedInvalid], 200)
+	})
+
+	t.Run("case 03: the years follow the configured range", func(t *testing.T) {
+		// arrange
+		cfg := generator.DefaultConfig()
+		cfg.Years = generator.Years{Min: 2000, Max: 2002, Mode: 2002}
+		g, err := generator.NewGeneratorVehicleRandom(cfg, 1)
+		require.NoError(t, err)
+
+		// act
+		years := map[int]int{}
+		for i := 0; i < 3000; i++ {
+			v, _ := g.Next()
+			years[v.FabricationYear]++
+		}
+
+		// assert
+		require.Len(t, years, 3)
+		require.Less(t, years[2000], years[2001])
+		require.Less(t, years[2001], years[2002])
+	})
+
+	t.Run("case 04: every fuel type is generated, mostly gasoline", func(t *testing.T) {
+		// arrange
+		g, err := generator.NewGeneratorVehicleRandom(generator.DefaultConfig(), 1)
+		require.NoError(t, err)
+
+		// act
+		fuelTypes := map[internal.FuelType]int{}
+		for i := 0; i < 3000; i++ {
+			v, _ := g.Next()
+			fuelTypes[v.FuelType]++
+		}
+
+		// assert
+		require.Len(t, fuelTypes, len(internal.FuelTypes))
+		for _, fuelType := range internal.FuelTypes {
+			require.Greater(t, fuelTypes[fuelType], 0)
+			require.LessOrEqual(t, fuelTypes[fuelType], fuelTypes[internal.FuelTypeGasoline])
+		}
+	})
+
+	t.Run("case 05: invalid config", func(t *testing.T) {
+		// arrange
+		cfg := generator.DefaultConfig()
+		cfg.DuplicateRate, cfg.InvalidRate = 0.6, 0.6
+
+		// act
+		_, err := generator.NewGeneratorVehicleRandom(cfg, 1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrGeneratorInvalidConfig)
+	})
+}
+
+// Tests for WriteJSON and WriteCSV
+func TestWrite(t *testing.T) {
+	t.Run("case 01: the JSON output is loaded by the loader", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "vehicles.json")
+		file, err := os.Create(path)
+		require.NoError(t, err)
+		g, err := generator.NewGeneratorVehicleRandom(generator.DefaultConfig(), 3)
+		require.NoError(t, err)
+
+		// act
+		s, err := generator.WriteJSON(file, g, 1000, internal.UnitsImperial)
+		require.NoError(t, file.Close())
+		v, errLoad := loader.NewLoaderVehicleJSON(path, internal.UnitsImperial).Load()
+
+		// assert
+		require.NoError(t, err)
+		require.NoError(t, errLoad)
+		require.Equal(t, generator.Stats{Total: 1000, Valid: 1000}, s)
+		require.Len(t, v, 1000)
+		require.Equal(t, "AAA-0001", v[1].Registration)
+	})
+
+	t.Run("case 02: the CSV output has a header and a row per vehicle", func(t *testing.T) {
+		// arrange
+		g, err := generator.NewGeneratorVehicleRandom(generator.DefaultConfig(), 3)
+		require.NoError(t, err)
+
+		// act
+		var out bytes.Buffer
+		s, err := generator.WriteCSV(&out, g, 10, internal.UnitsMetric)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 10, s.Total)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 11)
+		require.Equal(t, "id,brand,model,registration,color,year,passengers,max_speed,fuel_type,transmission,weight,height,length,width", lines[0])
+		require.True(t, strings.HasPrefix(lines[1], "1,"))
+	})
+
+	t.Run("case 03: no rows is an empty array", func(t *testing.T) {
+		// arrange
+		g, err := generator.NewGeneratorVehicleRandom(generator.DefaultConfig(), 3)
+		require.NoError(t, err)
+
+		// act
+		var out bytes.Buffer
+		_, err = generator.WriteJSON(&out, g, 0, internal.UnitsMetric)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "[]\n", out.String())
+	})
+	t.Run("case 04: the errors of the writer are returned", func(t *testing.T) {
+		// arrange
+		g, err := generator.NewGeneratorVehicleRandom(generator.DefaultConfig(), 3)
+		require.NoError(t, err)
+		errWrite := errors.New("disk full")
+
+		// act
+		_, err = generator.WriteJSON(writerFailing{err: errWrite}, g, 10000, internal.UnitsMetric)
+
+		// assert
+		require.ErrorIs(t, err, errWrite)
+	})
+}
+
+// writerFailing is a struct that implements io.Writer failing every write
+type writerFailing struct {
+	// err is the error of the writes
+	err error
+}
+
+// Write is a method that returns the error
+func (w writerFailing) Write(p []byte) (n int, err error) {
+	return 0, w.err
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Stats is a struct that represents the number of written vehicles by kind
+type Stats struct {
+	Total     int `json:"total"`
+	Valid     int `json:"valid"`
+	Duplicate int `json:"duplicate"`
+	Invalid   int `json:"invalid"`
+}
+
+// add is a method that counts a written vehicle
+func (s *Stats) add(kind internal.GeneratedKind) {
+	s.Total++
+	switch kind {
+	case internal.GeneratedValid:
+		s.Valid++
+	case internal.GeneratedDuplicate:
+		s.Duplicate++
+	case internal.GeneratedInvalid:
+		s.Invalid++
+	}
+}
+
+// columns are the CSV columns, named as the fields of loader.VehicleJSON
+var columns = []string{"id", "brand", "model", "registration", "color", "year", "passengers", "max_speed", "fuel_type", "transmission", "weight", "height", "length", "width"}
+
+// WriteJSON is a function that writes n vehicles of the generator as a JSON array in the loader.VehicleJSON schema
+// - the rows are encoded one at a time, so the memory does not grow with n
+// - units: units in which the quantities are written
+func WriteJSON(w io.Writer, g internal.GeneratorVehicle, n int, units internal.Units) (s Stats, err error) {
+	bw := bufio.NewWriter(w)
+	if _, err = bw.WriteString("["); err != nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		v, kind := g.Next()
+		var b []byte
+		b, err = json.Marshal(vehicleJSON(v, units))
+		if err != nil {
+			return
+		}
+		sep := ",\n  "
+		if i == 0 {
+			sep = "\n  "
+		}
+		if _, err = bw.WriteString(sep); err != nil {
+			return
+		}
+		if _, err = bw.Write(b); err != nil {
+			return
+		}
+		s.add(kind)
+	}
+	if n > 0 {
+		if _, err = bw.WriteString("\n"); err != nil {
+			return
+		}
+	}
+	if _, err = bw.WriteString("]\n"); err != nil {
+		return
+	}
+	err = bw.Flush()
+	return
+}
+
+// WriteCSV is a function that writes n vehicles of the generator as CSV with a header row
+// - the rows are written one at a time, so the memory does not grow with n
+// - units: units in which the quantities are written
+func WriteCSV(w io.Writer, g internal.GeneratorVehicle, n int, units internal.Units) (s Stats, err error) {
+	cw := csv.NewWriter(bufio.NewWriter(w))
+	if err = cw.Write(columns); err != nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		v, kind := g.Next()
+		vh := vehicleJSON(v, units)
+		err = cw.Write([]string{
+			strconv.Itoa(vh.Id),
+			vh.Brand,
+			vh.Model,
+			vh.Registration,
+			vh.Color,
+			strconv.Itoa(vh.FabricationYear),
+			strconv.Itoa(vh.Capacity),
+			formatFloat(vh.MaxSpeed),
+			vh.FuelType,
+			vh.Transmission,
+			formatFloat(vh.Weight),
+			formatFloat(vh.Height),
+			formatFloat(vh.Length),
+			formatFloat(vh.Width),
+		})
+		if err != nil {
+			return
+		}
+		s.add(kind)
+	}
+	cw.Flush()
+	err = cw.Error()
+	return
+}
+
+// vehicleJSON is a function that returns the vehicle in the loader.VehicleJSON schema
+func vehicleJSON(v internal.Vehicle, units internal.Units) loader.VehicleJSON {
+	return loader.VehicleJSON{
+		Id:              v.Id,
+		Brand:           v.Brand,
+		Model:           v.Model,
+		Registration:    v.Registration,
+		Color:           v.Color,
+		FabricationYear: v.FabricationYear,
+		Capacity:        v.Capacity,
+		MaxSpeed:        round(v.MaxSpeed.In(units.Speed)),
+		FuelType:        string(v.FuelType),
+		Transmission:    string(v.Transmission),
+		Weight:          round(v.Weight.In(units.Mass)),
+		Height:          round(v.Height.In(units.Length)),
+		Length:          round(v.Length.In(units.Length)),
+		Width:           round(v.Width.In(units.Length)),
+	}
+}
+
+// round is a function that rounds a quantity to two decimals
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// formatFloat is a function that returns the shortest representation of a quantity
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HandlerAudit is a struct with methods that represent handlers for the audit trail
+type HandlerAudit struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceAudit
+}
+
+// NewHandlerAudit is a function that returns a new instance of HandlerAudit
+func NewHandlerAudit(sv internal.ServiceAudit) *HandlerAudit {
+	return &HandlerAudit{sv: sv}
+}
+
+// Find returns a handler that returns the audit records that match the query
+// - query: vehicle_id, actor, fleet, since (RFC 3339), offset, limit (default 100, max 1000). All optional
+// - a principal of a single fleet only gets the records of its fleet
+func (h *HandlerAudit) Find() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		var query internal.AuditQuery
+		if r.URL.Query().Has("vehicle_id") {
+			var err error
+			query.VehicleId, err = strconv.Atoi(r.URL.Query().Get("vehicle_id"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid vehicle_id")
+				return
+			}
+		}
+		query.Actor = r.URL.Query().Get("actor")
+		query.Fleet = r.URL.Query().Get("fleet")
+		if p, ok := internal.PrincipalFromContext(r.Context()); ok && p.Fleet != "" {
+			if query.Fleet != "" && !p.CanAccessFleet(query.Fleet) {
+				response.Problem(w, http.StatusForbidden, "fleet "+query.Fleet+" not allowed")
+				return
+			}
+			query.Fleet = p.Fleet
+		}
+		if r.URL.Query().Has("since") {
+			var err error
+			query.Since, err = time.Parse(time.RFC3339, r.URL.Query().Get("since"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid since")
+				return
+			}
+		}
+
+		query.Limit = 100
+		for name, value := range map[string]*int{"offset": &query.Offset, "limit": &query.Limit} {
+			if !r.URL.Query().Has(name) {
+				continue
+			}
+			var err error
+			*value, err = strconv.Atoi(r.URL.Query().Get(name))
+			if err != nil || *value < 0 || (name == "limit" && (*value == 0 || *value > 1000)) {
+				response.Error(w, http.StatusBadRequest, "invalid "+name)
+				return
+			}
+		}
+
+		// process
+		rc, err := h.sv.Find(query)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		// response
+		data := make([]internal.AuditRecordJSON, 0, len(rc))
+		for _, record := range rc {
+			data = append(data, internal.NewAuditRecordJSON(record))
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "audit records found",
+			"data":    data,
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"app/internal"
+	"encoding/json"
+	"time"
+)
+
+// EventJSON is a struct that represents an event in JSON format
+// - the vehicle is in metric units
+type EventJSON struct {
+	Id        string               `json:"id"`
+	Type      internal.EventType   `json:"type"`
+	Time      time.Time            `json:"time"`
+	Fleet     string               `json:"fleet"`
+	Actor     string               `json:"actor"`
+	RequestId string               `json:"request_id"`
+	Vehicle   *VehicleResponseJSON `json:"vehicle"`
+}
+
+// NewEventJSON is a function that serializes an event
+func NewEventJSON(e internal.Event) EventJSON {
+	eventJSON := EventJSON{
+		Id:        e.Id,
+		Type:      e.Type,
+		Time:      e.Time,
+		Fleet:     e.Fleet,
+		Actor:     e.Actor,
+		RequestId: e.RequestId,
+	}
+	if e.Vehicle != nil {
+		v := NewVehicleResponseJSON(*e.Vehicle, internal.UnitsMetric)
+		eventJSON.Vehicle = &v
+	}
+	return eventJSON
+}
+
+// EncodeEvent is a function that returns the JSON body of an event, as delivered to the webhooks
+func EncodeEvent(e internal.Event) (body []byte, err error) {
+	body, err = json.Marshal(NewEventJSON(e))
+	return
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"net/http"
+	"sort"
+)
+
+// HandlerFleet is a struct with methods that represent handlers for the fleets
+type HandlerFleet struct {
+	// fleets are the names of the fleets served by the application
+	fleets []string
+}
+
+// NewHandlerFleet is a function that returns a new instance of HandlerFleet
+func NewHandlerFleet(fleets []string) *HandlerFleet {
+	sorted := append([]string(nil), fleets...)
+	sort.Strings(sorted)
+	return &HandlerFleet{fleets: sorted}
+}
+
+// List returns a handler that returns the names of the fleets the caller can access
+func (h *HandlerFleet) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		p, authenticated := internal.PrincipalFromContext(r.Context())
+
+		// process
+		data := make([]string, 0, len(h.fleets))
+		for _, fleet := range h.fleets {
+			if authenticated && !p.CanAccessFleet(fleet) {
+				continue
+			}
+			data = append(data, fleet)
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "fleets found",
+			"data":    data,
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerMaintenance is a struct with methods that represent handlers for the maintenance records
+type HandlerMaintenance struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceMaintenance
+}
+
+// NewHandlerMaintenance is a function that returns a new instance of HandlerMaintenance
+func NewHandlerMaintenance(sv internal.ServiceMaintenance) *HandlerMaintenance {
+	return &HandlerMaintenance{sv: sv}
+}
+
+// OverdueVehicleJSON is a struct that represents a vehicle overdue for service in JSON format
+type OverdueVehicleJSON struct {
+	Vehicle     VehicleResponseJSON `json:"vehicle"`
+	LastService *string             `json:"last_service"`
+	DueDate     string              `json:"due_date"`
+	DaysOverdue int                 `json:"days_overdue"`
+}
+
+// FindByVehicleId returns a handler that returns the maintenance records of the vehicle that matches the id
+func (h *HandlerMaintenance) FindByVehicleId() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		m, err := h.sv.FindByVehicleId(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		data := make([]internal.MaintenanceJSON, 0, len(m))
+		for _, rc := range m {
+			data = append(data, internal.NewMaintenanceJSON(rc, u))
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "maintenance records found",
+			"units":   NewUnitsJSON(u),
+			"data":    data,
+		})
+	}
+}
+
+// Create returns a handler that adds a maintenance record to the vehicle that matches the id
+func (h *HandlerMaintenance) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var body internal.MaintenanceJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+
+		m, err := body.Maintenance(u)
+		if err != nil {
+			response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		m.Id, m.VehicleId = 0, id
+
+		// process
+		if err := h.sv.Create(r.Context(), &m); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, internal.ErrServiceInvalidMaintenance):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "maintenance record created",
+			"units":   NewUnitsJSON(u),
+			"data":    internal.NewMaintenanceJSON(m, u),
+		})
+	}
+}
+
+// Overdue returns a handler that returns the vehicles overdue for service, most overdue first
+// - query at (YYYY-MM-DD, RFC 3339 or unix seconds): instant of the check. Default now
+func (h *HandlerMaintenance) Overdue() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		at := time.Now()
+		if r.URL.Query().Has("at") {
+			value := r.URL.Query().Get("at")
+			at, err = time.Parse(internal.MaintenanceDateLayout, value)
+			if err != nil {
+				at, err = parseInstant(value)
+			}
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid at")
+				return
+			}
+		}
+
+		// process
+		o, err := h.sv.Overdue(at)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		// response
+		data := make([]OverdueVehicleJSON, 0, len(o))
+		for _, ov := range o {
+			overdueJSON := OverdueVehicleJSON{
+				Vehicle:     NewVehicleResponseJSON(ov.Vehicle, u),
+				DueDate:     ov.DueDate.Format(internal.MaintenanceDateLayout),
+				DaysOverdue: ov.DaysOverdue,
+			}
+			if !ov.LastService.IsZero() {
+				lastService := ov.LastService.Format(internal.MaintenanceDateLayout)
+				overdueJSON.LastService = &lastService
+			}
+			data = append(data, overdueJSON)
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "overdue vehicles found",
+			"units":   NewUnitsJSON(u),
+			"data":    data,
+		})
+	}
+}
@@ -0,0 +1,1479 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/openapi"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// OpenAPIDocument is a function that returns the OpenAPI document of the routes served by the handlers
+// - keep it in sync with application.ApplicationDefault.SetUp: a test fails if they drift
+// - deprecated: the versions of the api whose operations are marked as deprecated (v1 or v2)
+func OpenAPIDocument(deprecated ...string) *openapi.Document {
+	doc := &openapi.Document{
+		OpenAPI: "3.1.0",
+		Info: openapi.Info{
+			Title:       "Vehicles API",
+			Version:     "1.0.0",
+			Description: "Fleet of vehicles: finders, aggregates, full-text search, mutations with history and audit trail.",
+		},
+		Paths: map[string]*openapi.PathItem{},
+		Components: openapi.Components{
+			Schemas: openAPISchemas(),
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				"apiKey":     {Type: "apiKey", Name: "X-API-Key", In: "header"},
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	// vehicles
+	doc.Paths["/vehicles"] = &openapi.PathItem{
+		Post: operation("createVehicle", "Create a vehicle", internal.RoleEditor,
+			[]*openapi.Parameter{paramUnits()},
+			body("VehicleInput"),
+			map[string]*openapi.Response{
+				"201": envelope("vehicle created", openapi.Ref("Vehicle")),
+				"400": errorResponse("invalid units or body"),
+				"409": errorResponse("vehicle or registration already exists"),
+				"422": errorResponse("invalid vehicle attributes or registration format"),
+			}),
+	}
+	doc.Paths["/vehicles/{id}"] = &openapi.PathItem{
+		Get: operation("findVehicleById", "Get a vehicle by id", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramAsOf(), paramUnits()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicle found", openapi.Ref("Vehicle")),
+				"400": errorResponse("invalid id, as_of or units"),
+				"404": errorResponse("vehicle not found"),
+			}),
+		Put: operation("updateVehicle", "Update a vehicle", internal.RoleEditor,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+			body("VehicleInput"),
+			map[string]*openapi.Response{
+				"200": envelope("vehicle updated", openapi.Ref("Vehicle")),
+				"400": errorResponse("invalid id, units or body"),
+				"404": errorResponse("vehicle not found"),
+				"409": errorResponse("registration already exists"),
+				"422": errorResponse("invalid vehicle attributes or registration format"),
+			}),
+		Delete: operation("deleteVehicle", "Delete a vehicle (soft delete)", internal.RoleEditor,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle")},
+			nil,
+			map[string]*openapi.Response{
+				"204": {Description: "vehicle deleted"},
+				"400": errorResponse("invalid id"),
+				"404": errorResponse("vehicle not found"),
+			}),
+	}
+	doc.Paths["/vehicles/{id}/history"] = &openapi.PathItem{
+		Get: operation("findVehicleHistory", "Get every version of a vehicle", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicle history found", &openapi.Schema{Type: "array", Items: openapi.Ref("VehicleVersion")}),
+				"400": errorResponse("invalid id or units"),
+				"404": errorResponse("vehicle not found"),
+			}),
+	}
+	doc.Paths["/vehicles/{id}/restore"] = &openapi.PathItem{
+		Post: operation("restoreVehicle", "Restore a deleted vehicle", internal.RoleEditor,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicle restored", openapi.Ref("Vehicle")),
+				"400": errorResponse("invalid id or units"),
+				"404": errorResponse("vehicle not found"),
+				"409": errorResponse("vehicle not deleted or its registration was taken"),
+			}),
+	}
+	doc.Paths["/vehicles/enums"] = &openapi.PathItem{
+		Get: operation("findEnums", "Get the allowed values of the enum attributes", internal.RoleReader,
+			nil,
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("enums found", openapi.Ref("Enums")),
+			}),
+	}
+	doc.Paths["/vehicles/search"] = &openapi.PathItem{
+		Get: operation("searchVehicles", "Full-text search over brand, model, color and registration", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("q", &openapi.Schema{Type: "string"}, "text to search, typos and prefixes are tolerated", true),
+				paramQuery("limit", &openapi.Schema{Type: "integer", Minimum: number(1), Maximum: number(100)}, "maximum number of results (default 20)", false),
+				paramUnits(),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicles found", &openapi.Schema{Type: "array", Items: openapi.Ref("SearchResult")}),
+				"400": errorResponse("invalid q, limit or units"),
+			}),
+	}
+	doc.Paths["/vehicles/recommend"] = &openapi.PathItem{
+		Post: operation("recommendVehicles", "Get the vehicles that meet hard constraints, ranked by weighted soft preferences", internal.RoleReader,
+			[]*openapi.Parameter{paramUnits()},
+			body("RecommendInput"),
+			map[string]*openapi.Response{
+				"200": envelope("vehicles recommended", &openapi.Schema{Type: "array", Items: openapi.Ref("Recommendation")}),
+				"400": errorResponse("invalid body or units"),
+				"422": errorResponse("invalid constraints or preferences"),
+			}),
+	}
+	doc.Paths["/vehicles/recommend"].Post.Description = "Attributes: " + fmt.Sprint(internal.VehicleMetrics) + ". " +
+		"The score of a preference is the position of the value in the range of the attribute among the vehicles that meet the constraints, from 0 to 1. " +
+		"The score of a vehicle is the sum of the contributions: the scores weighted by the share of each preference in the total weight."
+	doc.Paths["/vehicles/{id}/similar"] = &openapi.PathItem{
+		Get: operation("findSimilarVehicles", "Get the vehicles nearest to a vehicle over the normalized numeric attributes", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramPath("id", "integer", "id of the vehicle"),
+				paramQuery("k", &openapi.Schema{Type: "integer", Minimum: number(1), Maximum: number(100)}, "number of vehicles (default 10)", false),
+				paramUnits(),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("similar vehicles found", &openapi.Schema{Type: "array", Items: openapi.Ref("Similar")}),
+				"400": errorResponse("invalid id, k or units"),
+				"404": errorResponse("vehicle not found"),
+			}),
+	}
+	doc.Paths["/vehicles/{id}/similar"].Get.Description = "Attributes: " + fmt.Sprint(internal.VehicleMetrics) + ", each one scaled to [0, 1] by its range in the fleet."
+	doc.Paths["/vehicles/registration/{registration}"] = &openapi.PathItem{
+		Get: operation("findVehiclesByRegistration", "Get the vehicles by registration (more than one only for the duplicates of a loaded dataset)", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("registration", "string", "registration, compared in its canonical form"), paramAsOf(), paramUnits()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicles found", openapi.Ref("VehicleMap")),
+				"400": errorResponse("invalid as_of or units"),
+				"404": errorResponse("vehicle not found"),
+			}),
+	}
+	doc.Paths["/vehicles/color/{color}/year/{year}"] = &openapi.PathItem{
+		Get: operation("findVehiclesByColorAndYear", "Get vehicles by color and year", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("color", "string", "color, case and accent insensitive"), paramPath("year", "integer", "fabrication year"), paramAsOf(), paramUnits()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicles found", openapi.Ref("VehicleMap")),
+				"400": errorResponse("invalid color, year, as_of or units"),
+			}),
+	}
+	doc.Paths["/vehicles/brand/{brand}/between/{start_year}/{end_year}"] = &openapi.PathItem{
+		Get: operation("findVehiclesByBrandAndYearRange", "Get vehicles by brand between years", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("brand", "string", "brand, case and accent insensitive"), paramPath("start_year", "integer", "first fabrication year"), paramPath("end_year", "integer", "last fabrication year"), paramAsOf(), paramUnits()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicles found", openapi.Ref("VehicleMap")),
+				"400": errorResponse("invalid brand, start_year, end_year, as_of or units"),
+			}),
+	}
+	doc.Paths["/vehicles/average_speed/brand/{brand}"] = &openapi.PathItem{
+		Get: operation("averageMaxSpeedByBrand", "Get the average max speed of a brand", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("brand", "string", "brand, case and accent insensitive"), paramAsOf(), paramUnits()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("average max speed found", &openapi.Schema{Type: "number"}),
+				"400": errorResponse("invalid brand, as_of or units"),
+				"404": errorResponse("vehicles not found"),
+			}),
+	}
+	doc.Paths["/vehicles/average_capacity/brand/{brand}"] = &openapi.PathItem{
+		Get: operation("averageCapacityByBrand", "Get the average capacity of a brand", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("brand", "string", "brand, case and accent insensitive"), paramAsOf()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("average capacity found", &openapi.Schema{Type: "integer"}),
+				"400": errorResponse("invalid brand or as_of"),
+				"404": errorResponse("vehicles not found"),
+			}),
+	}
+	doc.Paths["/vehicles/compare"] = &openapi.PathItem{
+		Get: operation("compareVehicles", "Get vehicles side by side, attribute by attribute", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("ids", &openapi.Schema{Type: "string"}, "comma separated ids of the vehicles, at least two distinct, at most "+fmt.Sprint(internal.MaxCompareIds), true),
+				paramAsOf(),
+				paramUnits(),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicles compared", openapi.Ref("Comparison")),
+				"400": errorResponse("invalid ids, as_of or units"),
+				"404": errorResponse("vehicle not found"),
+			}),
+	}
+	doc.Paths["/vehicles/compare"].Get.Description = "Attributes: " + fmt.Sprint(internal.VehicleMetrics) + ". A higher max speed and capacity are better, a lower weight and dimensions too. " +
+		"The percentiles are the percentages of the vehicles of the same brand, and of the fleet, with a value lower or equal."
+	doc.Paths["/vehicles/histogram"] = &openapi.PathItem{
+		Get: operation("histogramVehicles", "Get distribution of an attribute among the vehicles that match the filters", internal.RoleReader,
+			append([]*openapi.Parameter{
+				paramQuery("field", &openapi.Schema{Type: "string"}, "numeric or categorical attribute", true),
+				paramQuery("bins", &openapi.Schema{Type: "integer", Minimum: number(1), Maximum: number(1000)}, "number of buckets of the same width between the lowest and the highest value (default 10, numeric fields)", false),
+				paramQuery("edges", &openapi.Schema{Type: "string"}, "comma separated bounds of the buckets, strictly increasing (numeric fields, instead of bins)", false),
+				paramQuery("group_by", &openapi.Schema{Type: "string", Enum: vehicleCategories()}, "categorical attribute that splits the counts in series", false),
+				paramQuery("format", &openapi.Schema{Type: "string", Enum: []any{"json", "csv"}}, "format of the response (default json)", false),
+				paramAsOf(),
+				paramUnits(),
+			}, paramsFilter()...),
+			nil,
+			map[string]*openapi.Response{
+				"200": {
+					Description: "histogram computed",
+					Content: map[string]*openapi.MediaType{
+						"application/json": envelope("histogram computed", openapi.Ref("Histogram")).Content["application/json"],
+						"text/csv":         {Schema: &openapi.Schema{Type: "string", Description: "a row per bucket (label, and lower and upper for numeric fields), a column per series (count without group_by)"}},
+					},
+				},
+				"400": errorResponse("invalid field, buckets, group_by, filters, format, as_of or units"),
+			}),
+	}
+	doc.Paths["/vehicles/histogram"].Get.Description = "Numeric fields: " + fmt.Sprint(internal.VehicleMetrics) + ", counted in buckets that include their lower bound, the last one its upper bound too; values out of the edges are not counted. " +
+		"Categorical fields: " + fmt.Sprint(internal.VehicleCategories) + ", a bucket per value, the most frequent first."
+	doc.Paths["/vehicles/trends"] = &openapi.PathItem{
+		Get: operation("trendVehicles", "Get averages of an attribute by fabrication year among the vehicles that match the filters", internal.RoleReader,
+			append([]*openapi.Parameter{
+				paramQuery("metric", &openapi.Schema{Type: "string", Enum: vehicleMetrics()}, "numeric attribute averaged", true),
+				paramQuery("by", &openapi.Schema{Type: "string", Enum: []any{"year"}}, "axis of the trend (default year)", false),
+				paramQuery("group", &openapi.Schema{Type: "string", Enum: vehicleCategories()}, "categorical attribute that splits the vehicles in series", false),
+				paramQuery("window", &openapi.Schema{Type: "integer", Minimum: number(1), Maximum: number(50)}, "years of the moving average, the year and the previous ones (default 3)", false),
+				paramAsOf(),
+				paramUnits(),
+			}, paramsFilter()...),
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("trends computed", openapi.Ref("Trend")),
+				"400": errorResponse("invalid metric, by, group, window, filters, as_of or units"),
+			}),
+	}
+	doc.Paths["/vehicles/trends"].Get.Description = "Every series has a point for every year between the first and the last fabrication year of the vehicles: the years without vehicles of the series have a zero count and null values. " +
+		"The moving average is over the vehicles of the window, the delta is the difference with the average of the previous year. " +
+		"The years can span at most " + fmt.Sprint(internal.MaxTrendYears) + " years."
+	doc.Paths["/vehicles/weight"] = &openapi.PathItem{
+		Get: operation("searchVehiclesByWeightRange", "Get vehicles by weight range (all vehicles without range)", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("weight_min", &openapi.Schema{Type: "number"}, "minimum weight, used with weight_max", false),
+				paramQuery("weight_max", &openapi.Schema{Type: "number"}, "maximum weight, used with weight_min", false),
+				paramAsOf(),
+				paramUnits(),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicles found", openapi.Ref("VehicleMap")),
+				"400": errorResponse("invalid weight_min, weight_max, as_of or units"),
+			}),
+	}
+
+	// maintenance
+	doc.Paths["/vehicles/{id}/maintenance"] = &openapi.PathItem{
+		Get: operation("findVehicleMaintenance", "Get the maintenance records of a vehicle, oldest first", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("maintenance records found", &openapi.Schema{Type: "array", Items: openapi.Ref("Maintenance")}),
+				"400": errorResponse("invalid id or units"),
+				"404": errorResponse("vehicle not found"),
+			}),
+		Post: operation("createVehicleMaintenance", "Add a maintenance record to a vehicle", internal.RoleEditor,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+			body("MaintenanceInput"),
+			map[string]*openapi.Response{
+				"201": envelope("maintenance record created", openapi.Ref("Maintenance")),
+				"400": errorResponse("invalid id, units or body"),
+				"404": errorResponse("vehicle not found"),
+				"422": errorResponse("invalid maintenance attributes"),
+			}),
+	}
+	doc.Paths["/vehicles/maintenance/overdue"] = &openapi.PathItem{
+		Get: operation("findVehiclesOverdueForService", "Get the vehicles overdue for service, most overdue first", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("at", &openapi.Schema{Type: "string"}, "day (YYYY-MM-DD) or instant (RFC 3339 or unix seconds) of the check (default now)", false),
+				paramUnits(),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("overdue vehicles found", &openapi.Schema{Type: "array", Items: openapi.Ref("OverdueVehicle")}),
+				"400": errorResponse("invalid at or units"),
+			}),
+	}
+	doc.Paths["/vehicles/maintenance/overdue"].Get.Description = "The service interval depends on the age of the vehicle: 24 months under 3 years, " +
+		"12 months under 10 years, 6 months otherwise. It starts at the last service, or at the start of the fabrication year."
+
+	// reservations
+	doc.Paths["/vehicles/{id}/reservations"] = &openapi.PathItem{
+		Get: operation("findVehicleReservations", "Get the reservations of a vehicle, by start", internal.RoleReader,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle")},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("reservations found", &openapi.Schema{Type: "array", Items: openapi.Ref("Reservation")}),
+				"400": errorResponse("invalid id"),
+				"404": errorResponse("vehicle not found"),
+			}),
+		Post: operation("bookVehicle", "Book a vehicle for a trip", internal.RoleEditor,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle")},
+			body("ReservationInput"),
+			map[string]*openapi.Response{
+				"201": envelope("reservation created", openapi.Ref("Reservation")),
+				"400": errorResponse("invalid id or body"),
+				"404": errorResponse("vehicle not found"),
+				"409": errorResponse("the vehicle is already booked in the interval"),
+				"422": errorResponse("empty interval or passengers above the capacity of the vehicle"),
+			}),
+	}
+	doc.Paths["/vehicles/{id}/reservations/{reservation_id}"] = &openapi.PathItem{
+		Delete: operation("cancelReservation", "Cancel a reservation of a vehicle", internal.RoleEditor,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramPath("reservation_id", "integer", "id of the reservation")},
+			nil,
+			map[string]*openapi.Response{
+				"204": {Description: "reservation cancelled"},
+				"400": errorResponse("invalid id or reservation_id"),
+				"404": errorResponse("reservation not found"),
+			}),
+	}
+	doc.Paths["/vehicles/{id}/reservations/{reservation_id}"].Delete.Responses["403"] = problemResponse("role editor required, or a reservation of another holder and not an admin")
+	doc.Paths["/vehicles/available"] = &openapi.PathItem{
+		Get: operation("findAvailableVehicles", "Get the vehicles free for a trip", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("from", &openapi.Schema{Type: "string"}, "instant (RFC 3339 or unix seconds) when the trip starts", true),
+				paramQuery("to", &openapi.Schema{Type: "string"}, "instant (RFC 3339 or unix seconds) when the trip ends", true),
+				paramQuery("passengers", &openapi.Schema{Type: "integer", Minimum: number(0)}, "number of people of the trip", false),
+				paramQuery("brand", &openapi.Schema{Type: "string"}, "brand, case and accent insensitive", false),
+				paramQuery("fuel_type", &openapi.Schema{Type: "string"}, "fuel type, aliases accepted (e.g. gas)", false),
+				paramUnits(),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("vehicles found", openapi.Ref("VehicleMap")),
+				"400": errorResponse("invalid from, to, passengers, fuel_type or units"),
+			}),
+	}
+
+	// telemetry
+	doc.Paths["/vehicles/{id}/telemetry"] = &openapi.PathItem{
+		Get: operation("findVehicleTelemetry", "Get the readings of a vehicle, oldest first", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramPath("id", "integer", "id of the vehicle"),
+				paramQuery("from", &openapi.Schema{Type: "string"}, "instant (RFC 3339 or unix seconds) from which readings are returned", false),
+				paramQuery("to", &openapi.Schema{Type: "string"}, "instant (RFC 3339 or unix seconds) until which readings are returned, excluded", false),
+				paramQuery("step", &openapi.Schema{Type: "string"}, "width of the buckets the readings are downsampled to (e.g. 5m)", false),
+				paramUnits(),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("telemetry found", &openapi.Schema{Type: "array", Items: openapi.Ref("Telemetry")}),
+				"400": errorResponse("invalid id, from, to, step or units"),
+				"404": errorResponse("vehicle not found"),
+			}),
+		Post: operation("ingestVehicleTelemetry", "Store readings of a vehicle", internal.RoleEditor,
+			[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+			&openapi.RequestBody{
+				Required: true,
+				Content: map[string]*openapi.MediaType{
+					"application/json": {Schema: openapi.Ref("Telemetry")},
+					contentTypeNDJSON:  {Schema: &openapi.Schema{Type: "string", Description: "batch of readings, one Telemetry object per line"}},
+				},
+			},
+			map[string]*openapi.Response{
+				"201": envelope("telemetry ingested", openapi.Ref("Ingest")),
+				"400": errorResponse("invalid id, units or body"),
+				"404": errorResponse("vehicle not found"),
+				"413": errorResponse("batch above 1 MiB or 1000 readings"),
+				"422": errorResponse("invalid reading, or reading not after the latest one"),
+			}),
+	}
+	doc.Paths["/vehicles/{id}/telemetry"].Get.Description = "With a step, each bucket is a reading at its start with the mean speed, " +
+		"and the position and odometer of its latest reading."
+	doc.Paths["/vehicles/{id}/telemetry"].Post.Description = "A batch is stored whole or not at all. A reading above the max speed of the vehicle " +
+		"raises an overspeed alert. A reading farther from the previous one than 1.5 times the max speed allows, or with a lower odometer, raises an impossible-jump alert."
+	doc.Paths["/vehicles/alerts"] = &openapi.PathItem{
+		Get: operation("findAlerts", "Get the alerts raised by the telemetry, oldest first", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("vehicle_id", &openapi.Schema{Type: "integer"}, "id of the vehicle that reported the reading", false),
+				paramQuery("kind", &openapi.Schema{Type: "string", Enum: []any{string(internal.AlertKindOverspeed), string(internal.AlertKindImpossibleJump)}}, "rule broken by the reading", false),
+				paramQuery("pending", &openapi.Schema{Type: "boolean"}, "only the alerts that were not acknowledged", false),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("alerts found", &openapi.Schema{Type: "array", Items: openapi.Ref("Alert")}),
+				"400": errorResponse("invalid vehicle_id, kind or pending"),
+			}),
+	}
+	doc.Paths["/vehicles/alerts/{alert_id}/acknowledge"] = &openapi.PathItem{
+		Post: operation("acknowledgeAlert", "Acknowledge an alert", internal.RoleEditor,
+			[]*openapi.Parameter{paramPath("alert_id", "integer", "id of the alert")},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("alert acknowledged", openapi.Ref("Alert")),
+				"400": errorResponse("invalid alert_id"),
+				"404": errorResponse("alert not found"),
+			}),
+	}
+
+	// audit
+	doc.Paths["/audit"] = &openapi.PathItem{
+		Get: operation("findAuditRecords", "Get the audit trail of the vehicle mutations", internal.RoleAdmin,
+			[]*openapi.Parameter{
+				paramQuery("vehicle_id", &openapi.Schema{Type: "integer"}, "id of the mutated vehicle", false),
+				paramQuery("actor", &openapi.Schema{Type: "string"}, "subject that made the mutation", false),
+				paramQuery("fleet", &openapi.Schema{Type: "string"}, "fleet of the mutated vehicle (a principal of a single fleet only gets its fleet)", false),
+				paramQuery("since", &openapi.Schema{Type: "string", Format: "date-time"}, "RFC 3339 instant from which records are returned", false),
+				paramQuery("offset", &openapi.Schema{Type: "integer", Minimum: number(0)}, "number of matching records skipped (default 0)", false),
+				paramQuery("limit", &openapi.Schema{Type: "integer", Minimum: number(1), Maximum: number(1000)}, "maximum number of records (default 100)", false),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("audit records found", &openapi.Schema{Type: "array", Items: openapi.Ref("AuditRecord")}),
+				"400": errorResponse("invalid vehicle_id, since, offset or limit"),
+			}),
+	}
+	doc.Paths["/audit"].Get.Responses["403"] = problemResponse("role admin required or fleet not allowed")
+
+	// live events
+	doc.Paths["/vehicles/events"] = &openapi.PathItem{
+		Get: operation("streamVehicleEvents", "Stream the changes of the vehicles as server-sent events", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("brand", &openapi.Schema{Type: "string"}, "brand of the changed vehicles, case and accent insensitive", false),
+				paramQuery("color", &openapi.Schema{Type: "string"}, "color of the changed vehicles, case and accent insensitive", false),
+				paramQuery("last_event_id", &openapi.Schema{Type: "integer", Minimum: number(0)}, "id of the last event seen, for the clients that cannot send the Last-Event-ID header", false),
+				{Name: "Last-Event-ID", In: "header", Description: "id of the last event seen: the buffered events after it are replayed", Schema: &openapi.Schema{Type: "integer", Minimum: number(0)}},
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": {Description: "text/event-stream of events with the position in the stream as id, the Event type as event and the Event as data", Content: map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}}},
+				"400": errorResponse("invalid last event id"),
+			}),
+	}
+	doc.Paths["/vehicles/events"].Get.Description = "Dataset events are sent whatever the filters. Comments are sent as heartbeats. " +
+		"When some events after the last event id are not buffered anymore, an event of type gap with {last_event_id} is sent first. " +
+		"A client that falls behind is disconnected."
+
+	doc.Paths["/vehicles/changes"] = &openapi.PathItem{
+		Get: operation("findVehicleChanges", "Get the changes of the vehicles since a position of the sequence of the fleet", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("since", &openapi.Schema{Type: "integer", Minimum: number(0)}, "seq of the previous changes (0 or missing: the whole fleet)", false),
+				paramUnits(),
+			},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("changes found", openapi.Ref("Changes")),
+				"400": errorResponse("invalid since or units"),
+			}),
+	}
+	doc.Paths["/vehicles/changes"].Get.Description = "Each vehicle appears once, with its last state. The seq of the response is the since of the next request. " +
+		"A since that is stale (the dataset was reloaded, a tombstone after it was evicted) or unknown (e.g. from before a restart) returns the whole fleet with resync."
+
+	// dataset
+	doc.Paths["/dataset/reload"] = &openapi.PathItem{
+		Post: operation("reloadDataset", "Reload the vehicles from the dataset of the fleet", internal.RoleAdmin,
+			nil,
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("dataset reloaded", openapi.Ref("Reload")),
+			}),
+	}
+	doc.Paths["/dataset/reload"].Post.Responses["500"] = errorResponse("dataset could not be loaded or internal error")
+	doc.Paths["/dataset/reload"].Post.Description = "The changes made since the previous load are discarded, every change is kept as a version in history. " +
+		"The reload is audited as a single record and published as a single dataset.reloaded event, to the stream, the feed of changes and the webhooks. " +
+		"A dataset that cannot be loaded leaves the fleet as it was."
+
+	// webhooks
+	webhookId := paramPath("id", "integer", "id of the webhook")
+	doc.Paths["/webhooks"] = &openapi.PathItem{
+		Get: operation("findWebhooks", "Get the webhooks (a principal of a single fleet only gets the ones of its fleet)", internal.RoleAdmin,
+			nil,
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("webhooks found", &openapi.Schema{Type: "array", Items: openapi.Ref("Webhook")}),
+			}),
+		Post: operation("createWebhook", "Subscribe an url to the events", internal.RoleAdmin,
+			nil,
+			body("WebhookInput"),
+			map[string]*openapi.Response{
+				"201": envelope("webhook created", openapi.Ref("Webhook")),
+				"400": errorResponse("invalid body"),
+				"422": errorResponse("invalid url, events or fleet"),
+			}),
+	}
+	doc.Paths["/webhooks"].Post.Description = "Events: " + fmt.Sprint(internal.EventTypes) + ". Each one is posted as an Event, " +
+		"signed in the " + internal.HeaderWebhookSignature + " header: sha256= and the hex HMAC-SHA256 of \"<" + internal.HeaderWebhookTimestamp + ">.<body>\" keyed by the secret. " +
+		"Failed deliveries (no 2xx status) are retried with exponential backoff and end in the dead letters of the webhook. " +
+		"Urls whose host is or resolves to a loopback, private, link-local or unspecified address are invalid, unless the server allows them."
+	doc.Paths["/webhooks"].Post.Responses["403"] = problemResponse("role admin required or fleet not allowed")
+	doc.Paths["/webhooks/{id}"] = &openapi.PathItem{
+		Get: operation("findWebhook", "Get a webhook", internal.RoleAdmin,
+			[]*openapi.Parameter{webhookId},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("webhook found", openapi.Ref("Webhook")),
+				"400": errorResponse("invalid id"),
+				"404": errorResponse("webhook not found"),
+			}),
+		Delete: operation("deleteWebhook", "Delete a webhook, dropping its pending retries", internal.RoleAdmin,
+			[]*openapi.Parameter{webhookId},
+			nil,
+			map[string]*openapi.Response{
+				"204": {Description: "webhook deleted"},
+				"400": errorResponse("invalid id"),
+				"404": errorResponse("webhook not found"),
+			}),
+	}
+	doc.Paths["/webhooks/{id}/deliveries"] = &openapi.PathItem{
+		Get: operation("findWebhookDeliveries", "Get the delivery attempts of a webhook, oldest first", internal.RoleAdmin,
+			[]*openapi.Parameter{webhookId},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("deliveries found", &openapi.Schema{Type: "array", Items: openapi.Ref("Delivery")}),
+				"400": errorResponse("invalid id"),
+				"404": errorResponse("webhook not found"),
+			}),
+	}
+	doc.Paths["/webhooks/{id}/dead-letters"] = &openapi.PathItem{
+		Get: operation("findWebhookDeadLetters", "Get the events that could not be delivered to a webhook, oldest first", internal.RoleAdmin,
+			[]*openapi.Parameter{webhookId},
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("dead letters found", &openapi.Schema{Type: "array", Items: openapi.Ref("DeadLetter")}),
+				"400": errorResponse("invalid id"),
+				"404": errorResponse("webhook not found"),
+			}),
+	}
+	doc.Paths["/webhooks/{id}/dead-letters/{dead_letter_id}/redeliver"] = &openapi.PathItem{
+		Post: operation("redeliverWebhookDeadLetter", "Deliver again the event of a dead letter, with new retries", internal.RoleAdmin,
+			[]*openapi.Parameter{webhookId, paramPath("dead_letter_id", "integer", "id of the dead letter")},
+			nil,
+			map[string]*openapi.Response{
+				"202": {Description: "delivery started"},
+				"400": errorResponse("invalid id or dead_letter_id"),
+				"404": errorResponse("webhook or dead letter not found"),
+			}),
+	}
+
+	// json-rpc
+	doc.Paths["/rpc"] = &openapi.PathItem{
+		Post: operation("callRPC", "Call the vehicle service methods over JSON-RPC 2.0", internal.RoleReader,
+			nil,
+			body("RPCRequest"),
+			map[string]*openapi.Response{
+				"200": {Description: "response object or batch of response objects", Content: map[string]*openapi.MediaType{"application/json": {Schema: openapi.Ref("RPCResponse")}}},
+				"204": {Description: "only notifications were sent"},
+			}),
+	}
+	doc.Paths["/rpc"].Post.Description = "Methods: vehicles.findById, vehicles.findByColorAndYear, vehicles.findByBrandAndYearRange, " +
+		"vehicles.averageMaxSpeedByBrand, vehicles.averageCapacityByBrand, vehicles.searchByWeightRange, vehicles.findHistory " +
+		"and, with role editor, vehicles.create, vehicles.update, vehicles.delete, vehicles.restore. " +
+		"Params are named like the REST parameters (as_of, units, ...) and can also be given by position. " +
+		"Bodies over 1 MiB and batches over 100 requests are invalid requests (-32600)."
+
+	// graphql
+	graphQLResponses := func() map[string]*openapi.Response {
+		return map[string]*openapi.Response{
+			"200": {Description: "executed query, with data and field errors", Content: map[string]*openapi.MediaType{"application/json": {Schema: openapi.Ref("GraphQLResult")}}},
+			"400": {Description: "query rejected before its execution (syntax, validation, variables, or deeper than 15 levels or with more than 1000 selections)", Content: map[string]*openapi.MediaType{"application/json": {Schema: openapi.Ref("GraphQLResult")}}},
+			"413": {Description: "body larger than 1 MiB", Content: map[string]*openapi.MediaType{"application/json": {Schema: openapi.Ref("GraphQLResult")}}},
+		}
+	}
+	doc.Paths["/graphql"] = &openapi.PathItem{
+		Get: operation("queryGraphQL", "Execute a GraphQL query from the query string", internal.RoleReader,
+			[]*openapi.Parameter{
+				paramQuery("query", &openapi.Schema{Type: "string"}, "GraphQL document", true),
+				paramQuery("operationName", &openapi.Schema{Type: "string"}, "operation to execute when the document has several", false),
+				paramQuery("variables", &openapi.Schema{Type: "string"}, "JSON object with the values of the variables", false),
+			},
+			nil,
+			graphQLResponses()),
+		Post: operation("postGraphQL", "Execute a GraphQL query from the body", internal.RoleReader,
+			nil,
+			&openapi.RequestBody{
+				Required: true,
+				Content: map[string]*openapi.MediaType{
+					"application/json":    {Schema: openapi.Ref("GraphQLRequest")},
+					"application/graphql": {Schema: &openapi.Schema{Type: "string"}},
+				},
+			},
+			graphQLResponses()),
+	}
+
+	// version 2: the vehicles in the shape of the body and the finders by the filters of the query instead of path segments
+	// - the routes that respond with the vehicles of the version 1 and the protocols are only served by the version 1
+	v2 := make(map[string]*openapi.PathItem)
+	for path, item := range doc.Paths {
+		if path == "/rpc" || path == "/graphql" || strings.HasPrefix(path, "/vehicles") && path != "/vehicles/enums" {
+			continue
+		}
+		v2[path] = item
+	}
+	for path, item := range openAPIPathsV2() {
+		if item.Delete == nil && doc.Paths[path] != nil {
+			item.Delete = doc.Paths[path].Delete
+		}
+		v2[path] = item
+	}
+
+	// fleets: the routes above, except the audit trail, scoped to a named fleet
+	doc.Paths["/fleets"] = &openapi.PathItem{
+		Get: operation("findFleets", "Get the fleets the caller can access", internal.RoleReader,
+			nil,
+			nil,
+			map[string]*openapi.Response{
+				"200": envelope("fleets found", &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}),
+			}),
+	}
+	v2["/fleets"] = doc.Paths["/fleets"]
+	fleetPaths(doc.Paths)
+	fleetPaths(v2)
+
+	// versions: the routes above are the version v1, served under /v1 and without a version prefix (never deprecated), the version 2 under /v2
+	v1 := maps.Clone(doc.Paths)
+	for path, item := range v1 {
+		doc.Paths["/v1"+path] = versionPathItem(item, "v1", slices.Contains(deprecated, "v1"))
+	}
+	for path, item := range v2 {
+		doc.Paths["/v2"+path] = versionPathItem(item, "v2", slices.Contains(deprecated, "v2"))
+	}
+
+	// metrics
+	doc.Paths["/metrics"] = &openapi.PathItem{
+		Get: operation("getMetrics", "Get the usage of the versions of the api", internal.RoleAdmin,
+			nil,
+			nil,
+			map[string]*openapi.Response{
+				"200": {Description: "counters in the Prometheus text format: api_requests_total by version (v1, v2 or unversioned), method and code", Content: map[string]*openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}}},
+			}),
+	}
+	doc.Paths["/metrics"].Get.Tags = []string{"metrics"}
+
+	// documentation
+	doc.Paths["/openapi.json"] = &openapi.PathItem{
+		Get: &openapi.Operation{
+			OperationId: "getOpenAPIDocument",
+			Summary:     "Get this OpenAPI document",
+			Tags:        []string{"documentation"},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "OpenAPI document", Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "object"}}}},
+			},
+		},
+	}
+	doc.Paths["/docs"] = &openapi.PathItem{
+		Get: &openapi.Operation{
+			OperationId: "getDocumentation",
+			Summary:     "Get the HTML viewer of this document",
+			Tags:        []string{"documentation"},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "HTML page", Content: map[string]*openapi.MediaType{"text/html": {Schema: &openapi.Schema{Type: "string"}}}},
+			},
+		},
+	}
+
+	return doc
+}
+
+// openAPIPathsV2 is a function that returns the paths of the version 2 of the api that differ from the version 1
+// - the DELETE of a path of the version 1 is kept
+func openAPIPathsV2() map[string]*openapi.PathItem {
+	return map[string]*openapi.PathItem{
+		"/vehicles": {
+			Get: operation("findVehicles", "Get the vehicles that match the filters", internal.RoleReader,
+				append(paramsFilter(), paramAsOf(), paramUnits()),
+				nil,
+				map[string]*openapi.Response{
+					"200": envelope("vehicles found", &openapi.Schema{Type: "array", Items: openapi.Ref("VehicleV2")}),
+					"400": errorResponse("invalid filters, as_of or units"),
+				}),
+			Post: operation("createVehicle", "Create a vehicle", internal.RoleEditor,
+				[]*openapi.Parameter{paramUnits()},
+				body("VehicleInput"),
+				map[string]*openapi.Response{
+					"201": envelope("vehicle created", openapi.Ref("VehicleV2")),
+					"400": errorResponse("invalid units or body"),
+					"409": errorResponse("vehicle or registration already exists"),
+					"422": errorResponse("invalid vehicle attributes or registration format"),
+				}),
+		},
+		"/vehicles/average": {
+			Get: operation("averageVehicles", "Get the average of an attribute among the vehicles that match the filters", internal.RoleReader,
+				append([]*openapi.Parameter{paramQuery("metric", &openapi.Schema{Type: "string", Enum: vehicleMetrics()}, "averaged attribute", true)}, append(paramsFilter(), paramAsOf(), paramUnits())...),
+				nil,
+				map[string]*openapi.Response{
+					"200": envelope("average found", openapi.Ref("Average")),
+					"400": errorResponse("invalid metric, filters, as_of or units"),
+					"404": errorResponse("vehicles not found"),
+				}),
+		},
+		"/vehicles/registration/{registration}": {
+			Get: operation("findVehiclesByRegistration", "Get the vehicles by registration (more than one only for the duplicates of a loaded dataset)", internal.RoleReader,
+				[]*openapi.Parameter{paramPath("registration", "string", "registration, compared in its canonical form"), paramAsOf(), paramUnits()},
+				nil,
+				map[string]*openapi.Response{
+					"200": envelope("vehicles found", &openapi.Schema{Type: "array", Items: openapi.Ref("VehicleV2")}),
+					"400": errorResponse("invalid as_of or units"),
+					"404": errorResponse("vehicle not found"),
+				}),
+		},
+		"/vehicles/{id}": {
+			Get: operation("findVehicleById", "Get a vehicle by id", internal.RoleReader,
+				[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramAsOf(), paramUnits()},
+				nil,
+				map[string]*openapi.Response{
+					"200": envelope("vehicle found", openapi.Ref("VehicleV2")),
+					"400": errorResponse("invalid id, as_of or units"),
+					"404": errorResponse("vehicle not found"),
+				}),
+			Put: operation("updateVehicle", "Update a vehicle", internal.RoleEditor,
+				[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+				body("VehicleInput"),
+				map[string]*openapi.Response{
+					"200": envelope("vehicle updated", openapi.Ref("VehicleV2")),
+					"400": errorResponse("invalid id, units or body"),
+					"404": errorResponse("vehicle not found"),
+					"409": errorResponse("registration already exists"),
+					"422": errorResponse("invalid vehicle attributes or registration format"),
+				}),
+		},
+		"/vehicles/{id}/history": {
+			Get: operation("findVehicleHistory", "Get every version of a vehicle", internal.RoleReader,
+				[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+				nil,
+				map[string]*openapi.Response{
+					"200": envelope("vehicle history found", &openapi.Schema{Type: "array", Items: openapi.Ref("VehicleVersionV2")}),
+					"400": errorResponse("invalid id or units"),
+					"404": errorResponse("vehicle not found"),
+				}),
+		},
+		"/vehicles/{id}/restore": {
+			Post: operation("restoreVehicle", "Restore a deleted vehicle", internal.RoleEditor,
+				[]*openapi.Parameter{paramPath("id", "integer", "id of the vehicle"), paramUnits()},
+				nil,
+				map[string]*openapi.Response{
+					"200": envelope("vehicle restored", openapi.Ref("VehicleV2")),
+					"400": errorResponse("invalid id or units"),
+					"404": errorResponse("vehicle not found"),
+					"409": errorResponse("vehicle not deleted or its registration was taken"),
+				}),
+		},
+	}
+}
+
+// operation is a function that returns an authenticated operation
+// - the responses of the authentication and authorization middlewares and the internal error are added
+func operation(id string, summary string, role internal.Role, params []*openapi.Parameter, rb *openapi.RequestBody, responses map[string]*openapi.Response) *openapi.Operation {
+	responses["401"] = problemResponse("missing or invalid credentials")
+	responses["403"] = problemResponse("role " + string(role) + " required")
+	responses["500"] = errorResponse("internal error")
+
+	tag := "vehicles"
+	if role == internal.RoleAdmin {
+		tag = "audit"
+	}
+	return &openapi.Operation{
+		OperationId: id,
+		Summary:     summary,
+		Tags:        []string{tag},
+		Parameters:  params,
+		RequestBody: rb,
+		Responses:   responses,
+		Security:    []map[string][]string{{"apiKey": {}}, {"bearerAuth": {}}},
+		Role:        string(role),
+	}
+}
+
+// fleetPathItem is a function that returns a copy of a path item of the default fleet scoped to the {fleet} path parameter
+func fleetPathItem(item *openapi.PathItem) *openapi.PathItem {
+	scoped := func(op *openapi.Operation) *openapi.Operation {
+		if op == nil {
+			return nil
+		}
+		c := *op
+		c.OperationId = "fleet" + strings.ToUpper(op.OperationId[:1]) + op.OperationId[1:]
+		c.Parameters = append([]*openapi.Parameter{paramPath("fleet", "string", "name of the fleet")}, op.Parameters...)
+		c.Responses = make(map[string]*openapi.Response, len(op.Responses)+1)
+		for code, rs := range op.Responses {
+			c.Responses[code] = rs
+		}
+		c.Responses["403"] = problemResponse("role " + op.Role + " required or fleet not allowed")
+		if _, ok := c.Responses["404"]; !ok {
+			c.Responses["404"] = problemResponse("fleet not found")
+		} else {
+			c.Responses["404"] = &openapi.Response{
+				Description: c.Responses["404"].Description + " or fleet not found",
+				Content: map[string]*openapi.MediaType{
+					"application/json":         {Schema: openapi.Ref("Error")},
+					"application/problem+json": {Schema: openapi.Ref("Problem")},
+				},
+			}
+		}
+		return &c
+	}
+	return &openapi.PathItem{Get: scoped(item.Get), Post: scoped(item.Post), Put: scoped(item.Put), Patch: scoped(item.Patch), Delete: scoped(item.Delete)}
+}
+
+// fleetPaths is a function that adds to the paths a copy of the routes of the default fleet scoped to the {fleet} path parameter
+func fleetPaths(paths map[string]*openapi.PathItem) {
+	for path, item := range paths {
+		if path != "/rpc" && path != "/graphql" && !strings.HasPrefix(path, "/vehicles") && !strings.HasPrefix(path, "/dataset") {
+			continue
+		}
+		paths["/fleets/{fleet}"+path] = fleetPathItem(item)
+	}
+}
+
+// versionPathItem is a function that returns a copy of a path item served under the prefix of a version of the api
+func versionPathItem(item *openapi.PathItem, version string, deprecated bool) *openapi.PathItem {
+	versioned := func(op *openapi.Operation) *openapi.Operation {
+		if op == nil {
+			return nil
+		}
+		c := *op
+		c.OperationId = version + strings.ToUpper(op.OperationId[:1]) + op.OperationId[1:]
+		c.Deprecated = deprecated
+		return &c
+	}
+	return &openapi.PathItem{Get: versioned(item.Get), Post: versioned(item.Post), Put: versioned(item.Put), Patch: versioned(item.Patch), Delete: versioned(item.Delete)}
+}
+
+// paramPath is a function that returns a required path parameter
+func paramPath(name string, typ string, description string) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "path", Required: true, Description: description, Schema: &openapi.Schema{Type: typ}}
+}
+
+// paramQuery is a function that returns a query parameter
+func paramQuery(name string, schema *openapi.Schema, description string, required bool) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Required: required, Description: description, Schema: schema}
+}
+
+// paramAsOf is a function that returns the as_of query parameter
+func paramAsOf() *openapi.Parameter {
+	return paramQuery("as_of", &openapi.Schema{Type: "string"}, "instant (RFC 3339 or unix seconds) at which the fleet is queried, 400 if the versions of a vehicle at that instant were dropped from history", false)
+}
+
+// paramUnits is a function that returns the units query parameter
+func paramUnits() *openapi.Parameter {
+	return paramQuery("units", &openapi.Schema{Type: "string", Enum: []any{internal.UnitsMetric.System, internal.UnitsImperial.System}}, "units of the quantities in filters, body and response (default metric)", false)
+}
+
+// paramsFilter is a function that returns the query parameters of the filters of the listings
+func paramsFilter() []*openapi.Parameter {
+	params := []*openapi.Parameter{
+		paramQuery("brand", &openapi.Schema{Type: "string"}, "brand, case and accent insensitive", false),
+		paramQuery("color", &openapi.Schema{Type: "string"}, "color, case and accent insensitive", false),
+		paramQuery("fuel_type", &openapi.Schema{Type: "string"}, "comma separated fuel types", false),
+		paramQuery("transmission", &openapi.Schema{Type: "string"}, "comma separated transmissions", false),
+		paramQuery("year_from", &openapi.Schema{Type: "integer"}, "first fabrication year", false),
+		paramQuery("year_to", &openapi.Schema{Type: "integer"}, "last fabrication year", false),
+	}
+	for _, m := range internal.VehicleMetrics {
+		params = append(params,
+			paramQuery(string(m)+"_min", &openapi.Schema{Type: "number"}, "minimum "+string(m)+" in the requested units", false),
+			paramQuery(string(m)+"_max", &openapi.Schema{Type: "number"}, "maximum "+string(m)+" in the requested units", false),
+		)
+	}
+	return params
+}
+
+// vehicleMetrics is a function that returns the numeric attributes as an enum
+func vehicleMetrics() []any {
+	metrics := make([]any, 0, len(internal.VehicleMetrics))
+	for _, m := range internal.VehicleMetrics {
+		metrics = append(metrics, string(m))
+	}
+	return metrics
+}
+
+// vehicleCategories is a function that returns the categorical attributes as an enum
+func vehicleCategories() []any {
+	categories := make([]any, 0, len(internal.VehicleCategories))
+	for _, c := range internal.VehicleCategories {
+		categories = append(categories, string(c))
+	}
+	return categories
+}
+
+// body is a function that returns a required JSON request body
+func body(schema string) *openapi.RequestBody {
+	return &openapi.RequestBody{
+		Required: true,
+		Content:  map[string]*openapi.MediaType{"application/json": {Schema: openapi.Ref(schema)}},
+	}
+}
+
+// envelope is a function that returns a response with the {message, data} envelope of response.JSON
+func envelope(message string, data *openapi.Schema) *openapi.Response {
+	return &openapi.Response{
+		Description: message,
+		Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{
+			Type:     "object",
+			Required: []string{"message", "data"},
+			Properties: map[string]*openapi.Schema{
+				"message": {Type: "string"},
+				"units":   openapi.Ref("Units"),
+				"data":    data,
+			},
+		}}},
+	}
+}
+
+// errorResponse is a function that returns a response written by response.Error
+func errorResponse(description string) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{"application/json": {Schema: openapi.Ref("Error")}},
+	}
+}
+
+// problemResponse is a function that returns a response written by response.Problem
+func problemResponse(description string) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{"application/problem+json": {Schema: openapi.Ref("Problem")}},
+	}
+}
+
+// number is a function that returns a pointer to a number, for the schema ranges
+func number(n float64) *float64 {
+	return &n
+}
+
+// openAPISchemas is a function that returns the component schemas: the JSON structs of the handlers
+func openAPISchemas() map[string]*openapi.Schema {
+	fuelTypes := make([]any, 0, len(internal.FuelTypes))
+	for _, f := range internal.FuelTypes {
+		fuelTypes = append(fuelTypes, string(f))
+	}
+	transmissions := make([]any, 0, len(internal.Transmissions))
+	for _, t := range internal.Transmissions {
+		transmissions = append(transmissions, string(t))
+	}
+	maintenanceTypes := make([]any, 0, len(internal.MaintenanceTypes))
+	for _, m := range internal.MaintenanceTypes {
+		maintenanceTypes = append(maintenanceTypes, string(m))
+	}
+	eventTypes := make([]any, 0, len(internal.EventTypes))
+	for _, e := range internal.EventTypes {
+		eventTypes = append(eventTypes, string(e))
+	}
+	str := func() *openapi.Schema { return &openapi.Schema{Type: "string"} }
+	integer := func() *openapi.Schema { return &openapi.Schema{Type: "integer"} }
+	num := func() *openapi.Schema { return &openapi.Schema{Type: "number", Minimum: number(0)} }
+
+	return map[string]*openapi.Schema{
+		// VehicleResponseJSON
+		"Vehicle": {
+			Type:        "object",
+			Description: "vehicle, quantities in the requested units",
+			Properties: map[string]*openapi.Schema{
+				"Id": integer(), "Brand": str(), "Model": str(), "Registration": str(), "Color": str(),
+				"FabricationYear": integer(), "Capacity": integer(), "MaxSpeed": num(),
+				"FuelType":     {Type: "string", Enum: fuelTypes},
+				"Transmission": {Type: "string", Enum: transmissions},
+				"Weight":       num(), "Height": num(), "Length": num(), "Width": num(),
+			},
+			Required: []string{"Id", "Brand", "Model", "Registration", "Color", "FabricationYear", "Capacity", "MaxSpeed", "FuelType", "Transmission", "Weight", "Height", "Length", "Width"},
+		},
+		"VehicleMap": {
+			Type:                 "object",
+			Description:          "vehicles by id",
+			AdditionalProperties: openapi.Ref("Vehicle"),
+		},
+		// VehicleJSON
+		"VehicleInput": {
+			Type:        "object",
+			Description: "vehicle attributes, quantities in the requested units. Enums accept aliases (e.g. gas)",
+			Properties: map[string]*openapi.Schema{
+				"id": integer(), "brand": str(), "model": str(), "registration": str(), "color": str(),
+				"year": {Type: "integer", Description: "fabrication year, from " + fmt.Sprint(internal.MinFabricationYear) + " to the next year"}, "passengers": integer(), "max_speed": num(),
+				"fuel_type": str(), "transmission": str(),
+				"weight": num(), "height": num(), "length": num(), "width": num(),
+			},
+			Required: []string{"brand", "model", "year", "fuel_type", "transmission"},
+		},
+		// VehicleJSON, in the responses of the version 2
+		"VehicleV2": {
+			Type:        "object",
+			Description: "vehicle in the shape of the body, quantities in the requested units",
+			Properties: map[string]*openapi.Schema{
+				"id": integer(), "brand": str(), "model": str(), "registration": str(), "color": str(),
+				"year": integer(), "passengers": integer(), "max_speed": num(),
+				"fuel_type":    {Type: "string", Enum: fuelTypes},
+				"transmission": {Type: "string", Enum: transmissions},
+				"weight":       num(), "height": num(), "length": num(), "width": num(),
+			},
+			Required: []string{"id", "brand", "model", "registration", "color", "year", "passengers", "max_speed", "fuel_type", "transmission", "weight", "height", "length", "width"},
+		},
+		// VehicleVersionV2JSON
+		"VehicleVersionV2": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"version":    integer(),
+				"valid_from": {Type: "string", Format: "date-time", Nullable: true, Description: "null for the loaded dataset"},
+				"valid_to":   {Type: "string", Format: "date-time", Nullable: true, Description: "null for the current version"},
+				"deleted":    {Type: "boolean"},
+				"vehicle":    openapi.Ref("VehicleV2"),
+			},
+			Required: []string{"version", "valid_from", "valid_to", "deleted", "vehicle"},
+		},
+		// AverageJSON
+		"Units": {
+			Type:        "object",
+			Description: "units of the quantities of the response, in the responses of the routes with the units parameter",
+			Properties: map[string]*openapi.Schema{
+				"system":   {Type: "string", Enum: []any{internal.UnitsMetric.System, internal.UnitsImperial.System}},
+				"mass":     {Type: "string", Enum: []any{string(internal.MassUnitKilogram), string(internal.MassUnitPound)}},
+				"length":   {Type: "string", Enum: []any{string(internal.LengthUnitCentimeter), string(internal.LengthUnitInch)}},
+				"speed":    {Type: "string", Enum: []any{string(internal.SpeedUnitKilometersPerHour), string(internal.SpeedUnitMilesPerHour)}},
+				"distance": {Type: "string", Enum: []any{string(internal.DistanceUnitKilometer), string(internal.DistanceUnitMile)}},
+			},
+			Required: []string{"system", "mass", "length", "speed", "distance"},
+		},
+		"Average": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"metric":  {Type: "string", Enum: vehicleMetrics()},
+				"unit":    {Type: "string", Description: "unit of the average in the requested units, empty for counts"},
+				"average": {Type: "number"},
+			},
+			Required: []string{"metric", "unit", "average"},
+		},
+		// VehicleVersionJSON
+		"VehicleVersion": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"version":    integer(),
+				"valid_from": {Type: "string", Format: "date-time", Nullable: true, Description: "null for the loaded dataset"},
+				"valid_to":   {Type: "string", Format: "date-time", Nullable: true, Description: "null for the current version"},
+				"deleted":    {Type: "boolean"},
+				"vehicle":    openapi.Ref("Vehicle"),
+			},
+			Required: []string{"version", "valid_from", "valid_to", "deleted", "vehicle"},
+		},
+		// SearchResultJSON
+		"SearchResult": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"score":   {Type: "number"},
+				"vehicle": openapi.Ref("Vehicle"),
+			},
+			Required: []string{"score", "vehicle"},
+		},
+		"Enums": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"fuel_type":    {Type: "array", Items: &openapi.Schema{Type: "string", Enum: fuelTypes}},
+				"transmission": {Type: "array", Items: &openapi.Schema{Type: "string", Enum: transmissions}},
+				"maintenance_type": {Type: "array", Items: &openapi.Schema{Type: "string", Enum: maintenanceTypes}},
+			},
+			Required: []string{"fuel_type", "transmission", "maintenance_type"},
+		},
+		// internal.MaintenanceJSON
+		"Maintenance": {
+			Type:        "object",
+			Description: "maintenance record, odometer in the requested units (km or mi)",
+			Properties: map[string]*openapi.Schema{
+				"id": integer(), "vehicle_id": integer(),
+				"date":     {Type: "string", Format: "date"},
+				"odometer": num(),
+				"type":     {Type: "string", Enum: maintenanceTypes},
+				"cost":     num(), "notes": str(), "vendor": str(),
+			},
+			Required: []string{"id", "vehicle_id", "date", "odometer", "type", "cost", "notes", "vendor"},
+		},
+		// internal.MaintenanceJSON, without the ids
+		"MaintenanceInput": {
+			Type:        "object",
+			Description: "maintenance attributes, odometer in the requested units (km or mi)",
+			Properties: map[string]*openapi.Schema{
+				"date":     {Type: "string", Format: "date"},
+				"odometer": num(),
+				"type":     str(),
+				"cost":     num(), "notes": str(), "vendor": str(),
+			},
+			Required: []string{"date", "type"},
+		},
+		// ReservationResponseJSON
+		"Reservation": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"id": integer(), "vehicle_id": integer(),
+				"from":       {Type: "string", Format: "date-time"},
+				"to":         {Type: "string", Format: "date-time", Description: "excluded: the next reservation can start at this instant"},
+				"passengers": integer(),
+				"holder":     str(),
+			},
+			Required: []string{"id", "vehicle_id", "from", "to", "passengers", "holder"},
+		},
+		// ReservationJSON
+		"ReservationInput": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"from":       {Type: "string", Format: "date-time"},
+				"to":         {Type: "string", Format: "date-time"},
+				"passengers": {Type: "integer", Minimum: number(1)},
+			},
+			Required: []string{"from", "to", "passengers"},
+		},
+		// TelemetryJSON
+		"Telemetry": {
+			Type:        "object",
+			Description: "reading, speed and odometer in the requested units (km/h or mph, km or mi)",
+			Properties: map[string]*openapi.Schema{
+				"time":      {Type: "string", Format: "date-time"},
+				"latitude":  {Type: "number", Minimum: number(-90), Maximum: number(90)},
+				"longitude": {Type: "number", Minimum: number(-180), Maximum: number(180)},
+				"speed":     num(), "odometer": num(),
+			},
+			Required: []string{"time", "latitude", "longitude", "speed", "odometer"},
+		},
+		// IngestResponseJSON
+		"Ingest": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"accepted": integer(),
+				"alerts":   {Type: "array", Items: openapi.Ref("Alert")},
+			},
+			Required: []string{"accepted", "alerts"},
+		},
+		// AlertResponseJSON
+		"Alert": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"id": integer(), "vehicle_id": integer(),
+				"kind":            {Type: "string", Enum: []any{string(internal.AlertKindOverspeed), string(internal.AlertKindImpossibleJump)}},
+				"time":            {Type: "string", Format: "date-time", Description: "instant of the reading"},
+				"message":         str(),
+				"acknowledged":    {Type: "boolean"},
+				"acknowledged_by": {Type: "string", Nullable: true},
+				"acknowledged_at": {Type: "string", Format: "date-time", Nullable: true},
+			},
+			Required: []string{"id", "vehicle_id", "kind", "time", "message", "acknowledged", "acknowledged_by", "acknowledged_at"},
+		},
+		// ComparisonJSON
+		"Comparison": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"vehicles":   {Type: "array", Items: openapi.Ref("Vehicle")},
+				"attributes": {Type: "array", Items: openapi.Ref("ComparisonRow")},
+			},
+			Required: []string{"vehicles", "attributes"},
+		},
+		// ComparisonRowJSON
+		"ComparisonRow": {
+			Type:        "object",
+			Description: "numeric attribute of the compared vehicles. values and percentiles are aligned with the vehicles",
+			Properties: map[string]*openapi.Schema{
+				"attribute":        {Type: "string", Enum: vehicleMetrics()},
+				"higher_is_better": {Type: "boolean"},
+				"values":           {Type: "array", Items: &openapi.Schema{Type: "number"}},
+				"best":             {Type: "array", Items: integer(), Description: "ids of the vehicles with the best value"},
+				"worst":            {Type: "array", Items: integer(), Description: "ids of the vehicles with the worst value"},
+				"percentile_brand": {Type: "array", Items: &openapi.Schema{Type: "number"}},
+				"percentile_fleet": {Type: "array", Items: &openapi.Schema{Type: "number"}},
+			},
+			Required: []string{"attribute", "higher_is_better", "values", "best", "worst", "percentile_brand", "percentile_fleet"},
+		},
+		// HistogramJSON
+		"Histogram": {
+			Type:        "object",
+			Description: "distribution of an attribute, bounds in the requested units. counts of the series are aligned with the buckets",
+			Properties: map[string]*openapi.Schema{
+				"field":    str(),
+				"unit":     {Type: "string", Description: "unit of the bounds, only for numeric fields with a unit"},
+				"group_by": {Type: "string", Enum: vehicleCategories()},
+				"buckets":  {Type: "array", Items: openapi.Ref("HistogramBucket")},
+				"series":   {Type: "array", Items: openapi.Ref("HistogramSeries")},
+			},
+			Required: []string{"field", "buckets", "series"},
+		},
+		// HistogramBucketJSON
+		"HistogramBucket": {
+			Type:        "object",
+			Description: "bucket: lower and upper only for numeric fields",
+			Properties: map[string]*openapi.Schema{
+				"label": str(),
+				"lower": {Type: "number"},
+				"upper": {Type: "number"},
+			},
+			Required: []string{"label"},
+		},
+		// HistogramSeriesJSON
+		"HistogramSeries": {
+			Type:        "object",
+			Description: "counts of a group of vehicles, a single series with an empty group without group_by",
+			Properties: map[string]*openapi.Schema{
+				"group":  str(),
+				"counts": {Type: "array", Items: integer()},
+				"total":  integer(),
+			},
+			Required: []string{"group", "counts", "total"},
+		},
+		// TrendJSON
+		"Trend": {
+			Type:        "object",
+			Description: "averages by fabrication year in the requested units. the points of every series are aligned with the years",
+			Properties: map[string]*openapi.Schema{
+				"metric": {Type: "string", Enum: vehicleMetrics()},
+				"unit":   {Type: "string", Description: "unit of the values, only for metrics with a unit"},
+				"by":     {Type: "string", Enum: []any{"year"}},
+				"group":  {Type: "string", Enum: vehicleCategories()},
+				"window": integer(),
+				"years":  {Type: "array", Items: integer()},
+				"series": {Type: "array", Items: openapi.Ref("TrendSeries")},
+			},
+			Required: []string{"metric", "by", "window", "years", "series"},
+		},
+		// TrendSeriesJSON
+		"TrendSeries": {
+			Type:        "object",
+			Description: "evolution of a group of vehicles, a single series with an empty group without group",
+			Properties: map[string]*openapi.Schema{
+				"group":  str(),
+				"points": {Type: "array", Items: openapi.Ref("TrendPoint")},
+			},
+			Required: []string{"group", "points"},
+		},
+		// TrendPointJSON
+		"TrendPoint": {
+			Type:        "object",
+			Description: "fabrication year of a series, null values for the years without vehicles",
+			Properties: map[string]*openapi.Schema{
+				"year":           integer(),
+				"count":          integer(),
+				"average":        {Type: "number", Nullable: true},
+				"moving_average": {Type: "number", Nullable: true},
+				"delta":          {Type: "number", Nullable: true},
+			},
+			Required: []string{"year", "count", "average", "moving_average", "delta"},
+		},
+		// RecommendJSON
+		"RecommendInput": {
+			Type:        "object",
+			Description: "hard constraints (empty or zero: not used, except a bound of min or max, which filters even at 0) and soft preferences",
+			Properties: map[string]*openapi.Schema{
+				"brand":         {Type: "string", Description: "case and accent insensitive"},
+				"fuel_types":    {Type: "array", Items: str(), Description: "allowed fuel types, aliases accepted (e.g. gas)"},
+				"transmissions": {Type: "array", Items: str(), Description: "allowed transmissions, aliases accepted"},
+				"min":           {Type: "object", AdditionalProperties: &openapi.Schema{Type: "number"}, Description: "minimum value by attribute, in the requested units"},
+				"max":           {Type: "object", AdditionalProperties: &openapi.Schema{Type: "number"}, Description: "maximum value by attribute, in the requested units"},
+				"preferences":   {Type: "array", Items: openapi.Ref("RecommendPreference")},
+				"limit":         {Type: "integer", Minimum: number(0), Description: "maximum number of vehicles (0: all)"},
+			},
+		},
+		// RecommendPreferenceJSON
+		"RecommendPreference": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"attribute": {Type: "string", Enum: vehicleMetrics()},
+				"weight":    {Type: "number", Description: "positive, default 1"},
+				"prefer":    {Type: "string", Enum: []any{"higher", "lower"}, Description: "default: higher for max_speed and capacity, lower for weight and dimensions"},
+			},
+			Required: []string{"attribute"},
+		},
+		// RecommendationJSON
+		"Recommendation": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"score":         {Type: "number", Description: "from 0 to 1 (1 without preferences)"},
+				"vehicle":       openapi.Ref("Vehicle"),
+				"contributions": {Type: "array", Items: openapi.Ref("RecommendContribution")},
+			},
+			Required: []string{"score", "vehicle", "contributions"},
+		},
+		// RecommendContributionJSON
+		"RecommendContribution": {
+			Type:        "object",
+			Description: "part of a preference in the score of a vehicle",
+			Properties: map[string]*openapi.Schema{
+				"attribute":    {Type: "string", Enum: vehicleMetrics()},
+				"prefer":       {Type: "string", Enum: []any{"higher", "lower"}},
+				"weight":       {Type: "number"},
+				"value":        {Type: "number", Description: "value of the vehicle, in the requested units"},
+				"score":        {Type: "number", Description: "from 0 (the worst value among the recommended vehicles) to 1 (the best)"},
+				"contribution": {Type: "number", Description: "score weighted by the share of the preference in the total weight"},
+			},
+			Required: []string{"attribute", "prefer", "weight", "value", "score", "contribution"},
+		},
+		// SimilarJSON
+		"Similar": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"distance": {Type: "number", Description: "euclidean distance over the normalized attributes, 0 for identical attributes"},
+				"vehicle":  openapi.Ref("Vehicle"),
+			},
+			Required: []string{"distance", "vehicle"},
+		},
+		// ChangesVehicleJSON
+		"Changes": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"seq":        {Type: "integer", Minimum: number(0), Description: "position of the last change included, the since of the next request"},
+				"resync":     {Type: "boolean", Description: "true if the upserts are the whole fleet: the local copy must be replaced, not patched"},
+				"upserts":    {Type: "array", Items: openapi.Ref("Vehicle"), Description: "vehicles created, updated or restored, by id"},
+				"tombstones": {Type: "array", Items: integer(), Description: "ids of the vehicles deleted, ascending"},
+			},
+			Required: []string{"seq", "resync", "upserts", "tombstones"},
+		},
+		// ReloadVehicleJSON
+		"Reload": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"created": {Type: "integer", Minimum: number(0), Description: "vehicles added or restored"},
+				"updated": {Type: "integer", Minimum: number(0), Description: "vehicles replaced"},
+				"deleted": {Type: "integer", Minimum: number(0), Description: "vehicles removed"},
+			},
+			Required: []string{"created", "updated", "deleted"},
+		},
+		// EventJSON
+		"Event": {
+			Type:        "object",
+			Description: "change of a fleet. vehicle, in metric units, is its state after the change, or before it for deleted vehicles (null for dataset events)",
+			Properties: map[string]*openapi.Schema{
+				"id":         str(),
+				"type":       {Type: "string", Enum: eventTypes},
+				"time":       {Type: "string", Format: "date-time"},
+				"fleet":      str(),
+				"actor":      {Type: "string", Description: "empty for the changes made by the application"},
+				"request_id": str(),
+				"vehicle":    openapi.Ref("Vehicle"),
+			},
+			Required: []string{"id", "type", "time", "fleet", "actor", "request_id", "vehicle"},
+		},
+		// WebhookResponseJSON
+		"Webhook": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"id":         integer(),
+				"url":        str(),
+				"secret":     {Type: "string", Description: "only returned when the webhook is created"},
+				"events":     {Type: "array", Items: &openapi.Schema{Type: "string", Enum: eventTypes}, Description: "empty: every type"},
+				"fleet":      {Type: "string", Description: "empty: every fleet"},
+				"created_by": str(),
+				"created_at": {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"id", "url", "events", "fleet", "created_by", "created_at"},
+		},
+		// WebhookJSON
+		"WebhookInput": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"url":    {Type: "string", Description: "absolute http or https url"},
+				"secret": {Type: "string", Description: "key of the signatures (random if empty)"},
+				"events": {Type: "array", Items: &openapi.Schema{Type: "string", Enum: eventTypes}, Description: "empty: every type"},
+				"fleet":  {Type: "string", Description: "empty: every fleet (a principal of a single fleet gets its fleet)"},
+			},
+			Required: []string{"url"},
+		},
+		// DeliveryJSON
+		"Delivery": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"id":          integer(),
+				"event_id":    str(),
+				"event_type":  {Type: "string", Enum: eventTypes},
+				"attempt":     integer(),
+				"time":        {Type: "string", Format: "date-time"},
+				"status_code": {Type: "integer", Description: "0 if there was no response"},
+				"error":       str(),
+				"status":      {Type: "string", Enum: []any{string(internal.DeliveryStatusDelivered), string(internal.DeliveryStatusRetrying), string(internal.DeliveryStatusDead)}},
+			},
+			Required: []string{"id", "event_id", "event_type", "attempt", "time", "status_code", "error", "status"},
+		},
+		// DeadLetterJSON
+		"DeadLetter": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"id":         integer(),
+				"event":      openapi.Ref("Event"),
+				"attempts":   integer(),
+				"last_error": str(),
+				"time":       {Type: "string", Format: "date-time", Description: "instant of the last attempt"},
+			},
+			Required: []string{"id", "event", "attempts", "last_error", "time"},
+		},
+		// OverdueVehicleJSON
+		"OverdueVehicle": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"vehicle":      openapi.Ref("Vehicle"),
+				"last_service": {Type: "string", Format: "date", Nullable: true, Description: "null if the vehicle was never serviced"},
+				"due_date":     {Type: "string", Format: "date"},
+				"days_overdue": integer(),
+			},
+			Required: []string{"vehicle", "last_service", "due_date", "days_overdue"},
+		},
+		// AuditRecordJSON
+		"AuditRecord": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"id":         {Type: "string", Description: "id of the mutation"},
+				"status":     {Type: "string", Enum: []any{string(internal.AuditStatusCommitted), string(internal.AuditStatusPending)}, Description: "pending: the outcome of the mutation is not recorded"},
+				"timestamp":  {Type: "string", Format: "date-time"},
+				"actor":      str(),
+				"fleet":      str(),
+				"request_id": str(),
+				"action":     {Type: "string", Enum: []any{string(internal.AuditActionCreated), string(internal.AuditActionUpdated), string(internal.AuditActionDeleted), string(internal.AuditActionRestored), string(internal.AuditActionReloaded)}},
+				"vehicle_id": {Type: "integer", Description: "0 for the reload of the dataset, a single record for the fleet"},
+				"changes": {Type: "array", Items: &openapi.Schema{
+					Type: "object",
+					Properties: map[string]*openapi.Schema{
+						"field":  str(),
+						"before": {Description: "value before the mutation, null for created vehicles"},
+						"after":  {Description: "value after the mutation, null for deleted vehicles"},
+					},
+				}},
+			},
+			Required: []string{"id", "status", "timestamp", "actor", "fleet", "request_id", "action", "vehicle_id", "changes"},
+		},
+		// jsonrpc.Request, or a batch of them: the shape is checked by the JSON-RPC server
+		"RPCRequest": {
+			Description: "request object {jsonrpc, method, params, id} or batch (array) of request objects",
+		},
+		// jsonrpc.Response, or a batch of them
+		"RPCResponse": {
+			Description: "response object {jsonrpc, result | error {code, message, data}, id} or batch (array) of response objects",
+		},
+		// graphql.Request
+		"GraphQLRequest": {
+			Type:        "object",
+			Description: "the schema is available by introspection",
+			Properties: map[string]*openapi.Schema{
+				"query":         str(),
+				"operationName": {Type: "string", Nullable: true},
+				"variables":     {Type: "object", Nullable: true},
+			},
+			Required: []string{"query"},
+		},
+		// graphql.Result
+		"GraphQLResult": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"data": {Type: "object", Nullable: true},
+				"errors": {Type: "array", Items: &openapi.Schema{
+					Type: "object",
+					Properties: map[string]*openapi.Schema{
+						"message":   str(),
+						"locations": {Type: "array", Items: &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{"line": integer(), "column": integer()}}},
+						"path":      {Type: "array", Description: "response keys and list indexes"},
+					},
+					Required: []string{"message"},
+				}},
+			},
+		},
+		// response.Error
+		"Error": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"status":  str(),
+				"message": str(),
+			},
+			Required: []string{"status", "message"},
+		},
+		// response.Problem
+		"Problem": {
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"type":   str(),
+				"title":  str(),
+				"status": {Type: "integer", Minimum: number(http.StatusBadRequest), Maximum: number(599)},
+				"detail": str(),
+			},
+			Required: []string{"type", "title", "status"},
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"app/internal"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestId is a middleware that puts the id of the request, set by the RequestID middleware of chi, on the context
+// - the layers below the handlers read it with internal.RequestIdFromContext
+func RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := internal.ContextWithRequestId(r.Context(), middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
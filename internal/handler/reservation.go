@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerReservation is a struct with methods that represent handlers for the reservations
+type HandlerReservation struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceReservation
+}
+
+// NewHandlerReservation is a function that returns a new instance of HandlerReservation
+func NewHandlerReservation(sv internal.ServiceReservation) *HandlerReservation {
+	return &HandlerReservation{sv: sv}
+}
+
+// ReservationJSON is a struct that represents the body of a reservation in JSON format
+type ReservationJSON struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Passengers int       `json:"passengers"`
+}
+
+// ReservationResponseJSON is a struct that represents a reservation in the responses
+type ReservationResponseJSON struct {
+	Id         int       `json:"id"`
+	VehicleId  int       `json:"vehicle_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Passengers int       `json:"passengers"`
+	Holder     string    `json:"holder"`
+}
+
+// NewReservationResponseJSON is a function that serializes a reservation
+func NewReservationResponseJSON(r internal.Reservation) ReservationResponseJSON {
+	return ReservationResponseJSON{
+		Id:         r.Id,
+		VehicleId:  r.VehicleId,
+		From:       r.From,
+		To:         r.To,
+		Passengers: r.Passengers,
+		Holder:     r.Holder,
+	}
+}
+
+// FindByVehicleId returns a handler that returns the reservations of the vehicle that matches the id
+func (h *HandlerReservation) FindByVehicleId() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		rs, err := h.sv.FindByVehicleId(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		data := make([]ReservationResponseJSON, 0, len(rs))
+		for _, reservation := range rs {
+			data = append(data, NewReservationResponseJSON(reservation))
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "reservations found",
+			"data":    data,
+		})
+	}
+}
+
+// Book returns a handler that books the vehicle that matches the id
+func (h *HandlerReservation) Book() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var body ReservationJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		rs := internal.Reservation{
+			VehicleId:  id,
+			From:       body.From,
+			To:         body.To,
+			Passengers: body.Passengers,
+		}
+
+		// process
+		if err := h.sv.Book(r.Context(), &rs); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, internal.ErrServiceInvalidReservation):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			case errors.Is(err, internal.ErrServiceReservationConflict):
+				response.Error(w, http.StatusConflict, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "reservation created",
+			"data":    NewReservationResponseJSON(rs),
+		})
+	}
+}
+
+// Cancel returns a handler that removes a reservation of the vehicle that matches the id
+func (h *HandlerReservation) Cancel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		reservationId, err := strconv.Atoi(chi.URLParam(r, "reservation_id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid reservation_id")
+			return
+		}
+
+		// process
+		if err := h.sv.Cancel(r.Context(), id, reservationId); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceReservationNotFound):
+				response.Error(w, http.StatusNotFound, "reservation not found")
+			case errors.Is(err, internal.ErrServiceReservationForbidden):
+				response.Problem(w, http.StatusForbidden, "reservation of another holder")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Available returns a handler that returns the vehicles free for a trip
+// - query: from and to (RFC 3339 or unix seconds) required. passengers, brand and fuel_type optional
+func (h *HandlerReservation) Available() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		var query internal.AvailabilityQuery
+		query.From, err = parseInstant(r.URL.Query().Get("from"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid from")
+			return
+		}
+		query.To, err = parseInstant(r.URL.Query().Get("to"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid to")
+			return
+		}
+		if r.URL.Query().Has("passengers") {
+			query.Passengers, err = strconv.Atoi(r.URL.Query().Get("passengers"))
+			if err != nil || query.Passengers < 0 {
+				response.Error(w, http.StatusBadRequest, "invalid passengers")
+				return
+			}
+		}
+		query.Brand = r.URL.Query().Get("brand")
+		if r.URL.Query().Has("fuel_type") {
+			query.FuelType, err = internal.ParseFuelType(r.URL.Query().Get("fuel_type"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// process
+		v, err := h.sv.Available(query)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidReservation):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles found",
+			"units":   NewUnitsJSON(u),
+			"data":    NewVehiclesResponseJSON(v, u),
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerTelemetry is a struct with methods that represent handlers for the telemetry and the alerts of the vehicles
+type HandlerTelemetry struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceTelemetry
+}
+
+// NewHandlerTelemetry is a function that returns a new instance of HandlerTelemetry
+func NewHandlerTelemetry(sv internal.ServiceTelemetry) *HandlerTelemetry {
+	return &HandlerTelemetry{sv: sv}
+}
+
+// contentTypeNDJSON is the content type of a batch of readings, one JSON object per line
+const contentTypeNDJSON = "application/x-ndjson"
+
+const (
+	// maxTelemetryBodyBytes is the size of the largest body of an ingestion
+	maxTelemetryBodyBytes = 1 << 20
+	// maxTelemetryReadings is the number of readings of the largest batch
+	maxTelemetryReadings = 1000
+)
+
+// errTelemetryTooLarge is an error that represents a body of an ingestion above the limits
+var errTelemetryTooLarge = fmt.Errorf("body too large: up to %d bytes and %d readings", maxTelemetryBodyBytes, maxTelemetryReadings)
+
+// TelemetryJSON is a struct that represents a reading in JSON format
+type TelemetryJSON struct {
+	Time      time.Time `json:"time"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Speed     float64   `json:"speed"`
+	Odometer  float64   `json:"odometer"`
+}
+
+// NewTelemetryJSON is a function that serializes a reading with the speed and the odometer expressed in the units
+func NewTelemetryJSON(t internal.Telemetry, u internal.Units) TelemetryJSON {
+	return TelemetryJSON{
+		Time:      t.Time,
+		Latitude:  t.Latitude,
+		Longitude: t.Longitude,
+		Speed:     t.Speed.In(u.Speed),
+		Odometer:  t.Odometer.In(u.Distance),
+	}
+}
+
+// Telemetry is a method that deserializes a reading with the speed and the odometer expressed in the units
+func (t TelemetryJSON) Telemetry(u internal.Units) internal.Telemetry {
+	return internal.Telemetry{
+		Time:      t.Time,
+		Latitude:  t.Latitude,
+		Longitude: t.Longitude,
+		Speed:     internal.NewSpeed(t.Speed, u.Speed),
+		Odometer:  internal.NewDistance(t.Odometer, u.Distance),
+	}
+}
+
+// AlertResponseJSON is a struct that represents an alert in the responses
+type AlertResponseJSON struct {
+	Id             int                `json:"id"`
+	VehicleId      int                `json:"vehicle_id"`
+	Kind           internal.AlertKind `json:"kind"`
+	Time           time.Time          `json:"time"`
+	Message        string             `json:"message"`
+	Acknowledged   bool               `json:"acknowledged"`
+	AcknowledgedBy *string            `json:"acknowledged_by"`
+	AcknowledgedAt *time.Time         `json:"acknowledged_at"`
+}
+
+// NewAlertResponseJSON is a function that serializes an alert
+func NewAlertResponseJSON(a internal.Alert) AlertResponseJSON {
+	alertJSON := AlertResponseJSON{
+		Id:           a.Id,
+		VehicleId:    a.VehicleId,
+		Kind:         a.Kind,
+		Time:         a.Time,
+		Message:      a.Message,
+		Acknowledged: a.Acknowledged(),
+	}
+	if a.Acknowledged() {
+		by, at := a.AcknowledgedBy, a.AcknowledgedAt
+		alertJSON.AcknowledgedBy, alertJSON.AcknowledgedAt = &by, &at
+	}
+	return alertJSON
+}
+
+// NewAlertsResponseJSON is a function that serializes alerts
+func NewAlertsResponseJSON(a []internal.Alert) []AlertResponseJSON {
+	data := make([]AlertResponseJSON, 0, len(a))
+	for _, alert := range a {
+		data = append(data, NewAlertResponseJSON(alert))
+	}
+	return data
+}
+
+// IngestResponseJSON is a struct that represents the result of an ingestion in JSON format
+type IngestResponseJSON struct {
+	Accepted int                 `json:"accepted"`
+	Alerts   []AlertResponseJSON `json:"alerts"`
+}
+
+// Ingest returns a handler that stores readings of the vehicle that matches the id
+// - body: a reading (application/json) or a batch of readings, one per line (application/x-ndjson)
+func (h *HandlerTelemetry) Ingest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxTelemetryBodyBytes)
+		var body []TelemetryJSON
+		switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType {
+		case contentTypeNDJSON:
+			body, err = readNDJSON(r)
+			switch {
+			case errors.Is(err, errTelemetryTooLarge):
+				response.Error(w, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			case err != nil:
+				response.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		default:
+			var reading TelemetryJSON
+			if err := request.JSON(r, &reading); err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid body")
+				return
+			}
+			body = []TelemetryJSON{reading}
+		}
+		t := make([]internal.Telemetry, 0, len(body))
+		for _, reading := range body {
+			t = append(t, reading.Telemetry(u))
+		}
+
+		// process
+		a, err := h.sv.Ingest(r.Context(), id, t)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, internal.ErrServiceInvalidTelemetry):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "telemetry ingested",
+			"units":   NewUnitsJSON(u),
+			"data":    IngestResponseJSON{Accepted: len(t), Alerts: NewAlertsResponseJSON(a)},
+		})
+	}
+}
+
+// readNDJSON is a function that decodes the readings of a body with one JSON object per line, skipping blank lines
+// - errTelemetryTooLarge: the body, limited by the caller, or the number of readings is above the limits
+func readNDJSON(r *http.Request) (t []TelemetryJSON, err error) {
+	sc := bufio.NewScanner(r.Body)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		if len(t) == maxTelemetryReadings {
+			err = errTelemetryTooLarge
+			return
+		}
+		var reading TelemetryJSON
+		if err = json.Unmarshal(sc.Bytes(), &reading); err != nil {
+			err = fmt.Errorf("invalid body: line %d", line)
+			return
+		}
+		t = append(t, reading)
+	}
+	if err = sc.Err(); err != nil {
+		var errTooLarge *http.MaxBytesError
+		if errors.As(err, &errTooLarge) {
+			err = errTelemetryTooLarge
+			return
+		}
+		err = errors.New("invalid body")
+		return
+	}
+	if len(t) == 0 {
+		err = errors.New("invalid body: no readings")
+		return
+	}
+	return
+}
+
+// FindRange returns a handler that returns the readings of the vehicle that matches the id
+// - query: from and to (RFC 3339 or unix seconds), step (duration such as 5m) and units optional
+func (h *HandlerTelemetry) FindRange() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var query internal.TelemetryQuery
+		if r.URL.Query().Has("from") {
+			query.From, err = parseInstant(r.URL.Query().Get("from"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid from")
+				return
+			}
+		}
+		if r.URL.Query().Has("to") {
+			query.To, err = parseInstant(r.URL.Query().Get("to"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid to")
+				return
+			}
+		}
+		if r.URL.Query().Has("step") {
+			query.Step, err = time.ParseDuration(r.URL.Query().Get("step"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid step")
+				return
+			}
+		}
+
+		// process
+		t, err := h.sv.FindRange(id, query)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, internal.ErrServiceInvalidTelemetry):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		data := make([]TelemetryJSON, 0, len(t))
+		for _, reading := range t {
+			data = append(data, NewTelemetryJSON(reading, u))
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "telemetry found",
+			"units":   NewUnitsJSON(u),
+			"data":    data,
+		})
+	}
+}
+
+// FindAlerts returns a handler that returns the alerts of the vehicles
+// - query: vehicle_id, kind and pending (true for the unacknowledged ones) optional
+func (h *HandlerTelemetry) FindAlerts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		var query internal.AlertQuery
+		var err error
+		if r.URL.Query().Has("vehicle_id") {
+			query.VehicleId, err = strconv.Atoi(r.URL.Query().Get("vehicle_id"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid vehicle_id")
+				return
+			}
+		}
+		if r.URL.Query().Has("kind") {
+			query.Kind = internal.AlertKind(r.URL.Query().Get("kind"))
+			if query.Kind != internal.AlertKindOverspeed && query.Kind != internal.AlertKindImpossibleJump {
+				response.Error(w, http.StatusBadRequest, "invalid kind")
+				return
+			}
+		}
+		if r.URL.Query().Has("pending") {
+			query.Unacknowledged, err = strconv.ParseBool(r.URL.Query().Get("pending"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid pending")
+				return
+			}
+		}
+
+		// process
+		a, err := h.sv.FindAlerts(query)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "alerts found",
+			"data":    NewAlertsResponseJSON(a),
+		})
+	}
+}
+
+// Acknowledge returns a handler that marks the alert that matches the id as acknowledged
+func (h *HandlerTelemetry) Acknowledge() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "alert_id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid alert_id")
+			return
+		}
+
+		// process
+		a, err := h.sv.Acknowledge(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceAlertNotFound):
+				response.Error(w, http.StatusNotFound, "alert not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "alert acknowledged",
+			"data":    NewAlertResponseJSON(a),
+		})
+	}
+}
@@ -1,169 +1,846 @@
-package handler
-
-import (
-	"app/internal"
-	"app/platform/web/response"
-	"errors"
-	"net/http"
-	"strconv"
-
-	"github.com/go-chi/chi/v5"
-)
-
-// HandlerVehicle is a struct with methods that represent handlers for vehicles
-type HandlerVehicle struct {
-	// sv is the service that will be used by the handler
-	sv internal.ServiceVehicle
-}
-
-// NewHandlerVehicle is a function that returns a new instance of HandlerVehicle
-func NewHandlerVehicle(sv internal.ServiceVehicle) *HandlerVehicle {
-	return &HandlerVehicle{sv: sv}
-}
-
-// FindByColorAndYear returns a handler that returns a map of vehicles that match the color and fabrication year
-func (h *HandlerVehicle) FindByColorAndYear() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// request
-		color := chi.URLParam(r, "color")
-		year, err := strconv.Atoi(chi.URLParam(r, "year"))
-		if err != nil {
-			response.Error(w, http.StatusBadRequest, "invalid year")
-			return
-		}
-
-		// process
-		v, err := h.sv.FindByColorAndYear(color, year)
-		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "internal error")
-			return
-		}
-
-		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "vehicles found",
-			"data": v,
-		})
-	}
-}
-
-// FindByBrandAndYearRange returns a handler that returns a map of vehicles that match the brand and a range of fabrication years
-func (h *HandlerVehicle) FindByBrandAndYearRange() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// request
-		brand := chi.URLParam(r, "brand")
-		startYear, err := strconv.Atoi(chi.URLParam(r, "start_year"))
-		if err != nil {
-			response.Error(w, http.StatusBadRequest, "invalid start_year")
-			return
-		}
-		endYear, err := strconv.Atoi(chi.URLParam(r, "end_year"))
-		if err != nil {
-			response.Error(w, http.StatusBadRequest, "invalid end_year")
-			return
-		}
-
-		// process
-		v, err := h.sv.FindByBrandAndYearRange(brand, startYear, endYear)
-		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "internal error")
-			return
-		}
-
-		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "vehicles found",
-			"data": v,
-		})
-	}
-}
-
-// AverageMaxSpeedByBrand returns a handler that returns the average speed of the vehicles by brand
-func (h *HandlerVehicle) AverageMaxSpeedByBrand() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// request
-		brand := chi.URLParam(r, "brand")
-
-		// process
-		average, err := h.sv.AverageMaxSpeedByBrand(brand)
-		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrServiceNoVehicles):
-				response.Error(w, http.StatusNotFound, "vehicles not found")
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal error")
-			}
-			return
-		}
-
-		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "average max speed found",
-			"data": average,
-		})
-	}
-}
-
-// AverageCapacityByBrand returns a handler that returns the average capacity of the vehicles by brand
-func (h *HandlerVehicle) AverageCapacityByBrand() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// request
-		brand := chi.URLParam(r, "brand")
-
-		// process
-		average, err := h.sv.AverageCapacityByBrand(brand)
-		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrServiceNoVehicles):
-				response.Error(w, http.StatusNotFound, "vehicles not found")
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal error")
-			}
-			return
-		}
-
-		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "average capacity found",
-			"data": average,
-		})
-	}
-}
-
-// SearchByWeightRange returns a handler that returns a map of vehicles that match the weight range
-func (h *HandlerVehicle) SearchByWeightRange() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// request
-		var query internal.SearchQuery
-
-		// check if query exists and decode
-		ok := r.URL.Query().Has("weight_min") && r.URL.Query().Has("weight_max")
-		if ok {
-			var err error
-			query.FromWeight, err = strconv.ParseFloat(r.URL.Query().Get("weight_min"), 64)
-			if err != nil {
-				response.Error(w, http.StatusBadRequest, "invalid weight_min")
-				return
-			}
-
-			query.ToWeight, err = strconv.ParseFloat(r.URL.Query().Get("weight_max"), 64)
-			if err != nil {
-				response.Error(w, http.StatusBadRequest, "invalid weight_max")
-				return
-			}
-		}
-
-		// process
-		v, err := h.sv.SearchByWeightRange(query, ok)
-		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "internal error")
-			return
-		}
-
-		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "vehicles found",
-			"data": v,
-		})
-	}
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerVehicle is a struct with methods that represent handlers for vehicles
+type HandlerVehicle struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceVehicle
+}
+
+// NewHandlerVehicle is a function that returns a new instance of HandlerVehicle
+func NewHandlerVehicle(sv internal.ServiceVehicle) *HandlerVehicle {
+	return &HandlerVehicle{sv: sv}
+}
+
+// service is a method that returns the service for the request
+// - query as_of (RFC 3339 or unix seconds): service over the fleet as it was at that instant
+func (h *HandlerVehicle) service(r *http.Request) (sv internal.ServiceVehicle, err error) {
+	if !r.URL.Query().Has("as_of") {
+		sv = h.sv
+		return
+	}
+
+	t, err := parseInstant(r.URL.Query().Get("as_of"))
+	if err != nil {
+		return
+	}
+	sv, err = h.sv.AsOf(t)
+	return
+}
+
+// asOfMessage is a function that returns the message of an error of the service over the fleet at as_of
+func asOfMessage(err error) string {
+	if errors.Is(err, internal.ErrServiceHistoryTruncated) {
+		return "history truncated before as_of"
+	}
+	return "invalid as_of"
+}
+
+// units is a function that returns the units requested
+// - query units (metric or imperial): units of the quantities in the filters, the body and the response. Default metric
+func units(r *http.Request) (u internal.Units, err error) {
+	if !r.URL.Query().Has("units") {
+		u = internal.UnitsMetric
+		return
+	}
+	u, err = internal.ParseUnits(r.URL.Query().Get("units"))
+	return
+}
+
+// filter is a function that returns the filters of the vehicles requested, the same for every listing
+// - query: brand, color, fuel_type and transmission (comma separated), year_from, year_to, <attribute>_min and <attribute>_max in the units
+func filter(r *http.Request, u internal.Units) (f internal.VehicleFilter, err error) {
+	q := r.URL.Query()
+	f = internal.VehicleFilter{Brand: q.Get("brand"), Color: q.Get("color"), Min: make(map[internal.VehicleMetric]float64), Max: make(map[internal.VehicleMetric]float64)}
+	if q.Has("fuel_type") {
+		for _, value := range strings.Split(q.Get("fuel_type"), ",") {
+			var fuel internal.FuelType
+			if fuel, err = internal.ParseFuelType(strings.TrimSpace(value)); err != nil {
+				return
+			}
+			f.FuelTypes = append(f.FuelTypes, fuel)
+		}
+	}
+	if q.Has("transmission") {
+		for _, value := range strings.Split(q.Get("transmission"), ",") {
+			var t internal.Transmission
+			if t, err = internal.ParseTransmission(strings.TrimSpace(value)); err != nil {
+				return
+			}
+			f.Transmissions = append(f.Transmissions, t)
+		}
+	}
+	for name, year := range map[string]*int{"year_from": &f.FromYear, "year_to": &f.ToYear} {
+		if !q.Has(name) {
+			continue
+		}
+		if *year, err = strconv.Atoi(q.Get(name)); err != nil {
+			err = fmt.Errorf("invalid %s", name)
+			return
+		}
+	}
+	for _, m := range internal.VehicleMetrics {
+		for suffix, bounds := range map[string]map[internal.VehicleMetric]float64{"_min": f.Min, "_max": f.Max} {
+			name := string(m) + suffix
+			if !q.Has(name) {
+				continue
+			}
+			value, errParse := strconv.ParseFloat(q.Get(name), 64)
+			if errParse != nil {
+				err = fmt.Errorf("invalid %s", name)
+				return
+			}
+			bounds[m] = m.From(value, u)
+		}
+	}
+	return
+}
+
+// parseInstant is a function that parses an instant in RFC 3339 or unix seconds
+func parseInstant(value string) (t time.Time, err error) {
+	if seconds, errUnix := strconv.ParseInt(value, 10, 64); errUnix == nil {
+		t = time.Unix(seconds, 0)
+		return
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return
+}
+
+// FindById returns a handler that returns the vehicle that matches the id
+func (h *HandlerVehicle) FindById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		v, err := sv.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicle found",
+			"units": NewUnitsJSON(u),
+			"data": NewVehicleResponseJSON(v, u),
+		})
+	}
+}
+
+// FindByRegistration returns a handler that returns the vehicles that match the registration
+// - the registration is compared in its canonical form
+// - more than one vehicle only for the duplicates of a loaded dataset
+func (h *HandlerVehicle) FindByRegistration() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		registration := chi.URLParam(r, "registration")
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		v, err := sv.FindByRegistration(registration)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles found",
+			"units": NewUnitsJSON(u),
+			"data": NewVehiclesResponseJSON(v, u),
+		})
+	}
+}
+
+// Create returns a handler that adds a vehicle
+func (h *HandlerVehicle) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		var body VehicleJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+
+		v, err := body.Vehicle(u)
+		if err != nil {
+			response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		// process
+		if err := h.sv.Create(r.Context(), &v); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidVehicle):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			case errors.Is(err, internal.ErrServiceVehicleAlreadyExists):
+				response.Error(w, http.StatusConflict, "vehicle already exists")
+			case errors.Is(err, internal.ErrServiceRegistrationConflict):
+				response.Error(w, http.StatusConflict, "registration already exists")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "vehicle created",
+			"units": NewUnitsJSON(u),
+			"data": NewVehicleResponseJSON(v, u),
+		})
+	}
+}
+
+// Update returns a handler that replaces the vehicle that matches the id
+func (h *HandlerVehicle) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var body VehicleJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+
+		v, err := body.Vehicle(u)
+		if err != nil {
+			response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		v.Id = id
+
+		// process
+		if err := h.sv.Update(r.Context(), &v); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidVehicle):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, internal.ErrServiceRegistrationConflict):
+				response.Error(w, http.StatusConflict, "registration already exists")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicle updated",
+			"units": NewUnitsJSON(u),
+			"data": NewVehicleResponseJSON(v, u),
+		})
+	}
+}
+
+// Delete returns a handler that removes the vehicle that matches the id
+func (h *HandlerVehicle) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		if err := h.sv.Delete(r.Context(), id); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusNoContent, nil)
+	}
+}
+
+// FindHistory returns a handler that returns every version of the vehicle that matches the id
+func (h *HandlerVehicle) FindHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		versions, err := h.sv.FindHistory(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		data := NewVehicleVersionsJSON(versions, u)
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicle history found",
+			"units": NewUnitsJSON(u),
+			"data": data,
+		})
+	}
+}
+
+// Restore returns a handler that undeletes the vehicle that matches the id
+func (h *HandlerVehicle) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		v, err := h.sv.Restore(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, internal.ErrServiceVehicleNotDeleted):
+				response.Error(w, http.StatusConflict, "vehicle not deleted")
+			case errors.Is(err, internal.ErrServiceRegistrationConflict):
+				response.Error(w, http.StatusConflict, "registration already exists")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicle restored",
+			"units": NewUnitsJSON(u),
+			"data": NewVehicleResponseJSON(v, u),
+		})
+	}
+}
+
+// Enums returns a handler that returns the allowed values of the enum attributes
+func (h *HandlerVehicle) Enums() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "enums found",
+			"data": map[string]any{
+				"fuel_type":    internal.FuelTypes,
+				"transmission": internal.Transmissions,
+				"maintenance_type": internal.MaintenanceTypes,
+			},
+		})
+	}
+}
+
+// FindByColorAndYear returns a handler that returns a map of vehicles that match the color and fabrication year
+func (h *HandlerVehicle) FindByColorAndYear() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		color := chi.URLParam(r, "color")
+		year, err := strconv.Atoi(chi.URLParam(r, "year"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid year")
+			return
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		v, err := sv.FindByColorAndYear(color, year)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidFilter):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles found",
+			"units": NewUnitsJSON(u),
+			"data": NewVehiclesResponseJSON(v, u),
+		})
+	}
+}
+
+// FindByBrandAndYearRange returns a handler that returns a map of vehicles that match the brand and a range of fabrication years
+func (h *HandlerVehicle) FindByBrandAndYearRange() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		brand := chi.URLParam(r, "brand")
+		startYear, err := strconv.Atoi(chi.URLParam(r, "start_year"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid start_year")
+			return
+		}
+		endYear, err := strconv.Atoi(chi.URLParam(r, "end_year"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid end_year")
+			return
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		v, err := sv.FindByBrandAndYearRange(brand, startYear, endYear)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidFilter):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles found",
+			"units": NewUnitsJSON(u),
+			"data": NewVehiclesResponseJSON(v, u),
+		})
+	}
+}
+
+// AverageMaxSpeedByBrand returns a handler that returns the average speed of the vehicles by brand
+func (h *HandlerVehicle) AverageMaxSpeedByBrand() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		brand := chi.URLParam(r, "brand")
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		average, err := sv.AverageMaxSpeedByBrand(brand)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidFilter):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, internal.ErrServiceNoVehicles):
+				response.Error(w, http.StatusNotFound, "vehicles not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "average max speed found",
+			"units": NewUnitsJSON(u),
+			"data": average.In(u.Speed),
+		})
+	}
+}
+
+// AverageCapacityByBrand returns a handler that returns the average capacity of the vehicles by brand
+func (h *HandlerVehicle) AverageCapacityByBrand() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		brand := chi.URLParam(r, "brand")
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		average, err := sv.AverageCapacityByBrand(brand)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidFilter):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, internal.ErrServiceNoVehicles):
+				response.Error(w, http.StatusNotFound, "vehicles not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "average capacity found",
+			"data": average,
+		})
+	}
+}
+
+// Compare returns a handler that returns vehicles side by side, attribute by attribute
+// - query: ids required (comma separated, at least two, at most internal.MaxCompareIds), units and as_of optional
+func (h *HandlerVehicle) Compare() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		values := strings.Split(r.URL.Query().Get("ids"), ",")
+		if len(values) > internal.MaxCompareIds {
+			response.Error(w, http.StatusBadRequest, fmt.Sprintf("at most %d ids are allowed", internal.MaxCompareIds))
+			return
+		}
+		var ids []int
+		for _, value := range values {
+			id, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid ids")
+				return
+			}
+			ids = append(ids, id)
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		c, err := sv.Compare(ids)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidCompare):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles compared",
+			"units": NewUnitsJSON(u),
+			"data": NewComparisonJSON(c, u),
+		})
+	}
+}
+
+// Histogram returns a handler that returns the distribution of an attribute among the vehicles that match the filters
+// - query: field required (a numeric or a categorical attribute), bins or edges optional (numeric fields), group_by optional
+// - query: the filters of the listings, format optional (json or csv), units optional, as_of optional
+func (h *HandlerVehicle) Histogram() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		q := r.URL.Query()
+		query, err := histogramQuery(r, u)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		format := q.Get("format")
+		if format != "" && format != "json" && format != "csv" {
+			response.Error(w, http.StatusBadRequest, "invalid format")
+			return
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		hs, err := sv.Histogram(query)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidHistogram), errors.Is(err, internal.ErrServiceInvalidFilter):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		hj := NewHistogramJSON(hs, query, u)
+		if format == "csv" {
+			response.CSV(w, http.StatusOK, hj.Records())
+			return
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "histogram computed",
+			"units": NewUnitsJSON(u),
+			"data": hj,
+		})
+	}
+}
+
+// histogramQuery is a function that returns the histogram requested
+func histogramQuery(r *http.Request, u internal.Units) (query internal.HistogramQuery, err error) {
+	q := r.URL.Query()
+	query.VehicleFilter, err = filter(r, u)
+	if err != nil {
+		return
+	}
+
+	// field: numeric or categorical
+	field := q.Get("field")
+	if m, errMetric := internal.ParseVehicleMetric(field); errMetric == nil {
+		query.Metric = m
+	} else if query.Category, err = internal.ParseVehicleCategory(field); err != nil {
+		err = fmt.Errorf("invalid field: %q (allowed: %v, %v)", field, internal.VehicleMetrics, internal.VehicleCategories)
+		return
+	}
+
+	// buckets
+	if q.Has("bins") {
+		if query.Bins, err = strconv.Atoi(q.Get("bins")); err != nil {
+			err = errors.New("invalid bins")
+			return
+		}
+	}
+	if q.Has("edges") {
+		for _, value := range strings.Split(q.Get("edges"), ",") {
+			edge, errParse := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if errParse != nil {
+				err = errors.New("invalid edges")
+				return
+			}
+			query.Edges = append(query.Edges, query.Metric.From(edge, u))
+		}
+	}
+
+	// group
+	if q.Has("group_by") {
+		if query.GroupBy, err = internal.ParseVehicleCategory(q.Get("group_by")); err != nil {
+			err = fmt.Errorf("invalid group_by: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// Trends returns a handler that returns the average of an attribute by fabrication year among the vehicles that match the filters
+// - query: metric required (a numeric attribute), by optional (year), group optional (a categorical attribute), window optional (default 3 years)
+// - query: the filters of the listings, units optional, as_of optional
+func (h *HandlerVehicle) Trends() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		q := r.URL.Query()
+		var query internal.TrendQuery
+		query.VehicleFilter, err = filter(r, u)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		query.Metric, err = internal.ParseVehicleMetric(q.Get("metric"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid metric: " + err.Error())
+			return
+		}
+		if q.Has("by") && q.Get("by") != "year" {
+			response.Error(w, http.StatusBadRequest, "invalid by (allowed: year)")
+			return
+		}
+		if q.Has("group") {
+			query.GroupBy, err = internal.ParseVehicleCategory(q.Get("group"))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid group: " + err.Error())
+				return
+			}
+		}
+		query.Window = 3
+		if q.Has("window") {
+			query.Window, err = strconv.Atoi(q.Get("window"))
+			if err != nil || query.Window < 1 {
+				response.Error(w, http.StatusBadRequest, "invalid window")
+				return
+			}
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		t, err := sv.Trends(query)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidTrend), errors.Is(err, internal.ErrServiceInvalidFilter):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "trends computed",
+			"units": NewUnitsJSON(u),
+			"data": NewTrendJSON(t, query, u),
+		})
+	}
+}
+
+// SearchByWeightRange returns a handler that returns a map of vehicles that match the weight range
+func (h *HandlerVehicle) SearchByWeightRange() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		var query internal.SearchQuery
+
+		// check if query exists and decode
+		ok := r.URL.Query().Has("weight_min") && r.URL.Query().Has("weight_max")
+		if ok {
+			weightMin, err := strconv.ParseFloat(r.URL.Query().Get("weight_min"), 64)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid weight_min")
+				return
+			}
+			query.FromWeight = internal.NewMass(weightMin, u.Mass)
+
+			weightMax, err := strconv.ParseFloat(r.URL.Query().Get("weight_max"), 64)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid weight_max")
+				return
+			}
+			query.ToWeight = internal.NewMass(weightMax, u.Mass)
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		v, err := sv.SearchByWeightRange(query, ok)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles found",
+			"units": NewUnitsJSON(u),
+			"data": NewVehiclesResponseJSON(v, u),
+		})
+	}
 }
\ No newline at end of file
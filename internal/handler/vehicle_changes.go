@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"net/http"
+	"strconv"
+)
+
+// HandlerChangesVehicle is a struct with methods that represent handlers for the feed of the changes of the vehicles
+type HandlerChangesVehicle struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceChangesVehicle
+}
+
+// NewHandlerChangesVehicle is a function that returns a new instance of HandlerChangesVehicle
+func NewHandlerChangesVehicle(sv internal.ServiceChangesVehicle) *HandlerChangesVehicle {
+	return &HandlerChangesVehicle{sv: sv}
+}
+
+// ChangesVehicleJSON is a struct that represents the changes of the vehicles in JSON format
+type ChangesVehicleJSON struct {
+	Seq        uint64                `json:"seq"`
+	Resync     bool                  `json:"resync"`
+	Upserts    []VehicleResponseJSON `json:"upserts"`
+	Tombstones []int                 `json:"tombstones"`
+}
+
+// NewChangesVehicleJSON is a function that serializes the changes with the quantities expressed in the units
+func NewChangesVehicleJSON(c internal.ChangesVehicle, u internal.Units) ChangesVehicleJSON {
+	data := ChangesVehicleJSON{
+		Seq:        c.Seq,
+		Resync:     c.Resync,
+		Upserts:    make([]VehicleResponseJSON, 0, len(c.Upserts)),
+		Tombstones: c.Tombstones,
+	}
+	if data.Tombstones == nil {
+		data.Tombstones = make([]int, 0)
+	}
+	for _, v := range c.Upserts {
+		data.Upserts = append(data.Upserts, NewVehicleResponseJSON(v, u))
+	}
+	return data
+}
+
+// Changes returns a handler that returns the changes of the vehicles since a position of the sequence of the fleet
+// - query: since optional (0 or missing: the whole fleet), units optional
+// - the seq of the response is the since of the next request
+// - resync true means that the upserts are the whole fleet: the local copy must be replaced, not patched
+func (h *HandlerChangesVehicle) Changes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		var since uint64
+		if r.URL.Query().Has("since") {
+			since, err = strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid since")
+				return
+			}
+		}
+
+		// process
+		c, err := h.sv.Since(since)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "changes found",
+			"units":   NewUnitsJSON(u),
+			"data":    NewChangesVehicleJSON(c, u),
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/graphql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// HandlerGraphQLVehicle is a struct that exposes the vehicle service as a GraphQL schema
+// - queries only: vehicle(id), vehicles(filters) and brandStats(brand), plus introspection
+type HandlerGraphQLVehicle struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceVehicle
+	// schema is the GraphQL schema resolved with the service
+	schema *graphql.Schema
+}
+
+// NewHandlerGraphQLVehicle is a function that returns a new instance of HandlerGraphQLVehicle
+func NewHandlerGraphQLVehicle(sv internal.ServiceVehicle) *HandlerGraphQLVehicle {
+	h := &HandlerGraphQLVehicle{sv: sv}
+	h.schema = graphql.NewSchema(h.query())
+	return h
+}
+
+// GraphQL returns a handler that executes the GraphQL requests
+func (h *HandlerGraphQLVehicle) GraphQL() http.HandlerFunc {
+	return graphql.Handler(h.schema)
+}
+
+// graphQLEnumName is a function that returns the GraphQL name of an enum value, e.g. semi-automatic -> SEMI_AUTOMATIC
+func graphQLEnumName(value string) string {
+	return strings.ToUpper(strings.ReplaceAll(value, "-", "_"))
+}
+
+// query is a method that returns the query root of the schema
+func (h *HandlerGraphQLVehicle) query() *graphql.Object {
+	// enums
+	units := &graphql.Enum{Name: "Units", Description: "System of units of the quantities", Values: []*graphql.EnumValue{
+		{Name: "METRIC", Description: "kg, cm and km/h", Value: internal.UnitsMetric},
+		{Name: "IMPERIAL", Description: "lb, in and mph", Value: internal.UnitsImperial},
+	}}
+	fuelType := &graphql.Enum{Name: "FuelType", Description: "Fuel type of a vehicle"}
+	for _, f := range internal.FuelTypes {
+		fuelType.Values = append(fuelType.Values, &graphql.EnumValue{Name: graphQLEnumName(string(f)), Value: f})
+	}
+	transmission := &graphql.Enum{Name: "Transmission", Description: "Transmission of a vehicle"}
+	for _, t := range internal.Transmissions {
+		transmission.Values = append(transmission.Values, &graphql.EnumValue{Name: graphQLEnumName(string(t)), Value: t})
+	}
+
+	// objects
+	nonNull := graphql.NewNonNull
+	dimensions := &graphql.Object{Name: "Dimensions", Description: "Dimensions of a vehicle, in cm or in", Fields: []*graphql.Field{
+		{Name: "height", Type: nonNull(graphql.Float)},
+		{Name: "length", Type: nonNull(graphql.Float)},
+		{Name: "width", Type: nonNull(graphql.Float)},
+	}}
+	vehicle := &graphql.Object{Name: "Vehicle", Description: "Vehicle of the fleet, quantities in the requested units", Fields: []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.Int)},
+		{Name: "brand", Type: nonNull(graphql.String)},
+		{Name: "model", Type: nonNull(graphql.String)},
+		{Name: "registration", Type: nonNull(graphql.String)},
+		{Name: "color", Type: nonNull(graphql.String)},
+		{Name: "fabricationYear", Type: nonNull(graphql.Int)},
+		{Name: "capacity", Description: "Number of passengers", Type: nonNull(graphql.Int)},
+		{Name: "maxSpeed", Description: "Max speed in km/h or mph", Type: nonNull(graphql.Float)},
+		{Name: "fuelType", Type: nonNull(fuelType)},
+		{Name: "transmission", Type: nonNull(transmission)},
+		{Name: "weight", Description: "Weight in kg or lb", Type: nonNull(graphql.Float)},
+		{Name: "dimensions", Type: nonNull(dimensions)},
+	}}
+	brandStats := &graphql.Object{Name: "BrandStats", Description: "Aggregates of the vehicles of a brand, computed only when selected", Fields: []*graphql.Field{
+		{Name: "brand", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(graphQLBrand).brand, nil
+		}},
+		{Name: "count", Type: nonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+			b := p.Source.(graphQLBrand)
+			v, err := b.sv.FindByBrandAndYearRange(b.brand, math.MinInt, math.MaxInt)
+			return len(v), err
+		}},
+		{Name: "averageMaxSpeed", Description: "Null without vehicles", Type: graphql.Float, Resolve: func(p graphql.ResolveParams) (any, error) {
+			b := p.Source.(graphQLBrand)
+			average, err := b.sv.AverageMaxSpeedByBrand(b.brand)
+			if errors.Is(err, internal.ErrServiceNoVehicles) {
+				return nil, nil
+			}
+			return average.In(b.u.Speed), err
+		}},
+		{Name: "averageCapacity", Description: "Null without vehicles", Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (any, error) {
+			b := p.Source.(graphQLBrand)
+			average, err := b.sv.AverageCapacityByBrand(b.brand)
+			if errors.Is(err, internal.ErrServiceNoVehicles) {
+				return nil, nil
+			}
+			return average, err
+		}},
+	}}
+
+	// arguments shared by the queries
+	asOf := &graphql.Argument{Name: "asOf", Description: "Instant (RFC 3339 or unix seconds) at which the fleet is queried", Type: graphql.String}
+	unitsArg := &graphql.Argument{Name: "units", Type: units, DefaultValue: internal.UnitsMetric}
+
+	return &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{
+			Name:        "vehicle",
+			Description: "Vehicle by id, null if it does not exist",
+			Type:        vehicle,
+			Args:        []*graphql.Argument{{Name: "id", Type: nonNull(graphql.Int)}, asOf, unitsArg},
+			Resolve:     h.resolveVehicle,
+		},
+		{
+			Name:        "vehicles",
+			Description: "Vehicles that match every given filter, ordered by id",
+			Type:        nonNull(graphql.NewList(nonNull(vehicle))),
+			Args: []*graphql.Argument{
+				{Name: "brand", Description: "Case and accent insensitive, aliases included", Type: graphql.String},
+				{Name: "color", Description: "Case and accent insensitive, aliases included", Type: graphql.String},
+				{Name: "yearFrom", Description: "First fabrication year", Type: graphql.Int},
+				{Name: "yearTo", Description: "Last fabrication year", Type: graphql.Int},
+				{Name: "weightMin", Description: "Minimum weight in the requested units", Type: graphql.Float},
+				{Name: "weightMax", Description: "Maximum weight in the requested units", Type: graphql.Float},
+				{Name: "fuelType", Type: fuelType},
+				{Name: "transmission", Type: transmission},
+				asOf,
+				unitsArg,
+				{Name: "limit", Description: "Maximum number of vehicles, all by default", Type: graphql.Int},
+				{Name: "offset", Description: "Number of vehicles skipped", Type: graphql.Int, DefaultValue: 0},
+			},
+			Resolve: h.resolveVehicles,
+		},
+		{
+			Name:    "brandStats",
+			Type:    nonNull(brandStats),
+			Args:    []*graphql.Argument{{Name: "brand", Type: nonNull(graphql.String)}, asOf, unitsArg},
+			Resolve: h.resolveBrandStats,
+		},
+	}}
+}
+
+// graphQLBrand is a struct that represents the source of the BrandStats fields
+type graphQLBrand struct {
+	sv    internal.ServiceVehicle
+	brand string
+	u     internal.Units
+}
+
+// service is a method that returns the service over the fleet at the asOf argument
+func (h *HandlerGraphQLVehicle) service(args map[string]any) (sv internal.ServiceVehicle, err error) {
+	asOf, ok := args["asOf"].(string)
+	if !ok {
+		sv = h.sv
+		return
+	}
+	t, err := parseInstant(asOf)
+	if err != nil {
+		err = fmt.Errorf("invalid asOf %q", asOf)
+		return
+	}
+	sv, err = h.sv.AsOf(t)
+	return
+}
+
+// graphQLVehicle is a function that returns the source of the Vehicle fields, with the quantities in the units
+func graphQLVehicle(v internal.Vehicle, u internal.Units) map[string]any {
+	return map[string]any{
+		"id":              v.Id,
+		"brand":           v.Brand,
+		"model":           v.Model,
+		"registration":    v.Registration,
+		"color":           v.Color,
+		"fabricationYear": v.FabricationYear,
+		"capacity":        v.Capacity,
+		"maxSpeed":        v.MaxSpeed.In(u.Speed),
+		"fuelType":        v.FuelType,
+		"transmission":    v.Transmission,
+		"weight":          v.Weight.In(u.Mass),
+		"dimensions": map[string]any{
+			"height": v.Height.In(u.Length),
+			"length": v.Length.In(u.Length),
+			"width":  v.Width.In(u.Length),
+		},
+	}
+}
+
+func (h *HandlerGraphQLVehicle) resolveVehicle(p graphql.ResolveParams) (result any, err error) {
+	sv, err := h.service(p.Args)
+	if err != nil {
+		return
+	}
+
+	v, err := sv.FindById(p.Args["id"].(int))
+	if err != nil {
+		if errors.Is(err, internal.ErrServiceVehicleNotFound) {
+			err = nil
+		}
+		return
+	}
+	result = graphQLVehicle(v, p.Args["units"].(internal.Units))
+	return
+}
+
+func (h *HandlerGraphQLVehicle) resolveVehicles(p graphql.ResolveParams) (result any, err error) {
+	sv, err := h.service(p.Args)
+	if err != nil {
+		return
+	}
+	u := p.Args["units"].(internal.Units)
+	offset := p.Args["offset"].(int)
+	limit, hasLimit := p.Args["limit"].(int)
+	if offset < 0 || (hasLimit && limit < 0) {
+		err = errors.New("limit and offset must not be negative")
+		return
+	}
+
+	// filters: the same model as the listings, a missing argument is not a filter
+	f := internal.VehicleFilter{Min: make(map[internal.VehicleMetric]float64), Max: make(map[internal.VehicleMetric]float64)}
+	f.Brand, _ = p.Args["brand"].(string)
+	f.Color, _ = p.Args["color"].(string)
+	f.FromYear, _ = p.Args["yearFrom"].(int)
+	f.ToYear, _ = p.Args["yearTo"].(int)
+	if weightMin, ok := p.Args["weightMin"].(float64); ok {
+		f.Min[internal.VehicleMetricWeight] = internal.VehicleMetricWeight.From(weightMin, u)
+	}
+	if weightMax, ok := p.Args["weightMax"].(float64); ok {
+		f.Max[internal.VehicleMetricWeight] = internal.VehicleMetricWeight.From(weightMax, u)
+	}
+	if fuel, ok := p.Args["fuelType"].(internal.FuelType); ok {
+		f.FuelTypes = []internal.FuelType{fuel}
+	}
+	if t, ok := p.Args["transmission"].(internal.Transmission); ok {
+		f.Transmissions = []internal.Transmission{t}
+	}
+	v, err := sv.FindByFilter(f)
+	if err != nil {
+		return
+	}
+
+	// page
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	ids = ids[min(offset, len(ids)):]
+	if hasLimit {
+		ids = ids[:min(limit, len(ids))]
+	}
+
+	vehicles := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		vehicles = append(vehicles, graphQLVehicle(v[id], u))
+	}
+	result = vehicles
+	return
+}
+
+func (h *HandlerGraphQLVehicle) resolveBrandStats(p graphql.ResolveParams) (result any, err error) {
+	sv, err := h.service(p.Args)
+	if err != nil {
+		return
+	}
+	result = graphQLBrand{sv: sv, brand: p.Args["brand"].(string), u: p.Args["units"].(internal.Units)}
+	return
+}
@@ -0,0 +1,420 @@
+package handler
+
+import (
+	"app/internal"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// VehicleJSON is a struct that represents the body of a vehicle in JSON format
+type VehicleJSON struct {
+	Id              int     `json:"id"`
+	Brand           string  `json:"brand"`
+	Model           string  `json:"model"`
+	Registration    string  `json:"registration"`
+	Color           string  `json:"color"`
+	FabricationYear int     `json:"year"`
+	Capacity        int     `json:"passengers"`
+	MaxSpeed        float64 `json:"max_speed"`
+	FuelType        string  `json:"fuel_type"`
+	Transmission    string  `json:"transmission"`
+	Weight          float64 `json:"weight"`
+	Height          float64 `json:"height"`
+	Length          float64 `json:"length"`
+	Width           float64 `json:"width"`
+}
+
+// Vehicle is a method that deserializes the body into a vehicle
+// - enums are parsed into their canonical value
+// - quantities are expressed in the given units
+func (vh VehicleJSON) Vehicle(u internal.Units) (v internal.Vehicle, err error) {
+	fuelType, err := internal.ParseFuelType(vh.FuelType)
+	if err != nil {
+		return
+	}
+	transmission, err := internal.ParseTransmission(vh.Transmission)
+	if err != nil {
+		return
+	}
+
+	v = internal.Vehicle{
+		Id: vh.Id,
+		VehicleAttributes: internal.VehicleAttributes{
+			Brand:           vh.Brand,
+			Model:           vh.Model,
+			Registration:    vh.Registration,
+			Color:           vh.Color,
+			FabricationYear: vh.FabricationYear,
+			Capacity:        vh.Capacity,
+			MaxSpeed:        internal.NewSpeed(vh.MaxSpeed, u.Speed),
+			FuelType:        fuelType,
+			Transmission:    transmission,
+			Weight:          internal.NewMass(vh.Weight, u.Mass),
+			Dimensions: internal.Dimensions{
+				Height: internal.NewLength(vh.Height, u.Length),
+				Length: internal.NewLength(vh.Length, u.Length),
+				Width:  internal.NewLength(vh.Width, u.Length),
+			},
+		},
+	}
+	return
+}
+
+// NewVehicleJSON is a function that serializes a vehicle in the shape of the body, with the quantities expressed in the units
+// - the vehicles of the responses of the version 2 of the api
+func NewVehicleJSON(v internal.Vehicle, u internal.Units) VehicleJSON {
+	return VehicleJSON{
+		Id:              v.Id,
+		Brand:           v.Brand,
+		Model:           v.Model,
+		Registration:    v.Registration,
+		Color:           v.Color,
+		FabricationYear: v.FabricationYear,
+		Capacity:        v.Capacity,
+		MaxSpeed:        v.MaxSpeed.In(u.Speed),
+		FuelType:        string(v.FuelType),
+		Transmission:    string(v.Transmission),
+		Weight:          v.Weight.In(u.Mass),
+		Height:          v.Height.In(u.Length),
+		Length:          v.Length.In(u.Length),
+		Width:           v.Width.In(u.Length),
+	}
+}
+
+// NewVehiclesJSON is a function that serializes a map of vehicles as a list sorted by id, with the quantities expressed in the units
+func NewVehiclesJSON(v map[int]internal.Vehicle, u internal.Units) []VehicleJSON {
+	data := make([]VehicleJSON, 0, len(v))
+	for _, value := range v {
+		data = append(data, NewVehicleJSON(value, u))
+	}
+	slices.SortFunc(data, func(a, b VehicleJSON) int { return a.Id - b.Id })
+	return data
+}
+
+// AverageJSON is a struct that represents the average of an attribute in JSON format
+type AverageJSON struct {
+	Metric  internal.VehicleMetric `json:"metric"`
+	Unit    string                 `json:"unit"`
+	Average float64                `json:"average"`
+}
+
+// UnitsJSON is a struct that represents the units of the quantities of a response in JSON format
+type UnitsJSON struct {
+	System   string                `json:"system"`
+	Mass     internal.MassUnit     `json:"mass"`
+	Length   internal.LengthUnit   `json:"length"`
+	Speed    internal.SpeedUnit    `json:"speed"`
+	Distance internal.DistanceUnit `json:"distance"`
+}
+
+// NewUnitsJSON is a function that serializes the units
+func NewUnitsJSON(u internal.Units) UnitsJSON {
+	return UnitsJSON{System: u.System, Mass: u.Mass, Length: u.Length, Speed: u.Speed, Distance: u.Distance}
+}
+
+// VehicleResponseJSON is a struct that represents a vehicle in the responses
+// - same fields as internal.Vehicle, with the quantities expressed in the requested units
+type VehicleResponseJSON struct {
+	Id              int
+	Brand           string
+	Model           string
+	Registration    string
+	Color           string
+	FabricationYear int
+	Capacity        int
+	MaxSpeed        float64
+	FuelType        internal.FuelType
+	Transmission    internal.Transmission
+	Weight          float64
+	Height          float64
+	Length          float64
+	Width           float64
+}
+
+// NewVehicleResponseJSON is a function that serializes a vehicle with the quantities expressed in the units
+func NewVehicleResponseJSON(v internal.Vehicle, u internal.Units) VehicleResponseJSON {
+	return VehicleResponseJSON{
+		Id:              v.Id,
+		Brand:           v.Brand,
+		Model:           v.Model,
+		Registration:    v.Registration,
+		Color:           v.Color,
+		FabricationYear: v.FabricationYear,
+		Capacity:        v.Capacity,
+		MaxSpeed:        v.MaxSpeed.In(u.Speed),
+		FuelType:        v.FuelType,
+		Transmission:    v.Transmission,
+		Weight:          v.Weight.In(u.Mass),
+		Height:          v.Height.In(u.Length),
+		Length:          v.Length.In(u.Length),
+		Width:           v.Width.In(u.Length),
+	}
+}
+
+// NewVehiclesResponseJSON is a function that serializes a map of vehicles with the quantities expressed in the units
+func NewVehiclesResponseJSON(v map[int]internal.Vehicle, u internal.Units) map[int]VehicleResponseJSON {
+	data := make(map[int]VehicleResponseJSON, len(v))
+	for key, value := range v {
+		data[key] = NewVehicleResponseJSON(value, u)
+	}
+	return data
+}
+
+// VehicleVersionJSON is a struct that represents a version of a vehicle in JSON format
+type VehicleVersionJSON struct {
+	Version   int                 `json:"version"`
+	ValidFrom *time.Time          `json:"valid_from"`
+	ValidTo   *time.Time          `json:"valid_to"`
+	Deleted   bool                `json:"deleted"`
+	Vehicle   VehicleResponseJSON `json:"vehicle"`
+}
+
+// NewVehicleVersionsJSON is a function that serializes the versions of a vehicle with the quantities expressed in the units
+// - zero instants (loaded dataset, current version) are serialized as null
+func NewVehicleVersionsJSON(versions []internal.VehicleVersion, u internal.Units) []VehicleVersionJSON {
+	instant := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+	data := make([]VehicleVersionJSON, 0, len(versions))
+	for _, vs := range versions {
+		data = append(data, VehicleVersionJSON{
+			Version:   vs.Version,
+			ValidFrom: instant(vs.ValidFrom),
+			ValidTo:   instant(vs.ValidTo),
+			Deleted:   vs.Deleted,
+			Vehicle:   NewVehicleResponseJSON(vs.Vehicle, u),
+		})
+	}
+	return data
+}
+
+// VehicleVersionV2JSON is a struct that represents a version of a vehicle in JSON format, in the version 2 of the api
+type VehicleVersionV2JSON struct {
+	Version   int         `json:"version"`
+	ValidFrom *time.Time  `json:"valid_from"`
+	ValidTo   *time.Time  `json:"valid_to"`
+	Deleted   bool        `json:"deleted"`
+	Vehicle   VehicleJSON `json:"vehicle"`
+}
+
+// NewVehicleVersionsV2JSON is a function that serializes the versions of a vehicle, each one in the shape of the body
+// - zero instants (loaded dataset, current version) are serialized as null
+func NewVehicleVersionsV2JSON(versions []internal.VehicleVersion, u internal.Units) []VehicleVersionV2JSON {
+	data := make([]VehicleVersionV2JSON, 0, len(versions))
+	for i, vs := range NewVehicleVersionsJSON(versions, u) {
+		data = append(data, VehicleVersionV2JSON{
+			Version:   vs.Version,
+			ValidFrom: vs.ValidFrom,
+			ValidTo:   vs.ValidTo,
+			Deleted:   vs.Deleted,
+			Vehicle:   NewVehicleJSON(versions[i].Vehicle, u),
+		})
+	}
+	return data
+}
+
+// ComparisonRowJSON is a struct that represents a numeric attribute of the compared vehicles in JSON format
+// - values and percentiles are aligned with the vehicles of the comparison
+type ComparisonRowJSON struct {
+	Attribute       internal.VehicleMetric `json:"attribute"`
+	HigherIsBetter  bool                   `json:"higher_is_better"`
+	Values          []float64              `json:"values"`
+	Best            []int                  `json:"best"`
+	Worst           []int                  `json:"worst"`
+	PercentileBrand []float64              `json:"percentile_brand"`
+	PercentileFleet []float64              `json:"percentile_fleet"`
+}
+
+// ComparisonJSON is a struct that represents vehicles side by side in JSON format
+type ComparisonJSON struct {
+	Vehicles   []VehicleResponseJSON `json:"vehicles"`
+	Attributes []ComparisonRowJSON   `json:"attributes"`
+}
+
+// NewComparisonJSON is a function that serializes a comparison with the values expressed in the units
+// - the percentiles are rounded to one decimal
+func NewComparisonJSON(c internal.Comparison, u internal.Units) ComparisonJSON {
+	round := func(p []float64) []float64 {
+		r := make([]float64, len(p))
+		for i, value := range p {
+			r[i] = math.Round(value*10) / 10
+		}
+		return r
+	}
+	data := ComparisonJSON{
+		Vehicles:   make([]VehicleResponseJSON, 0, len(c.Vehicles)),
+		Attributes: make([]ComparisonRowJSON, 0, len(c.Rows)),
+	}
+	for _, v := range c.Vehicles {
+		data.Vehicles = append(data.Vehicles, NewVehicleResponseJSON(v, u))
+	}
+	for _, row := range c.Rows {
+		values := make([]float64, len(row.Values))
+		for i, value := range row.Values {
+			values[i] = row.Metric.In(value, u)
+		}
+		data.Attributes = append(data.Attributes, ComparisonRowJSON{
+			Attribute:       row.Metric,
+			HigherIsBetter:  row.Metric.HigherIsBetter(),
+			Values:          values,
+			Best:            row.Best,
+			Worst:           row.Worst,
+			PercentileBrand: round(row.PercentileBrand),
+			PercentileFleet: round(row.PercentileFleet),
+		})
+	}
+	return data
+}
+
+// HistogramBucketJSON is a struct that represents a bucket of a histogram in JSON format
+// - lower and upper are only set for numeric buckets, in the requested units
+type HistogramBucketJSON struct {
+	Label string   `json:"label"`
+	Lower *float64 `json:"lower,omitempty"`
+	Upper *float64 `json:"upper,omitempty"`
+}
+
+// HistogramSeriesJSON is a struct that represents the counts of a group of vehicles in JSON format
+// - counts are aligned with the buckets of the histogram
+type HistogramSeriesJSON struct {
+	Group  string `json:"group"`
+	Counts []int  `json:"counts"`
+	Total  int    `json:"total"`
+}
+
+// HistogramJSON is a struct that represents the distribution of an attribute in JSON format, ready to be charted
+type HistogramJSON struct {
+	Field   string                `json:"field"`
+	Unit    string                `json:"unit,omitempty"`
+	GroupBy string                `json:"group_by,omitempty"`
+	Buckets []HistogramBucketJSON `json:"buckets"`
+	Series  []HistogramSeriesJSON `json:"series"`
+}
+
+// NewHistogramJSON is a function that serializes a histogram with the bounds expressed in the units
+// - bounds are rounded to two decimals, numeric labels are "lower-upper"
+func NewHistogramJSON(h internal.Histogram, query internal.HistogramQuery, u internal.Units) HistogramJSON {
+	round := func(value float64) *float64 {
+		r := math.Round(query.Metric.In(value, u)*100) / 100
+		return &r
+	}
+	data := HistogramJSON{
+		Field:   string(query.Category),
+		GroupBy: string(query.GroupBy),
+		Buckets: make([]HistogramBucketJSON, 0, len(h.Buckets)),
+		Series:  make([]HistogramSeriesJSON, 0, len(h.Series)),
+	}
+	if query.Metric != "" {
+		data.Field, data.Unit = string(query.Metric), query.Metric.Unit(u)
+	}
+	for _, b := range h.Buckets {
+		bj := HistogramBucketJSON{Label: b.Value}
+		if query.Metric != "" {
+			bj.Lower, bj.Upper = round(b.Lower), round(b.Upper)
+			bj.Label = strconv.FormatFloat(*bj.Lower, 'f', -1, 64) + "-" + strconv.FormatFloat(*bj.Upper, 'f', -1, 64)
+		}
+		data.Buckets = append(data.Buckets, bj)
+	}
+	for _, sr := range h.Series {
+		data.Series = append(data.Series, HistogramSeriesJSON{Group: sr.Group, Counts: sr.Counts, Total: sr.Total})
+	}
+	return data
+}
+
+// Records is a method that returns the histogram as CSV records: a row per bucket, a column per series
+// - the column of a histogram without group by is named count
+func (hj HistogramJSON) Records() (records [][]string) {
+	header := []string{"bucket"}
+	numeric := len(hj.Buckets) > 0 && hj.Buckets[0].Lower != nil
+	if numeric {
+		header = append(header, "lower", "upper")
+	}
+	for _, sr := range hj.Series {
+		if hj.GroupBy == "" {
+			header = append(header, "count")
+			continue
+		}
+		header = append(header, sr.Group)
+	}
+	records = append(records, header)
+
+	for i, b := range hj.Buckets {
+		row := []string{b.Label}
+		if numeric {
+			row = append(row, strconv.FormatFloat(*b.Lower, 'f', -1, 64), strconv.FormatFloat(*b.Upper, 'f', -1, 64))
+		}
+		for _, sr := range hj.Series {
+			row = append(row, strconv.Itoa(sr.Counts[i]))
+		}
+		records = append(records, row)
+	}
+	return
+}
+
+// TrendPointJSON is a struct that represents a fabrication year of a trend in JSON format
+// - the values are null for the years without vehicles, so charts render the gaps
+type TrendPointJSON struct {
+	Year          int      `json:"year"`
+	Count         int      `json:"count"`
+	Average       *float64 `json:"average"`
+	MovingAverage *float64 `json:"moving_average"`
+	Delta         *float64 `json:"delta"`
+}
+
+// TrendSeriesJSON is a struct that represents the evolution of a group of vehicles in JSON format
+type TrendSeriesJSON struct {
+	Group  string           `json:"group"`
+	Points []TrendPointJSON `json:"points"`
+}
+
+// TrendJSON is a struct that represents the evolution of an attribute across the fabrication years in JSON format
+type TrendJSON struct {
+	Metric internal.VehicleMetric `json:"metric"`
+	Unit   string                 `json:"unit,omitempty"`
+	By     string                 `json:"by"`
+	Group  string                 `json:"group,omitempty"`
+	Window int                    `json:"window"`
+	Years  []int                  `json:"years"`
+	Series []TrendSeriesJSON      `json:"series"`
+}
+
+// NewTrendJSON is a function that serializes a trend with the values expressed in the units
+// - the values are rounded to two decimals
+func NewTrendJSON(t internal.Trend, query internal.TrendQuery, u internal.Units) TrendJSON {
+	round := func(value *float64) *float64 {
+		if value == nil {
+			return nil
+		}
+		r := math.Round(query.Metric.In(*value, u)*100) / 100
+		return &r
+	}
+	data := TrendJSON{
+		Metric: query.Metric,
+		Unit:   query.Metric.Unit(u),
+		By:     "year",
+		Group:  string(query.GroupBy),
+		Window: query.Window,
+		Years:  make([]int, 0, len(t.Years)),
+		Series: make([]TrendSeriesJSON, 0, len(t.Series)),
+	}
+	data.Years = append(data.Years, t.Years...)
+	for _, sr := range t.Series {
+		sj := TrendSeriesJSON{Group: sr.Group, Points: make([]TrendPointJSON, 0, len(sr.Points))}
+		for _, p := range sr.Points {
+			sj.Points = append(sj.Points, TrendPointJSON{
+				Year:          p.Year,
+				Count:         p.Count,
+				Average:       round(p.Average),
+				MovingAverage: round(p.MovingAverage),
+				Delta:         round(p.Delta),
+			})
+		}
+		data.Series = append(data.Series, sj)
+	}
+	return data
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerRecommendVehicle is a struct with methods that represent handlers for the recommendation and the similarity of vehicles
+type HandlerRecommendVehicle struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceRecommendVehicle
+}
+
+// NewHandlerRecommendVehicle is a function that returns a new instance of HandlerRecommendVehicle
+func NewHandlerRecommendVehicle(sv internal.ServiceRecommendVehicle) *HandlerRecommendVehicle {
+	return &HandlerRecommendVehicle{sv: sv}
+}
+
+// RecommendPreferenceJSON is a struct that represents a soft preference in JSON format
+type RecommendPreferenceJSON struct {
+	Attribute string   `json:"attribute"`
+	Weight    *float64 `json:"weight"`
+	Prefer    string   `json:"prefer"`
+}
+
+// RecommendJSON is a struct that represents the body of a recommendation in JSON format
+// - min and max are keyed by attribute, with the values in the requested units
+type RecommendJSON struct {
+	Brand         string                    `json:"brand"`
+	FuelTypes     []string                  `json:"fuel_types"`
+	Transmissions []string                  `json:"transmissions"`
+	Min           map[string]float64        `json:"min"`
+	Max           map[string]float64        `json:"max"`
+	Preferences   []RecommendPreferenceJSON `json:"preferences"`
+	Limit         int                       `json:"limit"`
+}
+
+// Query is a method that deserializes the body into a query
+// - enums are parsed into their canonical value, the weight of a preference defaults to 1
+// - prefer (higher or lower) defaults to the better direction of the attribute
+func (rj RecommendJSON) Query(u internal.Units) (q internal.RecommendQuery, err error) {
+	q = internal.RecommendQuery{VehicleFilter: internal.VehicleFilter{Brand: rj.Brand}, Limit: rj.Limit}
+	for _, value := range rj.FuelTypes {
+		var f internal.FuelType
+		if f, err = internal.ParseFuelType(value); err != nil {
+			return
+		}
+		q.FuelTypes = append(q.FuelTypes, f)
+	}
+	for _, value := range rj.Transmissions {
+		var t internal.Transmission
+		if t, err = internal.ParseTransmission(value); err != nil {
+			return
+		}
+		q.Transmissions = append(q.Transmissions, t)
+	}
+	bounds := func(b map[string]float64) (m map[internal.VehicleMetric]float64, err error) {
+		m = make(map[internal.VehicleMetric]float64, len(b))
+		for name, value := range b {
+			var metric internal.VehicleMetric
+			if metric, err = internal.ParseVehicleMetric(name); err != nil {
+				return
+			}
+			m[metric] = metric.From(value, u)
+		}
+		return
+	}
+	if q.Min, err = bounds(rj.Min); err != nil {
+		return
+	}
+	if q.Max, err = bounds(rj.Max); err != nil {
+		return
+	}
+	for _, p := range rj.Preferences {
+		var metric internal.VehicleMetric
+		if metric, err = internal.ParseVehicleMetric(p.Attribute); err != nil {
+			return
+		}
+		preference := internal.RecommendPreference{Metric: metric, Weight: 1, Higher: metric.HigherIsBetter()}
+		if p.Weight != nil {
+			preference.Weight = *p.Weight
+		}
+		switch p.Prefer {
+		case "":
+		case "higher":
+			preference.Higher = true
+		case "lower":
+			preference.Higher = false
+		default:
+			err = fmt.Errorf("invalid prefer: %q (allowed: higher, lower)", p.Prefer)
+			return
+		}
+		q.Preferences = append(q.Preferences, preference)
+	}
+	return
+}
+
+// RecommendContributionJSON is a struct that represents the part of a preference in the score in JSON format
+type RecommendContributionJSON struct {
+	Attribute    internal.VehicleMetric `json:"attribute"`
+	Prefer       string                 `json:"prefer"`
+	Weight       float64                `json:"weight"`
+	Value        float64                `json:"value"`
+	Score        float64                `json:"score"`
+	Contribution float64                `json:"contribution"`
+}
+
+// RecommendationJSON is a struct that represents a recommended vehicle in JSON format
+type RecommendationJSON struct {
+	Score         float64                     `json:"score"`
+	Vehicle       VehicleResponseJSON         `json:"vehicle"`
+	Contributions []RecommendContributionJSON `json:"contributions"`
+}
+
+// NewRecommendationsJSON is a function that serializes the recommendations with the values expressed in the units
+// - scores are rounded to three decimals
+func NewRecommendationsJSON(r []internal.Recommendation, u internal.Units) []RecommendationJSON {
+	round := func(value float64) float64 { return math.Round(value*1000) / 1000 }
+	data := make([]RecommendationJSON, 0, len(r))
+	for _, rc := range r {
+		item := RecommendationJSON{
+			Score:         round(rc.Score),
+			Vehicle:       NewVehicleResponseJSON(rc.Vehicle, u),
+			Contributions: make([]RecommendContributionJSON, 0, len(rc.Contributions)),
+		}
+		for _, c := range rc.Contributions {
+			prefer := "lower"
+			if c.Preference.Higher {
+				prefer = "higher"
+			}
+			item.Contributions = append(item.Contributions, RecommendContributionJSON{
+				Attribute:    c.Preference.Metric,
+				Prefer:       prefer,
+				Weight:       c.Preference.Weight,
+				Value:        c.Preference.Metric.In(c.Value, u),
+				Score:        round(c.Score),
+				Contribution: round(c.Contribution),
+			})
+		}
+		data = append(data, item)
+	}
+	return data
+}
+
+// SimilarJSON is a struct that represents a similar vehicle in JSON format
+type SimilarJSON struct {
+	Distance float64             `json:"distance"`
+	Vehicle  VehicleResponseJSON `json:"vehicle"`
+}
+
+// Recommend returns a handler that returns the vehicles that meet the constraints of the body, best score first
+// - query: units optional, for the bounds of the body and the response
+func (h *HandlerRecommendVehicle) Recommend() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		var body RecommendJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		query, err := body.Query(u)
+		if err != nil {
+			response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		// process
+		rc, err := h.sv.Recommend(query)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidRecommendation):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles recommended",
+			"units":   NewUnitsJSON(u),
+			"data":    NewRecommendationsJSON(rc, u),
+		})
+	}
+}
+
+// Similar returns a handler that returns the vehicles most similar to the vehicle that matches the id
+// - query: k optional (default 10, at most 100), units optional
+func (h *HandlerRecommendVehicle) Similar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		k := 10
+		if r.URL.Query().Has("k") {
+			k, err = strconv.Atoi(r.URL.Query().Get("k"))
+			if err != nil || k < 1 || k > 100 {
+				response.Error(w, http.StatusBadRequest, "invalid k")
+				return
+			}
+		}
+
+		// process
+		s, err := h.sv.Similar(id, k)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		data := make([]SimilarJSON, 0, len(s))
+		for _, sr := range s {
+			data = append(data, SimilarJSON{Distance: math.Round(sr.Distance*1000) / 1000, Vehicle: NewVehicleResponseJSON(sr.Vehicle, u)})
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "similar vehicles found",
+			"units":   NewUnitsJSON(u),
+			"data":    data,
+		})
+	}
+}
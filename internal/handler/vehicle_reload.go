@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+)
+
+// HandlerReloadVehicle is a struct with methods that represent handlers for the reload of the dataset of a fleet
+type HandlerReloadVehicle struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceReloadVehicle
+}
+
+// NewHandlerReloadVehicle is a function that returns a new instance of HandlerReloadVehicle
+func NewHandlerReloadVehicle(sv internal.ServiceReloadVehicle) *HandlerReloadVehicle {
+	return &HandlerReloadVehicle{sv: sv}
+}
+
+// ReloadVehicleJSON is a struct that represents the changes of a reload in JSON format
+type ReloadVehicleJSON struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+}
+
+// Reload returns a handler that loads the dataset of the fleet again and replaces its vehicles
+// - the changes made since the previous load are discarded, the reload is published as a dataset.reloaded event
+func (h *HandlerReloadVehicle) Reload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// process
+		rl, err := h.sv.Reload(r.Context())
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceDatasetNotLoaded):
+				response.Error(w, http.StatusInternalServerError, "dataset could not be loaded")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "dataset reloaded",
+			"data": ReloadVehicleJSON{
+				Created: len(rl.Created),
+				Updated: len(rl.Updated),
+				Deleted: len(rl.Deleted),
+			},
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/jsonrpc"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const (
+	// CodeRPCNotFound is the JSON-RPC code of a vehicle (or vehicles) that does not exist
+	CodeRPCNotFound = -32004
+	// CodeRPCConflict is the JSON-RPC code of a mutation that conflicts with the state of the fleet
+	CodeRPCConflict = -32009
+	// CodeRPCForbidden is the JSON-RPC code of a call that the role of the caller does not allow
+	CodeRPCForbidden = -32003
+)
+
+// HandlerRPCVehicle is a struct that exposes the methods of the vehicle service over JSON-RPC 2.0
+// - methods are named vehicles.<method>, e.g. vehicles.findByColorAndYear
+// - finders accept as_of (see ServiceVehicle.AsOf) and units, like the REST handlers
+type HandlerRPCVehicle struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceVehicle
+}
+
+// NewHandlerRPCVehicle is a function that returns a new instance of HandlerRPCVehicle
+func NewHandlerRPCVehicle(sv internal.ServiceVehicle) *HandlerRPCVehicle {
+	return &HandlerRPCVehicle{sv: sv}
+}
+
+// RPC returns a handler that serves the JSON-RPC methods
+func (h *HandlerRPCVehicle) RPC() http.HandlerFunc {
+	server := jsonrpc.NewServer(rpcError)
+	server.Register("vehicles.findById", h.findById)
+	server.Register("vehicles.findByColorAndYear", h.findByColorAndYear)
+	server.Register("vehicles.findByBrandAndYearRange", h.findByBrandAndYearRange)
+	server.Register("vehicles.averageMaxSpeedByBrand", h.averageMaxSpeedByBrand)
+	server.Register("vehicles.averageCapacityByBrand", h.averageCapacityByBrand)
+	server.Register("vehicles.searchByWeightRange", h.searchByWeightRange)
+	server.Register("vehicles.create", h.create)
+	server.Register("vehicles.update", h.update)
+	server.Register("vehicles.delete", h.delete)
+	server.Register("vehicles.findHistory", h.findHistory)
+	server.Register("vehicles.restore", h.restore)
+	return server.ServeHTTP
+}
+
+// rpcError is a function that maps the errors of the service to JSON-RPC errors
+func rpcError(err error) *jsonrpc.Error {
+	switch {
+	case errors.Is(err, internal.ErrServiceVehicleNotFound):
+		return jsonrpc.NewError(CodeRPCNotFound, "vehicle not found", nil)
+	case errors.Is(err, internal.ErrServiceNoVehicles):
+		return jsonrpc.NewError(CodeRPCNotFound, "vehicles not found", nil)
+	case errors.Is(err, internal.ErrServiceVehicleAlreadyExists):
+		return jsonrpc.NewError(CodeRPCConflict, "vehicle already exists", nil)
+	case errors.Is(err, internal.ErrServiceVehicleNotDeleted):
+		return jsonrpc.NewError(CodeRPCConflict, "vehicle not deleted", nil)
+	case errors.Is(err, internal.ErrServiceRegistrationConflict):
+		return jsonrpc.NewError(CodeRPCConflict, "registration already exists", err.Error())
+	case errors.Is(err, internal.ErrServiceInvalidVehicle),
+		errors.Is(err, internal.ErrInvalidFuelType),
+		errors.Is(err, internal.ErrInvalidTransmission):
+		return jsonrpc.NewError(jsonrpc.CodeInvalidParams, "invalid vehicle", err.Error())
+	case errors.Is(err, internal.ErrInvalidUnits):
+		return jsonrpc.NewError(jsonrpc.CodeInvalidParams, "invalid units", nil)
+	case errors.Is(err, internal.ErrServiceInvalidFilter):
+		return jsonrpc.NewError(jsonrpc.CodeInvalidParams, "invalid filter", err.Error())
+	case errors.Is(err, internal.ErrServiceHistoryTruncated):
+		return jsonrpc.NewError(jsonrpc.CodeInvalidParams, "history truncated before as_of", err.Error())
+	}
+	return jsonrpc.NewError(jsonrpc.CodeInternalError, "internal error", nil)
+}
+
+// rpcOptions is a struct that represents the params shared by the finders
+type rpcOptions struct {
+	// AsOf is the instant (RFC 3339 or unix seconds) at which the fleet is queried
+	AsOf string `json:"as_of"`
+	// Units is the system of units of the quantities (default metric)
+	Units string `json:"units"`
+}
+
+// service is a method that returns the service over the fleet at the as_of instant and the requested units
+func (h *HandlerRPCVehicle) service(o rpcOptions) (sv internal.ServiceVehicle, u internal.Units, err error) {
+	u, err = o.units()
+	if err != nil {
+		return
+	}
+	sv = h.sv
+	if o.AsOf == "" {
+		return
+	}
+	t, err := parseInstant(o.AsOf)
+	if err != nil {
+		err = jsonrpc.NewError(jsonrpc.CodeInvalidParams, "invalid as_of", nil)
+		return
+	}
+	sv, err = h.sv.AsOf(t)
+	return
+}
+
+// units is a method that returns the requested units
+func (o rpcOptions) units() (u internal.Units, err error) {
+	if o.Units == "" {
+		u = internal.UnitsMetric
+		return
+	}
+	u, err = internal.ParseUnits(o.Units)
+	return
+}
+
+// authorize is a function that returns an error if the caller is not allowed to mutate the fleet
+// - without principal (authentication disabled) every call is allowed
+func authorize(ctx context.Context, role internal.Role) (err error) {
+	p, ok := internal.PrincipalFromContext(ctx)
+	if ok && !p.Role.Includes(role) {
+		err = jsonrpc.NewError(CodeRPCForbidden, "forbidden", "role "+string(role)+" required")
+	}
+	return
+}
+
+func (h *HandlerRPCVehicle) findById(ctx context.Context, params json.RawMessage) (result any, err error) {
+	var p struct {
+		Id int `json:"id"`
+		rpcOptions
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "id", "as_of", "units"); err != nil {
+		return
+	}
+	sv, u, err := h.service(p.rpcOptions)
+	if err != nil {
+		return
+	}
+
+	v, err := sv.FindById(p.Id)
+	if err != nil {
+		return
+	}
+	result = NewVehicleResponseJSON(v, u)
+	return
+}
+
+func (h *HandlerRPCVehicle) findByColorAndYear(ctx context.Context, params json.RawMessage) (result any, err error) {
+	var p struct {
+		Color string `json:"color"`
+		Year  int    `json:"year"`
+		rpcOptions
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "color", "year", "as_of", "units"); err != nil {
+		return
+	}
+	sv, u, err := h.service(p.rpcOptions)
+	if err != nil {
+		return
+	}
+
+	v, err := sv.FindByColorAndYear(p.Color, p.Year)
+	if err != nil {
+		return
+	}
+	result = NewVehiclesResponseJSON(v, u)
+	return
+}
+
+func (h *HandlerRPCVehicle) findByBrandAndYearRange(ctx context.Context, params json.RawMessage) (result any, err error) {
+	var p struct {
+		Brand     string `json:"brand"`
+		StartYear int    `json:"start_year"`
+		EndYear   int    `json:"end_year"`
+		rpcOptions
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "brand", "start_year", "end_year", "as_of", "units"); err != nil {
+		return
+	}
+	sv, u, err := h.service(p.rpcOptions)
+	if err != nil {
+		return
+	}
+
+	v, err := sv.FindByBrandAndYearRange(p.Brand, p.StartYear, p.EndYear)
+	if err != nil {
+		return
+	}
+	result = NewVehiclesResponseJSON(v, u)
+	return
+}
+
+func (h *HandlerRPCVehicle) averageMaxSpeedByBrand(ctx context.Context, params json.RawMessage) (result any, err error) {
+	var p struct {
+		Brand string `json:"brand"`
+		rpcOptions
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "brand", "as_of", "units"); err != nil {
+		return
+	}
+	sv, u, err := h.service(p.rpcOptions)
+	if err != nil {
+		return
+	}
+
+	average, err := sv.AverageMaxSpeedByBrand(p.Brand)
+	if err != nil {
+		return
+	}
+	result = average.In(u.Speed)
+	return
+}
+
+func (h *HandlerRPCVehicle) averageCapacityByBrand(ctx context.Context, params json.RawMessage) (result any, err error) {
+	var p struct {
+		Brand string `json:"brand"`
+		rpcOptions
+	}
+	// - the capacity has no unit, units is accepted like in the other methods
+	if err = jsonrpc.DecodeParams(params, &p, "brand", "as_of", "units"); err != nil {
+		return
+	}
+	sv, _, err := h.service(p.rpcOptions)
+	if err != nil {
+		return
+	}
+
+	result, err = sv.AverageCapacityByBrand(p.Brand)
+	return
+}
+
+func (h *HandlerRPCVehicle) searchByWeightRange(ctx context.Context, params json.RawMessage) (result any, err error) {
+	var p struct {
+		WeightMin *float64 `json:"weight_min"`
+		WeightMax *float64 `json:"weight_max"`
+		rpcOptions
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "weight_min", "weight_max", "as_of", "units"); err != nil {
+		return
+	}
+	sv, u, err := h.service(p.rpcOptions)
+	if err != nil {
+		return
+	}
+
+	// without both bounds every vehicle is returned
+	var query internal.SearchQuery
+	ok := p.WeightMin != nil && p.WeightMax != nil
+	if ok {
+		query.FromWeight = internal.NewMass(*p.WeightMin, u.Mass)
+		query.ToWeight = internal.NewMass(*p.WeightMax, u.Mass)
+	}
+	v, err := sv.SearchByWeightRange(query, ok)
+	if err != nil {
+		return
+	}
+	result = NewVehiclesResponseJSON(v, u)
+	return
+}
+
+func (h *HandlerRPCVehicle) create(ctx context.Context, params json.RawMessage) (result any, err error) {
+	if err = authorize(ctx, internal.RoleEditor); err != nil {
+		return
+	}
+	var p struct {
+		Vehicle VehicleJSON `json:"vehicle"`
+		Units   string      `json:"units"`
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "vehicle", "units"); err != nil {
+		return
+	}
+	u, err := rpcOptions{Units: p.Units}.units()
+	if err != nil {
+		return
+	}
+	v, err := p.Vehicle.Vehicle(u)
+	if err != nil {
+		return
+	}
+
+	if err = h.sv.Create(ctx, &v); err != nil {
+		return
+	}
+	result = NewVehicleResponseJSON(v, u)
+	return
+}
+
+func (h *HandlerRPCVehicle) update(ctx context.Context, params json.RawMessage) (result any, err error) {
+	if err = authorize(ctx, internal.RoleEditor); err != nil {
+		return
+	}
+	var p struct {
+		Id      int         `json:"id"`
+		Vehicle VehicleJSON `json:"vehicle"`
+		Units   string      `json:"units"`
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "id", "vehicle", "units"); err != nil {
+		return
+	}
+	u, err := rpcOptions{Units: p.Units}.units()
+	if err != nil {
+		return
+	}
+	v, err := p.Vehicle.Vehicle(u)
+	if err != nil {
+		return
+	}
+	v.Id = p.Id
+
+	if err = h.sv.Update(ctx, &v); err != nil {
+		return
+	}
+	result = NewVehicleResponseJSON(v, u)
+	return
+}
+
+func (h *HandlerRPCVehicle) delete(ctx context.Context, params json.RawMessage) (result any, err error) {
+	if err = authorize(ctx, internal.RoleEditor); err != nil {
+		return
+	}
+	var p struct {
+		Id int `json:"id"`
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "id"); err != nil {
+		return
+	}
+
+	err = h.sv.Delete(ctx, p.Id)
+	return
+}
+
+func (h *HandlerRPCVehicle) findHistory(ctx context.Context, params json.RawMessage) (result any, err error) {
+	var p struct {
+		Id    int    `json:"id"`
+		Units string `json:"units"`
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "id", "units"); err != nil {
+		return
+	}
+	u, err := rpcOptions{Units: p.Units}.units()
+	if err != nil {
+		return
+	}
+
+	versions, err := h.sv.FindHistory(p.Id)
+	if err != nil {
+		return
+	}
+	result = NewVehicleVersionsJSON(versions, u)
+	return
+}
+
+func (h *HandlerRPCVehicle) restore(ctx context.Context, params json.RawMessage) (result any, err error) {
+	if err = authorize(ctx, internal.RoleEditor); err != nil {
+		return
+	}
+	var p struct {
+		Id    int    `json:"id"`
+		Units string `json:"units"`
+	}
+	if err = jsonrpc.DecodeParams(params, &p, "id", "units"); err != nil {
+		return
+	}
+	u, err := rpcOptions{Units: p.Units}.units()
+	if err != nil {
+		return
+	}
+
+	v, err := h.sv.Restore(ctx, p.Id)
+	if err != nil {
+		return
+	}
+	result = NewVehicleResponseJSON(v, u)
+	return
+}
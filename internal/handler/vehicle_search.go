@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// HandlerSearchVehicle is a struct with methods that represent handlers for the full-text search of vehicles
+type HandlerSearchVehicle struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceSearchVehicle
+}
+
+// NewHandlerSearchVehicle is a function that returns a new instance of HandlerSearchVehicle
+func NewHandlerSearchVehicle(sv internal.ServiceSearchVehicle) *HandlerSearchVehicle {
+	return &HandlerSearchVehicle{sv: sv}
+}
+
+// SearchResultJSON is a struct that represents a vehicle found by a full-text search in JSON format
+type SearchResultJSON struct {
+	Score   float64             `json:"score"`
+	Vehicle VehicleResponseJSON `json:"vehicle"`
+}
+
+// Search returns a handler that returns the vehicles that match the text, most relevant first
+// - query: q (required), limit (default 20, max 100), units (metric or imperial)
+func (h *HandlerSearchVehicle) Search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		text := r.URL.Query().Get("q")
+		limit := 20
+		if r.URL.Query().Has("limit") {
+			limit, err = strconv.Atoi(r.URL.Query().Get("limit"))
+			if err != nil || limit <= 0 || limit > 100 {
+				response.Error(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+		}
+
+		// process
+		results, err := h.sv.Search(text, limit)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidSearch):
+				response.Error(w, http.StatusBadRequest, "invalid q")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		data := make([]SearchResultJSON, 0, len(results))
+		for _, result := range results {
+			data = append(data, SearchResultJSON{Score: result.Score, Vehicle: NewVehicleResponseJSON(result.Vehicle, u)})
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles found",
+			"units":   NewUnitsJSON(u),
+			"data":    data,
+		})
+	}
+}
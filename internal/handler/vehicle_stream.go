@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HandlerStreamVehicle is a struct with methods that represent handlers for the live events of the vehicles
+type HandlerStreamVehicle struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceStream
+	// heartbeat is the interval of the comments sent to keep idle connections open
+	heartbeat time.Duration
+	// writeTimeout is the time a write to a client can take before the client is disconnected
+	writeTimeout time.Duration
+}
+
+// NewHandlerStreamVehicle is a function that returns a new instance of HandlerStreamVehicle
+func NewHandlerStreamVehicle(sv internal.ServiceStream, heartbeat time.Duration, writeTimeout time.Duration) *HandlerStreamVehicle {
+	return &HandlerStreamVehicle{sv: sv, heartbeat: heartbeat, writeTimeout: writeTimeout}
+}
+
+// GapJSON is a struct that represents the data of a gap event in JSON format
+type GapJSON struct {
+	LastEventId uint64 `json:"last_event_id"`
+}
+
+// Events returns a handler that streams the changes of the vehicles as server-sent events (text/event-stream)
+// - each event has the position in the stream as id, the event type as event and the EventJSON as data
+// - query: brand and color optional (case and accent insensitive)
+// - resumption: the Last-Event-ID header (or the last_event_id query parameter) replays the buffered events after it
+// - an event of type gap is sent first when some events after it are not buffered anymore
+// - a client that falls behind, or that does not read a write in time, is disconnected
+func (h *HandlerStreamVehicle) Events() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		query := internal.StreamQuery{
+			Brand: r.URL.Query().Get("brand"),
+			Color: r.URL.Query().Get("color"),
+		}
+		lastEventId := r.Header.Get("Last-Event-ID")
+		if lastEventId == "" {
+			lastEventId = r.URL.Query().Get("last_event_id")
+		}
+		var lastSeq uint64
+		if lastEventId != "" {
+			var err error
+			lastSeq, err = strconv.ParseUint(lastEventId, 10, 64)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid last event id")
+				return
+			}
+		}
+
+		// process
+		sub, replay, gap, err := h.sv.Subscribe(query, lastSeq)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		defer sub.Close()
+
+		// response
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		// - write: each write is flushed, and fails if the client does not read it in time
+		write := func(format string, args ...any) bool {
+			_ = rc.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+			if _, err := fmt.Fprintf(w, format, args...); err != nil {
+				return false
+			}
+			return rc.Flush() == nil
+		}
+		// - send: an event with its position as id
+		send := func(se internal.StreamedEvent) bool {
+			data, err := json.Marshal(NewEventJSON(se.Event))
+			if err != nil {
+				return false
+			}
+			return write("id: %d\nevent: %s\ndata: %s\n\n", se.Seq, se.Event.Type, data)
+		}
+
+		if gap {
+			data, _ := json.Marshal(GapJSON{LastEventId: lastSeq})
+			if !write("event: gap\ndata: %s\n\n", data) {
+				return
+			}
+		}
+		for _, se := range replay {
+			if !send(se) {
+				return
+			}
+		}
+		if !write(": connected\n\n") {
+			return
+		}
+
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				if !write(": heartbeat\n\n") {
+					return
+				}
+			case se, ok := <-sub.Events():
+				if !ok || !send(se) {
+					return
+				}
+			}
+		}
+	}
+}
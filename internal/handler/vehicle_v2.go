@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerVehicleV2 is a struct with methods that represent handlers for vehicles in the version 2 of the api
+// - vehicles are serialized in the shape of the body (VehicleJSON) and listed sorted by id
+// - the finders and averages by path segments of HandlerVehicle are replaced by Find and Average, driven by the filters of the query
+// - the rest of the handlers are the ones of HandlerVehicle
+type HandlerVehicleV2 struct {
+	*HandlerVehicle
+}
+
+// NewHandlerVehicleV2 is a function that returns a new instance of HandlerVehicleV2
+func NewHandlerVehicleV2(sv internal.ServiceVehicle) *HandlerVehicleV2 {
+	return &HandlerVehicleV2{HandlerVehicle: NewHandlerVehicle(sv)}
+}
+
+// Find returns a handler that returns the vehicles that match the filters
+// - query: the filters of the listings, units optional, as_of optional
+func (h *HandlerVehicleV2) Find() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		f, err := filter(r, u)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		v, err := sv.FindByFilter(f)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidFilter):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles found",
+			"units":   NewUnitsJSON(u),
+			"data":    NewVehiclesJSON(v, u),
+		})
+	}
+}
+
+// Average returns a handler that returns the average of an attribute among the vehicles that match the filters
+// - query: metric required (a numeric attribute), the filters of the listings, units optional, as_of optional
+func (h *HandlerVehicleV2) Average() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		var query internal.AverageQuery
+		query.Metric, err = internal.ParseVehicleMetric(r.URL.Query().Get("metric"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, fmt.Sprintf("invalid metric: %v", err))
+			return
+		}
+		query.VehicleFilter, err = filter(r, u)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		average, err := sv.Average(query)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidFilter):
+				response.Error(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, internal.ErrServiceNoVehicles):
+				response.Error(w, http.StatusNotFound, "vehicles not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "average found",
+			"units":   NewUnitsJSON(u),
+			"data": AverageJSON{
+				Metric:  query.Metric,
+				Unit:    query.Metric.Unit(u),
+				Average: query.Metric.In(average, u),
+			},
+		})
+	}
+}
+
+// FindById returns a handler that returns the vehicle that matches the id
+func (h *HandlerVehicleV2) FindById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		v, err := sv.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicle found",
+			"units":   NewUnitsJSON(u),
+			"data":    NewVehicleJSON(v, u),
+		})
+	}
+}
+
+// FindByRegistration returns a handler that returns the vehicles that match the registration
+// - the registration is compared in its canonical form
+// - more than one vehicle only for the duplicates of a loaded dataset
+func (h *HandlerVehicleV2) FindByRegistration() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		registration := chi.URLParam(r, "registration")
+		sv, err := h.service(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, asOfMessage(err))
+			return
+		}
+
+		// process
+		v, err := sv.FindByRegistration(registration)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicles found",
+			"units":   NewUnitsJSON(u),
+			"data":    NewVehiclesJSON(v, u),
+		})
+	}
+}
+
+// Create returns a handler that adds a vehicle
+func (h *HandlerVehicleV2) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		var body VehicleJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+
+		v, err := body.Vehicle(u)
+		if err != nil {
+			response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		// process
+		if err := h.sv.Create(r.Context(), &v); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidVehicle):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			case errors.Is(err, internal.ErrServiceVehicleAlreadyExists):
+				response.Error(w, http.StatusConflict, "vehicle already exists")
+			case errors.Is(err, internal.ErrServiceRegistrationConflict):
+				response.Error(w, http.StatusConflict, "registration already exists")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "vehicle created",
+			"units":   NewUnitsJSON(u),
+			"data":    NewVehicleJSON(v, u),
+		})
+	}
+}
+
+// Update returns a handler that replaces the vehicle that matches the id
+func (h *HandlerVehicleV2) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var body VehicleJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+
+		v, err := body.Vehicle(u)
+		if err != nil {
+			response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		v.Id = id
+
+		// process
+		if err := h.sv.Update(r.Context(), &v); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidVehicle):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, internal.ErrServiceRegistrationConflict):
+				response.Error(w, http.StatusConflict, "registration already exists")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicle updated",
+			"units":   NewUnitsJSON(u),
+			"data":    NewVehicleJSON(v, u),
+		})
+	}
+}
+
+// FindHistory returns a handler that returns every version of the vehicle that matches the id
+func (h *HandlerVehicleV2) FindHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		versions, err := h.sv.FindHistory(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicle history found",
+			"units":   NewUnitsJSON(u),
+			"data":    NewVehicleVersionsV2JSON(versions, u),
+		})
+	}
+}
+
+// Restore returns a handler that undeletes the vehicle that matches the id
+func (h *HandlerVehicleV2) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		u, err := units(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid units")
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		v, err := h.sv.Restore(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceVehicleNotFound):
+				response.Error(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, internal.ErrServiceVehicleNotDeleted):
+				response.Error(w, http.StatusConflict, "vehicle not deleted")
+			case errors.Is(err, internal.ErrServiceRegistrationConflict):
+				response.Error(w, http.StatusConflict, "registration already exists")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "vehicle restored",
+			"units":   NewUnitsJSON(u),
+			"data":    NewVehicleJSON(v, u),
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerWebhook is a struct with methods that represent handlers for the webhooks
+// - a principal of a single fleet only sees, and only creates, the webhooks of its fleet
+type HandlerWebhook struct {
+	// sv is the service that will be used by the handler
+	sv internal.ServiceWebhook
+	// fleets are the names of the fleets served by the application
+	fleets map[string]bool
+}
+
+// NewHandlerWebhook is a function that returns a new instance of HandlerWebhook
+func NewHandlerWebhook(sv internal.ServiceWebhook, fleets []string) *HandlerWebhook {
+	names := make(map[string]bool, len(fleets))
+	for _, fleet := range fleets {
+		names[fleet] = true
+	}
+	return &HandlerWebhook{sv: sv, fleets: names}
+}
+
+// WebhookJSON is a struct that represents the body of a webhook in JSON format
+type WebhookJSON struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Fleet  string   `json:"fleet"`
+}
+
+// WebhookResponseJSON is a struct that represents a webhook in the responses
+// - the secret is only returned when the webhook is created
+type WebhookResponseJSON struct {
+	Id        int                  `json:"id"`
+	URL       string               `json:"url"`
+	Secret    string               `json:"secret,omitempty"`
+	Events    []internal.EventType `json:"events"`
+	Fleet     string               `json:"fleet"`
+	CreatedBy string               `json:"created_by"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// NewWebhookResponseJSON is a function that serializes a webhook without its secret
+func NewWebhookResponseJSON(wh internal.Webhook) WebhookResponseJSON {
+	events := append(make([]internal.EventType, 0, len(wh.Events)), wh.Events...)
+	return WebhookResponseJSON{
+		Id:        wh.Id,
+		URL:       wh.URL,
+		Events:    events,
+		Fleet:     wh.Fleet,
+		CreatedBy: wh.CreatedBy,
+		CreatedAt: wh.CreatedAt,
+	}
+}
+
+// DeliveryJSON is a struct that represents a delivery attempt in JSON format
+type DeliveryJSON struct {
+	Id         int                     `json:"id"`
+	EventId    string                  `json:"event_id"`
+	EventType  internal.EventType      `json:"event_type"`
+	Attempt    int                     `json:"attempt"`
+	Time       time.Time               `json:"time"`
+	StatusCode int                     `json:"status_code"`
+	Error      string                  `json:"error"`
+	Status     internal.DeliveryStatus `json:"status"`
+}
+
+// DeadLetterJSON is a struct that represents a dead letter in JSON format
+type DeadLetterJSON struct {
+	Id        int       `json:"id"`
+	Event     EventJSON `json:"event"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	Time      time.Time `json:"time"`
+}
+
+// List returns a handler that returns the webhooks the caller can see
+func (h *HandlerWebhook) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		p, authenticated := internal.PrincipalFromContext(r.Context())
+
+		// process
+		wh, err := h.sv.FindAll()
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		// response
+		data := make([]WebhookResponseJSON, 0, len(wh))
+		for _, webhook := range wh {
+			if authenticated && p.Fleet != "" && webhook.Fleet != p.Fleet {
+				continue
+			}
+			data = append(data, NewWebhookResponseJSON(webhook))
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "webhooks found",
+			"data":    data,
+		})
+	}
+}
+
+// Create returns a handler that subscribes an url to the events
+// - body: url required. secret (random if empty), events (every type if empty) and fleet (every fleet if empty) optional
+func (h *HandlerWebhook) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		var body WebhookJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		if p, ok := internal.PrincipalFromContext(r.Context()); ok && p.Fleet != "" {
+			if body.Fleet != "" && !p.CanAccessFleet(body.Fleet) {
+				response.Problem(w, http.StatusForbidden, "fleet "+body.Fleet+" not allowed")
+				return
+			}
+			body.Fleet = p.Fleet
+		}
+		if body.Fleet != "" && !h.fleets[body.Fleet] {
+			response.Error(w, http.StatusUnprocessableEntity, "fleet "+body.Fleet+" not found")
+			return
+		}
+		wh := internal.Webhook{URL: body.URL, Secret: body.Secret, Fleet: body.Fleet}
+		for _, name := range body.Events {
+			wh.Events = append(wh.Events, internal.EventType(name))
+		}
+
+		// process
+		if err := h.sv.Create(r.Context(), &wh); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidWebhook):
+				response.Error(w, http.StatusUnprocessableEntity, err.Error())
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		data := NewWebhookResponseJSON(wh)
+		data.Secret = wh.Secret
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "webhook created",
+			"data":    data,
+		})
+	}
+}
+
+// FindById returns a handler that returns the webhook that matches the id
+func (h *HandlerWebhook) FindById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		wh, ok := h.webhook(w, r)
+		if !ok {
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "webhook found",
+			"data":    NewWebhookResponseJSON(wh),
+		})
+	}
+}
+
+// Delete returns a handler that removes the webhook that matches the id
+func (h *HandlerWebhook) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		wh, ok := h.webhook(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		if err := h.sv.Delete(r.Context(), wh.Id); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceWebhookNotFound):
+				response.Error(w, http.StatusNotFound, "webhook not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Deliveries returns a handler that returns the delivery attempts of the webhook that matches the id
+func (h *HandlerWebhook) Deliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		wh, ok := h.webhook(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		d, err := h.sv.FindDeliveries(wh.Id)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		// response
+		data := make([]DeliveryJSON, 0, len(d))
+		for _, delivery := range d {
+			data = append(data, DeliveryJSON{
+				Id:         delivery.Id,
+				EventId:    delivery.EventId,
+				EventType:  delivery.EventType,
+				Attempt:    delivery.Attempt,
+				Time:       delivery.Time,
+				StatusCode: delivery.StatusCode,
+				Error:      delivery.Error,
+				Status:     delivery.Status,
+			})
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "deliveries found",
+			"data":    data,
+		})
+	}
+}
+
+// DeadLetters returns a handler that returns the events that could not be delivered to the webhook that matches the id
+func (h *HandlerWebhook) DeadLetters() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		wh, ok := h.webhook(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		dl, err := h.sv.FindDeadLetters(wh.Id)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		// response
+		data := make([]DeadLetterJSON, 0, len(dl))
+		for _, deadLetter := range dl {
+			data = append(data, DeadLetterJSON{
+				Id:        deadLetter.Id,
+				Event:     NewEventJSON(deadLetter.Event),
+				Attempts:  deadLetter.Attempts,
+				LastError: deadLetter.LastError,
+				Time:      deadLetter.Time,
+			})
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "dead letters found",
+			"data":    data,
+		})
+	}
+}
+
+// Redeliver returns a handler that delivers again the event of a dead letter of the webhook that matches the id
+func (h *HandlerWebhook) Redeliver() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		wh, ok := h.webhook(w, r)
+		if !ok {
+			return
+		}
+		deadLetterId, err := strconv.Atoi(chi.URLParam(r, "dead_letter_id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid dead_letter_id")
+			return
+		}
+
+		// process
+		if err := h.sv.Redeliver(r.Context(), wh.Id, deadLetterId); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceWebhookNotFound):
+				response.Error(w, http.StatusNotFound, "webhook not found")
+			case errors.Is(err, internal.ErrServiceDeadLetterNotFound):
+				response.Error(w, http.StatusNotFound, "dead letter not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// webhook is a method that returns the webhook of the id url parameter, writing the error response if it is not found
+// - the webhooks of other fleets are not found for a principal of a single fleet
+func (h *HandlerWebhook) webhook(w http.ResponseWriter, r *http.Request) (wh internal.Webhook, ok bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	wh, err = h.sv.FindById(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrServiceWebhookNotFound):
+			response.Error(w, http.StatusNotFound, "webhook not found")
+		default:
+			response.Error(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+	if p, authenticated := internal.PrincipalFromContext(r.Context()); authenticated && p.Fleet != "" && wh.Fleet != p.Fleet {
+		response.Error(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	ok = true
+	return
+}
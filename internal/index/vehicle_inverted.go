@@ -0,0 +1,328 @@
+package index
+
+import (
+	"app/internal"
+	"app/internal/normalizer"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// fieldWeights is the weight of a match in each indexed field
+var fieldWeights = map[string]float64{
+	"brand":        3,
+	"model":        3,
+	"registration": 2,
+	"color":        1,
+}
+
+// fieldTypos are the indexed fields whose terms match within an edit distance
+// - the registrations are left out: a term each vehicle, they would make the typos scan the whole fleet
+var fieldTypos = map[string]bool{
+	"brand": true,
+	"model": true,
+	"color": true,
+}
+
+// NewIndexVehicleInverted is a function that returns a new instance of IndexVehicleInverted
+// - nz: normalizer used to expand the aliases of the search terms (nil disables the expansion)
+func NewIndexVehicleInverted(nz internal.Normalizer) *IndexVehicleInverted {
+	return &IndexVehicleInverted{
+		nz:       nz,
+		postings: make(map[string]map[int]float64),
+		docs:     make(map[int]map[string]bool),
+		typos:    make(map[string]int),
+		lengths:  make(map[int]map[string]struct{}),
+	}
+}
+
+// IndexVehicleInverted is a struct that implements the IndexVehicle interface with an in-memory inverted index
+// - text is tokenized with normalizer.Fold
+// - terms match exactly, by prefix or within an edit distance that grows with the length of the term
+// - exact terms are looked up in the postings, prefixes in the sorted terms and typos only among the terms of a similar length
+// of the fields in fieldTypos
+type IndexVehicleInverted struct {
+	// mu is the lock that guards postings, docs, terms, typos and lengths
+	mu sync.RWMutex
+	// nz is the normalizer used to expand the aliases of the search terms
+	nz internal.Normalizer
+	// postings is a map of term to vehicle id to the weight of the best field that contains the term
+	postings map[string]map[int]float64
+	// docs is a map of vehicle id to its terms, true if the term is in a field of fieldTypos, used to remove the vehicle
+	docs map[int]map[string]bool
+	// terms are the indexed terms, sorted, used to find the terms of a prefix
+	terms []string
+	// typos is a map of term to the number of vehicles that have it in a field of fieldTypos
+	typos map[string]int
+	// lengths is a map of length in runes to the terms of typos of that length, used to find the terms within an edit distance
+	lengths map[int]map[string]struct{}
+}
+
+// Index is a method that adds or replaces the vehicle in the index
+func (ix *IndexVehicleInverted) Index(v internal.Vehicle) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(v.Id)
+
+	fields := map[string]string{
+		"brand":        v.Brand,
+		"model":        v.Model,
+		"registration": v.Registration,
+		"color":        v.Color,
+	}
+	terms := make(map[string]bool)
+	for field, value := range fields {
+		for _, term := range tokenize(value) {
+			if _, ok := ix.postings[term]; !ok {
+				ix.postings[term] = make(map[int]float64)
+				ix.addTerm(term)
+			}
+			ix.postings[term][v.Id] = math.Max(ix.postings[term][v.Id], fieldWeights[field])
+			terms[term] = terms[term] || fieldTypos[field]
+		}
+	}
+	for term, typos := range terms {
+		if typos {
+			ix.addTypos(term)
+		}
+	}
+	ix.docs[v.Id] = terms
+}
+
+// Remove is a method that removes the vehicle from the index
+func (ix *IndexVehicleInverted) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(id)
+}
+
+// Search is a method that returns the vehicles that match the text, most relevant first
+// - the score of a vehicle is the sum, over the search terms, of its best match weighted by field and rarity,
+// scaled by the fraction of search terms it matches
+func (ix *IndexVehicleInverted) Search(text string, limit int) (h []internal.SearchHit) {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	total := float64(len(ix.docs))
+	scores := make(map[int]float64)
+	matches := make(map[int]int)
+	for _, token := range tokens {
+		// best score of each vehicle for this token
+		best := make(map[int]float64)
+		for _, variant := range ix.variants(token) {
+			for term, similarity := range ix.matches(variant) {
+				postings := ix.postings[term]
+				idf := math.Log(1 + total/float64(len(postings)))
+				for id, weight := range postings {
+					best[id] = math.Max(best[id], similarity*weight*idf)
+				}
+			}
+		}
+
+		for id, score := range best {
+			scores[id] += score
+			matches[id]++
+		}
+	}
+
+	// rank
+	h = make([]internal.SearchHit, 0, len(scores))
+	for id, score := range scores {
+		h = append(h, internal.SearchHit{Id: id, Score: score * float64(matches[id]) / float64(len(tokens))})
+	}
+	sort.Slice(h, func(i, j int) bool {
+		if h[i].Score != h[j].Score {
+			return h[i].Score > h[j].Score
+		}
+		return h[i].Id < h[j].Id
+	})
+	if limit > 0 && len(h) > limit {
+		h = h[:limit]
+	}
+	return
+}
+
+// remove is a method that removes the vehicle from the postings
+// - the caller must hold the write lock
+func (ix *IndexVehicleInverted) remove(id int) {
+	for term, typos := range ix.docs[id] {
+		if typos {
+			ix.removeTypos(term)
+		}
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+			ix.removeTerm(term)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// variants is a method that returns the token and the terms of its aliases (e.g. "chevy" -> "chevrolet")
+func (ix *IndexVehicleInverted) variants(token string) (v []string) {
+	v = []string{token}
+	if ix.nz == nil {
+		return
+	}
+
+	seen := map[string]bool{token: true}
+	for _, field := range []string{"brand", "model", "color"} {
+		for _, term := range strings.Fields(ix.nz.Key(field, token)) {
+			if !seen[term] {
+				seen[term] = true
+				v = append(v, term)
+			}
+		}
+	}
+	return
+}
+
+// tokenize is a function that splits a text into folded terms
+func tokenize(text string) []string {
+	return strings.Fields(normalizer.Fold(text))
+}
+
+// addTerm is a method that adds a new term to the sorted terms
+// - the caller must hold the write lock
+func (ix *IndexVehicleInverted) addTerm(term string) {
+	i, _ := slices.BinarySearch(ix.terms, term)
+	ix.terms = slices.Insert(ix.terms, i, term)
+}
+
+// removeTerm is a method that removes a term without postings from the sorted terms
+// - the caller must hold the write lock
+func (ix *IndexVehicleInverted) removeTerm(term string) {
+	if i, ok := slices.BinarySearch(ix.terms, term); ok {
+		ix.terms = slices.Delete(ix.terms, i, i+1)
+	}
+}
+
+// addTypos is a method that counts a vehicle with the term in a field of fieldTypos, the first one adds it to the terms of its length
+// - the caller must hold the write lock
+func (ix *IndexVehicleInverted) addTypos(term string) {
+	ix.typos[term]++
+	if ix.typos[term] > 1 {
+		return
+	}
+
+	n := utf8.RuneCountInString(term)
+	if _, ok := ix.lengths[n]; !ok {
+		ix.lengths[n] = make(map[string]struct{})
+	}
+	ix.lengths[n][term] = struct{}{}
+}
+
+// removeTypos is a method that uncounts a vehicle with the term in a field of fieldTypos, the last one removes it from the terms of its length
+// - the caller must hold the write lock
+func (ix *IndexVehicleInverted) removeTypos(term string) {
+	ix.typos[term]--
+	if ix.typos[term] > 0 {
+		return
+	}
+	delete(ix.typos, term)
+
+	n := utf8.RuneCountInString(term)
+	delete(ix.lengths[n], term)
+	if len(ix.lengths[n]) == 0 {
+		delete(ix.lengths, n)
+	}
+}
+
+// matches is a method that returns the indexed terms that match a search token and their similarity
+// - 1 for exact matches, (0.5, 0.9) for prefixes, 0.6 and 0.4 for one and two edits
+// - the caller must hold the read lock
+func (ix *IndexVehicleInverted) matches(token string) (m map[string]float64) {
+	m = make(map[string]float64)
+
+	// exact
+	if _, ok := ix.postings[token]; ok {
+		m[token] = 1
+	}
+
+	// prefix: the terms of a prefix are contiguous in the sorted terms
+	if len(token) >= 2 {
+		i, _ := slices.BinarySearch(ix.terms, token)
+		for ; i < len(ix.terms) && strings.HasPrefix(ix.terms[i], token); i++ {
+			if term := ix.terms[i]; term != token {
+				m[term] = 0.5 + 0.4*float64(len(token))/float64(len(term))
+			}
+		}
+	}
+
+	// typos: tolerance grows with the length of the token, only the terms of fieldTypos whose length is within it can match
+	n := utf8.RuneCountInString(token)
+	maxEdits := 0
+	switch {
+	case n >= 8:
+		maxEdits = 2
+	case n >= 4:
+		maxEdits = 1
+	}
+	if maxEdits == 0 {
+		return
+	}
+	for length := n - maxEdits; length <= n+maxEdits; length++ {
+		for term := range ix.lengths[length] {
+			if _, ok := m[term]; ok {
+				continue
+			}
+			switch d := editDistance(token, term, maxEdits); {
+			case d > maxEdits:
+				continue
+			case d == 1:
+				m[term] = 0.6
+			default:
+				m[term] = 0.4
+			}
+		}
+	}
+	return
+}
+
+// editDistance is a function that returns the optimal string alignment distance between a and b
+// - it returns max+1 as soon as the distance is known to be greater than max
+func editDistance(a string, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+
+	// rows of the dynamic programming matrix: two rows back, previous and current
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			// transposition
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,134 @@
+package index_test
+
+import (
+	"app/internal"
+	"app/internal/index"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for IndexVehicleInverted.Search method
+func TestIndexVehicleInverted_Search(t *testing.T) {
+	// arrange
+	ix := index.NewIndexVehicleInverted(nil)
+	ix.Index(internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Hummer", Model: "H2", Color: "Orange"}})
+	ix.Index(internal.Vehicle{Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Chevrolet", Model: "Cavalier", Color: "Blue"}})
+	ix.Index(internal.Vehicle{Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Chevrolet", Model: "Camaro", Color: "Orange"}})
+
+	t.Run("typo tolerance", func(t *testing.T) {
+		// act
+		h := ix.Search("hummr", 10)
+
+		// assert
+		require.Len(t, h, 1)
+		require.Equal(t, 1, h[0].Id)
+	})
+
+	t.Run("prefix and ranking", func(t *testing.T) {
+		// act
+		h := ix.Search("chev cavalier", 10)
+
+		// assert
+		require.Len(t, h, 2)
+		require.Equal(t, 2, h[0].Id)
+		require.Equal(t, 3, h[1].Id)
+	})
+
+	t.Run("removed vehicles are not found", func(t *testing.T) {
+		// arrange
+		ix.Remove(1)
+
+		// act
+		h := ix.Search("hummer", 10)
+
+		// assert
+		require.Empty(t, h)
+	})
+}
+
+// Tests for the ranking and the typo tolerance of IndexVehicleInverted.Search method
+func TestIndexVehicleInverted_Search_Ranking(t *testing.T) {
+	// arrange
+	ix := index.NewIndexVehicleInverted(nil)
+	ix.Index(internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Chevrolet", Model: "Camaro", Color: "Blue"}})
+	ix.Index(internal.Vehicle{Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Chevrolet", Model: "Cavalier", Color: "Red"}})
+	ix.Index(internal.Vehicle{Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Camarosa", Color: "Red"}})
+	ix.Index(internal.Vehicle{Id: 4, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Blue", Color: "Green"}})
+	ids := func(h []internal.SearchHit) (s []int) {
+		for _, hit := range h {
+			s = append(s, hit.Id)
+		}
+		return
+	}
+
+	t.Run("exact matches rank above prefixes, prefixes above typos", func(t *testing.T) {
+		// act
+		exact := ix.Search("camaro", 10)
+		typo := ix.Search("camarp", 10)
+
+		// assert
+		require.Equal(t, []int{1, 3}, ids(exact))
+		require.Greater(t, exact[0].Score, exact[1].Score)
+		require.Equal(t, []int{1}, ids(typo))
+		require.Less(t, typo[0].Score, exact[0].Score)
+	})
+
+	t.Run("fields are weighted: a model ranks above a color", func(t *testing.T) {
+		// act
+		h := ix.Search("blue", 10)
+
+		// assert
+		require.Equal(t, []int{4, 1}, ids(h))
+	})
+
+	t.Run("vehicles that match more search terms rank first", func(t *testing.T) {
+		// act
+		h := ix.Search("ford red", 10)
+
+		// assert
+		require.Equal(t, []int{3, 4, 2}, ids(h))
+	})
+
+	t.Run("typo tolerance grows with the length of the term", func(t *testing.T) {
+		// act
+		short := ix.Search("rex", 10)
+		oneEdit := ix.Search("bleu", 10)
+		twoEditsShort := ix.Search("cemarp", 10)
+		twoEditsLong := ix.Search("cavlaeir", 10)
+
+		// assert
+		require.Empty(t, short)
+		require.Equal(t, []int{4, 1}, ids(oneEdit))
+		require.Empty(t, twoEditsShort)
+		require.Equal(t, []int{2}, ids(twoEditsLong))
+	})
+
+	t.Run("prefixes and typos do not find the terms of removed vehicles", func(t *testing.T) {
+		// arrange
+		ix.Remove(3)
+
+		// act
+		prefix := ix.Search("camar", 10)
+		typo := ix.Search("camarisa", 10)
+
+		// assert
+		require.Equal(t, []int{1}, ids(prefix))
+		require.Empty(t, typo)
+	})
+
+	t.Run("registrations match exactly or by prefix, not within an edit distance", func(t *testing.T) {
+		// arrange
+		ix.Index(internal.Vehicle{Id: 5, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Model: "Panda", Registration: "ABCD-1234"}})
+
+		// act
+		exact := ix.Search("abcd", 10)
+		prefix := ix.Search("abc", 10)
+		typo := ix.Search("abce", 10)
+
+		// assert
+		require.Equal(t, []int{5}, ids(exact))
+		require.Equal(t, []int{5}, ids(prefix))
+		require.Empty(t, typo)
+	})
+}
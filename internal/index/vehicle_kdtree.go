@@ -0,0 +1,335 @@
+package index
+
+import (
+	"app/internal"
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// NewIndexVehicleKDTree is a function that returns a new instance of IndexVehicleKDTree
+func NewIndexVehicleKDTree() *IndexVehicleKDTree {
+	return &IndexVehicleKDTree{points: make(map[int][]float64)}
+}
+
+// IndexVehicleKDTree is a struct that implements the IndexSimilarVehicle interface with an in-memory k-d tree
+// - a point is a vehicle, with a dimension per attribute of internal.VehicleMetrics normalized by its range in the fleet
+// - the searches share an immutable snapshot of the tree. A mutation within the ranges of the snapshot is added to a copy of it, as a point
+// searched linearly or as a node to skip, since the normalized points do not change
+// - a mutation that changes the ranges, or that fills the points searched linearly, drops the snapshot: the first search after it builds a new one,
+// without blocking the mutations, and swaps it in
+// - a search visits the branches that can hold a closer vehicle only, a sublinear part of the fleet (see BenchmarkIndexVehicleKDTree_Nearest)
+type IndexVehicleKDTree struct {
+	// mu is the lock that guards the points, the version and the tree
+	mu sync.RWMutex
+	// points are the attributes of each vehicle, in the stored units
+	points map[int][]float64
+	// version is the number of mutations of the points
+	version uint64
+	// tree is the snapshot of the tree of the current points (nil: never built or dropped by a mutation)
+	tree *treeKDTree
+	// building is the lock that serializes the builds, so the searches after a mutation build the tree once
+	building sync.Mutex
+}
+
+// treeKDTree is a struct that represents a k-d tree of the points of a version, immutable once built
+// - the mutations after the build, within the ranges, are kept aside: the nodes and the ranges are shared by the versions
+type treeKDTree struct {
+	// version is the version of the points of the tree
+	version uint64
+	// min is the minimum of each attribute
+	min []float64
+	// max is the maximum of each attribute
+	max []float64
+	// atMin is the number of points on the minimum of each attribute
+	atMin []int
+	// atMax is the number of points on the maximum of each attribute
+	atMax []int
+	// span is the range of each attribute (0: every vehicle has the same value)
+	span []float64
+	// nodes are the nodes of the tree
+	nodes []nodeKDTree
+	// root is the position of the root in the nodes (-1: empty tree)
+	root int
+	// added are the normalized points indexed after the build, searched linearly
+	added map[int][]float64
+	// removed are the ids of the nodes removed or replaced after the build, skipped by the searches
+	removed map[int]bool
+	// limit is the number of mutations kept aside, past which the tree is built again
+	limit int
+}
+
+// nodeKDTree is a struct that represents a vehicle in the tree
+type nodeKDTree struct {
+	// id is the id of the vehicle
+	id int
+	// point is the normalized attributes of the vehicle
+	point []float64
+	// axis is the attribute that splits the children
+	axis int
+	// left is the position of the child with lower or equal values (-1: none)
+	left int
+	// right is the position of the child with greater or equal values (-1: none)
+	right int
+}
+
+// Index is a method that adds or replaces the vehicle in the index
+func (ix *IndexVehicleKDTree) Index(v internal.Vehicle) {
+	point := make([]float64, len(internal.VehicleMetrics))
+	for i, m := range internal.VehicleMetrics {
+		point[i] = m.Value(v)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	previous := ix.points[v.Id]
+	ix.points[v.Id] = point
+	ix.version++
+	ix.tree = ix.tree.mutate(ix.version, v.Id, previous, point)
+}
+
+// Remove is a method that removes the vehicle from the index
+func (ix *IndexVehicleKDTree) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	point, indexed := ix.points[id]
+	if !indexed {
+		return
+	}
+	delete(ix.points, id)
+	ix.version++
+	ix.tree = ix.tree.mutate(ix.version, id, point, nil)
+}
+
+// Nearest is a method that returns the k vehicles closest to the vehicle of the id, closest first, itself excluded
+// - ties are broken by id
+func (ix *IndexVehicleKDTree) Nearest(id int, k int) (h []internal.SimilarHit, ok bool) {
+	ix.mu.RLock()
+	point, ok := ix.points[id]
+	tree := ix.tree
+	version := ix.version
+	ix.mu.RUnlock()
+	if !ok {
+		return
+	}
+	if tree == nil || tree.version != version {
+		tree = ix.snapshot()
+	}
+
+	// search with the squared distances
+	h = make([]internal.SimilarHit, 0, k+1)
+	if k > 0 {
+		q := tree.normalize(point)
+		tree.search(tree.root, q, id, k, &h)
+		for added, p := range tree.added {
+			if added != id {
+				insert(internal.SimilarHit{Id: added, Distance: distance(q, p)}, k, &h)
+			}
+		}
+	}
+	for i := range h {
+		h[i].Distance = math.Sqrt(h[i].Distance)
+	}
+	return
+}
+
+// snapshot is a method that returns the tree of the current points, building it if the last one is stale
+// - the tree is built from a copy of the points, so the mutations and the searches on the last tree go on meanwhile
+func (ix *IndexVehicleKDTree) snapshot() *treeKDTree {
+	ix.building.Lock()
+	defer ix.building.Unlock()
+
+	// a concurrent search may have built it
+	ix.mu.RLock()
+	if ix.tree != nil && ix.tree.version == ix.version {
+		defer ix.mu.RUnlock()
+		return ix.tree
+	}
+	version := ix.version
+	points := maps.Clone(ix.points)
+	ix.mu.RUnlock()
+
+	tree := newTreeKDTree(points, version)
+
+	// the tree is kept if no mutation happened meanwhile, otherwise it only serves this search
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.version == tree.version {
+		ix.tree = tree
+	}
+	return tree
+}
+
+// newTreeKDTree is a function that builds the ranges and the tree of the points
+func newTreeKDTree(points map[int][]float64, version uint64) (t *treeKDTree) {
+	dims := len(internal.VehicleMetrics)
+	t = &treeKDTree{version: version, min: make([]float64, dims), max: make([]float64, dims), span: make([]float64, dims)}
+	ids := make([]int, 0, len(points))
+	for id, point := range points {
+		for d, value := range point {
+			if len(ids) == 0 || value < t.min[d] {
+				t.min[d] = value
+			}
+			if len(ids) == 0 || value > t.max[d] {
+				t.max[d] = value
+			}
+		}
+		ids = append(ids, id)
+	}
+	t.atMin, t.atMax = make([]int, dims), make([]int, dims)
+	for _, point := range points {
+		for d, value := range point {
+			if value == t.min[d] {
+				t.atMin[d]++
+			}
+			if value == t.max[d] {
+				t.atMax[d]++
+			}
+		}
+	}
+	for d := range t.span {
+		t.span[d] = t.max[d] - t.min[d]
+	}
+	// - the mutations kept aside are searched linearly: a few times as many points as a search visits, about the square root of the fleet
+	t.limit = max(64, 4*int(math.Sqrt(float64(len(ids)))))
+
+	entries := make([]nodeKDTree, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, nodeKDTree{id: id, point: t.normalize(points[id])})
+	}
+	t.nodes = make([]nodeKDTree, 0, len(ids))
+	t.root = t.split(entries, 0)
+	return
+}
+
+// mutate is a method that returns a copy of the tree with the previous point of the id replaced by the point, sharing the nodes
+// - previous nil: the id is added, point nil: the id is removed
+// - nil if the tree is nil, if the mutation changes the ranges or if the mutations kept aside reach the limit
+func (t *treeKDTree) mutate(version uint64, id int, previous []float64, point []float64) *treeKDTree {
+	if t == nil || len(t.removed) >= t.limit {
+		return nil
+	}
+	m := *t
+	m.version = version
+	m.atMin, m.atMax = slices.Clone(t.atMin), slices.Clone(t.atMax)
+	for d := range m.min {
+		// - a range shrinks when its last point on a bound is removed, grows with a point out of it
+		if previous != nil && previous[d] == m.min[d] {
+			m.atMin[d]--
+		}
+		if previous != nil && previous[d] == m.max[d] {
+			m.atMax[d]--
+		}
+		if point != nil && point[d] == m.min[d] {
+			m.atMin[d]++
+		}
+		if point != nil && point[d] == m.max[d] {
+			m.atMax[d]++
+		}
+		if m.atMin[d] == 0 || m.atMax[d] == 0 || point != nil && (point[d] < m.min[d] || point[d] > m.max[d]) {
+			return nil
+		}
+	}
+	m.added, m.removed = maps.Clone(t.added), maps.Clone(t.removed)
+	if m.added == nil {
+		m.added, m.removed = make(map[int][]float64), make(map[int]bool)
+	}
+	delete(m.added, id)
+	m.removed[id] = true
+	if point != nil {
+		m.added[id] = t.normalize(point)
+	}
+	return &m
+}
+
+// split is a method that adds the subtree of the entries, split by the median of the axis of the depth, and returns its root
+// - the entries are sorted by the value of the axis, then by id, so the tree is the same whatever the order of the points
+func (t *treeKDTree) split(entries []nodeKDTree, depth int) int {
+	if len(entries) == 0 {
+		return -1
+	}
+	axis := depth % len(internal.VehicleMetrics)
+	slices.SortFunc(entries, func(a, b nodeKDTree) int {
+		if c := cmp.Compare(a.point[axis], b.point[axis]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+	median := len(entries) / 2
+
+	n := len(t.nodes)
+	t.nodes = append(t.nodes, nodeKDTree{id: entries[median].id, point: entries[median].point, axis: axis})
+	left := t.split(entries[:median], depth+1)
+	right := t.split(entries[median+1:], depth+1)
+	t.nodes[n].left, t.nodes[n].right = left, right
+	return n
+}
+
+// search is a method that adds the vehicles of the subtree closer than the k found so far, kept sorted
+// - the distances are squared
+func (t *treeKDTree) search(n int, q []float64, exclude int, k int, h *[]internal.SimilarHit) {
+	if n < 0 {
+		return
+	}
+	node := t.nodes[n]
+	if node.id != exclude && !t.removed[node.id] {
+		insert(internal.SimilarHit{Id: node.id, Distance: distance(q, node.point)}, k, h)
+	}
+
+	// the far side can only hold a closer vehicle if the splitting plane is within the k-th distance
+	diff := q[node.axis] - node.point[node.axis]
+	near, far := node.left, node.right
+	if diff > 0 {
+		near, far = far, near
+	}
+	t.search(near, q, exclude, k, h)
+	if len(*h) < k || diff*diff <= (*h)[len(*h)-1].Distance {
+		t.search(far, q, exclude, k, h)
+	}
+}
+
+// insert is a function that adds the hit to the k closest hits found so far, kept sorted, if it is closer than the last one
+func insert(hit internal.SimilarHit, k int, h *[]internal.SimilarHit) {
+	if len(*h) == k && !closer(hit, (*h)[len(*h)-1]) {
+		return
+	}
+	i := sort.Search(len(*h), func(i int) bool { return closer(hit, (*h)[i]) })
+	*h = append(*h, internal.SimilarHit{})
+	copy((*h)[i+1:], (*h)[i:])
+	(*h)[i] = hit
+	if len(*h) > k {
+		*h = (*h)[:k]
+	}
+}
+
+// normalize is a method that returns the attributes scaled to [0, 1] by the ranges
+func (t *treeKDTree) normalize(point []float64) []float64 {
+	n := make([]float64, len(point))
+	for d, value := range point {
+		if t.span[d] > 0 {
+			n[d] = (value - t.min[d]) / t.span[d]
+		}
+	}
+	return n
+}
+
+// distance is a function that returns the squared euclidean distance between two points
+func distance(a []float64, b []float64) (d float64) {
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return
+}
+
+// closer is a function that returns true if the hit a goes before the hit b: smaller distance, then smaller id
+func closer(a internal.SimilarHit, b internal.SimilarHit) bool {
+	if a.Distance != b.Distance {
+		return a.Distance < b.Distance
+	}
+	return a.Id < b.Id
+}
@@ -0,0 +1,201 @@
+package index_test
+
+import (
+	"app/internal"
+	"app/internal/index"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for IndexVehicleKDTree.Nearest method
+func TestIndexVehicleKDTree_Nearest(t *testing.T) {
+	vehicle := func(id int, speed float64, capacity int, weight float64) internal.Vehicle {
+		return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+			MaxSpeed: internal.Speed(speed), Capacity: capacity, Weight: internal.Mass(weight),
+		}}
+	}
+
+	t.Run("case 01: the neighbours are the same as a linear scan over the normalized attributes", func(t *testing.T) {
+		// arrange
+		rd := rand.New(rand.NewSource(42))
+		ix := index.NewIndexVehicleKDTree()
+		vehicles := make([]internal.Vehicle, 0, 500)
+		for id := 1; id <= 500; id++ {
+			v := vehicle(id, 80+rd.Float64()*170, 1+rd.Intn(8), 500+rd.Float64()*2500)
+			v.Height, v.Length, v.Width = internal.Length(rd.Float64()*300), internal.Length(rd.Float64()*600), internal.Length(rd.Float64()*250)
+			vehicles = append(vehicles, v)
+			ix.Index(v)
+		}
+		// - linear scan: attributes normalized by their ranges
+		scan := func(id int, k int) (ids []int) {
+			lo, hi := make([]float64, len(internal.VehicleMetrics)), make([]float64, len(internal.VehicleMetrics))
+			for d, m := range internal.VehicleMetrics {
+				lo[d], hi[d] = math.Inf(1), math.Inf(-1)
+				for _, v := range vehicles {
+					lo[d], hi[d] = math.Min(lo[d], m.Value(v)), math.Max(hi[d], m.Value(v))
+				}
+			}
+			dist := func(a internal.Vehicle, b internal.Vehicle) (s float64) {
+				for d, m := range internal.VehicleMetrics {
+					diff := (m.Value(a) - m.Value(b)) / (hi[d] - lo[d])
+					s += diff * diff
+				}
+				return
+			}
+			others := make([]internal.Vehicle, 0, len(vehicles))
+			for _, v := range vehicles {
+				if v.Id != id {
+					others = append(others, v)
+				}
+			}
+			sort.Slice(others, func(i, j int) bool { return dist(vehicles[id-1], others[i]) < dist(vehicles[id-1], others[j]) })
+			for _, v := range others[:k] {
+				ids = append(ids, v.Id)
+			}
+			return
+		}
+
+		// act
+		h, ok := ix.Nearest(7, 10)
+
+		// assert
+		require.True(t, ok)
+		ids := make([]int, 0, len(h))
+		for i, hit := range h {
+			ids = append(ids, hit.Id)
+			if i > 0 {
+				require.GreaterOrEqual(t, hit.Distance, h[i-1].Distance)
+			}
+		}
+		require.Equal(t, scan(7, 10), ids)
+	})
+
+	t.Run("case 02: mutations are reflected, ties are broken by id and unknown ids are not found", func(t *testing.T) {
+		// arrange
+		ix := index.NewIndexVehicleKDTree()
+		ix.Index(vehicle(1, 100, 4, 1000))
+		ix.Index(vehicle(2, 200, 4, 1000))
+		ix.Index(vehicle(3, 150, 4, 1000))
+		ix.Index(vehicle(4, 150, 4, 1000))
+		ix.Index(vehicle(5, 150, 4, 1000))
+		_, _ = ix.Nearest(1, 1)
+
+		// act
+		ix.Remove(3)
+		ix.Index(vehicle(2, 110, 4, 1000))
+		h, ok := ix.Nearest(1, 5)
+		_, okRemoved := ix.Nearest(3, 5)
+
+		// assert
+		require.True(t, ok)
+		require.Len(t, h, 3)
+		require.Equal(t, 2, h[0].Id)
+		require.InDelta(t, 0.2, h[0].Distance, 1e-9)
+		require.Equal(t, 4, h[1].Id)
+		require.InDelta(t, 1.0, h[1].Distance, 1e-9)
+		require.Equal(t, 5, h[2].Id)
+		require.Equal(t, h[1].Distance, h[2].Distance)
+		require.False(t, okRemoved)
+	})
+
+	t.Run("case 03: the mutations between the searches give the same neighbours as an index of the resulting fleet", func(t *testing.T) {
+		// arrange
+		rd := rand.New(rand.NewSource(7))
+		random := func(id int) internal.Vehicle {
+			return vehicle(id, 80+rd.Float64()*170, 1+rd.Intn(8), 500+rd.Float64()*2500)
+		}
+		ix := index.NewIndexVehicleKDTree()
+		vehicles := make(map[int]internal.Vehicle)
+		for id := 1; id <= 300; id++ {
+			vehicles[id] = random(id)
+			ix.Index(vehicles[id])
+		}
+		_, _ = ix.Nearest(1, 1)
+
+		// act
+		for i := 0; i < 200; i++ {
+			id := 2 + rd.Intn(400)
+			if _, ok := vehicles[id]; ok && i%3 == 0 {
+				delete(vehicles, id)
+				ix.Remove(id)
+			} else {
+				vehicles[id] = random(id)
+				ix.Index(vehicles[id])
+			}
+			if i%10 == 0 {
+				_, _ = ix.Nearest(1, 1)
+			}
+		}
+		h, ok := ix.Nearest(1, 20)
+
+		// assert
+		rebuilt := index.NewIndexVehicleKDTree()
+		for _, v := range vehicles {
+			rebuilt.Index(v)
+		}
+		expected, okExpected := rebuilt.Nearest(1, 20)
+		require.True(t, ok)
+		require.True(t, okExpected)
+		require.Len(t, h, 20)
+		for i := range expected {
+			require.Equal(t, expected[i].Id, h[i].Id)
+			require.InDelta(t, expected[i].Distance, h[i].Distance, 1e-9)
+		}
+	})
+}
+
+// Benchmarks for IndexVehicleKDTree.Nearest method
+// - the time per search grows far slower than the fleet, a mutation within the ranges before the search does not build the tree again
+func BenchmarkIndexVehicleKDTree_Nearest(b *testing.B) {
+	newIndex := func(n int) *index.IndexVehicleKDTree {
+		rd := rand.New(rand.NewSource(42))
+		ix := index.NewIndexVehicleKDTree()
+		for id := 1; id <= n; id++ {
+			v := internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+				MaxSpeed: internal.Speed(80 + rd.Float64()*170), Capacity: 1 + rd.Intn(8), Weight: internal.Mass(500 + rd.Float64()*2500),
+			}}
+			v.Height, v.Length, v.Width = internal.Length(rd.Float64()*300), internal.Length(rd.Float64()*600), internal.Length(rd.Float64()*250)
+			ix.Index(v)
+		}
+		return ix
+	}
+
+	for _, n := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("fleet %d", n), func(b *testing.B) {
+			ix := newIndex(n)
+			ix.Nearest(1, 10)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ix.Nearest(1+i%n, 10)
+			}
+		})
+	}
+
+	b.Run("fleet 10000, mutation before every search", func(b *testing.B) {
+		ix := newIndex(10000)
+		ix.Nearest(1, 10)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ix.Index(internal.Vehicle{Id: 1 + i%10000, VehicleAttributes: internal.VehicleAttributes{
+				MaxSpeed: 150, Capacity: 4, Weight: 1500, Dimensions: internal.Dimensions{Height: 150, Length: 300, Width: 120},
+			}})
+			ix.Nearest(1+(i+1)%10000, 10)
+		}
+	})
+
+	b.Run("fleet 10000, parallel searches", func(b *testing.B) {
+		ix := newIndex(10000)
+		ix.Nearest(1, 10)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				ix.Nearest(1+i%10000, 10)
+			}
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package loader
+
+import (
+	"app/internal"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// NewLoaderMaintenanceJSON is a function that returns a new instance of LoaderMaintenanceJSON
+func NewLoaderMaintenanceJSON(path string) *LoaderMaintenanceJSON {
+	return &LoaderMaintenanceJSON{path: path}
+}
+
+// LoaderMaintenanceJSON is a struct that implements the LoaderMaintenance and PersisterMaintenance interfaces with a JSON lines file
+// - the odometer readings of the file are in kilometers
+// - a record is appended as a line, the file is never rewritten
+type LoaderMaintenanceJSON struct {
+	// mu is the lock that serializes the appends
+	mu sync.Mutex
+	// path is the path to the file that contains the records, one per line
+	path string
+}
+
+// Load is a method that loads the maintenance records
+// - a file that does not exist yet has no records
+func (l *LoaderMaintenanceJSON) Load() (m []internal.Maintenance, err error) {
+	// open file
+	file, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	defer file.Close()
+
+	// decode lines
+	m = make([]internal.Maintenance, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var recordJSON internal.MaintenanceJSON
+		if err = json.Unmarshal(scanner.Bytes(), &recordJSON); err != nil {
+			err = fmt.Errorf("loader: corrupted maintenance file %s: %w", l.path, err)
+			return
+		}
+
+		rc, errParse := recordJSON.Maintenance(internal.UnitsMetric)
+		if errParse != nil {
+			err = fmt.Errorf("loader: maintenance %d: %w", recordJSON.Id, errParse)
+			return
+		}
+		m = append(m, rc)
+	}
+	err = scanner.Err()
+	return
+}
+
+// Append is a method that adds a record at the end of the file
+// - a line that is only partially written is removed
+func (l *LoaderMaintenanceJSON) Append(m internal.Maintenance) (err error) {
+	line, err := json.Marshal(internal.NewMaintenanceJSON(m, internal.UnitsMetric))
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// open file
+	if err = os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+
+	// write line
+	n, err := file.Write(line)
+	if err != nil && n > 0 {
+		file.Truncate(info.Size())
+	}
+	return
+}
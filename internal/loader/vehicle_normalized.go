@@ -0,0 +1,40 @@
+package loader
+
+import (
+	"app/internal"
+	"slices"
+)
+
+// NewLoaderVehicleNormalized is a function that returns a new instance of LoaderVehicleNormalized
+func NewLoaderVehicleNormalized(ld internal.LoaderVehicle, nz internal.Normalizer) *LoaderVehicleNormalized {
+	return &LoaderVehicleNormalized{ld: ld, nz: nz}
+}
+
+// LoaderVehicleNormalized is a struct that decorates a loader replacing the string attributes by their canonical form
+type LoaderVehicleNormalized struct {
+	// ld is the decorated loader
+	ld internal.LoaderVehicle
+	// nz is the normalizer of the attributes
+	nz internal.Normalizer
+}
+
+// Load is a method that loads the vehicles
+// - the vehicles are normalized in id order, so the forms seen first (see NormalizerAlias) do not depend on the order of the map
+func (l *LoaderVehicleNormalized) Load() (v map[int]internal.Vehicle, err error) {
+	v, err = l.ld.Load()
+	if err != nil {
+		return
+	}
+
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		value := v[id]
+		internal.NormalizeVehicleAttributes(l.nz, &value.VehicleAttributes)
+		v[id] = value
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package loader_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/normalizer"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// loaderVehicleStub is a struct that implements the LoaderVehicle interface with fixed vehicles
+type loaderVehicleStub map[int]internal.Vehicle
+
+// Load is a method that returns a copy of the vehicles
+func (l loaderVehicleStub) Load() (v map[int]internal.Vehicle, err error) {
+	v = make(map[int]internal.Vehicle, len(l))
+	for id, vh := range l {
+		v[id] = vh
+	}
+	return
+}
+
+// Tests for LoaderVehicleNormalized
+func TestLoaderVehicleNormalized_Load(t *testing.T) {
+	t.Run("case 01: the spelling of the lowest id is the canonical one, whatever the order of the map", func(t *testing.T) {
+		// arrange
+		ld := loaderVehicleStub{}
+		for id, color := range []string{"Dark Blue", "dark blue", "DARK  BLUE", "Dark-Blue", "dark Blue"} {
+			ld[id+1] = internal.Vehicle{Id: id + 1, VehicleAttributes: internal.VehicleAttributes{Color: color}}
+		}
+
+		for i := 0; i < 20; i++ {
+			// act
+			v, err := loader.NewLoaderVehicleNormalized(ld, normalizer.NewNormalizerAlias(nil)).Load()
+
+			// assert
+			require.NoError(t, err)
+			for id := range ld {
+				require.Equal(t, "Dark Blue", v[id].Color)
+			}
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package loader
+
+import (
+	"app/internal"
+	"fmt"
+	"slices"
+)
+
+// NewLoaderVehicleRegistration is a function that returns a new instance of LoaderVehicleRegistration
+// - rr: rules of the registrations of the jurisdiction (nil: any format is valid)
+// - report: called with every registration in error (nil: not reported)
+func NewLoaderVehicleRegistration(ld internal.LoaderVehicle, nz internal.Normalizer, rr internal.RegistrationRules, report func(err error)) *LoaderVehicleRegistration {
+	if report == nil {
+		report = func(err error) {}
+	}
+	return &LoaderVehicleRegistration{ld: ld, nz: nz, rr: rr, report: report}
+}
+
+// LoaderVehicleRegistration is a struct that decorates a loader reporting the registrations of the vehicles in error
+// - a registration out of the formats of the jurisdiction or of more than one vehicle is reported, not refused: a dataset from before the rules
+// is loaded as it is, the formats and the uniqueness are enforced on the creates and the updates only
+// - an empty registration is not checked
+type LoaderVehicleRegistration struct {
+	// ld is the decorated loader
+	ld internal.LoaderVehicle
+	// nz is the normalizer that compares the registrations
+	nz internal.Normalizer
+	// rr are the rules of the registrations
+	rr internal.RegistrationRules
+	// report is the function called with every registration in error
+	report func(err error)
+}
+
+// Load is a method that loads the vehicles
+// - a duplicate is reported on the vehicles after the one with the lowest id
+func (l *LoaderVehicleRegistration) Load() (v map[int]internal.Vehicle, err error) {
+	v, err = l.ld.Load()
+	if err != nil {
+		return
+	}
+
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	registrations := make(map[string]int, len(ids))
+	for _, id := range ids {
+		registration := v[id].Registration
+		key := l.nz.Key(internal.RegistrationField, registration)
+		if key == "" {
+			continue
+		}
+		if l.rr != nil {
+			if errFormat := l.rr.Validate(l.rr.Canonical(registration)); errFormat != nil {
+				l.report(fmt.Errorf("loader: vehicle %d: %w", id, errFormat))
+			}
+		}
+		if first, ok := registrations[key]; ok {
+			l.report(fmt.Errorf("loader: vehicle %d: %w: %q is the registration of vehicle %d", id, internal.ErrDuplicateRegistration, registration, first))
+			continue
+		}
+		registrations[key] = id
+	}
+	return
+}
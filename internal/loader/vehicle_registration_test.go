@@ -0,0 +1,89 @@
+package loader_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/normalizer"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for LoaderVehicleRegistration
+func TestLoaderVehicleRegistration_Load(t *testing.T) {
+	rr, err := normalizer.NewRegistrationRulesRegex(normalizer.RegistrationRules{
+		"ar": {Strip: `[\s-]`, Upper: true, Formats: []string{`^[A-Z]{3}[0-9]{3}$`}},
+	}, "ar")
+	require.NoError(t, err)
+	nz := normalizer.NewNormalizerRegistration(normalizer.NewNormalizerAlias(nil), rr)
+	vehicle := func(id int, registration string) internal.Vehicle {
+		return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Registration: registration}}
+	}
+
+	// collect returns a report that appends the errors to reported
+	collect := func(reported *[]error) func(err error) {
+		return func(err error) { *reported = append(*reported, err) }
+	}
+
+	t.Run("case 01: unique registrations in the formats of the rules are loaded, none reported", func(t *testing.T) {
+		// arrange
+		ld := loaderVehicleStub{1: vehicle(1, "ABC123"), 2: vehicle(2, "XYZ987"), 3: vehicle(3, ""), 4: vehicle(4, "")}
+		var reported []error
+
+		// act
+		v, err := loader.NewLoaderVehicleRegistration(ld, nz, rr, collect(&reported)).Load()
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 4)
+		require.Empty(t, reported)
+	})
+
+	t.Run("case 02: duplicates and registrations out of the formats are loaded, every one reported", func(t *testing.T) {
+		// arrange
+		ld := loaderVehicleStub{1: vehicle(1, "ABC123"), 2: vehicle(2, "abc-123"), 3: vehicle(3, "12-34"), 4: vehicle(4, "ABC 123")}
+		var reported []error
+
+		// act
+		v, err := loader.NewLoaderVehicleRegistration(ld, nz, rr, collect(&reported)).Load()
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 4)
+		require.Len(t, reported, 3)
+		require.ErrorIs(t, reported[0], internal.ErrDuplicateRegistration)
+		require.Contains(t, reported[0].Error(), "vehicle 2:")
+		require.ErrorIs(t, reported[1], internal.ErrInvalidRegistration)
+		require.Contains(t, reported[1].Error(), "vehicle 3:")
+		require.ErrorIs(t, reported[2], internal.ErrDuplicateRegistration)
+		require.Contains(t, reported[2].Error(), "vehicle 4:")
+	})
+
+	t.Run("case 03: without rules any format is loaded, only the duplicates reported", func(t *testing.T) {
+		// arrange
+		ld := loaderVehicleStub{1: vehicle(1, "0"), 2: vehicle(2, "05715"), 3: vehicle(3, "0")}
+		var reported []error
+
+		// act
+		v, err := loader.NewLoaderVehicleRegistration(ld, normalizer.NewNormalizerAlias(nil), nil, collect(&reported)).Load()
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 3)
+		require.Len(t, reported, 1)
+		require.ErrorIs(t, reported[0], internal.ErrDuplicateRegistration)
+		require.Contains(t, reported[0].Error(), "vehicle 3:")
+	})
+
+	t.Run("case 04: a nil report loads the same", func(t *testing.T) {
+		// arrange
+		ld := loaderVehicleStub{1: vehicle(1, "ABC123"), 2: vehicle(2, "ABC123")}
+
+		// act
+		v, err := loader.NewLoaderVehicleRegistration(ld, nz, rr, nil).Load()
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 2)
+	})
+}
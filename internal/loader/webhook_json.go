@@ -0,0 +1,116 @@
+package loader
+
+import (
+	"app/internal"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// NewLoaderWebhookJSON is a function that returns a new instance of LoaderWebhookJSON
+func NewLoaderWebhookJSON(path string) *LoaderWebhookJSON {
+	return &LoaderWebhookJSON{path: path}
+}
+
+// LoaderWebhookJSON is a struct that implements the LoaderWebhook and PersisterWebhook interfaces
+// - the file holds the secrets of the webhooks: it is only readable by its owner
+type LoaderWebhookJSON struct {
+	// path is the path to the file that contains the webhooks in JSON format
+	path string
+}
+
+// WebhookJSON is a struct that represents a webhook in JSON format
+type WebhookJSON struct {
+	Id        int       `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	Events    []string  `json:"events"`
+	Fleet     string    `json:"fleet"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Load is a method that loads the webhooks
+// - a file that does not exist yet has no webhooks
+func (l *LoaderWebhookJSON) Load() (w []internal.Webhook, err error) {
+	// open file
+	file, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	defer file.Close()
+
+	// decode file
+	var webhooksJSON []WebhookJSON
+	err = json.NewDecoder(file).Decode(&webhooksJSON)
+	if err != nil {
+		return
+	}
+
+	// serialize webhooks
+	w = make([]internal.Webhook, 0, len(webhooksJSON))
+	for _, wh := range webhooksJSON {
+		events := make([]internal.EventType, 0, len(wh.Events))
+		for _, name := range wh.Events {
+			t, errParse := internal.ParseEventType(name)
+			if errParse != nil {
+				err = fmt.Errorf("loader: webhook %d: %w", wh.Id, errParse)
+				return
+			}
+			events = append(events, t)
+		}
+
+		w = append(w, internal.Webhook{
+			Id:        wh.Id,
+			URL:       wh.URL,
+			Secret:    wh.Secret,
+			Events:    events,
+			Fleet:     wh.Fleet,
+			CreatedBy: wh.CreatedBy,
+			CreatedAt: wh.CreatedAt,
+		})
+	}
+	return
+}
+
+// Persist is a method that replaces the file with the given webhooks
+// - the file is written next to the old one and renamed, so a crash never leaves it half written
+func (l *LoaderWebhookJSON) Persist(w []internal.Webhook) (err error) {
+	webhooksJSON := make([]WebhookJSON, 0, len(w))
+	for _, wh := range w {
+		events := make([]string, 0, len(wh.Events))
+		for _, t := range wh.Events {
+			events = append(events, string(t))
+		}
+		webhooksJSON = append(webhooksJSON, WebhookJSON{
+			Id:        wh.Id,
+			URL:       wh.URL,
+			Secret:    wh.Secret,
+			Events:    events,
+			Fleet:     wh.Fleet,
+			CreatedBy: wh.CreatedBy,
+			CreatedAt: wh.CreatedAt,
+		})
+	}
+	data, err := json.MarshalIndent(webhooksJSON, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return
+	}
+	tmp := l.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	err = os.Rename(tmp, l.path)
+	return
+}
@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidMaintenanceType is an error that represents an unknown maintenance type
+	ErrInvalidMaintenanceType = errors.New("invalid maintenance type")
+	// ErrInvalidMaintenanceDate is an error that represents a date of a maintenance record that is not in the layout
+	ErrInvalidMaintenanceDate = errors.New("invalid date, expected YYYY-MM-DD")
+)
+
+// MaintenanceDateLayout is the layout of the dates of the maintenance records
+const MaintenanceDateLayout = "2006-01-02"
+
+// MaintenanceType is a type that represents the kind of service done on a vehicle
+type MaintenanceType string
+
+const (
+	// MaintenanceTypeOilChange is the change of the oil and the filters
+	MaintenanceTypeOilChange MaintenanceType = "oil-change"
+	// MaintenanceTypeInspection is the periodic inspection of the vehicle
+	MaintenanceTypeInspection MaintenanceType = "inspection"
+	// MaintenanceTypeTires is the rotation or replacement of the tires
+	MaintenanceTypeTires MaintenanceType = "tires"
+	// MaintenanceTypeBrakes is the service of the brakes
+	MaintenanceTypeBrakes MaintenanceType = "brakes"
+	// MaintenanceTypeRepair is the repair of a failure
+	MaintenanceTypeRepair MaintenanceType = "repair"
+	// MaintenanceTypeOther is any other service
+	MaintenanceTypeOther MaintenanceType = "other"
+)
+
+// MaintenanceTypes are the allowed maintenance types
+var MaintenanceTypes = []MaintenanceType{MaintenanceTypeOilChange, MaintenanceTypeInspection, MaintenanceTypeTires, MaintenanceTypeBrakes, MaintenanceTypeRepair, MaintenanceTypeOther}
+
+// ParseMaintenanceType is a function that returns the canonical maintenance type of a value (e.g. "Oil Change" -> oil-change)
+func ParseMaintenanceType(value string) (m MaintenanceType, err error) {
+	key := enumKey(value)
+	for _, maintenanceType := range MaintenanceTypes {
+		if key == string(maintenanceType) {
+			m = maintenanceType
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: %q (allowed: %s)", ErrInvalidMaintenanceType, value, joinEnum(MaintenanceTypes))
+	return
+}
+
+// Valid is a method that returns true if the maintenance type is one of the allowed values
+func (m MaintenanceType) Valid() bool {
+	for _, maintenanceType := range MaintenanceTypes {
+		if m == maintenanceType {
+			return true
+		}
+	}
+	return false
+}
+
+// Maintenance is a struct that represents a service done on a vehicle
+type Maintenance struct {
+	// Id is the unique identifier of the record
+	Id int
+	// VehicleId is the id of the serviced vehicle
+	VehicleId int
+	// Date is the day of the service
+	Date time.Time
+	// Odometer is the reading of the odometer at the service
+	Odometer Distance
+	// Type is the kind of service
+	Type MaintenanceType
+	// Cost is the cost of the service, in the currency of the fleet
+	Cost float64
+	// Notes are free text notes of the service
+	Notes string
+	// Vendor is the workshop that did the service
+	Vendor string
+}
+
+// MaintenanceJSON is a struct that represents a maintenance record in JSON format, as stored in the files and served by the api
+// - the id and the vehicle id of a request body are ignored, they are taken from the route
+type MaintenanceJSON struct {
+	Id        int     `json:"id"`
+	VehicleId int     `json:"vehicle_id"`
+	Date      string  `json:"date"`
+	Odometer  float64 `json:"odometer"`
+	Type      string  `json:"type"`
+	Cost      float64 `json:"cost"`
+	Notes     string  `json:"notes"`
+	Vendor    string  `json:"vendor"`
+}
+
+// NewMaintenanceJSON is a function that serializes a maintenance record with the odometer expressed in the units
+func NewMaintenanceJSON(m Maintenance, u Units) MaintenanceJSON {
+	return MaintenanceJSON{
+		Id:        m.Id,
+		VehicleId: m.VehicleId,
+		Date:      m.Date.Format(MaintenanceDateLayout),
+		Odometer:  m.Odometer.In(u.Distance),
+		Type:      string(m.Type),
+		Cost:      m.Cost,
+		Notes:     m.Notes,
+		Vendor:    m.Vendor,
+	}
+}
+
+// Maintenance is a method that deserializes the maintenance record, the odometer expressed in the units
+func (j MaintenanceJSON) Maintenance(u Units) (m Maintenance, err error) {
+	date, err := time.Parse(MaintenanceDateLayout, j.Date)
+	if err != nil {
+		err = fmt.Errorf("%w: %q", ErrInvalidMaintenanceDate, j.Date)
+		return
+	}
+	maintenanceType, err := ParseMaintenanceType(j.Type)
+	if err != nil {
+		return
+	}
+
+	m = Maintenance{
+		Id:        j.Id,
+		VehicleId: j.VehicleId,
+		Date:      date,
+		Odometer:  NewDistance(j.Odometer, u.Distance),
+		Type:      maintenanceType,
+		Cost:      j.Cost,
+		Notes:     j.Notes,
+		Vendor:    j.Vendor,
+	}
+	return
+}
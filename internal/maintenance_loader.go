@@ -0,0 +1,13 @@
+package internal
+
+// LoaderMaintenance is an interface that represents the loader for maintenance records
+type LoaderMaintenance interface {
+	// Load is a method that loads the maintenance records
+	Load() (m []Maintenance, err error)
+}
+
+// PersisterMaintenance is an interface that represents the storage where the maintenance records are persisted
+type PersisterMaintenance interface {
+	// Append is a method that adds a record to the stored ones
+	Append(m Maintenance) (err error)
+}
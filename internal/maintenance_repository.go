@@ -0,0 +1,20 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrRepositoryMaintenancePersist is an error that represents maintenance records that could not be persisted
+	ErrRepositoryMaintenancePersist = errors.New("repository: maintenance records could not be persisted")
+)
+
+// RepositoryMaintenance is an interface that represents a maintenance repository
+type RepositoryMaintenance interface {
+	// FindByVehicleId is a method that returns the records of the vehicle, oldest first
+	FindByVehicleId(vehicleId int) (m []Maintenance, err error)
+
+	// FindLast is a method that returns the most recent record of each vehicle, by vehicle id
+	FindLast() (m map[int]Maintenance, err error)
+
+	// Save is a method that adds a record. The id is replaced by the next free id
+	Save(m *Maintenance) (err error)
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrServiceInvalidMaintenance is an error that represents a maintenance record with invalid attributes
+	ErrServiceInvalidMaintenance = errors.New("service: invalid maintenance")
+)
+
+// OverdueVehicle is a struct that represents a vehicle that is overdue for service
+type OverdueVehicle struct {
+	// Vehicle is the vehicle
+	Vehicle Vehicle
+	// LastService is the date of the last service (zero if the vehicle was never serviced)
+	LastService time.Time
+	// DueDate is the date when the next service was due
+	DueDate time.Time
+	// DaysOverdue is the number of whole days since the due date
+	DaysOverdue int
+}
+
+// ServiceMaintenance is an interface that represents a maintenance service
+type ServiceMaintenance interface {
+	// FindByVehicleId is a method that returns the records of the vehicle, oldest first
+	FindByVehicleId(vehicleId int) (m []Maintenance, err error)
+
+	// Create is a method that validates and adds a record to an existing vehicle
+	Create(ctx context.Context, m *Maintenance) (err error)
+
+	// Overdue is a method that returns the vehicles that are overdue for service at the instant, most overdue first
+	// - the service interval shortens with the age of the vehicle (from its fabrication year)
+	// - it starts at the last service, or at the start of the fabrication year for vehicles never serviced
+	Overdue(at time.Time) (o []OverdueVehicle, err error)
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalizer is an interface that represents the normalization of the string attributes of a vehicle
+// - field is the name of the attribute (see VehicleFieldNames)
+type Normalizer interface {
+	// Key is a method that returns the folded form of a value, used to compare values
+	Key(field string, value string) (k string)
+
+	// Canonical is a method that returns the canonical form of a value, used to store and return values
+	Canonical(field string, value string) (c string)
+}
+
+// NormalizerNone is a struct that implements the Normalizer interface without aliases, keeping the values as they are
+type NormalizerNone struct{}
+
+// Key is a method that returns the value in lower case
+func (NormalizerNone) Key(field string, value string) string { return strings.ToLower(value) }
+
+// Canonical is a method that returns the value
+func (NormalizerNone) Canonical(field string, value string) string { return value }
+
+// NormalizedFields are the string attributes of a vehicle that are normalized
+// - fuel type and transmission are enums, canonicalized by ParseFuelType and ParseTransmission
+var NormalizedFields = []string{"brand", "model", "color"}
+
+// NormalizeVehicleAttributes is a function that replaces the normalized attributes of a vehicle by their canonical form
+// - the registration too, see RegistrationRules
+func NormalizeVehicleAttributes(nz Normalizer, a *VehicleAttributes) {
+	a.Brand = nz.Canonical("brand", a.Brand)
+	a.Model = nz.Canonical("model", a.Model)
+	a.Color = nz.Canonical("color", a.Color)
+	a.Registration = nz.Canonical(RegistrationField, a.Registration)
+}
+
+// Blank is a function that is true if the value has neither a letter nor a digit
+// - such values are folded to nothing by the normalizers, so they are rejected instead of matching nothing
+func Blank(value string) bool {
+	return strings.IndexFunc(value, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0
+}
@@ -0,0 +1,164 @@
+package normalizer
+
+import (
+	"app/internal"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// MaxSeen is the number of forms remembered by field, past it the values are canonicalized without being remembered
+const MaxSeen = 10000
+
+// Aliases is a map of field to canonical value to the variants of that value
+// - e.g. {"brand": {"Chevrolet": ["chevy"]}}
+type Aliases map[string]map[string][]string
+
+// LoadAliasesJSON is a function that reads the aliases from a JSON file
+func LoadAliasesJSON(path string) (a Aliases, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	err = json.NewDecoder(file).Decode(&a)
+	return
+}
+
+// NewNormalizerAlias is a function that returns a new instance of NormalizerAlias
+func NewNormalizerAlias(aliases Aliases) *NormalizerAlias {
+	// index the aliases by folded variant
+	// - the canonical value is a variant of itself
+	index := make(map[string]map[string]string)
+	for field, canonicals := range aliases {
+		index[field] = make(map[string]string)
+		for canonical, variants := range canonicals {
+			index[field][Fold(canonical)] = canonical
+			for _, variant := range variants {
+				index[field][Fold(variant)] = canonical
+			}
+		}
+	}
+
+	return &NormalizerAlias{
+		aliases: index,
+		seen:    make(map[string]map[string]string),
+	}
+}
+
+// NormalizerAlias is a struct that implements the Normalizer interface
+// - values are folded (case, diacritics, whitespace and punctuation) and then mapped through the alias table
+// - values without alias are canonicalized to the first form seen for their key, with its whitespace collapsed
+// - the loaders normalize in id order, so the first form seen does not depend on the order of the dataset in memory
+// - only the stored and loaded vehicles are canonicalized, the queries are folded by Key, and only the normalized fields
+// (see internal.NormalizedFields) are remembered, up to MaxSeen forms each
+type NormalizerAlias struct {
+	// aliases is a map of field to folded variant to canonical value
+	aliases map[string]map[string]string
+	// mu is the lock that guards seen
+	mu sync.Mutex
+	// seen is a map of field to key to the first form seen
+	seen map[string]map[string]string
+}
+
+// Key is a method that returns the folded form of a value, used to compare values
+func (n *NormalizerAlias) Key(field string, value string) (k string) {
+	k = Fold(value)
+	if canonical, ok := n.aliases[field][k]; ok {
+		k = Fold(canonical)
+	}
+	return
+}
+
+// Canonical is a method that returns the canonical form of a value, used to store and return values
+func (n *NormalizerAlias) Canonical(field string, value string) (c string) {
+	folded := Fold(value)
+	if folded == "" {
+		return
+	}
+	if canonical, ok := n.aliases[field][folded]; ok {
+		c = canonical
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	c, ok := n.seen[field][folded]
+	if ok {
+		return
+	}
+	c = strings.Join(strings.Fields(value), " ")
+	if !slices.Contains(internal.NormalizedFields, field) || len(n.seen[field]) >= MaxSeen {
+		return
+	}
+	if _, ok := n.seen[field]; !ok {
+		n.seen[field] = make(map[string]string)
+	}
+	n.seen[field][folded] = c
+	return
+}
+
+// Fold is a function that returns the folded form of a string
+// - lower case, without diacritics, punctuation and extra whitespace: "  Mercedes-Benz " -> "mercedes benz"
+func Fold(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if base, ok := diacritics[r]; ok {
+			r = base
+		}
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// combining marks of decomposed characters
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+			sb.WriteRune(r)
+		default:
+			// whitespace and punctuation separate words
+			space = true
+		}
+	}
+	return sb.String()
+}
+
+// diacritics is a map of lower case latin letters with diacritics to their base letter
+var diacritics = func() map[rune]rune {
+	table := map[rune]string{
+		'a': "àáâãäåāăą",
+		'c': "çćĉċč",
+		'd': "ďđ",
+		'e': "èéêëēĕėęě",
+		'g': "ĝğġģ",
+		'h': "ĥħ",
+		'i': "ìíîïĩīĭįı",
+		'j': "ĵ",
+		'k': "ķ",
+		'l': "ĺļľŀł",
+		'n': "ñńņňŉ",
+		'o': "òóôõöøōŏő",
+		'r': "ŕŗř",
+		's': "śŝşšș",
+		't': "ţťŧț",
+		'u': "ùúûüũūŭůűų",
+		'w': "ŵ",
+		'y': "ýÿŷ",
+		'z': "źżž",
+	}
+
+	m := make(map[rune]rune)
+	for base, variants := range table {
+		for _, r := range variants {
+			m[r] = base
+		}
+	}
+	return m
+}()
@@ -0,0 +1,75 @@
+package normalizer_test
+
+import (
+	"app/internal/normalizer"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Fold function
+func TestFold(t *testing.T) {
+	t.Run("case, diacritics, whitespace and punctuation", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		output := normalizer.Fold("  Mercédes-Benz   GLA.200 ")
+
+		// assert
+		expectedOutput := "mercedes benz gla 200"
+		require.Equal(t, expectedOutput, output)
+	})
+}
+
+// Tests for NormalizerAlias
+func TestNormalizerAlias(t *testing.T) {
+	t.Run("alias maps variants to the canonical value", func(t *testing.T) {
+		// arrange
+		nz := normalizer.NewNormalizerAlias(normalizer.Aliases{
+			"brand": {"Mercedes-Benz": {"mercedes", "benz"}},
+		})
+
+		// act
+		canonical := nz.Canonical("brand", "BENZ")
+		key := nz.Key("brand", "mercedes")
+
+		// assert
+		require.Equal(t, "Mercedes-Benz", canonical)
+		require.Equal(t, nz.Key("brand", "Mercedes-Benz"), key)
+	})
+
+	t.Run("value without alias keeps the first form seen", func(t *testing.T) {
+		// arrange
+		nz := normalizer.NewNormalizerAlias(nil)
+
+		// act
+		first := nz.Canonical("color", "Blue")
+		second := nz.Canonical("color", " blue ")
+
+		// assert
+		require.Equal(t, "Blue", first)
+		require.Equal(t, "Blue", second)
+	})
+
+	t.Run("only the normalized fields are remembered, up to MaxSeen forms", func(t *testing.T) {
+		// arrange
+		nz := normalizer.NewNormalizerAlias(nil)
+		for i := 0; i < normalizer.MaxSeen; i++ {
+			nz.Canonical("model", fmt.Sprintf("Model %d", i))
+		}
+
+		// act
+		first := nz.Canonical("model", "Extra")
+		second := nz.Canonical("model", "EXTRA")
+		registration := nz.Canonical("registration", "AB-1")
+		again := nz.Canonical("registration", "ab-1")
+
+		// assert
+		require.Equal(t, "Extra", first)
+		require.Equal(t, "EXTRA", second)
+		require.Equal(t, "AB-1", registration)
+		require.Equal(t, "ab-1", again)
+	})
+}
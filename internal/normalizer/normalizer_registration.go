@@ -0,0 +1,33 @@
+package normalizer
+
+import "app/internal"
+
+// NewNormalizerRegistration is a function that returns a new instance of NormalizerRegistration
+func NewNormalizerRegistration(nz internal.Normalizer, rules internal.RegistrationRules) *NormalizerRegistration {
+	return &NormalizerRegistration{nz: nz, rules: rules}
+}
+
+// NormalizerRegistration is a struct that decorates a normalizer with the rules of the registrations of a jurisdiction
+// - the registrations are compared and stored in their canonical form, the other attributes by the decorated normalizer
+type NormalizerRegistration struct {
+	// nz is the decorated normalizer
+	nz internal.Normalizer
+	// rules are the rules of the registrations
+	rules internal.RegistrationRules
+}
+
+// Key is a method that returns the folded form of a value, used to compare values
+func (n *NormalizerRegistration) Key(field string, value string) (k string) {
+	if field == internal.RegistrationField {
+		return n.rules.Canonical(value)
+	}
+	return n.nz.Key(field, value)
+}
+
+// Canonical is a method that returns the canonical form of a value, used to store and return values
+func (n *NormalizerRegistration) Canonical(field string, value string) (c string) {
+	if field == internal.RegistrationField {
+		return n.rules.Canonical(value)
+	}
+	return n.nz.Canonical(field, value)
+}
@@ -0,0 +1,102 @@
+package normalizer
+
+import (
+	"app/internal"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RegistrationRule is a struct that represents the registrations of a jurisdiction in a rule file
+type RegistrationRule struct {
+	// Strip is a regex of the characters removed from the registrations (e.g. separators "[\\s.-]")
+	Strip string `json:"strip"`
+	// Upper is true if the registrations are upper cased
+	Upper bool `json:"upper"`
+	// Formats are the regexes of the valid canonical registrations, a registration must match one (none: every registration is valid)
+	Formats []string `json:"formats"`
+}
+
+// RegistrationRules is a map of jurisdiction to the rule of its registrations
+// - e.g. {"ar": {"strip": "[\\s.-]", "upper": true, "formats": ["^[A-Z]{3}[0-9]{3}$", "^[A-Z]{2}[0-9]{3}[A-Z]{2}$"]}}
+type RegistrationRules map[string]RegistrationRule
+
+// LoadRegistrationRulesJSON is a function that reads the registration rules from a JSON file
+func LoadRegistrationRulesJSON(path string) (r RegistrationRules, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	err = json.NewDecoder(file).Decode(&r)
+	return
+}
+
+// NewRegistrationRulesRegex is a function that returns the rules of the registrations of the jurisdiction
+func NewRegistrationRulesRegex(rules RegistrationRules, jurisdiction string) (r *RegistrationRulesRegex, err error) {
+	rule, ok := rules[jurisdiction]
+	if !ok {
+		err = fmt.Errorf("normalizer: unknown registration jurisdiction %q", jurisdiction)
+		return
+	}
+
+	r = &RegistrationRulesRegex{jurisdiction: jurisdiction, upper: rule.Upper}
+	if rule.Strip != "" {
+		if r.strip, err = regexp.Compile(rule.Strip); err != nil {
+			err = fmt.Errorf("normalizer: strip of %q: %w", jurisdiction, err)
+			return
+		}
+	}
+	for _, format := range rule.Formats {
+		var re *regexp.Regexp
+		if re, err = regexp.Compile(format); err != nil {
+			err = fmt.Errorf("normalizer: format of %q: %w", jurisdiction, err)
+			return
+		}
+		r.formats = append(r.formats, re)
+	}
+	return
+}
+
+// RegistrationRulesRegex is a struct that implements the RegistrationRules interface with regexes
+type RegistrationRulesRegex struct {
+	// jurisdiction is the name of the jurisdiction
+	jurisdiction string
+	// strip matches the characters removed from the registrations (nil: none)
+	strip *regexp.Regexp
+	// upper is true if the registrations are upper cased
+	upper bool
+	// formats are the valid canonical registrations
+	formats []*regexp.Regexp
+}
+
+// Canonical is a method that returns the canonical form of a registration, used to store, compare and find registrations
+// - surrounding whitespace is trimmed, then the strip characters are removed and the letters upper cased
+func (r *RegistrationRulesRegex) Canonical(registration string) (c string) {
+	c = strings.TrimSpace(registration)
+	if r.strip != nil {
+		c = r.strip.ReplaceAllString(c, "")
+	}
+	if r.upper {
+		c = strings.ToUpper(c)
+	}
+	return
+}
+
+// Validate is a method that returns an error if the canonical registration does not have a valid format
+// - an empty registration is valid, the vehicle is not registered
+func (r *RegistrationRulesRegex) Validate(registration string) (err error) {
+	if registration == "" || len(r.formats) == 0 {
+		return
+	}
+	for _, format := range r.formats {
+		if format.MatchString(registration) {
+			return
+		}
+	}
+	err = fmt.Errorf("%w: %q is not a registration of %s", internal.ErrInvalidRegistration, registration, r.jurisdiction)
+	return
+}
@@ -0,0 +1,52 @@
+package normalizer_test
+
+import (
+	"app/internal"
+	"app/internal/normalizer"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for RegistrationRulesRegex
+func TestRegistrationRulesRegex(t *testing.T) {
+	rules := normalizer.RegistrationRules{
+		"ar":   {Strip: `[\s.-]`, Upper: true, Formats: []string{`^[A-Z]{3}[0-9]{3}$`, `^[A-Z]{2}[0-9]{3}[A-Z]{2}$`}},
+		"free": {},
+	}
+
+	t.Run("separators are stripped, letters upper cased and the canonical form matched against the formats", func(t *testing.T) {
+		// arrange
+		rr, err := normalizer.NewRegistrationRulesRegex(rules, "ar")
+		require.NoError(t, err)
+
+		// act
+		canonical := rr.Canonical(" ab-123.cd ")
+		errValid := rr.Validate(canonical)
+		errInvalid := rr.Validate(rr.Canonical("05715"))
+		errEmpty := rr.Validate("")
+
+		// assert
+		require.Equal(t, "AB123CD", canonical)
+		require.NoError(t, errValid)
+		require.ErrorIs(t, errInvalid, internal.ErrInvalidRegistration)
+		require.NoError(t, errEmpty)
+	})
+
+	t.Run("a rule without formats accepts every registration and unknown jurisdictions or bad regexes are rejected", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr, err := normalizer.NewRegistrationRulesRegex(rules, "free")
+		_, errUnknown := normalizer.NewRegistrationRulesRegex(rules, "xx")
+		_, errRegex := normalizer.NewRegistrationRulesRegex(normalizer.RegistrationRules{"bad": {Formats: []string{"("}}}, "bad")
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "ab-1", rr.Canonical(" ab-1 "))
+		require.NoError(t, rr.Validate("ab-1"))
+		require.Error(t, errUnknown)
+		require.Error(t, errRegex)
+	})
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"app/internal"
+	"sync"
+	"time"
+)
+
+// NewRepositoryAlertMap is a function that returns a new instance of RepositoryAlertMap
+// - capacity: number of alerts kept
+func NewRepositoryAlertMap(capacity int) *RepositoryAlertMap {
+	return &RepositoryAlertMap{capacity: capacity}
+}
+
+// RepositoryAlertMap is a struct that implements the RepositoryAlert interface
+type RepositoryAlertMap struct {
+	// mu is the lock that guards db
+	mu sync.RWMutex
+	// db is the list of alerts, oldest first
+	db []internal.Alert
+	// capacity is the number of alerts kept
+	capacity int
+	// lastId is the highest id of the alerts
+	lastId int
+}
+
+// Find is a method that returns the alerts that match the query, oldest first
+func (r *RepositoryAlertMap) Find(query internal.AlertQuery) (a []internal.Alert, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a = make([]internal.Alert, 0)
+	for _, alert := range r.db {
+		if query.Match(alert) {
+			a = append(a, alert)
+		}
+	}
+	return
+}
+
+// Save is a method that adds an alert. The id is replaced by the next free id
+func (r *RepositoryAlertMap) Save(a *internal.Alert) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	a.Id = r.lastId
+	r.db = append(r.db, *a)
+	if len(r.db) > r.capacity {
+		r.db = append(r.db[:0:0], r.db[len(r.db)-r.capacity:]...)
+	}
+	return
+}
+
+// Acknowledge is a method that marks the alert that matches the id as acknowledged, if it was not
+func (r *RepositoryAlertMap) Acknowledge(id int, by string, at time.Time) (a internal.Alert, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.db {
+		if r.db[i].Id != id {
+			continue
+		}
+		if !r.db[i].Acknowledged() {
+			r.db[i].AcknowledgedBy = by
+			r.db[i].AcknowledgedAt = at
+		}
+		a = r.db[i]
+		return
+	}
+
+	err = internal.ErrRepositoryAlertNotFound
+	return
+}
@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"app/internal"
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// NewRepositoryAuditFile is a function that returns a new instance of RepositoryAuditFile
+// - maxBytes: size after which the file is rotated (<= 0 disables rotation)
+// - maxBackups: number of rotated files that are kept (path.1 is the newest)
+func NewRepositoryAuditFile(path string, maxBytes int64, maxBackups int) *RepositoryAuditFile {
+	// default values
+	if maxBackups < 0 {
+		maxBackups = 0
+	}
+	r := &RepositoryAuditFile{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+	}
+	r.written.Store(-1)
+	return r
+}
+
+// RepositoryAuditFile is a struct that implements the RepositoryAudit interface with a rotating JSON lines file
+// - the files are only appended to, the searches read them without blocking the writes but during a rotation
+type RepositoryAuditFile struct {
+	// mu is the lock that serializes the writes
+	mu sync.Mutex
+	// rotating is the lock that keeps the files in place: the searches share it, a rotation takes it exclusively
+	rotating sync.RWMutex
+	// path is the path of the current file
+	path string
+	// maxBytes is the size after which the file is rotated
+	maxBytes int64
+	// maxBackups is the number of rotated files that are kept
+	maxBackups int
+	// file is the current file, opened lazily in append mode
+	file *os.File
+	// size is the size of the current file
+	size int64
+	// written is the size of the current file up to its last complete record, read by the searches (-1: not open, read it whole)
+	written atomic.Int64
+}
+
+// Append is a method that adds a record at the end of the trail
+func (r *RepositoryAuditFile) Append(rc internal.AuditRecord) (err error) {
+	line, err := encodeAuditRecord(rc)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err = r.write(line)
+	return
+}
+
+// AppendCommit is a method that adds the record of a mutation, commits the mutation and adds the record of its outcome
+// - the lock is not held during commit, so the mutations of the other fleets are recorded meanwhile
+func (r *RepositoryAuditFile) AppendCommit(rc internal.AuditRecord, commit func() (internal.AuditRecord, error)) (err error) {
+	// record: a mutation is never committed without a record
+	rc.Id, rc.Status = newAuditId(), internal.AuditStatusPending
+	if err = r.Append(rc); err != nil {
+		return
+	}
+
+	// commit: a failed mutation is recorded as aborted
+	committed, err := commit()
+	if err != nil {
+		aborted := rc
+		aborted.Status = internal.AuditStatusAborted
+		if errAborted := r.Append(aborted); errAborted != nil {
+			err = errors.Join(err, errAborted)
+		}
+		return
+	}
+
+	// record as committed: the pending record stays pending if it cannot be written
+	committed.Id, committed.Status = rc.Id, internal.AuditStatusCommitted
+	err = r.Append(committed)
+	return
+}
+
+// Find is a method that returns the records that match the query, oldest first
+// - a mutation is at the position of its pending record. Only the records up to the page are kept in memory
+func (r *RepositoryAuditFile) Find(query internal.AuditQuery) (rc []internal.AuditRecord, err error) {
+	// open the current file, so its complete records are known
+	r.mu.Lock()
+	r.open()
+	r.mu.Unlock()
+
+	r.rotating.RLock()
+	defer r.rotating.RUnlock()
+
+	// oldest backup first, current file last
+	paths := make([]string, 0, r.maxBackups+1)
+	for i := r.maxBackups; i > 0; i-- {
+		paths = append(paths, r.backupPath(i))
+	}
+	paths = append(paths, r.path)
+
+	// page: the matching records sorted by position, at most offset + limit
+	type entry struct {
+		position int
+		record   internal.AuditRecord
+	}
+	var page []entry
+	keep := func(position int, record internal.AuditRecord) {
+		if !query.Match(record) {
+			return
+		}
+		i, _ := slices.BinarySearchFunc(page, position, func(e entry, p int) int { return e.position - p })
+		if query.Limit > 0 && i >= query.Offset+query.Limit {
+			return
+		}
+		page = slices.Insert(page, i, entry{position: position, record: record})
+		if query.Limit > 0 && len(page) > query.Offset+query.Limit {
+			page = page[:query.Offset+query.Limit]
+		}
+	}
+
+	// records: the outcome of a mutation replaces its pending record
+	position := 0
+	pending := make(map[string]entry)
+	for _, path := range paths {
+		limit := int64(-1)
+		if path == r.path {
+			limit = r.written.Load()
+		}
+		err = r.scan(path, limit, func(record internal.AuditRecord) {
+			position++
+			switch record.Status {
+			case internal.AuditStatusPending:
+				pending[record.Id] = entry{position: position, record: record}
+			case internal.AuditStatusAborted:
+				delete(pending, record.Id)
+			default:
+				p := position
+				if e, ok := pending[record.Id]; ok && record.Id != "" {
+					p = e.position
+					delete(pending, record.Id)
+				}
+				keep(p, record)
+			}
+		})
+		if err != nil {
+			return
+		}
+	}
+	for _, e := range pending {
+		keep(e.position, e.record)
+	}
+
+	rc = make([]internal.AuditRecord, 0, max(len(page)-query.Offset, 0))
+	for _, e := range page[min(query.Offset, len(page)):] {
+		rc = append(rc, e.record)
+	}
+	return
+}
+
+// Close is a method that closes the current file
+func (r *RepositoryAuditFile) Close() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return
+	}
+	err = r.file.Close()
+	r.file = nil
+	return
+}
+
+// write is a method that opens the current file, rotates it if the line does not fit and writes the line
+// - offset: size of the file before the line, where it can be truncated to remove it
+func (r *RepositoryAuditFile) write(line []byte) (offset int64, err error) {
+	// open and rotate
+	if err = r.open(); err != nil {
+		err = fmt.Errorf("%w: %v", internal.ErrRepositoryAuditAppend, err)
+		return
+	}
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(line)) > r.maxBytes {
+		if err = r.rotate(); err != nil {
+			err = fmt.Errorf("%w: %v", internal.ErrRepositoryAuditAppend, err)
+			return
+		}
+	}
+
+	// write: a partial line is removed
+	offset = r.size
+	n, err := r.file.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", internal.ErrRepositoryAuditAppend, err)
+		if n > 0 {
+			r.truncate(offset)
+		}
+		return
+	}
+	r.written.Store(r.size)
+	return
+}
+
+// truncate is a method that cuts the current file at offset, removing a line partially written after it
+func (r *RepositoryAuditFile) truncate(offset int64) (err error) {
+	if err = r.file.Truncate(offset); err != nil {
+		return
+	}
+	r.size = offset
+	return
+}
+
+// open is a method that opens the current file if it is not open yet
+func (r *RepositoryAuditFile) open() (err error) {
+	if r.file != nil {
+		return
+	}
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.written.Store(r.size)
+	return
+}
+
+// encodeAuditRecord is a function that returns the line of a record in the file
+func encodeAuditRecord(rc internal.AuditRecord) (line []byte, err error) {
+	line, err = json.Marshal(internal.NewAuditRecordJSON(rc))
+	if err != nil {
+		err = fmt.Errorf("%w: %v", internal.ErrRepositoryAuditAppend, err)
+		return
+	}
+	line = append(line, '\n')
+	return
+}
+
+// rotate is a method that shifts the backups, moves the current file to the first backup and opens a new one
+// - it waits for the searches that are reading the files
+func (r *RepositoryAuditFile) rotate() (err error) {
+	r.rotating.Lock()
+	defer r.rotating.Unlock()
+
+	if err = r.file.Close(); err != nil {
+		return
+	}
+	r.file = nil
+
+	if r.maxBackups == 0 {
+		// no backups: the trail restarts
+		if err = os.Remove(r.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		return r.open()
+	}
+
+	// drop the oldest backup and shift the rest
+	if err = os.Remove(r.backupPath(r.maxBackups)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	for i := r.maxBackups - 1; i > 0; i-- {
+		if err = os.Rename(r.backupPath(i), r.backupPath(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+	}
+	if err = os.Rename(r.path, r.backupPath(1)); err != nil {
+		return
+	}
+
+	return r.open()
+}
+
+// backupPath is a method that returns the path of the i-th backup
+func (r *RepositoryAuditFile) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
+
+// scan is a method that decodes every record of a file. Missing files are skipped
+// - limit: size of the file up to which it is read (< 0: the whole file)
+func (r *RepositoryAuditFile) scan(path string, limit int64, fn func(record internal.AuditRecord)) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	defer file.Close()
+
+	var rd io.Reader = file
+	if limit >= 0 {
+		rd = io.LimitReader(file, limit)
+	}
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var recordJSON internal.AuditRecordJSON
+		if err = json.Unmarshal(scanner.Bytes(), &recordJSON); err != nil {
+			err = fmt.Errorf("repository: corrupted audit file %s: %w", path, err)
+			return
+		}
+
+		// - records written before the fleets belong to the default fleet
+		if recordJSON.Fleet == "" {
+			recordJSON.Fleet = internal.DefaultFleet
+		}
+		fn(recordJSON.AuditRecord())
+	}
+	err = scanner.Err()
+	return
+}
+
+// newAuditId is a function that returns a random id for the records of a mutation
+func newAuditId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,157 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for RepositoryAuditFile
+func TestRepositoryAuditFile(t *testing.T) {
+	at := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+	record := func(id int, actor string) internal.AuditRecord {
+		return internal.AuditRecord{
+			Status:    internal.AuditStatusCommitted,
+			Timestamp: at.Add(time.Duration(id) * time.Minute),
+			Actor:     actor,
+			Fleet:     internal.DefaultFleet,
+			Action:    internal.AuditActionUpdated,
+			VehicleId: id,
+			Changes:   []internal.AuditChange{{Field: "color", Before: "Red", After: "Blue"}},
+		}
+	}
+	ids := func(rc []internal.AuditRecord) (s []int) {
+		for _, r := range rc {
+			s = append(s, r.VehicleId)
+		}
+		return
+	}
+
+	t.Run("case 01: the records are found oldest first by the query, the records without fleet in the default fleet", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		err := os.WriteFile(path, []byte(`{"timestamp":"2024-05-01T07:00:00Z","actor":"legacy","action":"deleted","vehicle_id":9,"changes":[]}`+"\n"), 0o644)
+		require.NoError(t, err)
+		rp := repository.NewRepositoryAuditFile(path, 0, 0)
+		defer rp.Close()
+
+		// act
+		errAppend := errors.Join(rp.Append(record(1, "john")), rp.Append(record(2, "jane")), rp.Append(record(3, "john")))
+		all, errAll := rp.Find(internal.AuditQuery{})
+		john, errJohn := rp.Find(internal.AuditQuery{Actor: "john", Since: at.Add(2 * time.Minute)})
+
+		// assert
+		require.NoError(t, errAppend)
+		require.NoError(t, errAll)
+		require.Equal(t, []int{9, 1, 2, 3}, ids(all))
+		require.Equal(t, internal.DefaultFleet, all[0].Fleet)
+		require.Equal(t, record(1, "john"), all[1])
+		require.NoError(t, errJohn)
+		require.Equal(t, []int{3}, ids(john))
+	})
+
+	t.Run("case 02: the file is rotated by size, the oldest backups dropped", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		rp := repository.NewRepositoryAuditFile(path, 200, 1)
+		defer rp.Close()
+
+		// act
+		for id := 1; id <= 4; id++ {
+			require.NoError(t, rp.Append(record(id, "john")))
+		}
+		rc, err := rp.Find(internal.AuditQuery{})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []int{3, 4}, ids(rc))
+		require.FileExists(t, path+".1")
+		require.NoFileExists(t, path+".2")
+	})
+
+	t.Run("case 03: a mutation is found as committed, not found if the commit fails, and the file is only appended to", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		rp := repository.NewRepositoryAuditFile(path, 0, 0)
+		defer rp.Close()
+		errCommit := errors.New("commit failed")
+
+		// act
+		errFailed := rp.AppendCommit(record(1, "john"), func() (internal.AuditRecord, error) {
+			return internal.AuditRecord{}, errCommit
+		})
+		errCommitted := rp.AppendCommit(record(0, "john"), func() (internal.AuditRecord, error) {
+			return record(2, "john"), nil
+		})
+		rc, err := rp.Find(internal.AuditQuery{})
+		content, errRead := os.ReadFile(path)
+
+		// assert
+		require.ErrorIs(t, errFailed, errCommit)
+		require.NoError(t, errCommitted)
+		require.NoError(t, err)
+		require.Len(t, rc, 1)
+		require.Equal(t, internal.AuditStatusCommitted, rc[0].Status)
+		require.NotEmpty(t, rc[0].Id)
+		rc[0].Id = ""
+		require.Equal(t, record(2, "john"), rc[0])
+		require.NoError(t, errRead)
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		require.Len(t, lines, 4)
+		require.Contains(t, lines[0], `"status":"pending"`)
+		require.Contains(t, lines[1], `"status":"aborted"`)
+		require.Contains(t, lines[2], `"status":"pending"`)
+		require.Contains(t, lines[3], `"status":"committed"`)
+	})
+
+	t.Run("case 04: a record that cannot be written does not commit", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryAuditFile(filepath.Join(t.TempDir(), "missing", "audit.jsonl"), 0, 0)
+		committed := false
+
+		// act
+		err := rp.AppendCommit(record(1, "john"), func() (internal.AuditRecord, error) {
+			committed = true
+			return record(1, "john"), nil
+		})
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryAuditAppend)
+		require.False(t, committed)
+	})
+	t.Run("case 05: a mutation is at the position of its pending record, still pending without outcome, and paged", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		rp := repository.NewRepositoryAuditFile(path, 0, 0)
+		defer rp.Close()
+		// - the commit of vehicle 1 records vehicle 2 meanwhile
+		errCommit := rp.AppendCommit(record(1, "john"), func() (internal.AuditRecord, error) {
+			return record(1, "john"), rp.Append(record(2, "jane"))
+		})
+		require.NoError(t, errCommit)
+		pending := record(3, "john")
+		pending.Id, pending.Status = "interrupted", internal.AuditStatusPending
+		require.NoError(t, errors.Join(rp.Append(pending), rp.Append(record(4, "jane"))))
+
+		// act
+		all, errAll := rp.Find(internal.AuditQuery{})
+		page, errPage := rp.Find(internal.AuditQuery{Offset: 1, Limit: 2})
+		jane, errJane := rp.Find(internal.AuditQuery{Actor: "jane", Offset: 1, Limit: 5})
+
+		// assert
+		require.NoError(t, errAll)
+		require.Equal(t, []int{1, 2, 3, 4}, ids(all))
+		require.Equal(t, internal.AuditStatusPending, all[2].Status)
+		require.NoError(t, errPage)
+		require.Equal(t, []int{2, 3}, ids(page))
+		require.NoError(t, errJane)
+		require.Equal(t, []int{4}, ids(jane))
+	})
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"app/internal"
+	"sync"
+)
+
+// NewRepositoryDeliveryMap is a function that returns a new instance of RepositoryDeliveryMap
+// - capacity: number of attempts, and of dead letters, kept
+func NewRepositoryDeliveryMap(capacity int) *RepositoryDeliveryMap {
+	return &RepositoryDeliveryMap{capacity: capacity}
+}
+
+// RepositoryDeliveryMap is a struct that implements the RepositoryDelivery interface
+type RepositoryDeliveryMap struct {
+	// mu is the lock that guards deliveries and deadLetters
+	mu sync.RWMutex
+	// deliveries is the list of attempts, oldest first
+	deliveries []internal.Delivery
+	// deadLetters is the list of dead letters, oldest first
+	deadLetters []internal.DeadLetter
+	// capacity is the number of attempts, and of dead letters, kept
+	capacity int
+	// lastDeliveryId is the highest id of the attempts
+	lastDeliveryId int
+	// lastDeadLetterId is the highest id of the dead letters
+	lastDeadLetterId int
+}
+
+// FindByWebhookId is a method that returns the attempts of the webhook, oldest first
+func (r *RepositoryDeliveryMap) FindByWebhookId(webhookId int) (d []internal.Delivery, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d = make([]internal.Delivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookId == webhookId {
+			d = append(d, delivery)
+		}
+	}
+	return
+}
+
+// Append is a method that adds an attempt. The id is replaced by the next free id
+func (r *RepositoryDeliveryMap) Append(d *internal.Delivery) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastDeliveryId++
+	d.Id = r.lastDeliveryId
+	r.deliveries = append(r.deliveries, *d)
+	if len(r.deliveries) > r.capacity {
+		r.deliveries = append(r.deliveries[:0:0], r.deliveries[len(r.deliveries)-r.capacity:]...)
+	}
+	return
+}
+
+// FindDeadLetters is a method that returns the dead letters of the webhook, oldest first
+func (r *RepositoryDeliveryMap) FindDeadLetters(webhookId int) (dl []internal.DeadLetter, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dl = make([]internal.DeadLetter, 0)
+	for _, deadLetter := range r.deadLetters {
+		if deadLetter.WebhookId == webhookId {
+			dl = append(dl, deadLetter)
+		}
+	}
+	return
+}
+
+// SaveDeadLetter is a method that adds a dead letter. The id is replaced by the next free id
+func (r *RepositoryDeliveryMap) SaveDeadLetter(dl *internal.DeadLetter) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastDeadLetterId++
+	dl.Id = r.lastDeadLetterId
+	r.deadLetters = append(r.deadLetters, *dl)
+	if len(r.deadLetters) > r.capacity {
+		r.deadLetters = append(r.deadLetters[:0:0], r.deadLetters[len(r.deadLetters)-r.capacity:]...)
+	}
+	return
+}
+
+// DeleteDeadLetter is a method that removes and returns the dead letter of the webhook that matches the id
+func (r *RepositoryDeliveryMap) DeleteDeadLetter(webhookId int, id int) (dl internal.DeadLetter, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, deadLetter := range r.deadLetters {
+		if deadLetter.WebhookId == webhookId && deadLetter.Id == id {
+			dl = deadLetter
+			r.deadLetters = append(r.deadLetters[:i:i], r.deadLetters[i+1:]...)
+			return
+		}
+	}
+
+	err = internal.ErrRepositoryDeadLetterNotFound
+	return
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"app/internal"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// NewRepositoryMaintenanceMap is a function that returns a new instance of RepositoryMaintenanceMap
+// - ps: storage where every saved record is appended (nil keeps them in memory only)
+func NewRepositoryMaintenanceMap(db []internal.Maintenance, ps internal.PersisterMaintenance) *RepositoryMaintenanceMap {
+	// records by vehicle, oldest first, and last id
+	var lastId int
+	byVehicle := make(map[int][]internal.Maintenance)
+	for _, m := range db {
+		if m.Id > lastId {
+			lastId = m.Id
+		}
+		byVehicle[m.VehicleId] = append(byVehicle[m.VehicleId], m)
+	}
+	for _, records := range byVehicle {
+		sortMaintenance(records)
+	}
+
+	return &RepositoryMaintenanceMap{
+		db:     byVehicle,
+		lastId: lastId,
+		ps:     ps,
+	}
+}
+
+// RepositoryMaintenanceMap is a struct that implements the RepositoryMaintenance interface
+type RepositoryMaintenanceMap struct {
+	// mu is the lock that guards db
+	mu sync.RWMutex
+	// db is a map of the records of each vehicle, oldest first
+	db map[int][]internal.Maintenance
+	// lastId is the highest id of the records
+	lastId int
+	// ps is the storage where the records are persisted
+	ps internal.PersisterMaintenance
+}
+
+// FindByVehicleId is a method that returns the records of the vehicle, oldest first
+func (r *RepositoryMaintenanceMap) FindByVehicleId(vehicleId int) (m []internal.Maintenance, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m = append(make([]internal.Maintenance, 0, len(r.db[vehicleId])), r.db[vehicleId]...)
+	return
+}
+
+// FindLast is a method that returns the most recent record of each vehicle, by vehicle id
+func (r *RepositoryMaintenanceMap) FindLast() (m map[int]internal.Maintenance, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m = make(map[int]internal.Maintenance, len(r.db))
+	for vehicleId, records := range r.db {
+		m[vehicleId] = records[len(records)-1]
+	}
+	return
+}
+
+// Save is a method that adds a record. The id is replaced by the next free id
+// - when the records cannot be persisted the record is not added
+func (r *RepositoryMaintenanceMap) Save(m *internal.Maintenance) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rc := *m
+	rc.Id = r.lastId + 1
+	records := append(append(make([]internal.Maintenance, 0, len(r.db[rc.VehicleId])+1), r.db[rc.VehicleId]...), rc)
+	sortMaintenance(records)
+
+	// persist
+	if r.ps != nil {
+		if err = r.ps.Append(rc); err != nil {
+			err = fmt.Errorf("%w: %v", internal.ErrRepositoryMaintenancePersist, err)
+			return
+		}
+	}
+
+	r.db[rc.VehicleId] = records
+	r.lastId = rc.Id
+	m.Id = rc.Id
+	return
+}
+
+// sortMaintenance is a function that sorts records by date, then by id
+func sortMaintenance(m []internal.Maintenance) {
+	sort.SliceStable(m, func(i, j int) bool {
+		if !m[i].Date.Equal(m[j].Date) {
+			return m[i].Date.Before(m[j].Date)
+		}
+		return m[i].Id < m[j].Id
+	})
+}
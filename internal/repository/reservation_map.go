@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"app/internal"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewRepositoryReservationMap is a function that returns a new instance of RepositoryReservationMap
+func NewRepositoryReservationMap(db []internal.Reservation) *RepositoryReservationMap {
+	// reservations by vehicle, by start, and last id
+	var lastId int
+	byVehicle := make(map[int][]internal.Reservation)
+	for _, r := range db {
+		if r.Id > lastId {
+			lastId = r.Id
+		}
+		byVehicle[r.VehicleId] = append(byVehicle[r.VehicleId], r)
+	}
+	for _, reservations := range byVehicle {
+		sortReservations(reservations)
+	}
+
+	return &RepositoryReservationMap{
+		db:     byVehicle,
+		lastId: lastId,
+	}
+}
+
+// RepositoryReservationMap is a struct that implements the RepositoryReservation interface
+type RepositoryReservationMap struct {
+	// mu is the lock that guards db. Save holds it for the check of the caller, the conflict check and the insert
+	mu sync.RWMutex
+	// db is a map of the reservations of each vehicle, by start
+	db map[int][]internal.Reservation
+	// lastId is the highest id of the reservations
+	lastId int
+}
+
+// FindByVehicleId is a method that returns the reservations of the vehicle, by start
+func (r *RepositoryReservationMap) FindByVehicleId(vehicleId int) (rs []internal.Reservation, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rs = append(make([]internal.Reservation, 0, len(r.db[vehicleId])), r.db[vehicleId]...)
+	return
+}
+
+// FindBooked is a method that returns the ids of the vehicles with a reservation that overlaps the interval [from, to)
+func (r *RepositoryReservationMap) FindBooked(from time.Time, to time.Time) (ids map[int]bool, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids = make(map[int]bool)
+	for vehicleId, reservations := range r.db {
+		for _, rs := range reservations {
+			if rs.Overlaps(from, to) {
+				ids[vehicleId] = true
+				break
+			}
+		}
+	}
+	return
+}
+
+// Save is a method that adds a reservation if it does not overlap another one of the same vehicle
+// - check is called under the lock, before the overlap check
+func (r *RepositoryReservationMap) Save(rs *internal.Reservation, check func() error) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if check != nil {
+		if err = check(); err != nil {
+			return
+		}
+	}
+	for _, other := range r.db[rs.VehicleId] {
+		if other.Overlaps(rs.From, rs.To) {
+			err = fmt.Errorf("%w: reservation %d from %s to %s", internal.ErrRepositoryReservationConflict, other.Id, other.From.Format(time.RFC3339), other.To.Format(time.RFC3339))
+			return
+		}
+	}
+
+	r.lastId++
+	rs.Id = r.lastId
+	reservations := append(r.db[rs.VehicleId], *rs)
+	sortReservations(reservations)
+	r.db[rs.VehicleId] = reservations
+	return
+}
+
+// Delete is a method that removes the reservation of the vehicle that matches the id
+func (r *RepositoryReservationMap) Delete(vehicleId int, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservations := r.db[vehicleId]
+	for i, rs := range reservations {
+		if rs.Id == id {
+			r.db[vehicleId] = append(reservations[:i:i], reservations[i+1:]...)
+			return
+		}
+	}
+
+	err = internal.ErrRepositoryReservationNotFound
+	return
+}
+
+// sortReservations is a function that sorts reservations by start, then by id
+func sortReservations(r []internal.Reservation) {
+	sort.SliceStable(r, func(i, j int) bool {
+		if !r[i].From.Equal(r[j].From) {
+			return r[i].From.Before(r[j].From)
+		}
+		return r[i].Id < r[j].Id
+	})
+}
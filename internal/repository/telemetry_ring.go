@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"app/internal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewRepositoryTelemetryRing is a function that returns a new instance of RepositoryTelemetryRing
+// - capacity: number of readings kept per vehicle, at least 1
+func NewRepositoryTelemetryRing(capacity int) *RepositoryTelemetryRing {
+	// default values
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RepositoryTelemetryRing{
+		db:       make(map[int]*ring),
+		capacity: capacity,
+	}
+}
+
+// RepositoryTelemetryRing is a struct that implements the RepositoryTelemetry interface
+// - the readings of each vehicle are kept in a ring buffer, oldest first, that overwrites the oldest reading when full
+type RepositoryTelemetryRing struct {
+	// mu is the lock that guards db
+	mu sync.RWMutex
+	// db is a map of the readings of each vehicle
+	db map[int]*ring
+	// capacity is the number of readings kept per vehicle
+	capacity int
+}
+
+// ring is a struct that represents a fixed size buffer of readings, oldest first
+type ring struct {
+	// buf is the buffer, allocated up to the capacity as readings come
+	buf []internal.Telemetry
+	// start is the position of the oldest reading in buf
+	start int
+}
+
+// at is a method that returns the i-th oldest reading
+func (r *ring) at(i int) internal.Telemetry {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+// push is a method that adds the latest reading, overwriting the oldest one when the buffer is full
+func (r *ring) push(t internal.Telemetry, capacity int) {
+	if len(r.buf) < capacity {
+		r.buf = append(r.buf, t)
+		return
+	}
+	r.buf[r.start] = t
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// FindLast is a method that returns the latest reading of the vehicle (ok false if there is none)
+func (r *RepositoryTelemetryRing) FindLast(vehicleId int) (t internal.Telemetry, ok bool, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rg, ok := r.db[vehicleId]
+	if !ok || len(rg.buf) == 0 {
+		ok = false
+		return
+	}
+	t = rg.at(len(rg.buf) - 1)
+	return
+}
+
+// FindRange is a method that returns the readings of the vehicle in the interval [from, to), oldest first
+func (r *RepositoryTelemetryRing) FindRange(vehicleId int, from time.Time, to time.Time) (t []internal.Telemetry, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t = make([]internal.Telemetry, 0)
+	rg, ok := r.db[vehicleId]
+	if !ok {
+		return
+	}
+	// the readings are sorted by time: binary search of the bounds
+	n := len(rg.buf)
+	lo := 0
+	if !from.IsZero() {
+		lo = sort.Search(n, func(i int) bool { return !rg.at(i).Time.Before(from) })
+	}
+	hi := n
+	if !to.IsZero() {
+		hi = sort.Search(n, func(i int) bool { return !rg.at(i).Time.Before(to) })
+	}
+	for i := lo; i < hi; i++ {
+		t = append(t, rg.at(i))
+	}
+	return
+}
+
+// Append is a method that adds readings of the vehicle, oldest first and after its latest reading
+func (r *RepositoryTelemetryRing) Append(vehicleId int, t []internal.Telemetry) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rg, ok := r.db[vehicleId]
+	if !ok {
+		rg = &ring{}
+		r.db[vehicleId] = rg
+	}
+	for _, reading := range t {
+		rg.push(reading, r.capacity)
+	}
+	return
+}
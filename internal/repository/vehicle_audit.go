@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"sync"
+	"time"
+)
+
+// NewRepositoryVehicleAudit is a function that returns a new instance of RepositoryVehicleAudit
+// - fleet: fleet of the vehicles, stamped on every record
+func NewRepositoryVehicleAudit(rp internal.RepositoryVehicle, au internal.RepositoryAudit, fleet string) *RepositoryVehicleAudit {
+	return &RepositoryVehicleAudit{
+		RepositoryVehicle: rp,
+		au:                au,
+		fleet:             fleet,
+		now:               time.Now,
+	}
+}
+
+// RepositoryVehicleAudit is a struct that decorates a vehicle repository appending an audit record for every mutation
+// - a mutation and its record are committed together: a failed mutation leaves no record, a mutation without record fails
+// - reads are forwarded to the decorated repository
+type RepositoryVehicleAudit struct {
+	// RepositoryVehicle is the decorated repository
+	internal.RepositoryVehicle
+	// mu is the lock that serializes the mutations, so the state read before a mutation is the one it replaces
+	mu sync.Mutex
+	// au is the store where the records are appended
+	au internal.RepositoryAudit
+	// fleet is the fleet of the vehicles
+	fleet string
+	// now is the clock used to timestamp the records
+	now func() time.Time
+}
+
+// Save is a method that adds a vehicle and records its creation
+func (r *RepositoryVehicleAudit) Save(ctx context.Context, v *internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	after := *v
+	err = r.record(ctx, internal.AuditActionCreated, nil, &after, func() (*internal.Vehicle, error) {
+		if err := r.RepositoryVehicle.Save(ctx, v); err != nil {
+			return nil, err
+		}
+		// - the id and the canonical attributes are set by the save
+		after := *v
+		return &after, nil
+	})
+	return
+}
+
+// Update is a method that replaces a vehicle and records the fields that changed
+func (r *RepositoryVehicleAudit) Update(ctx context.Context, v *internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.RepositoryVehicle.FindById(v.Id)
+	if err != nil {
+		return
+	}
+
+	after := *v
+	err = r.record(ctx, internal.AuditActionUpdated, &before, &after, func() (*internal.Vehicle, error) {
+		if err := r.RepositoryVehicle.Update(ctx, v); err != nil {
+			return nil, err
+		}
+		after := *v
+		return &after, nil
+	})
+	return
+}
+
+// Delete is a method that removes a vehicle and records its last state
+func (r *RepositoryVehicleAudit) Delete(ctx context.Context, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.RepositoryVehicle.FindById(id)
+	if err != nil {
+		return
+	}
+
+	err = r.record(ctx, internal.AuditActionDeleted, &before, nil, func() (*internal.Vehicle, error) {
+		return nil, r.RepositoryVehicle.Delete(ctx, id)
+	})
+	return
+}
+
+// Restore is a method that undeletes a vehicle and records its restored state
+func (r *RepositoryVehicleAudit) Restore(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// - the restored state is the last version of the vehicle
+	h, err := r.RepositoryVehicle.FindHistory(id)
+	if err != nil {
+		return
+	}
+
+	after := h[len(h)-1].Vehicle
+	err = r.record(ctx, internal.AuditActionRestored, nil, &after, func() (*internal.Vehicle, error) {
+		var err error
+		if v, err = r.RepositoryVehicle.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		after := v
+		return &after, nil
+	})
+	return
+}
+
+// Reload is a method that replaces the vehicles by the ones of the dataset and records the reload, a single record for the fleet
+func (r *RepositoryVehicleAudit) Reload(ctx context.Context, db map[int]internal.Vehicle) (rl internal.VehicleReload, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err = r.record(ctx, internal.AuditActionReloaded, nil, nil, func() (*internal.Vehicle, error) {
+		var err error
+		rl, err = r.RepositoryVehicle.Reload(ctx, db)
+		return nil, err
+	})
+	return
+}
+
+// record is a method that records a mutation and commits it as a single step
+// - the record is appended before the mutation with the expected state, and removed if the mutation fails
+// - commit returns the state after the mutation, that replaces the expected one in the record
+func (r *RepositoryVehicleAudit) record(ctx context.Context, action internal.AuditAction, before *internal.Vehicle, after *internal.Vehicle, commit func() (*internal.Vehicle, error)) (err error) {
+	// actor
+	actor := "anonymous"
+	if p, ok := internal.PrincipalFromContext(ctx); ok {
+		actor = p.Subject
+	}
+
+	newRecord := func(after *internal.Vehicle) internal.AuditRecord {
+		id := 0
+		if before != nil {
+			id = before.Id
+		}
+		if after != nil {
+			id = after.Id
+		}
+		return internal.AuditRecord{
+			Timestamp: r.now(),
+			Actor:     actor,
+			Fleet:     r.fleet,
+			RequestId: internal.RequestIdFromContext(ctx),
+			Action:    action,
+			VehicleId: id,
+			Changes:   internal.DiffVehicles(before, after),
+		}
+	}
+
+	rc := newRecord(after)
+	err = r.au.AppendCommit(rc, func() (committed internal.AuditRecord, err error) {
+		after, err := commit()
+		if err != nil {
+			return
+		}
+		committed = newRecord(after)
+		committed.Timestamp = rc.Timestamp
+		return
+	})
+	return
+}
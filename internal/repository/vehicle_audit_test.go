@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for RepositoryVehicleAudit
+func TestRepositoryVehicleAudit(t *testing.T) {
+	vehicles := func() *repository.RepositoryReadVehicleMap {
+		return repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Red", Registration: "AB-1"}},
+		}, nil)
+	}
+	ctx := internal.ContextWithRequestId(internal.ContextWithPrincipal(context.Background(), internal.Principal{Subject: "john", Role: internal.RoleEditor}), "req-1")
+	actions := func(rc []internal.AuditRecord) (s []internal.AuditAction) {
+		for _, r := range rc {
+			s = append(s, r.Action)
+		}
+		return
+	}
+
+	t.Run("case 01: every mutation is recorded with its actor, request, vehicle and changes", func(t *testing.T) {
+		// arrange
+		au := repository.NewRepositoryAuditFile(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 0)
+		defer au.Close()
+		rp := repository.NewRepositoryVehicleAudit(vehicles(), au, "north")
+
+		// act
+		created := &internal.Vehicle{VehicleAttributes: internal.VehicleAttributes{Brand: "Toyota", Color: "Blue", Registration: "CD-2"}}
+		require.NoError(t, rp.Save(ctx, created))
+		require.NoError(t, rp.Update(ctx, &internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Green", Registration: "AB-1"}}))
+		require.NoError(t, rp.Delete(ctx, 1))
+		_, err := rp.Restore(ctx, 1)
+		require.NoError(t, err)
+		rc, errFind := au.Find(internal.AuditQuery{})
+
+		// assert
+		require.NoError(t, errFind)
+		require.Equal(t, []internal.AuditAction{internal.AuditActionCreated, internal.AuditActionUpdated, internal.AuditActionDeleted, internal.AuditActionRestored}, actions(rc))
+		require.Equal(t, 2, created.Id)
+		require.Equal(t, 2, rc[0].VehicleId)
+		require.Equal(t, "john", rc[0].Actor)
+		require.Equal(t, "req-1", rc[0].RequestId)
+		require.Equal(t, "north", rc[0].Fleet)
+		require.Equal(t, []internal.AuditChange{{Field: "color", Before: "Red", After: "Green"}}, rc[1].Changes)
+		require.Equal(t, 1, rc[2].VehicleId)
+		require.Contains(t, rc[3].Changes, internal.AuditChange{Field: "color", Before: nil, After: "Green"})
+	})
+
+	t.Run("case 02: a failed mutation is not recorded", func(t *testing.T) {
+		// arrange
+		au := repository.NewRepositoryAuditFile(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 0)
+		defer au.Close()
+		rp := repository.NewRepositoryVehicleAudit(vehicles(), au, internal.DefaultFleet)
+
+		// act
+		errSave := rp.Save(ctx, &internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford"}})
+		errUpdate := rp.Update(ctx, &internal.Vehicle{Id: 7})
+		_, errRestore := rp.Restore(ctx, 1)
+		rc, err := au.Find(internal.AuditQuery{})
+
+		// assert
+		require.ErrorIs(t, errSave, internal.ErrRepositoryVehicleAlreadyExists)
+		require.ErrorIs(t, errUpdate, internal.ErrRepositoryVehicleNotFound)
+		require.ErrorIs(t, errRestore, internal.ErrRepositoryVehicleNotDeleted)
+		require.NoError(t, err)
+		require.Empty(t, rc)
+	})
+
+	t.Run("case 03: a mutation that cannot be recorded is not done", func(t *testing.T) {
+		// arrange
+		au := repository.NewRepositoryAuditFile(filepath.Join(t.TempDir(), "missing", "audit.jsonl"), 0, 0)
+		vh := vehicles()
+		rp := repository.NewRepositoryVehicleAudit(vh, au, internal.DefaultFleet)
+
+		// act
+		errSave := rp.Save(ctx, &internal.Vehicle{Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Toyota"}})
+		errDelete := rp.Delete(ctx, 1)
+
+		// assert
+		require.ErrorIs(t, errSave, internal.ErrRepositoryAuditAppend)
+		require.ErrorIs(t, errDelete, internal.ErrRepositoryAuditAppend)
+		all, err := vh.FindAll()
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		require.Contains(t, all, 1)
+	})
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"sync"
+	"time"
+)
+
+// NewRepositoryVehicleEvents is a function that returns a new instance of RepositoryVehicleEvents
+// - fleet: fleet of the vehicles, stamped on every event
+func NewRepositoryVehicleEvents(rp internal.RepositoryVehicle, pb internal.PublisherEvent, fleet string) *RepositoryVehicleEvents {
+	return &RepositoryVehicleEvents{
+		RepositoryVehicle: rp,
+		pb:                pb,
+		fleet:             fleet,
+		now:               time.Now,
+	}
+}
+
+// RepositoryVehicleEvents is a struct that decorates a vehicle repository publishing an event for every mutation
+// - reads are forwarded to the decorated repository
+// - a restored vehicle is published as created
+type RepositoryVehicleEvents struct {
+	// RepositoryVehicle is the decorated repository
+	internal.RepositoryVehicle
+	// mu is the lock that serializes the mutations, so the events are published in the order of the mutations
+	mu sync.Mutex
+	// pb is the publisher of the events
+	pb internal.PublisherEvent
+	// fleet is the fleet of the vehicles
+	fleet string
+	// now is the clock used to timestamp the events
+	now func() time.Time
+}
+
+// Save is a method that adds a vehicle and publishes its creation
+func (r *RepositoryVehicleEvents) Save(ctx context.Context, v *internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.RepositoryVehicle.Save(ctx, v); err != nil {
+		return
+	}
+
+	r.publish(ctx, internal.EventVehicleCreated, v)
+	return
+}
+
+// Update is a method that replaces a vehicle and publishes its new state
+func (r *RepositoryVehicleEvents) Update(ctx context.Context, v *internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.RepositoryVehicle.Update(ctx, v); err != nil {
+		return
+	}
+
+	r.publish(ctx, internal.EventVehicleUpdated, v)
+	return
+}
+
+// Delete is a method that removes a vehicle and publishes its last state
+func (r *RepositoryVehicleEvents) Delete(ctx context.Context, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.RepositoryVehicle.FindById(id)
+	if err != nil {
+		return
+	}
+	if err = r.RepositoryVehicle.Delete(ctx, id); err != nil {
+		return
+	}
+
+	r.publish(ctx, internal.EventVehicleDeleted, &before)
+	return
+}
+
+// Restore is a method that undeletes a vehicle and publishes it as created
+func (r *RepositoryVehicleEvents) Restore(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err = r.RepositoryVehicle.Restore(ctx, id)
+	if err != nil {
+		return
+	}
+
+	r.publish(ctx, internal.EventVehicleCreated, &v)
+	return
+}
+
+// Reload is a method that replaces the vehicles by the ones of the dataset and publishes the reload, a single event without vehicle
+func (r *RepositoryVehicleEvents) Reload(ctx context.Context, db map[int]internal.Vehicle) (rl internal.VehicleReload, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rl, err = r.RepositoryVehicle.Reload(ctx, db)
+	if err != nil {
+		return
+	}
+
+	r.publish(ctx, internal.EventDatasetReloaded, nil)
+	return
+}
+
+// publish is a method that publishes the event of a mutation that already happened
+// - v is nil for the events of the dataset
+func (r *RepositoryVehicleEvents) publish(ctx context.Context, t internal.EventType, v *internal.Vehicle) {
+	e := internal.NewEvent(t, r.fleet, r.now())
+	e.Actor = "anonymous"
+	if p, ok := internal.PrincipalFromContext(ctx); ok {
+		e.Actor = p.Subject
+	}
+	e.RequestId = internal.RequestIdFromContext(ctx)
+	if v != nil {
+		c := *v
+		e.Vehicle = &c
+	}
+	r.pb.Publish(e)
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"sync"
+)
+
+// NewRepositoryVehicleIndexed is a function that returns a new instance of RepositoryVehicleIndexed
+// - the indexes are built with the vehicles already stored in the repository
+func NewRepositoryVehicleIndexed(rp internal.RepositoryVehicle, ix ...internal.IndexerVehicle) (r *RepositoryVehicleIndexed, err error) {
+	v, err := rp.FindAll()
+	if err != nil {
+		return
+	}
+	for _, value := range v {
+		for _, i := range ix {
+			i.Index(value)
+		}
+	}
+
+	r = &RepositoryVehicleIndexed{RepositoryVehicle: rp, ix: ix}
+	return
+}
+
+// RepositoryVehicleIndexed is a struct that decorates a vehicle repository keeping indexes (full-text, similarity) in sync with its mutations
+// - reads are forwarded to the decorated repository
+type RepositoryVehicleIndexed struct {
+	// RepositoryVehicle is the decorated repository
+	internal.RepositoryVehicle
+	// mu is the lock that serializes the mutations, so the indexes are updated in the same order as the repository
+	mu sync.Mutex
+	// ix are the indexes kept in sync
+	ix []internal.IndexerVehicle
+}
+
+// Save is a method that adds a vehicle and indexes it
+func (r *RepositoryVehicleIndexed) Save(ctx context.Context, v *internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.RepositoryVehicle.Save(ctx, v); err != nil {
+		return
+	}
+
+	r.index(*v)
+	return
+}
+
+// Update is a method that replaces a vehicle and reindexes it
+func (r *RepositoryVehicleIndexed) Update(ctx context.Context, v *internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.RepositoryVehicle.Update(ctx, v); err != nil {
+		return
+	}
+
+	r.index(*v)
+	return
+}
+
+// Delete is a method that removes a vehicle and drops it from the indexes
+func (r *RepositoryVehicleIndexed) Delete(ctx context.Context, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.RepositoryVehicle.Delete(ctx, id); err != nil {
+		return
+	}
+
+	for _, ix := range r.ix {
+		ix.Remove(id)
+	}
+	return
+}
+
+// Restore is a method that undeletes a vehicle and indexes it again
+func (r *RepositoryVehicleIndexed) Restore(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err = r.RepositoryVehicle.Restore(ctx, id)
+	if err != nil {
+		return
+	}
+
+	r.index(v)
+	return
+}
+
+// Reload is a method that replaces the vehicles by the ones of the dataset and indexes the changes
+func (r *RepositoryVehicleIndexed) Reload(ctx context.Context, db map[int]internal.Vehicle) (rl internal.VehicleReload, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rl, err = r.RepositoryVehicle.Reload(ctx, db)
+	if err != nil {
+		return
+	}
+
+	for _, id := range append(rl.Created, rl.Updated...) {
+		r.index(db[id])
+	}
+	for _, id := range rl.Deleted {
+		for _, ix := range r.ix {
+			ix.Remove(id)
+		}
+	}
+	return
+}
+
+// index is a method that adds or replaces the vehicle in every index
+func (r *RepositoryVehicleIndexed) index(v internal.Vehicle) {
+	for _, ix := range r.ix {
+		ix.Index(v)
+	}
+}
@@ -1,87 +1,544 @@
-package repository
-
-import "app/internal"
-
-// NewRepositoryReadVehicleMap is a function that returns a new instance of RepositoryReadVehicleMap
-func NewRepositoryReadVehicleMap(db map[int]internal.Vehicle) *RepositoryReadVehicleMap {
-	// default db
-	defaultDb := make(map[int]internal.Vehicle)
-	if db != nil {
-		defaultDb = db
-	}
-	return &RepositoryReadVehicleMap{db: defaultDb}
-}
-
-// RepositoryReadVehicleMap is a struct that represents a vehicle repository
-type RepositoryReadVehicleMap struct {
-	// db is a map of vehicles
-	db map[int]internal.Vehicle
-}
-
-// FindAll is a method that returns a map of all vehicles
-func (r *RepositoryReadVehicleMap) FindAll() (v map[int]internal.Vehicle, err error) {
-	v = make(map[int]internal.Vehicle)
-
-	// copy db
-	for key, value := range r.db {
-		v[key] = value
-	}
-
-	return
-}
-
-// FindByColorAndYear is a method that returns a map of vehicles that match the color and fabrication year
-func (r *RepositoryReadVehicleMap) FindByColorAndYear(color string, fabricationYear int) (v map[int]internal.Vehicle, err error) {
-	v = make(map[int]internal.Vehicle)
-
-	// filter db
-	for key, value := range r.db {
-		if value.Color == color && value.FabricationYear == fabricationYear {
-			v[key] = value
-		}
-	}
-
-	return
-}
-
-// FindByBrandAndYearRange is a method that returns a map of vehicles that match the brand and a range of fabrication years
-func (r *RepositoryReadVehicleMap) FindByBrandAndYearRange(brand string, startYear int, endYear int) (v map[int]internal.Vehicle, err error) {
-	v = make(map[int]internal.Vehicle)
-
-	// filter db
-	for key, value := range r.db {
-		if value.Brand == brand && value.FabricationYear >= startYear && value.FabricationYear <= endYear {
-			v[key] = value
-		}
-	}
-
-	return
-}
-
-// FindByBrand is a method that returns a map of vehicles that match the brand
-func (r *RepositoryReadVehicleMap) FindByBrand(brand string) (v map[int]internal.Vehicle, err error) {
-	v = make(map[int]internal.Vehicle)
-
-	// filter db
-	for key, value := range r.db {
-		if value.Brand == brand {
-			v[key] = value
-		}
-	}
-
-	return
-}
-
-// FindByWeightRange is a method that returns a map of vehicles that match the weight range
-func (r *RepositoryReadVehicleMap) FindByWeightRange(fromWeight float64, toWeight float64) (v map[int]internal.Vehicle, err error) {
-	v = make(map[int]internal.Vehicle)
-
-	// filter db
-	for key, value := range r.db {
-		if value.Weight >= fromWeight && value.Weight <= toWeight {
-			v[key] = value
-		}
-	}
-
-	return
-}
\ No newline at end of file
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ConfigRepositoryReadVehicleMap is a struct that represents the configuration for RepositoryReadVehicleMap
+type ConfigRepositoryReadVehicleMap struct {
+	// MaxVersions is the number of versions of a vehicle kept in history (0: every version)
+	// - the first version is always kept, the oldest of the others are dropped
+	MaxVersions int
+}
+
+// NewRepositoryReadVehicleMap is a function that returns a new instance of RepositoryReadVehicleMap that keeps every version
+// - nz: normalizer used to compare and store the string attributes (nil compares them without case and stores them as they are)
+func NewRepositoryReadVehicleMap(db map[int]internal.Vehicle, nz internal.Normalizer) *RepositoryReadVehicleMap {
+	return NewRepositoryReadVehicleMapWithConfig(db, nz, nil)
+}
+
+// NewRepositoryReadVehicleMapWithConfig is a function that returns a new instance of RepositoryReadVehicleMap with a configuration
+func NewRepositoryReadVehicleMapWithConfig(db map[int]internal.Vehicle, nz internal.Normalizer, cfg *ConfigRepositoryReadVehicleMap) *RepositoryReadVehicleMap {
+	// default values
+	defaultConfig := &ConfigRepositoryReadVehicleMap{}
+	if cfg != nil {
+		if cfg.MaxVersions > 0 {
+			// - the first version and the current one
+			defaultConfig.MaxVersions = max(cfg.MaxVersions, 2)
+		}
+	}
+	// default db
+	defaultDb := make(map[int]internal.Vehicle)
+	if db != nil {
+		defaultDb = db
+	}
+	// default normalizer
+	if nz == nil {
+		nz = internal.NormalizerNone{}
+	}
+
+	// last id and first version of the loaded vehicles
+	var lastId int
+	history := make(map[int][]internal.VehicleVersion, len(defaultDb))
+	for key, value := range defaultDb {
+		if key > lastId {
+			lastId = key
+		}
+		history[key] = []internal.VehicleVersion{{Version: 1, Vehicle: value}}
+	}
+	r := &RepositoryReadVehicleMap{
+		db:          defaultDb,
+		history:     history,
+		truncated:   make(map[int]time.Time),
+		maxVersions: defaultConfig.MaxVersions,
+		lastId:      lastId,
+		now:         time.Now,
+		nz:          nz,
+	}
+	r.indexRegistrations()
+	return r
+}
+
+// RepositoryReadVehicleMap is a struct that represents a vehicle repository
+// - besides the reads, it implements the mutations of internal.RepositoryWriteVehicle
+// - every mutation is kept as a version in history, deletes are soft
+type RepositoryReadVehicleMap struct {
+	// mu is the lock that guards db and history
+	mu sync.RWMutex
+	// db is a map of the current (not deleted) vehicles
+	db map[int]internal.Vehicle
+	// history is a map of the versions of every vehicle, oldest first
+	history map[int][]internal.VehicleVersion
+	// truncated is a map of the instant from which versions of the vehicle were dropped, until its second kept version
+	truncated map[int]time.Time
+	// maxVersions is the number of versions of a vehicle kept in history (0: every version)
+	maxVersions int
+	// lastId is the highest id stored in history
+	lastId int
+	// now is the clock used to timestamp the versions
+	now func() time.Time
+	// readOnly is true for past snapshots, which reject mutations
+	readOnly bool
+	// nz is the normalizer used to compare and store the string attributes
+	nz internal.Normalizer
+	// registrations is a map of registration key to the ids of the current vehicles with that registration, sorted
+	// - more than one id only for the duplicates of a loaded dataset, the mutations cannot add one
+	registrations map[string][]int
+}
+
+// FindAll is a method that returns a map of all vehicles
+func (r *RepositoryReadVehicleMap) FindAll() (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+
+	// copy db
+	for key, value := range r.db {
+		v[key] = value
+	}
+
+	return
+}
+
+// FindById is a method that returns the vehicle that matches the id
+func (r *RepositoryReadVehicleMap) FindById(id int) (v internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, ok := r.db[id]
+	if !ok {
+		err = internal.ErrRepositoryVehicleNotFound
+		return
+	}
+
+	return
+}
+
+// FindByColorAndYear is a method that returns a map of vehicles that match the color and fabrication year
+func (r *RepositoryReadVehicleMap) FindByColorAndYear(color string, fabricationYear int) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+
+	// filter db
+	color = r.nz.Key("color", color)
+	for key, value := range r.db {
+		if r.nz.Key("color", value.Color) == color && value.FabricationYear == fabricationYear {
+			v[key] = value
+		}
+	}
+
+	return
+}
+
+// FindByBrandAndYearRange is a method that returns a map of vehicles that match the brand and a range of fabrication years
+func (r *RepositoryReadVehicleMap) FindByBrandAndYearRange(brand string, startYear int, endYear int) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+
+	// filter db
+	brand = r.nz.Key("brand", brand)
+	for key, value := range r.db {
+		if r.nz.Key("brand", value.Brand) == brand && value.FabricationYear >= startYear && value.FabricationYear <= endYear {
+			v[key] = value
+		}
+	}
+
+	return
+}
+
+// FindByBrand is a method that returns a map of vehicles that match the brand
+func (r *RepositoryReadVehicleMap) FindByBrand(brand string) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+
+	// filter db
+	brand = r.nz.Key("brand", brand)
+	for key, value := range r.db {
+		if r.nz.Key("brand", value.Brand) == brand {
+			v[key] = value
+		}
+	}
+
+	return
+}
+
+// FindByWeightRange is a method that returns a map of vehicles that match the weight range
+func (r *RepositoryReadVehicleMap) FindByWeightRange(fromWeight internal.Mass, toWeight internal.Mass) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+
+	// filter db
+	for key, value := range r.db {
+		if value.Weight >= fromWeight && value.Weight <= toWeight {
+			v[key] = value
+		}
+	}
+
+	return
+}
+
+// FindByFilter is a method that returns a map of vehicles that match every filter
+func (r *RepositoryReadVehicleMap) FindByFilter(f internal.VehicleFilter) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+
+	// filter db
+	matches := f.Matcher(r.nz)
+	for key, value := range r.db {
+		if matches(value) {
+			v[key] = value
+		}
+	}
+
+	return
+}
+
+// FindByRegistration is a method that returns a map of vehicles that match the registration
+// - more than one vehicle only for the duplicates of a loaded dataset
+func (r *RepositoryReadVehicleMap) FindByRegistration(registration string) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.registrations[r.nz.Key(internal.RegistrationField, registration)]
+	if len(ids) == 0 {
+		err = internal.ErrRepositoryVehicleNotFound
+		return
+	}
+	v = make(map[int]internal.Vehicle, len(ids))
+	for _, id := range ids {
+		v[id] = r.db[id]
+	}
+	return
+}
+
+// Save is a method that adds a vehicle. A zero id is replaced by the next free id
+func (r *RepositoryReadVehicleMap) Save(ctx context.Context, v *internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.readOnly {
+		err = internal.ErrRepositoryReadOnly
+		return
+	}
+
+	// canonical attributes
+	internal.NormalizeVehicleAttributes(r.nz, &v.VehicleAttributes)
+
+	// assign id
+	if v.Id == 0 {
+		v.Id = r.lastId + 1
+	}
+	// - ids of deleted vehicles are kept so they can be restored
+	if _, ok := r.history[v.Id]; ok {
+		err = internal.ErrRepositoryVehicleAlreadyExists
+		return
+	}
+	if err = r.checkRegistration(*v); err != nil {
+		return
+	}
+
+	// save
+	r.db[v.Id] = *v
+	r.register(*v)
+	r.addVersion(*v, false)
+	if v.Id > r.lastId {
+		r.lastId = v.Id
+	}
+
+	return
+}
+
+// Update is a method that replaces the vehicle with the same id
+func (r *RepositoryReadVehicleMap) Update(ctx context.Context, v *internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.readOnly {
+		err = internal.ErrRepositoryReadOnly
+		return
+	}
+	current, ok := r.db[v.Id]
+	if !ok {
+		err = internal.ErrRepositoryVehicleNotFound
+		return
+	}
+
+	internal.NormalizeVehicleAttributes(r.nz, &v.VehicleAttributes)
+	// - a duplicate of a loaded dataset can be updated as long as it keeps its registration
+	if r.nz.Key(internal.RegistrationField, v.Registration) != r.nz.Key(internal.RegistrationField, current.Registration) {
+		if err = r.checkRegistration(*v); err != nil {
+			return
+		}
+	}
+	r.unregister(current)
+	r.db[v.Id] = *v
+	r.register(*v)
+	r.addVersion(*v, false)
+	return
+}
+
+// Delete is a method that removes the vehicle that matches the id
+// - the delete is soft: the vehicle is kept in history and can be restored
+func (r *RepositoryReadVehicleMap) Delete(ctx context.Context, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.readOnly {
+		err = internal.ErrRepositoryReadOnly
+		return
+	}
+	v, ok := r.db[id]
+	if !ok {
+		err = internal.ErrRepositoryVehicleNotFound
+		return
+	}
+
+	delete(r.db, id)
+	r.unregister(v)
+	r.addVersion(v, true)
+	return
+}
+
+// FindHistory is a method that returns the kept versions of the vehicle, oldest first
+// - the first version is valid until the first dropped one
+func (r *RepositoryReadVehicleMap) FindHistory(id int) (h []internal.VehicleVersion, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.history[id]
+	if !ok {
+		err = internal.ErrRepositoryVehicleNotFound
+		return
+	}
+
+	// copy versions and close their validity with the next one
+	h = make([]internal.VehicleVersion, len(versions))
+	copy(h, versions)
+	for i := 0; i < len(h)-1; i++ {
+		h[i].ValidTo = h[i+1].ValidFrom
+	}
+	if from, ok := r.truncated[id]; ok {
+		h[0].ValidTo = from
+	}
+
+	return
+}
+
+// AsOf is a method that returns a read only repository with the fleet as it was at the instant
+// - an instant within the dropped versions of a vehicle is an error, its state is unknown
+func (r *RepositoryReadVehicleMap) AsOf(t time.Time) (rp internal.RepositoryVehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot := &RepositoryReadVehicleMap{
+		db:        make(map[int]internal.Vehicle),
+		history:   make(map[int][]internal.VehicleVersion),
+		truncated: make(map[int]time.Time),
+		now:       r.now,
+		readOnly:  true,
+		nz:        r.nz,
+	}
+	for id, versions := range r.history {
+		// versions up to the instant
+		n := 0
+		for n < len(versions) && !versions[n].ValidFrom.After(t) {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		if from, ok := r.truncated[id]; ok && !t.Before(from) {
+			if n == 1 {
+				err = fmt.Errorf("%w: versions of vehicle %d from %s dropped", internal.ErrRepositoryHistoryTruncated, id, from.Format(time.RFC3339))
+				return
+			}
+			snapshot.truncated[id] = from
+		}
+
+		snapshot.history[id] = versions[:n:n]
+		if last := versions[n-1]; !last.Deleted {
+			snapshot.db[id] = last.Vehicle
+		}
+		if id > snapshot.lastId {
+			snapshot.lastId = id
+		}
+	}
+
+	snapshot.indexRegistrations()
+	rp = snapshot
+	return
+}
+
+// Restore is a method that undeletes the vehicle with its last state
+func (r *RepositoryReadVehicleMap) Restore(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.readOnly {
+		err = internal.ErrRepositoryReadOnly
+		return
+	}
+	versions, ok := r.history[id]
+	if !ok {
+		err = internal.ErrRepositoryVehicleNotFound
+		return
+	}
+	last := versions[len(versions)-1]
+	if !last.Deleted {
+		err = internal.ErrRepositoryVehicleNotDeleted
+		return
+	}
+
+	v = last.Vehicle
+	if err = r.checkRegistration(v); err != nil {
+		return
+	}
+	r.db[id] = v
+	r.register(v)
+	r.addVersion(v, false)
+	return
+}
+
+// Reload is a method that replaces the current vehicles by the ones of the dataset, every change kept as a version in history
+// - the vehicles are stored as they are loaded, so the index of the registrations is built again as on the first load
+func (r *RepositoryReadVehicleMap) Reload(ctx context.Context, db map[int]internal.Vehicle) (rl internal.VehicleReload, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.readOnly {
+		err = internal.ErrRepositoryReadOnly
+		return
+	}
+
+	// created, restored and updated vehicles, in id order
+	ids := make([]int, 0, len(db))
+	for id := range db {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		v := db[id]
+		current, ok := r.db[id]
+		switch {
+		case !ok:
+			rl.Created = append(rl.Created, id)
+		case current != v:
+			rl.Updated = append(rl.Updated, id)
+		default:
+			continue
+		}
+		r.db[id] = v
+		r.addVersion(v, false)
+		if id > r.lastId {
+			r.lastId = id
+		}
+	}
+
+	// deleted vehicles, in id order
+	ids = ids[:0]
+	for id := range r.db {
+		if _, ok := db[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		v := r.db[id]
+		delete(r.db, id)
+		r.addVersion(v, true)
+		rl.Deleted = append(rl.Deleted, id)
+	}
+
+	r.indexRegistrations()
+	return
+}
+
+// addVersion is a method that appends a version to the history of the vehicle, keeping at most maxVersions
+// - the caller must hold the write lock
+func (r *RepositoryReadVehicleMap) addVersion(v internal.Vehicle, deleted bool) {
+	versions := r.history[v.Id]
+	version := 1
+	if len(versions) > 0 {
+		version = versions[len(versions)-1].Version + 1
+	}
+	versions = append(versions, internal.VehicleVersion{
+		Version:   version,
+		Vehicle:   v,
+		ValidFrom: r.now(),
+		Deleted:   deleted,
+	})
+
+	// - the oldest versions beyond the cap are dropped, but the first one
+	if r.maxVersions > 0 && len(versions) > r.maxVersions {
+		if _, ok := r.truncated[v.Id]; !ok {
+			r.truncated[v.Id] = versions[1].ValidFrom
+		}
+		versions = append(versions[:1:1], versions[len(versions)-r.maxVersions+1:]...)
+	}
+	r.history[v.Id] = versions
+}
+
+// indexRegistrations is a method that builds the index of the registrations of the current vehicles
+// - the duplicates of the dataset are indexed as they are, every one found by the lookups
+func (r *RepositoryReadVehicleMap) indexRegistrations() {
+	r.registrations = make(map[string][]int)
+	for _, v := range r.db {
+		r.register(v)
+	}
+}
+
+// checkRegistration is a method that returns a conflict if another current vehicle has the registration of the vehicle
+// - the caller must hold the write lock
+func (r *RepositoryReadVehicleMap) checkRegistration(v internal.Vehicle) (err error) {
+	for _, id := range r.registrations[r.nz.Key(internal.RegistrationField, v.Registration)] {
+		if id != v.Id {
+			err = fmt.Errorf("%w: %q is the registration of vehicle %d", internal.ErrRepositoryRegistrationConflict, v.Registration, id)
+			return
+		}
+	}
+	return
+}
+
+// register is a method that adds the vehicle to the index of the registrations, an empty registration is not indexed
+// - the caller must hold the write lock
+func (r *RepositoryReadVehicleMap) register(v internal.Vehicle) {
+	key := r.nz.Key(internal.RegistrationField, v.Registration)
+	if key == "" {
+		return
+	}
+	ids := r.registrations[key]
+	if i, found := slices.BinarySearch(ids, v.Id); !found {
+		r.registrations[key] = slices.Insert(ids, i, v.Id)
+	}
+}
+
+// unregister is a method that removes the vehicle from the index of the registrations
+// - the caller must hold the write lock
+func (r *RepositoryReadVehicleMap) unregister(v internal.Vehicle) {
+	key := r.nz.Key(internal.RegistrationField, v.Registration)
+	ids := r.registrations[key]
+	if i, found := slices.BinarySearch(ids, v.Id); found {
+		ids = slices.Delete(ids, i, i+1)
+	}
+	if len(ids) == 0 {
+		delete(r.registrations, key)
+		return
+	}
+	r.registrations[key] = ids
+}
@@ -0,0 +1,256 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for the history of RepositoryReadVehicleMap
+func TestRepositoryReadVehicleMap_History(t *testing.T) {
+	vehicles := func() *repository.RepositoryReadVehicleMap {
+		return repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Red", Registration: "AB-1"}},
+		}, nil)
+	}
+	colored := func(id int, color string) *internal.Vehicle {
+		return &internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: color, Registration: "AB-1"}}
+	}
+	ctx := context.Background()
+
+	t.Run("case 01: the versions are in order, each one valid until the next", func(t *testing.T) {
+		// arrange
+		rp := vehicles()
+
+		// act
+		require.NoError(t, rp.Update(ctx, colored(1, "Green")))
+		require.NoError(t, rp.Delete(ctx, 1))
+		h, err := rp.FindHistory(1)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, h, 3)
+		for i, version := range h {
+			require.Equal(t, i+1, version.Version)
+		}
+		require.True(t, h[0].ValidFrom.IsZero())
+		require.Equal(t, "Green", h[1].Vehicle.Color)
+		require.Equal(t, h[1].ValidFrom, h[0].ValidTo)
+		require.Equal(t, h[2].ValidFrom, h[1].ValidTo)
+		require.True(t, h[2].ValidTo.IsZero())
+		require.True(t, h[2].Deleted)
+	})
+
+	t.Run("case 02: the fleet as of an instant has the versions up to it, not the vehicles created after it", func(t *testing.T) {
+		// arrange
+		rp := vehicles()
+		beforeCreate := time.Now()
+		require.NoError(t, rp.Save(ctx, &internal.Vehicle{Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Toyota", Registration: "CD-2"}}))
+		require.NoError(t, rp.Update(ctx, colored(1, "Green")))
+		afterUpdate := time.Now()
+		require.NoError(t, rp.Update(ctx, colored(1, "Black")))
+
+		// act
+		past, errPast := rp.AsOf(beforeCreate)
+		middle, errMiddle := rp.AsOf(afterUpdate)
+
+		// assert
+		require.NoError(t, errPast)
+		_, err := past.FindById(2)
+		require.ErrorIs(t, err, internal.ErrRepositoryVehicleNotFound)
+		v, err := past.FindById(1)
+		require.NoError(t, err)
+		require.Equal(t, "Red", v.Color)
+		require.ErrorIs(t, past.Delete(ctx, 1), internal.ErrRepositoryReadOnly)
+		require.NoError(t, errMiddle)
+		v, err = middle.FindById(1)
+		require.NoError(t, err)
+		require.Equal(t, "Green", v.Color)
+	})
+
+	t.Run("case 03: a deleted vehicle is restored with its last state, only once", func(t *testing.T) {
+		// arrange
+		rp := vehicles()
+		require.NoError(t, rp.Update(ctx, colored(1, "Green")))
+		require.NoError(t, rp.Delete(ctx, 1))
+
+		// act
+		v, err := rp.Restore(ctx, 1)
+		_, errAgain := rp.Restore(ctx, 1)
+		_, errMissing := rp.Restore(ctx, 9)
+		found, errFound := rp.FindById(1)
+		h, errHistory := rp.FindHistory(1)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "Green", v.Color)
+		require.ErrorIs(t, errAgain, internal.ErrRepositoryVehicleNotDeleted)
+		require.ErrorIs(t, errMissing, internal.ErrRepositoryVehicleNotFound)
+		require.NoError(t, errFound)
+		require.Equal(t, v, found)
+		require.NoError(t, errHistory)
+		require.Len(t, h, 4)
+		require.False(t, h[3].Deleted)
+	})
+
+	t.Run("case 04: every version is kept by default", func(t *testing.T) {
+		// arrange
+		rp := vehicles()
+
+		// act
+		for i := 0; i < 150; i++ {
+			require.NoError(t, rp.Update(ctx, colored(1, "Green")))
+		}
+		h, err := rp.FindHistory(1)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, h, 151)
+	})
+
+	t.Run("case 05: a capped history keeps the first version and the last ones, an instant within the dropped ones is an error", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryReadVehicleMapWithConfig(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Red", Registration: "AB-1"}},
+		}, nil, &repository.ConfigRepositoryReadVehicleMap{MaxVersions: 3})
+		require.NoError(t, rp.Update(ctx, colored(1, "Green")))
+		dropped := time.Now()
+		for _, color := range []string{"Blue", "White", "Black"} {
+			require.NoError(t, rp.Update(ctx, colored(1, color)))
+		}
+
+		// act
+		h, err := rp.FindHistory(1)
+		loaded, errLoaded := rp.AsOf(time.Time{})
+		_, errDropped := rp.AsOf(dropped)
+		current, errCurrent := rp.AsOf(time.Now())
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, h, 3)
+		require.Equal(t, []int{1, 4, 5}, []int{h[0].Version, h[1].Version, h[2].Version})
+		require.True(t, h[0].ValidTo.Before(dropped))
+		require.NoError(t, errLoaded)
+		v, err := loaded.FindById(1)
+		require.NoError(t, err)
+		require.Equal(t, "Red", v.Color)
+		require.ErrorIs(t, errDropped, internal.ErrRepositoryHistoryTruncated)
+		require.NoError(t, errCurrent)
+		v, err = current.FindById(1)
+		require.NoError(t, err)
+		require.Equal(t, "Black", v.Color)
+	})
+}
+
+// Tests for the reload of RepositoryReadVehicleMap
+func TestRepositoryReadVehicleMap_Reload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("case 01: the vehicles are replaced by the dataset, each change kept as a version", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Red", Registration: "AB-1"}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Toyota", Color: "Blue", Registration: "CD-2"}},
+			3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Color: "White", Registration: "EF-3"}},
+		}, nil)
+		require.NoError(t, rp.Delete(ctx, 3))
+		db := map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Green", Registration: "AB-1"}},
+			3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Color: "White", Registration: "EF-3"}},
+			4: {Id: 4, VehicleAttributes: internal.VehicleAttributes{Brand: "Seat", Color: "Black", Registration: "GH-4"}},
+		}
+
+		// act
+		rl, err := rp.Reload(ctx, db)
+		all, errAll := rp.FindAll()
+		h, errHistory := rp.FindHistory(2)
+		found, errFound := rp.FindByRegistration("GH-4")
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.VehicleReload{Created: []int{3, 4}, Updated: []int{1}, Deleted: []int{2}}, rl)
+		require.NoError(t, errAll)
+		require.Equal(t, db, all)
+		require.NoError(t, errHistory)
+		require.Len(t, h, 2)
+		require.True(t, h[1].Deleted)
+		require.NoError(t, errFound)
+		require.Len(t, found, 1)
+		require.Contains(t, found, 4)
+	})
+
+	t.Run("case 02: the same dataset changes nothing, a fleet as of an instant is read only", func(t *testing.T) {
+		// arrange
+		db := map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Red", Registration: "AB-1"}},
+		}
+		rp := repository.NewRepositoryReadVehicleMap(db, nil)
+		past, err := rp.AsOf(time.Now())
+		require.NoError(t, err)
+
+		// act
+		rl, err := rp.Reload(ctx, db)
+		_, errPast := past.Reload(ctx, nil)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.VehicleReload{}, rl)
+		require.ErrorIs(t, errPast, internal.ErrRepositoryReadOnly)
+	})
+}
+
+// Tests for the registrations of RepositoryReadVehicleMap
+func TestRepositoryReadVehicleMap_Registration(t *testing.T) {
+	vehicle := func(id int, registration string) *internal.Vehicle {
+		return &internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: registration}}
+	}
+	vehicles := func() *repository.RepositoryReadVehicleMap {
+		return repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: *vehicle(1, "AB-1"),
+			2: *vehicle(2, "AB-1"),
+			3: *vehicle(3, "CD-2"),
+		}, nil)
+	}
+	ctx := context.Background()
+
+	t.Run("case 01: the duplicates of a loaded dataset are every one found", func(t *testing.T) {
+		// arrange
+		rp := vehicles()
+
+		// act
+		v, err := rp.FindByRegistration("AB-1")
+		_, errNotFound := rp.FindByRegistration("EF-3")
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 2)
+		require.Contains(t, v, 1)
+		require.Contains(t, v, 2)
+		require.ErrorIs(t, errNotFound, internal.ErrRepositoryVehicleNotFound)
+	})
+
+	t.Run("case 02: a mutation to the registration of another vehicle is a conflict, a duplicate keeping its registration is updated", func(t *testing.T) {
+		// arrange
+		rp := vehicles()
+
+		// act
+		errSave := rp.Save(ctx, vehicle(4, "AB-1"))
+		errUpdate := rp.Update(ctx, vehicle(3, "AB-1"))
+		errKeep := rp.Update(ctx, &internal.Vehicle{Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Registration: "AB-1"}})
+		errMove := rp.Update(ctx, vehicle(1, "GH-4"))
+		v, err := rp.FindByRegistration("AB-1")
+
+		// assert
+		require.ErrorIs(t, errSave, internal.ErrRepositoryRegistrationConflict)
+		require.ErrorIs(t, errUpdate, internal.ErrRepositoryRegistrationConflict)
+		require.NoError(t, errKeep)
+		require.NoError(t, errMove)
+		require.NoError(t, err)
+		require.Len(t, v, 1)
+		require.Equal(t, "Fiat", v[2].Brand)
+	})
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"app/internal"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// NewRepositoryWebhookMap is a function that returns a new instance of RepositoryWebhookMap
+// - ps: storage where the webhooks are persisted after every mutation (nil keeps them in memory only)
+func NewRepositoryWebhookMap(db []internal.Webhook, ps internal.PersisterWebhook) *RepositoryWebhookMap {
+	// webhooks by id, and last id
+	var lastId int
+	byId := make(map[int]internal.Webhook, len(db))
+	for _, w := range db {
+		if w.Id > lastId {
+			lastId = w.Id
+		}
+		byId[w.Id] = w
+	}
+
+	return &RepositoryWebhookMap{
+		db:     byId,
+		lastId: lastId,
+		ps:     ps,
+	}
+}
+
+// RepositoryWebhookMap is a struct that implements the RepositoryWebhook interface
+type RepositoryWebhookMap struct {
+	// mu is the lock that guards db
+	mu sync.RWMutex
+	// db is a map of the webhooks by id
+	db map[int]internal.Webhook
+	// lastId is the highest id of the webhooks
+	lastId int
+	// ps is the storage where the webhooks are persisted
+	ps internal.PersisterWebhook
+}
+
+// FindAll is a method that returns the webhooks, by id
+func (r *RepositoryWebhookMap) FindAll() (w []internal.Webhook, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w = r.sorted(r.db)
+	return
+}
+
+// FindById is a method that returns the webhook that matches the id
+func (r *RepositoryWebhookMap) FindById(id int) (w internal.Webhook, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.db[id]
+	if !ok {
+		err = internal.ErrRepositoryWebhookNotFound
+		return
+	}
+	return
+}
+
+// Save is a method that adds a webhook. The id is replaced by the next free id
+// - when the webhooks cannot be persisted the webhook is not added
+func (r *RepositoryWebhookMap) Save(w *internal.Webhook) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wh := *w
+	wh.Id = r.lastId + 1
+	db := make(map[int]internal.Webhook, len(r.db)+1)
+	for id, webhook := range r.db {
+		db[id] = webhook
+	}
+	db[wh.Id] = wh
+	if err = r.persist(db); err != nil {
+		return
+	}
+
+	r.db = db
+	r.lastId = wh.Id
+	w.Id = wh.Id
+	return
+}
+
+// Delete is a method that removes the webhook that matches the id
+// - when the webhooks cannot be persisted the webhook is not removed
+func (r *RepositoryWebhookMap) Delete(id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.db[id]; !ok {
+		err = internal.ErrRepositoryWebhookNotFound
+		return
+	}
+	db := make(map[int]internal.Webhook, len(r.db))
+	for webhookId, webhook := range r.db {
+		if webhookId != id {
+			db[webhookId] = webhook
+		}
+	}
+	if err = r.persist(db); err != nil {
+		return
+	}
+
+	r.db = db
+	return
+}
+
+// persist is a method that stores the webhooks, if there is a storage
+func (r *RepositoryWebhookMap) persist(db map[int]internal.Webhook) (err error) {
+	if r.ps == nil {
+		return
+	}
+	if err = r.ps.Persist(r.sorted(db)); err != nil {
+		err = fmt.Errorf("%w: %v", internal.ErrRepositoryWebhookPersist, err)
+		return
+	}
+	return
+}
+
+// sorted is a method that returns the webhooks of db, by id
+func (r *RepositoryWebhookMap) sorted(db map[int]internal.Webhook) (w []internal.Webhook) {
+	w = make([]internal.Webhook, 0, len(db))
+	for _, webhook := range db {
+		w = append(w, webhook)
+	}
+	sort.Slice(w, func(i, j int) bool { return w[i].Id < w[j].Id })
+	return
+}
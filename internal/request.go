@@ -0,0 +1,17 @@
+package internal
+
+import "context"
+
+// requestIdContextKey is the key used to store the id of the request in a context
+type requestIdContextKey struct{}
+
+// ContextWithRequestId is a function that returns a copy of ctx that carries the id of the request
+func ContextWithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, id)
+}
+
+// RequestIdFromContext is a function that returns the id of the request stored in ctx (empty if there is none)
+func RequestIdFromContext(ctx context.Context) (id string) {
+	id, _ = ctx.Value(requestIdContextKey{}).(string)
+	return
+}
@@ -0,0 +1,24 @@
+package internal
+
+import "time"
+
+// Reservation is a struct that represents the booking of a vehicle for a trip
+type Reservation struct {
+	// Id is the unique identifier of the reservation
+	Id int
+	// VehicleId is the id of the booked vehicle
+	VehicleId int
+	// From is the instant when the trip starts
+	From time.Time
+	// To is the instant when the trip ends, excluded: a reservation can start when the previous one ends
+	To time.Time
+	// Passengers is the number of people of the trip
+	Passengers int
+	// Holder is the subject of the principal that made the reservation
+	Holder string
+}
+
+// Overlaps is a method that returns true if the reservation overlaps the interval [from, to)
+func (r Reservation) Overlaps(from time.Time, to time.Time) bool {
+	return r.From.Before(to) && from.Before(r.To)
+}
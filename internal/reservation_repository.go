@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRepositoryReservationConflict is an error that represents a reservation that overlaps another one of the same vehicle
+	ErrRepositoryReservationConflict = errors.New("repository: reservation conflict")
+	// ErrRepositoryReservationNotFound is an error that represents a reservation that does not exist
+	ErrRepositoryReservationNotFound = errors.New("repository: reservation not found")
+)
+
+// RepositoryReservation is an interface that represents a reservation repository
+type RepositoryReservation interface {
+	// FindByVehicleId is a method that returns the reservations of the vehicle, by start
+	FindByVehicleId(vehicleId int) (r []Reservation, err error)
+
+	// FindBooked is a method that returns the ids of the vehicles with a reservation that overlaps the interval [from, to)
+	FindBooked(from time.Time, to time.Time) (ids map[int]bool, err error)
+
+	// Save is a method that adds a reservation if it does not overlap another one of the same vehicle
+	// - the check and the insert are atomic, so concurrent bookings of the same interval cannot both succeed
+	// - check is called under the same lock before the overlap check, its error is returned and nothing is saved (nil: no check)
+	// - the id is replaced by the next free id
+	Save(r *Reservation, check func() error) (err error)
+
+	// Delete is a method that removes the reservation of the vehicle that matches the id
+	Delete(vehicleId int, id int) (err error)
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrServiceInvalidReservation is an error that represents a reservation with invalid attributes
+	ErrServiceInvalidReservation = errors.New("service: invalid reservation")
+	// ErrServiceReservationConflict is an error that represents a reservation that overlaps another one of the same vehicle
+	ErrServiceReservationConflict = errors.New("service: reservation conflict")
+	// ErrServiceReservationNotFound is an error that represents a reservation that does not exist
+	ErrServiceReservationNotFound = errors.New("service: reservation not found")
+	// ErrServiceReservationForbidden is an error that represents a reservation of another holder
+	ErrServiceReservationForbidden = errors.New("service: reservation of another holder")
+)
+
+// AvailabilityQuery is a struct that represents a query of the vehicles free for a trip
+// - zero values of the optional filters are not used
+type AvailabilityQuery struct {
+	// From is the instant when the trip starts
+	From time.Time
+	// To is the instant when the trip ends
+	To time.Time
+	// Passengers is the number of people of the trip (optional)
+	Passengers int
+	// Brand is the brand of the vehicles (optional)
+	Brand string
+	// FuelType is the fuel type of the vehicles (optional)
+	FuelType FuelType
+}
+
+// ServiceReservation is an interface that represents a reservation service
+type ServiceReservation interface {
+	// FindByVehicleId is a method that returns the reservations of the vehicle, by start
+	FindByVehicleId(vehicleId int) (r []Reservation, err error)
+
+	// Book is a method that validates and adds a reservation
+	// - ErrServiceInvalidReservation: empty interval, or passengers outside 1..capacity of the vehicle
+	// - ErrServiceReservationConflict: the vehicle is already booked in the interval
+	Book(ctx context.Context, r *Reservation) (err error)
+
+	// Cancel is a method that removes the reservation of the vehicle that matches the id
+	// - ErrServiceReservationForbidden: the principal of ctx is neither the holder nor an admin
+	Cancel(ctx context.Context, vehicleId int, id int) (err error)
+
+	// Available is a method that returns the vehicles that match the query and are not booked in its interval
+	Available(query AvailabilityQuery) (v map[int]Vehicle, err error)
+}
@@ -0,0 +1,20 @@
+package service
+
+import "app/internal"
+
+// ServiceAuditDefault is a struct that represents the default service for the audit trail
+type ServiceAuditDefault struct {
+	// rp is the repository that will be used by the service
+	rp internal.RepositoryAudit
+}
+
+// NewServiceAuditDefault is a function that returns a new instance of ServiceAuditDefault
+func NewServiceAuditDefault(rp internal.RepositoryAudit) *ServiceAuditDefault {
+	return &ServiceAuditDefault{rp: rp}
+}
+
+// Find is a method that returns the records that match the query, oldest first
+func (s *ServiceAuditDefault) Find(query internal.AuditQuery) (rc []internal.AuditRecord, err error) {
+	rc, err = s.rp.Find(query)
+	return
+}
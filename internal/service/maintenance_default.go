@@ -0,0 +1,158 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ServiceMaintenanceDefault is a struct that represents the default service for maintenance records
+type ServiceMaintenanceDefault struct {
+	// mu is the lock that serializes the creations, so a record is validated against the records saved before it
+	mu sync.Mutex
+	// rp is the repository of the records
+	rp internal.RepositoryMaintenance
+	// rpVehicle is the repository of the serviced vehicles
+	rpVehicle internal.RepositoryReadVehicle
+	// now is the clock used to reject services in the future
+	now func() time.Time
+}
+
+// NewServiceMaintenanceDefault is a function that returns a new instance of ServiceMaintenanceDefault
+func NewServiceMaintenanceDefault(rp internal.RepositoryMaintenance, rpVehicle internal.RepositoryReadVehicle) *ServiceMaintenanceDefault {
+	return &ServiceMaintenanceDefault{rp: rp, rpVehicle: rpVehicle, now: time.Now}
+}
+
+// FindByVehicleId is a method that returns the records of the vehicle, oldest first
+func (s *ServiceMaintenanceDefault) FindByVehicleId(vehicleId int) (m []internal.Maintenance, err error) {
+	if _, err = s.rpVehicle.FindById(vehicleId); err != nil {
+		err = s.translate(err)
+		return
+	}
+
+	m, err = s.rp.FindByVehicleId(vehicleId)
+	return
+}
+
+// Create is a method that validates and adds a record to an existing vehicle
+// - the record is validated and saved under the lock of the service
+func (s *ServiceMaintenanceDefault) Create(ctx context.Context, m *internal.Maintenance) (err error) {
+	if _, err = s.rpVehicle.FindById(m.VehicleId); err != nil {
+		err = s.translate(err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.validate(m); err != nil {
+		return
+	}
+	// - a request that is canceled while waiting for the lock saves nothing
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	err = s.rp.Save(m)
+	return
+}
+
+// Overdue is a method that returns the vehicles that are overdue for service at the instant, most overdue first
+// - the service interval shortens with the age of the vehicle (from its fabrication year)
+// - it starts at the last service, or at the start of the fabrication year for vehicles never serviced
+func (s *ServiceMaintenanceDefault) Overdue(at time.Time) (o []internal.OverdueVehicle, err error) {
+	v, err := s.rpVehicle.FindAll()
+	if err != nil {
+		return
+	}
+	last, err := s.rp.FindLast()
+	if err != nil {
+		return
+	}
+
+	o = make([]internal.OverdueVehicle, 0)
+	for id, vehicle := range v {
+		from := time.Date(vehicle.FabricationYear, time.January, 1, 0, 0, 0, 0, time.UTC)
+		rc, serviced := last[id]
+		if serviced {
+			from = rc.Date
+		}
+
+		due := from.AddDate(0, serviceInterval(at.Year()-vehicle.FabricationYear), 0)
+		if !at.After(due) {
+			continue
+		}
+		overdue := internal.OverdueVehicle{
+			Vehicle:     vehicle,
+			DueDate:     due,
+			DaysOverdue: int(at.Sub(due).Hours() / 24),
+		}
+		if serviced {
+			overdue.LastService = rc.Date
+		}
+		o = append(o, overdue)
+	}
+	sort.Slice(o, func(i, j int) bool {
+		if !o[i].DueDate.Equal(o[j].DueDate) {
+			return o[i].DueDate.Before(o[j].DueDate)
+		}
+		return o[i].Vehicle.Id < o[j].Vehicle.Id
+	})
+	return
+}
+
+// serviceInterval is a function that returns the months between services of a vehicle of the given age in years
+// - new vehicles (under 3 years) every 24 months, up to 10 years every 12 months, older ones every 6 months
+func serviceInterval(age int) (months int) {
+	switch {
+	case age < 3:
+		months = 24
+	case age < 10:
+		months = 12
+	default:
+		months = 6
+	}
+	return
+}
+
+// validate is a method that checks the attributes of a record
+func (s *ServiceMaintenanceDefault) validate(m *internal.Maintenance) (err error) {
+	switch {
+	case m.Date.IsZero():
+		return fmt.Errorf("%w: date is required", internal.ErrServiceInvalidMaintenance)
+	case m.Date.After(s.now()):
+		return fmt.Errorf("%w: date must not be in the future", internal.ErrServiceInvalidMaintenance)
+	case m.Odometer < 0:
+		return fmt.Errorf("%w: odometer must not be negative", internal.ErrServiceInvalidMaintenance)
+	case m.Cost < 0:
+		return fmt.Errorf("%w: cost must not be negative", internal.ErrServiceInvalidMaintenance)
+	case !m.Type.Valid():
+		return fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidMaintenance, internal.ErrInvalidMaintenanceType, m.Type)
+	}
+
+	// - the odometer never goes back: a reading must not be below the one of an earlier service, nor above the one of a later service
+	records, err := s.rp.FindByVehicleId(m.VehicleId)
+	if err != nil {
+		return
+	}
+	for _, rc := range records {
+		switch {
+		case !rc.Date.After(m.Date) && rc.Odometer > m.Odometer:
+			return fmt.Errorf("%w: odometer must not be below %.0f km, the reading of the service of %s", internal.ErrServiceInvalidMaintenance, float64(rc.Odometer), rc.Date.Format(internal.MaintenanceDateLayout))
+		case rc.Date.After(m.Date) && rc.Odometer < m.Odometer:
+			return fmt.Errorf("%w: odometer must not be above %.0f km, the reading of the service of %s", internal.ErrServiceInvalidMaintenance, float64(rc.Odometer), rc.Date.Format(internal.MaintenanceDateLayout))
+		}
+	}
+	return
+}
+
+// translate is a method that maps the repository errors to service errors
+func (s *ServiceMaintenanceDefault) translate(err error) error {
+	if errors.Is(err, internal.ErrRepositoryVehicleNotFound) {
+		return fmt.Errorf("%w: %v", internal.ErrServiceVehicleNotFound, err)
+	}
+	return err
+}
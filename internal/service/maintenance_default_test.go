@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ServiceMaintenanceDefault
+func TestServiceMaintenanceDefault(t *testing.T) {
+	vehicles := func() *repository.RepositoryReadVehicleMap {
+		return repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Focus", FabricationYear: 2023}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Ranger", FabricationYear: 2018}},
+			3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Escort", FabricationYear: 1990}},
+		}, nil)
+	}
+	day := func(value string) time.Time {
+		d, err := time.Parse("2006-01-02", value)
+		require.NoError(t, err)
+		return d
+	}
+
+	t.Run("case 01: the service interval depends on the age and starts at the last service", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryMaintenanceMap([]internal.Maintenance{
+			{Id: 1, VehicleId: 2, Date: day("2023-05-01"), Type: internal.MaintenanceTypeInspection},
+			{Id: 2, VehicleId: 3, Date: day("2024-03-01"), Type: internal.MaintenanceTypeInspection},
+		}, nil)
+		sv := service.NewServiceMaintenanceDefault(rp, vehicles())
+
+		// act
+		o, err := sv.Overdue(day("2024-10-01"))
+
+		// assert
+		// - 1: new, due 24 months after 2023-01-01
+		// - 2: 6 years old, due 12 months after 2023-05-01
+		// - 3: 34 years old, due 6 months after 2024-03-01
+		require.NoError(t, err)
+		require.Len(t, o, 2)
+		require.Equal(t, 2, o[0].Vehicle.Id)
+		require.Equal(t, day("2024-05-01"), o[0].DueDate)
+		require.Equal(t, day("2023-05-01"), o[0].LastService)
+		require.Equal(t, 153, o[0].DaysOverdue)
+		require.Equal(t, 3, o[1].Vehicle.Id)
+		require.Equal(t, day("2024-09-01"), o[1].DueDate)
+	})
+
+	t.Run("case 02: records are validated and persisted", func(t *testing.T) {
+		// arrange
+		ld := loader.NewLoaderMaintenanceJSON(filepath.Join(t.TempDir(), "maintenance", "default.jsonl"))
+		sv := service.NewServiceMaintenanceDefault(repository.NewRepositoryMaintenanceMap(nil, ld), vehicles())
+
+		// act
+		first := internal.Maintenance{VehicleId: 1, Date: day("2024-01-10"), Odometer: 15000, Type: internal.MaintenanceTypeOilChange, Cost: 90}
+		errFirst := sv.Create(context.Background(), &first)
+		backwards := internal.Maintenance{VehicleId: 1, Date: day("2024-02-10"), Odometer: 14000, Type: internal.MaintenanceTypeTires}
+		errBackwards := sv.Create(context.Background(), &backwards)
+		earlierAbove := internal.Maintenance{VehicleId: 1, Date: day("2023-12-10"), Odometer: 16000, Type: internal.MaintenanceTypeTires}
+		errEarlierAbove := sv.Create(context.Background(), &earlierAbove)
+		earlier := internal.Maintenance{VehicleId: 1, Date: day("2023-12-10"), Odometer: 12000, Type: internal.MaintenanceTypeTires}
+		errEarlier := sv.Create(context.Background(), &earlier)
+		missing := internal.Maintenance{VehicleId: 9, Date: day("2024-02-10"), Type: internal.MaintenanceTypeTires}
+		errMissing := sv.Create(context.Background(), &missing)
+		loaded, errLoad := ld.Load()
+
+		// assert
+		require.NoError(t, errFirst)
+		require.Equal(t, 1, first.Id)
+		require.ErrorIs(t, errBackwards, internal.ErrServiceInvalidMaintenance)
+		require.ErrorIs(t, errEarlierAbove, internal.ErrServiceInvalidMaintenance)
+		require.NoError(t, errEarlier)
+		require.Equal(t, 2, earlier.Id)
+		require.ErrorIs(t, errMissing, internal.ErrServiceVehicleNotFound)
+		require.NoError(t, errLoad)
+		require.Equal(t, []internal.Maintenance{first, earlier}, loaded)
+	})
+
+	t.Run("case 03: a canceled request saves nothing", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryMaintenanceMap(nil, nil)
+		sv := service.NewServiceMaintenanceDefault(rp, vehicles())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		m := internal.Maintenance{VehicleId: 1, Date: day("2024-01-10"), Odometer: 15000, Type: internal.MaintenanceTypeOilChange}
+		err := sv.Create(ctx, &m)
+		records, errFind := rp.FindByVehicleId(1)
+
+		// assert
+		require.ErrorIs(t, err, context.Canceled)
+		require.NoError(t, errFind)
+		require.Empty(t, records)
+	})
+}
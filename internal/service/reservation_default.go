@@ -0,0 +1,144 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ServiceReservationDefault is a struct that represents the default service for reservations
+type ServiceReservationDefault struct {
+	// rp is the repository of the reservations
+	rp internal.RepositoryReservation
+	// rpVehicle is the repository of the booked vehicles
+	rpVehicle internal.RepositoryReadVehicle
+}
+
+// NewServiceReservationDefault is a function that returns a new instance of ServiceReservationDefault
+func NewServiceReservationDefault(rp internal.RepositoryReservation, rpVehicle internal.RepositoryReadVehicle) *ServiceReservationDefault {
+	return &ServiceReservationDefault{rp: rp, rpVehicle: rpVehicle}
+}
+
+// FindByVehicleId is a method that returns the reservations of the vehicle, by start
+func (s *ServiceReservationDefault) FindByVehicleId(vehicleId int) (r []internal.Reservation, err error) {
+	if _, err = s.rpVehicle.FindById(vehicleId); err != nil {
+		err = s.translate(err)
+		return
+	}
+
+	r, err = s.rp.FindByVehicleId(vehicleId)
+	return
+}
+
+// Book is a method that validates and adds a reservation
+// - the holder is the principal of ctx
+// - the capacity of the vehicle is read under the lock of the reservations, with the overlap check
+func (s *ServiceReservationDefault) Book(ctx context.Context, r *internal.Reservation) (err error) {
+	switch {
+	case !r.From.Before(r.To):
+		return fmt.Errorf("%w: from must be before to", internal.ErrServiceInvalidReservation)
+	case r.Passengers <= 0:
+		return fmt.Errorf("%w: passengers must be positive", internal.ErrServiceInvalidReservation)
+	}
+
+	r.Holder = holder(ctx)
+	err = s.rp.Save(r, func() error {
+		v, err := s.rpVehicle.FindById(r.VehicleId)
+		if err != nil {
+			return err
+		}
+		if r.Passengers > v.Capacity {
+			return fmt.Errorf("%w: %d passengers exceed the capacity of the vehicle (%d)", internal.ErrServiceInvalidReservation, r.Passengers, v.Capacity)
+		}
+		return nil
+	})
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// Cancel is a method that removes the reservation of the vehicle that matches the id
+// - only the holder of the reservation or an admin can cancel it
+func (s *ServiceReservationDefault) Cancel(ctx context.Context, vehicleId int, id int) (err error) {
+	reservations, err := s.rp.FindByVehicleId(vehicleId)
+	if err != nil {
+		return
+	}
+	i := slices.IndexFunc(reservations, func(r internal.Reservation) bool { return r.Id == id })
+	if i < 0 {
+		return fmt.Errorf("%w: reservation %d of vehicle %d", internal.ErrServiceReservationNotFound, id, vehicleId)
+	}
+	p, ok := internal.PrincipalFromContext(ctx)
+	if reservations[i].Holder != holder(ctx) && !(ok && p.Role.Includes(internal.RoleAdmin)) {
+		return fmt.Errorf("%w: held by %s", internal.ErrServiceReservationForbidden, reservations[i].Holder)
+	}
+
+	if err = s.rp.Delete(vehicleId, id); err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// Available is a method that returns the vehicles that match the query and are not booked in its interval
+func (s *ServiceReservationDefault) Available(query internal.AvailabilityQuery) (v map[int]internal.Vehicle, err error) {
+	if !query.From.Before(query.To) {
+		err = fmt.Errorf("%w: from must be before to", internal.ErrServiceInvalidReservation)
+		return
+	}
+
+	// candidates
+	var candidates map[int]internal.Vehicle
+	if query.Brand != "" {
+		candidates, err = s.rpVehicle.FindByBrand(query.Brand)
+	} else {
+		candidates, err = s.rpVehicle.FindAll()
+	}
+	if err != nil {
+		return
+	}
+	booked, err := s.rp.FindBooked(query.From, query.To)
+	if err != nil {
+		return
+	}
+
+	// filters
+	v = make(map[int]internal.Vehicle)
+	for id, vehicle := range candidates {
+		switch {
+		case booked[id]:
+			continue
+		case query.Passengers > vehicle.Capacity:
+			continue
+		case query.FuelType != "" && vehicle.FuelType != query.FuelType:
+			continue
+		}
+		v[id] = vehicle
+	}
+	return
+}
+
+// holder is a function that returns the holder of the reservations of the principal of ctx
+func holder(ctx context.Context) string {
+	if p, ok := internal.PrincipalFromContext(ctx); ok {
+		return p.Subject
+	}
+	return "anonymous"
+}
+
+// translate is a method that maps the repository errors to service errors
+func (s *ServiceReservationDefault) translate(err error) error {
+	switch {
+	case errors.Is(err, internal.ErrRepositoryVehicleNotFound):
+		return fmt.Errorf("%w: %v", internal.ErrServiceVehicleNotFound, err)
+	case errors.Is(err, internal.ErrRepositoryReservationConflict):
+		return fmt.Errorf("%w: %v", internal.ErrServiceReservationConflict, err)
+	case errors.Is(err, internal.ErrRepositoryReservationNotFound):
+		return fmt.Errorf("%w: %v", internal.ErrServiceReservationNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ServiceReservationDefault
+func TestServiceReservationDefault(t *testing.T) {
+	vehicles := func() *repository.RepositoryReadVehicleMap {
+		return repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Transit", Capacity: 9, FuelType: internal.FuelTypeDiesel}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Focus", Capacity: 5, FuelType: internal.FuelTypeGasoline}},
+			3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Toyota", Model: "Prius", Capacity: 5, FuelType: internal.FuelTypeHybrid}},
+		}, nil)
+	}
+	at := func(hour int) time.Time {
+		return time.Date(2024, time.May, 1, hour, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("case 01: overlapping bookings and passengers above the capacity are rejected", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceReservationDefault(repository.NewRepositoryReservationMap(nil), vehicles())
+
+		// act
+		first := internal.Reservation{VehicleId: 2, From: at(8), To: at(12), Passengers: 4}
+		errFirst := sv.Book(context.Background(), &first)
+		overlap := internal.Reservation{VehicleId: 2, From: at(11), To: at(13), Passengers: 2}
+		errOverlap := sv.Book(context.Background(), &overlap)
+		next := internal.Reservation{VehicleId: 2, From: at(12), To: at(14), Passengers: 2}
+		errNext := sv.Book(context.Background(), &next)
+		crowded := internal.Reservation{VehicleId: 3, From: at(8), To: at(12), Passengers: 6}
+		errCrowded := sv.Book(context.Background(), &crowded)
+
+		// assert
+		require.NoError(t, errFirst)
+		require.ErrorIs(t, errOverlap, internal.ErrServiceReservationConflict)
+		require.NoError(t, errNext)
+		require.ErrorIs(t, errCrowded, internal.ErrServiceInvalidReservation)
+	})
+
+	t.Run("case 02: concurrent bookings of the same interval, only one succeeds", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceReservationDefault(repository.NewRepositoryReservationMap(nil), vehicles())
+
+		// act
+		var wg sync.WaitGroup
+		errs := make([]error, 50)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				rs := internal.Reservation{VehicleId: 1, From: at(8 + i%3), To: at(12), Passengers: 1}
+				errs[i] = sv.Book(context.Background(), &rs)
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		var booked int
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				booked++
+			case !errors.Is(err, internal.ErrServiceReservationConflict):
+				require.NoError(t, err)
+			}
+		}
+		require.Equal(t, 1, booked)
+	})
+
+	t.Run("case 03: available vehicles combine the interval with the capacity, brand and fuel filters", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceReservationDefault(repository.NewRepositoryReservationMap([]internal.Reservation{
+			{Id: 1, VehicleId: 1, From: at(9), To: at(10), Passengers: 3},
+		}), vehicles())
+
+		// act
+		busy, errBusy := sv.Available(internal.AvailabilityQuery{From: at(8), To: at(12), Brand: "ford"})
+		free, errFree := sv.Available(internal.AvailabilityQuery{From: at(10), To: at(12), Passengers: 6})
+		hybrid, errHybrid := sv.Available(internal.AvailabilityQuery{From: at(8), To: at(12), FuelType: internal.FuelTypeHybrid})
+		_, errEmpty := sv.Available(internal.AvailabilityQuery{From: at(12), To: at(12)})
+
+		// assert
+		require.NoError(t, errBusy)
+		require.NoError(t, errFree)
+		require.NoError(t, errHybrid)
+		require.Equal(t, []int{2}, keys(busy))
+		require.Equal(t, []int{1}, keys(free))
+		require.Equal(t, []int{3}, keys(hybrid))
+		require.ErrorIs(t, errEmpty, internal.ErrServiceInvalidReservation)
+	})
+
+	t.Run("case 04: only the holder or an admin cancels a reservation", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceReservationDefault(repository.NewRepositoryReservationMap(nil), vehicles())
+		as := func(subject string, role internal.Role) context.Context {
+			return internal.ContextWithPrincipal(context.Background(), internal.Principal{Subject: subject, Role: role})
+		}
+		first := internal.Reservation{VehicleId: 1, From: at(8), To: at(10), Passengers: 2}
+		require.NoError(t, sv.Book(as("alice", internal.RoleEditor), &first))
+		second := internal.Reservation{VehicleId: 1, From: at(10), To: at(12), Passengers: 2}
+		require.NoError(t, sv.Book(as("alice", internal.RoleEditor), &second))
+
+		// act
+		errOther := sv.Cancel(as("bob", internal.RoleEditor), 1, first.Id)
+		errHolder := sv.Cancel(as("alice", internal.RoleEditor), 1, first.Id)
+		errAdmin := sv.Cancel(as("root", internal.RoleAdmin), 1, second.Id)
+		errMissing := sv.Cancel(as("root", internal.RoleAdmin), 1, second.Id)
+
+		// assert
+		require.Equal(t, "alice", first.Holder)
+		require.ErrorIs(t, errOther, internal.ErrServiceReservationForbidden)
+		require.NoError(t, errHolder)
+		require.NoError(t, errAdmin)
+		require.ErrorIs(t, errMissing, internal.ErrServiceReservationNotFound)
+	})
+}
+
+// keys is a function that returns the sorted ids of a map of vehicles
+func keys(v map[int]internal.Vehicle) (ids []int) {
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"app/internal"
+	"sync"
+)
+
+// NewServiceStreamDefault is a function that returns a new instance of ServiceStreamDefault
+// - replay: number of events kept for the resuming subscribers
+// - buffer: number of events a subscriber can fall behind before it is dropped
+func NewServiceStreamDefault(nz internal.Normalizer, replay int, buffer int) *ServiceStreamDefault {
+	return &ServiceStreamDefault{
+		nz:          nz,
+		replay:      make([]internal.StreamedEvent, 0, replay),
+		capacity:    replay,
+		buffer:      buffer,
+		subscribers: make(map[*subscriptionStream]bool),
+	}
+}
+
+// ServiceStreamDefault is a struct that represents the default stream of the events of a fleet
+// - a subscriber whose buffer is full is dropped, so a slow subscriber never blocks the publisher
+type ServiceStreamDefault struct {
+	// mu is the lock that guards the sequence, the replay buffer and the subscribers
+	mu sync.Mutex
+	// nz is the normalizer of the filters
+	nz internal.Normalizer
+	// seq is the position of the last event
+	seq uint64
+	// replay is the buffer of the last events, oldest first
+	replay []internal.StreamedEvent
+	// capacity is the number of events kept in the replay buffer
+	capacity int
+	// buffer is the number of events a subscriber can fall behind
+	buffer int
+	// subscribers are the active subscriptions
+	subscribers map[*subscriptionStream]bool
+}
+
+// subscriptionStream is a struct that implements the SubscriptionEvent interface
+type subscriptionStream struct {
+	// sv is the stream of the subscription
+	sv *ServiceStreamDefault
+	// ch is the channel of the live events
+	ch chan internal.StreamedEvent
+	// brand is the folded brand filter
+	brand string
+	// color is the folded color filter
+	color string
+}
+
+// Events is a method that returns the channel of the live events
+func (s *subscriptionStream) Events() <-chan internal.StreamedEvent {
+	return s.ch
+}
+
+// Close is a method that ends the subscription
+func (s *subscriptionStream) Close() {
+	s.sv.mu.Lock()
+	defer s.sv.mu.Unlock()
+
+	s.sv.drop(s)
+}
+
+// Publish is a method that appends the event to the replay buffer and sends it to the matching subscribers
+func (s *ServiceStreamDefault) Publish(e internal.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	se := internal.StreamedEvent{Seq: s.seq, Event: e}
+	if s.capacity > 0 {
+		if len(s.replay) == s.capacity {
+			s.replay = append(s.replay[:0], s.replay[1:]...)
+		}
+		s.replay = append(s.replay, se)
+	}
+
+	for sub := range s.subscribers {
+		if !s.match(sub, e) {
+			continue
+		}
+		select {
+		case sub.ch <- se:
+		default:
+			s.drop(sub)
+		}
+	}
+}
+
+// Subscribe is a method that returns a subscription to the events that match the query
+// - a lastSeq after the last event (e.g. from before a restart) is a gap: the whole replay buffer is sent
+func (s *ServiceStreamDefault) Subscribe(query internal.StreamQuery, lastSeq uint64) (sub internal.SubscriptionEvent, replay []internal.StreamedEvent, gap bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := &subscriptionStream{sv: s, ch: make(chan internal.StreamedEvent, s.buffer)}
+	if query.Brand != "" {
+		st.brand = s.nz.Key("brand", query.Brand)
+	}
+	if query.Color != "" {
+		st.color = s.nz.Key("color", query.Color)
+	}
+
+	// replay
+	replay = make([]internal.StreamedEvent, 0)
+	if lastSeq > 0 {
+		oldest := s.seq + 1
+		if len(s.replay) > 0 {
+			oldest = s.replay[0].Seq
+		}
+		if lastSeq > s.seq || lastSeq+1 < oldest {
+			gap = true
+			lastSeq = 0
+		}
+		for _, se := range s.replay {
+			if se.Seq > lastSeq && s.match(st, se.Event) {
+				replay = append(replay, se)
+			}
+		}
+	}
+
+	s.subscribers[st] = true
+	sub = st
+	return
+}
+
+// match is a method that returns true if the event matches the filters of the subscription
+func (s *ServiceStreamDefault) match(sub *subscriptionStream, e internal.Event) bool {
+	if e.Vehicle == nil {
+		return true
+	}
+	if sub.brand != "" && s.nz.Key("brand", e.Vehicle.Brand) != sub.brand {
+		return false
+	}
+	if sub.color != "" && s.nz.Key("color", e.Vehicle.Color) != sub.color {
+		return false
+	}
+	return true
+}
+
+// drop is a method that removes the subscription and closes its channel, if it is still active
+// - the lock must be held
+func (s *ServiceStreamDefault) drop(sub *subscriptionStream) {
+	if !s.subscribers[sub] {
+		return
+	}
+	delete(s.subscribers, sub)
+	close(sub.ch)
+}
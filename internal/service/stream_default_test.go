@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/normalizer"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ServiceStreamDefault
+func TestServiceStreamDefault(t *testing.T) {
+	event := func(t internal.EventType, brand string, color string) internal.Event {
+		e := internal.NewEvent(t, internal.DefaultFleet, time.Now())
+		if brand != "" {
+			e.Vehicle = &internal.Vehicle{VehicleAttributes: internal.VehicleAttributes{Brand: brand, Color: color}}
+		}
+		return e
+	}
+
+	t.Run("case 01: live events are filtered by brand and color, dataset events always pass", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceStreamDefault(normalizer.NewNormalizerAlias(nil), 10, 10)
+		sub, _, _, err := sv.Subscribe(internal.StreamQuery{Brand: "citroen", Color: "RED"}, 0)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		// act
+		sv.Publish(event(internal.EventVehicleCreated, "Citroën", "Red"))
+		sv.Publish(event(internal.EventVehicleCreated, "Citroën", "Blue"))
+		sv.Publish(event(internal.EventVehicleUpdated, "Ford", "Red"))
+		sv.Publish(event(internal.EventDatasetReloaded, "", ""))
+
+		// assert
+		first, second := <-sub.Events(), <-sub.Events()
+		require.Equal(t, uint64(1), first.Seq)
+		require.Equal(t, uint64(4), second.Seq)
+		require.Empty(t, sub.Events())
+	})
+
+	t.Run("case 02: resuming replays the buffered events after the last one seen, with a gap when they are gone", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceStreamDefault(normalizer.NewNormalizerAlias(nil), 3, 10)
+		for i := 0; i < 5; i++ {
+			sv.Publish(event(internal.EventVehicleUpdated, "Ford", "Red"))
+		}
+
+		// act
+		_, resumed, resumedGap, errResumed := sv.Subscribe(internal.StreamQuery{}, 3)
+		_, old, oldGap, errOld := sv.Subscribe(internal.StreamQuery{}, 1)
+		_, future, futureGap, errFuture := sv.Subscribe(internal.StreamQuery{}, 99)
+		_, live, liveGap, errLive := sv.Subscribe(internal.StreamQuery{}, 0)
+
+		// assert
+		seqs := func(se []internal.StreamedEvent) (s []uint64) {
+			for _, e := range se {
+				s = append(s, e.Seq)
+			}
+			return
+		}
+		require.NoError(t, errResumed)
+		require.Equal(t, []uint64{4, 5}, seqs(resumed))
+		require.False(t, resumedGap)
+		require.NoError(t, errOld)
+		require.Equal(t, []uint64{3, 4, 5}, seqs(old))
+		require.True(t, oldGap)
+		require.NoError(t, errFuture)
+		require.Equal(t, []uint64{3, 4, 5}, seqs(future))
+		require.True(t, futureGap)
+		require.NoError(t, errLive)
+		require.Empty(t, live)
+		require.False(t, liveGap)
+	})
+
+	t.Run("case 03: a subscriber that falls behind is dropped without blocking the publisher", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceStreamDefault(normalizer.NewNormalizerAlias(nil), 10, 2)
+		slow, _, _, err := sv.Subscribe(internal.StreamQuery{}, 0)
+		require.NoError(t, err)
+		fast, _, _, err := sv.Subscribe(internal.StreamQuery{}, 0)
+		require.NoError(t, err)
+		defer fast.Close()
+
+		// act
+		var received int
+		for i := 0; i < 5; i++ {
+			sv.Publish(event(internal.EventVehicleUpdated, "Ford", "Red"))
+			<-fast.Events()
+			received++
+		}
+
+		// assert
+		require.Equal(t, 5, received)
+		var buffered int
+		for range slow.Events() {
+			buffered++
+		}
+		require.Equal(t, 2, buffered)
+		slow.Close()
+	})
+	t.Run("case 04: the reload of the fleet is published as a dataset event, whatever the filters of the subscriber", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceStreamDefault(normalizer.NewNormalizerAlias(nil), 10, 10)
+		rp := repository.NewRepositoryVehicleEvents(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Red", Registration: "AB-1"}},
+		}, nil), sv, internal.DefaultFleet)
+		svReload := service.NewServiceReloadVehicleDefault(loaderVehicleStub{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Green", Registration: "AB-1"}},
+		}, rp)
+		sub, _, _, err := sv.Subscribe(internal.StreamQuery{Brand: "citroen"}, 0)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		// act
+		rl, err := svReload.Reload(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []int{1}, rl.Updated)
+		se := <-sub.Events()
+		require.Equal(t, internal.EventDatasetReloaded, se.Event.Type)
+		require.Nil(t, se.Event.Vehicle)
+		require.Empty(t, sub.Events())
+	})
+}
+
+// loaderVehicleStub is a struct that implements the LoaderVehicle interface with fixed vehicles
+type loaderVehicleStub map[int]internal.Vehicle
+
+// Load is a method that returns a copy of the vehicles
+func (l loaderVehicleStub) Load() (v map[int]internal.Vehicle, err error) {
+	v = make(map[int]internal.Vehicle, len(l))
+	for id, vh := range l {
+		v[id] = vh
+	}
+	return
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// jumpSpeedFactor is the factor of the max speed of the vehicle above which the distance between two readings is impossible
+	jumpSpeedFactor = 1.5
+	// jumpSlack is the distance allowed on top of the max speed for the error of the position and the odometer
+	jumpSlack internal.Distance = 0.5
+	// earthRadius is the mean radius of the earth
+	earthRadius internal.Distance = 6371.0088
+)
+
+// ServiceTelemetryDefault is a struct that represents the default service for telemetry
+type ServiceTelemetryDefault struct {
+	// mu is the lock that guards locks
+	mu sync.Mutex
+	// locks is a map of the lock of each vehicle, that serializes its ingestions so the check against its latest reading and the append are atomic
+	locks map[int]*sync.Mutex
+	// rp is the repository of the readings
+	rp internal.RepositoryTelemetry
+	// rpAlert is the repository of the alerts
+	rpAlert internal.RepositoryAlert
+	// rpVehicle is the repository of the vehicles that report the readings
+	rpVehicle internal.RepositoryReadVehicle
+	// now is the clock of the acknowledgements
+	now func() time.Time
+}
+
+// NewServiceTelemetryDefault is a function that returns a new instance of ServiceTelemetryDefault
+func NewServiceTelemetryDefault(rp internal.RepositoryTelemetry, rpAlert internal.RepositoryAlert, rpVehicle internal.RepositoryReadVehicle) *ServiceTelemetryDefault {
+	return &ServiceTelemetryDefault{rp: rp, rpAlert: rpAlert, rpVehicle: rpVehicle, locks: make(map[int]*sync.Mutex), now: time.Now}
+}
+
+// lock is a method that returns the lock of the ingestions of the vehicle
+func (s *ServiceTelemetryDefault) lock(vehicleId int) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mu, ok := s.locks[vehicleId]
+	if !ok {
+		mu = &sync.Mutex{}
+		s.locks[vehicleId] = mu
+	}
+	return mu
+}
+
+// Ingest is a method that validates and stores readings of an existing vehicle, and returns the alerts they raised
+func (s *ServiceTelemetryDefault) Ingest(ctx context.Context, vehicleId int, t []internal.Telemetry) (a []internal.Alert, err error) {
+	v, err := s.rpVehicle.FindById(vehicleId)
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+
+	mu := s.lock(vehicleId)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// validation: the whole batch, before anything is stored
+	last, hasLast, err := s.rp.FindLast(vehicleId)
+	if err != nil {
+		return
+	}
+	prev, hasPrev := last, hasLast
+	for i := range t {
+		t[i].VehicleId = vehicleId
+		reading := t[i]
+		switch {
+		case reading.Time.IsZero():
+			err = fmt.Errorf("%w: reading %d: time is required", internal.ErrServiceInvalidTelemetry, i+1)
+		case reading.Latitude < -90 || reading.Latitude > 90:
+			err = fmt.Errorf("%w: reading %d: latitude must be between -90 and 90", internal.ErrServiceInvalidTelemetry, i+1)
+		case reading.Longitude < -180 || reading.Longitude > 180:
+			err = fmt.Errorf("%w: reading %d: longitude must be between -180 and 180", internal.ErrServiceInvalidTelemetry, i+1)
+		case reading.Speed < 0:
+			err = fmt.Errorf("%w: reading %d: speed must not be negative", internal.ErrServiceInvalidTelemetry, i+1)
+		case reading.Odometer < 0:
+			err = fmt.Errorf("%w: reading %d: odometer must not be negative", internal.ErrServiceInvalidTelemetry, i+1)
+		case hasPrev && !reading.Time.After(prev.Time):
+			err = fmt.Errorf("%w: reading %d: time %s is not after the previous reading (%s)", internal.ErrServiceInvalidTelemetry, i+1, reading.Time.Format(time.RFC3339), prev.Time.Format(time.RFC3339))
+		}
+		if err != nil {
+			return
+		}
+		prev, hasPrev = reading, true
+	}
+
+	// alerts
+	prev, hasPrev = last, hasLast
+	a = make([]internal.Alert, 0)
+	for _, reading := range t {
+		if v.MaxSpeed > 0 && reading.Speed > v.MaxSpeed {
+			a = append(a, internal.Alert{
+				VehicleId: vehicleId,
+				Kind:      internal.AlertKindOverspeed,
+				Time:      reading.Time,
+				Message:   fmt.Sprintf("speed of %.1f km/h above the max speed of the vehicle (%.1f km/h)", reading.Speed, v.MaxSpeed),
+			})
+		}
+		if hasPrev {
+			if message, ok := s.jump(v, prev, reading); ok {
+				a = append(a, internal.Alert{
+					VehicleId: vehicleId,
+					Kind:      internal.AlertKindImpossibleJump,
+					Time:      reading.Time,
+					Message:   message,
+				})
+			}
+		}
+		prev, hasPrev = reading, true
+	}
+
+	if err = s.rp.Append(vehicleId, t); err != nil {
+		return
+	}
+	for i := range a {
+		if err = s.rpAlert.Save(&a[i]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// jump is a method that returns the description of the move from prev to t if the vehicle could not make it
+// - the odometer cannot go backwards
+// - the distance between the positions and the odometer difference cannot exceed jumpSpeedFactor times the max speed, plus jumpSlack
+func (s *ServiceTelemetryDefault) jump(v internal.Vehicle, prev internal.Telemetry, t internal.Telemetry) (message string, ok bool) {
+	if t.Odometer < prev.Odometer {
+		return fmt.Sprintf("odometer went back from %.1f km to %.1f km", prev.Odometer, t.Odometer), true
+	}
+	if v.MaxSpeed <= 0 {
+		return
+	}
+
+	hours := t.Time.Sub(prev.Time).Hours()
+	limit := internal.Distance(float64(v.MaxSpeed)*jumpSpeedFactor*hours) + jumpSlack
+	if d := haversine(prev, t); d > limit {
+		return fmt.Sprintf("moved %.1f km in %s, more than the vehicle can cover (%.1f km)", d, t.Time.Sub(prev.Time), limit), true
+	}
+	if d := t.Odometer - prev.Odometer; d > limit {
+		return fmt.Sprintf("odometer advanced %.1f km in %s, more than the vehicle can cover (%.1f km)", d, t.Time.Sub(prev.Time), limit), true
+	}
+	return
+}
+
+// haversine is a function that returns the great-circle distance between the positions of two readings
+func haversine(a internal.Telemetry, b internal.Telemetry) internal.Distance {
+	rad := math.Pi / 180
+	dLat := (b.Latitude - a.Latitude) * rad
+	dLon := (b.Longitude - a.Longitude) * rad
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(a.Latitude*rad)*math.Cos(b.Latitude*rad)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadius * internal.Distance(math.Asin(math.Min(1, math.Sqrt(h))))
+}
+
+// FindRange is a method that returns the readings of the vehicle that match the query, oldest first
+func (s *ServiceTelemetryDefault) FindRange(vehicleId int, query internal.TelemetryQuery) (t []internal.Telemetry, err error) {
+	switch {
+	case !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To):
+		err = fmt.Errorf("%w: from must be before to", internal.ErrServiceInvalidTelemetry)
+		return
+	case query.Step < 0:
+		err = fmt.Errorf("%w: step must not be negative", internal.ErrServiceInvalidTelemetry)
+		return
+	}
+	if _, err = s.rpVehicle.FindById(vehicleId); err != nil {
+		err = s.translate(err)
+		return
+	}
+
+	t, err = s.rp.FindRange(vehicleId, query.From, query.To)
+	if err != nil || query.Step == 0 {
+		return
+	}
+
+	// downsampling: buckets aligned to multiples of the step
+	buckets := make([]internal.Telemetry, 0)
+	var sum internal.Speed
+	var n int
+	for i, reading := range t {
+		start := reading.Time.Truncate(query.Step)
+		if i == 0 || !start.Equal(buckets[len(buckets)-1].Time) {
+			if n > 0 {
+				buckets[len(buckets)-1].Speed = sum / internal.Speed(n)
+			}
+			buckets = append(buckets, internal.Telemetry{VehicleId: vehicleId, Time: start})
+			sum, n = 0, 0
+		}
+		bucket := &buckets[len(buckets)-1]
+		bucket.Latitude, bucket.Longitude, bucket.Odometer = reading.Latitude, reading.Longitude, reading.Odometer
+		sum += reading.Speed
+		n++
+	}
+	if n > 0 {
+		buckets[len(buckets)-1].Speed = sum / internal.Speed(n)
+	}
+	t = buckets
+	return
+}
+
+// FindAlerts is a method that returns the alerts that match the query, oldest first
+func (s *ServiceTelemetryDefault) FindAlerts(query internal.AlertQuery) (a []internal.Alert, err error) {
+	a, err = s.rpAlert.Find(query)
+	return
+}
+
+// Acknowledge is a method that marks the alert as acknowledged by the principal of ctx
+func (s *ServiceTelemetryDefault) Acknowledge(ctx context.Context, id int) (a internal.Alert, err error) {
+	by := "anonymous"
+	if p, ok := internal.PrincipalFromContext(ctx); ok {
+		by = p.Subject
+	}
+	a, err = s.rpAlert.Acknowledge(id, by, s.now())
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// translate is a method that maps the repository errors to service errors
+func (s *ServiceTelemetryDefault) translate(err error) error {
+	switch {
+	case errors.Is(err, internal.ErrRepositoryVehicleNotFound):
+		return fmt.Errorf("%w: %v", internal.ErrServiceVehicleNotFound, err)
+	case errors.Is(err, internal.ErrRepositoryAlertNotFound):
+		return fmt.Errorf("%w: %v", internal.ErrServiceAlertNotFound, err)
+	}
+	return err
+}
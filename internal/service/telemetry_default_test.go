@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ServiceTelemetryDefault
+func TestServiceTelemetryDefault(t *testing.T) {
+	newService := func(capacity int) *service.ServiceTelemetryDefault {
+		vehicles := repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Transit", MaxSpeed: 120}},
+		}, nil)
+		return service.NewServiceTelemetryDefault(repository.NewRepositoryTelemetryRing(capacity), repository.NewRepositoryAlertMap(100), vehicles)
+	}
+	at := func(minute int) time.Time {
+		return time.Date(2024, time.May, 1, 8, minute, 0, 0, time.UTC)
+	}
+
+	t.Run("case 01: overspeed and impossible jumps raise alerts", func(t *testing.T) {
+		// arrange
+		sv := newService(100)
+
+		// act
+		a, err := sv.Ingest(context.Background(), 1, []internal.Telemetry{
+			{Time: at(0), Latitude: 40.4168, Longitude: -3.7038, Speed: 100, Odometer: 1000},
+			// ~1 km in a minute: 60 km/h, plausible
+			{Time: at(1), Latitude: 40.4258, Longitude: -3.7038, Speed: 130, Odometer: 1001},
+			// Madrid to Barcelona in a minute
+			{Time: at(2), Latitude: 41.3874, Longitude: 2.1686, Speed: 90, Odometer: 1002},
+			// odometer backwards
+			{Time: at(3), Latitude: 41.3874, Longitude: 2.1686, Speed: 0, Odometer: 900},
+		})
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, a, 3)
+		require.Equal(t, internal.AlertKindOverspeed, a[0].Kind)
+		require.Equal(t, at(1), a[0].Time)
+		require.Equal(t, internal.AlertKindImpossibleJump, a[1].Kind)
+		require.Equal(t, at(2), a[1].Time)
+		require.Equal(t, internal.AlertKindImpossibleJump, a[2].Kind)
+		require.Equal(t, at(3), a[2].Time)
+		pending, err := sv.FindAlerts(internal.AlertQuery{Unacknowledged: true})
+		require.NoError(t, err)
+		require.Len(t, pending, 3)
+	})
+
+	t.Run("case 02: a batch with an invalid reading is rejected whole", func(t *testing.T) {
+		// arrange
+		sv := newService(100)
+		_, err := sv.Ingest(context.Background(), 1, []internal.Telemetry{{Time: at(5)}})
+		require.NoError(t, err)
+
+		// act
+		_, errLatitude := sv.Ingest(context.Background(), 1, []internal.Telemetry{{Time: at(6)}, {Time: at(7), Latitude: 91}})
+		_, errOrder := sv.Ingest(context.Background(), 1, []internal.Telemetry{{Time: at(5)}})
+		_, errVehicle := sv.Ingest(context.Background(), 2, []internal.Telemetry{{Time: at(6)}})
+
+		// assert
+		require.ErrorIs(t, errLatitude, internal.ErrServiceInvalidTelemetry)
+		require.ErrorIs(t, errOrder, internal.ErrServiceInvalidTelemetry)
+		require.ErrorIs(t, errVehicle, internal.ErrServiceVehicleNotFound)
+		readings, err := sv.FindRange(1, internal.TelemetryQuery{})
+		require.NoError(t, err)
+		require.Len(t, readings, 1)
+	})
+
+	t.Run("case 03: the store is bounded and the range is downsampled", func(t *testing.T) {
+		// arrange
+		sv := newService(8)
+		readings := make([]internal.Telemetry, 0, 10)
+		for i := 0; i < 10; i++ {
+			readings = append(readings, internal.Telemetry{Time: at(i), Speed: internal.Speed(10 * i), Odometer: internal.Distance(i)})
+		}
+		_, err := sv.Ingest(context.Background(), 1, readings)
+		require.NoError(t, err)
+
+		// act
+		all, errAll := sv.FindRange(1, internal.TelemetryQuery{})
+		buckets, errBuckets := sv.FindRange(1, internal.TelemetryQuery{From: at(4), To: at(10), Step: 5 * time.Minute})
+
+		// assert
+		require.NoError(t, errAll)
+		require.Len(t, all, 8)
+		require.Equal(t, at(2), all[0].Time)
+		require.NoError(t, errBuckets)
+		expected := []internal.Telemetry{
+			{VehicleId: 1, Time: at(0), Speed: 40, Odometer: 4},
+			{VehicleId: 1, Time: at(5), Speed: 70, Odometer: 9},
+		}
+		require.Equal(t, expected, buckets)
+	})
+
+	t.Run("case 04: acknowledging keeps the first acknowledgement", func(t *testing.T) {
+		// arrange
+		sv := newService(100)
+		a, err := sv.Ingest(context.Background(), 1, []internal.Telemetry{{Time: at(0), Speed: 150}})
+		require.NoError(t, err)
+		ctx := internal.ContextWithPrincipal(context.Background(), internal.Principal{Subject: "alice", Role: internal.RoleEditor})
+
+		// act
+		first, errFirst := sv.Acknowledge(ctx, a[0].Id)
+		second, errSecond := sv.Acknowledge(context.Background(), a[0].Id)
+		_, errMissing := sv.Acknowledge(ctx, 99)
+
+		// assert
+		require.NoError(t, errFirst)
+		require.NoError(t, errSecond)
+		require.Equal(t, "alice", first.AcknowledgedBy)
+		require.Equal(t, first, second)
+		require.ErrorIs(t, errMissing, internal.ErrServiceAlertNotFound)
+		pending, err := sv.FindAlerts(internal.AlertQuery{Unacknowledged: true})
+		require.NoError(t, err)
+		require.Empty(t, pending)
+	})
+
+	t.Run("case 05: a store without capacity keeps the latest reading", func(t *testing.T) {
+		// arrange
+		sv := newService(0)
+
+		// act
+		_, err := sv.Ingest(context.Background(), 1, []internal.Telemetry{{Time: at(0)}, {Time: at(1)}})
+		readings, errFind := sv.FindRange(1, internal.TelemetryQuery{})
+
+		// assert
+		require.NoError(t, err)
+		require.NoError(t, errFind)
+		require.Len(t, readings, 1)
+		require.Equal(t, at(1), readings[0].Time)
+	})
+}
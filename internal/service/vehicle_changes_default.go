@@ -0,0 +1,137 @@
+package service
+
+import (
+	"app/internal"
+	"sort"
+	"sync"
+)
+
+// NewServiceChangesVehicleDefault is a function that returns a new instance of ServiceChangesVehicleDefault
+// - rp: repository of the fleet, read for the whole fleet on a resync
+// - tombstones: number of deleted ids kept, a client that misses an evicted one has to resync
+func NewServiceChangesVehicleDefault(rp internal.RepositoryReadVehicle, tombstones int) *ServiceChangesVehicleDefault {
+	return &ServiceChangesVehicleDefault{
+		rp:       rp,
+		changes:  make(map[int]changeVehicle),
+		capacity: tombstones,
+	}
+}
+
+// ServiceChangesVehicleDefault is a struct that represents the default feed of the changes of the vehicles of a fleet
+// - only the last change of each vehicle is kept: the upserts are bounded by the fleet, the tombstones by the capacity
+// - a reload starts the sequence over from the instant of the load in microseconds, higher than any position of a previous load
+type ServiceChangesVehicleDefault struct {
+	// mu is the lock that guards the sequence and the changes
+	mu sync.Mutex
+	// rp is the repository of the fleet
+	rp internal.RepositoryReadVehicle
+	// seq is the position of the last change
+	seq uint64
+	// floor is the position before which the changes are not complete anymore (the load, or the last evicted tombstone)
+	floor uint64
+	// changes is the last change of each vehicle
+	changes map[int]changeVehicle
+	// tombstones is the number of changes that are deletes
+	tombstones int
+	// capacity is the number of tombstones kept
+	capacity int
+}
+
+// changeVehicle is a struct that represents the last change of a vehicle
+type changeVehicle struct {
+	// seq is the position of the change
+	seq uint64
+	// vehicle is the state of the vehicle after the change (nil: deleted)
+	vehicle *internal.Vehicle
+}
+
+// Publish is a method that advances the sequence with the change of the event
+func (s *ServiceChangesVehicleDefault) Publish(e internal.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e.Type == internal.EventDatasetReloaded {
+		s.seq = max(s.seq+1, uint64(e.Time.UnixMicro()))
+		s.floor = s.seq
+		s.changes = make(map[int]changeVehicle)
+		s.tombstones = 0
+		return
+	}
+	if e.Vehicle == nil {
+		return
+	}
+
+	s.seq++
+	if prev, ok := s.changes[e.Vehicle.Id]; ok && prev.vehicle == nil {
+		s.tombstones--
+	}
+	c := changeVehicle{seq: s.seq}
+	if e.Type == internal.EventVehicleDeleted {
+		s.tombstones++
+	} else {
+		v := *e.Vehicle
+		c.vehicle = &v
+	}
+	s.changes[e.Vehicle.Id] = c
+
+	// evict the oldest tombstone
+	if s.tombstones > s.capacity {
+		var oldest int
+		var oldestSeq uint64
+		for id, c := range s.changes {
+			if c.vehicle == nil && (oldestSeq == 0 || c.seq < oldestSeq) {
+				oldest, oldestSeq = id, c.seq
+			}
+		}
+		s.floor = oldestSeq
+		delete(s.changes, oldest)
+		s.tombstones--
+	}
+}
+
+// Since is a method that returns the changes after the position
+// - the position is read before the fleet on a resync: a change in between is sent again by the next request, which is harmless
+func (s *ServiceChangesVehicleDefault) Since(since uint64) (c internal.ChangesVehicle, err error) {
+	c, ok := s.since(since)
+	if ok {
+		return
+	}
+
+	// resync
+	v, err := s.rp.FindAll()
+	if err != nil {
+		return
+	}
+	c.Resync = true
+	c.Upserts = make([]internal.Vehicle, 0, len(v))
+	for _, value := range v {
+		c.Upserts = append(c.Upserts, value)
+	}
+	sort.Slice(c.Upserts, func(i, j int) bool { return c.Upserts[i].Id < c.Upserts[j].Id })
+	return
+}
+
+// since is a method that returns the changes kept after the position, and false if they are not complete
+func (s *ServiceChangesVehicleDefault) since(since uint64) (c internal.ChangesVehicle, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c = internal.ChangesVehicle{Seq: s.seq, Upserts: make([]internal.Vehicle, 0), Tombstones: make([]int, 0)}
+	if since == 0 || since < s.floor || since > s.seq {
+		return
+	}
+	for id, ch := range s.changes {
+		if ch.seq <= since {
+			continue
+		}
+		if ch.vehicle == nil {
+			c.Tombstones = append(c.Tombstones, id)
+			continue
+		}
+		c.Upserts = append(c.Upserts, *ch.vehicle)
+	}
+	sort.Slice(c.Upserts, func(i, j int) bool { return c.Upserts[i].Id < c.Upserts[j].Id })
+	sort.Ints(c.Tombstones)
+	ok = true
+	return
+}
@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ServiceChangesVehicleDefault
+func TestServiceChangesVehicleDefault(t *testing.T) {
+	vehicles := func() *repository.RepositoryReadVehicleMap {
+		return repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Red"}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "Blue"}},
+			3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Toyota", Color: "Red"}},
+		}, nil)
+	}
+	loaded := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+	event := func(t internal.EventType, id int, color string) internal.Event {
+		e := internal.NewEvent(t, internal.DefaultFleet, loaded)
+		e.Vehicle = &internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: color}}
+		return e
+	}
+	ids := func(v []internal.Vehicle) (s []int) {
+		for _, vh := range v {
+			s = append(s, vh.Id)
+		}
+		return
+	}
+
+	t.Run("case 01: the changes after a position are compacted to the last state of each vehicle", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceChangesVehicleDefault(vehicles(), 10)
+		sv.Publish(internal.NewEvent(internal.EventDatasetReloaded, internal.DefaultFleet, loaded))
+		full, err := sv.Since(0)
+		require.NoError(t, err)
+
+		// act
+		sv.Publish(event(internal.EventVehicleUpdated, 1, "Green"))
+		sv.Publish(event(internal.EventVehicleDeleted, 2, "Blue"))
+		sv.Publish(event(internal.EventVehicleUpdated, 1, "Black"))
+		sv.Publish(event(internal.EventVehicleCreated, 4, "White"))
+		changes, errChanges := sv.Since(full.Seq)
+		none, errNone := sv.Since(changes.Seq)
+
+		// assert
+		require.Equal(t, uint64(loaded.UnixMicro()), full.Seq)
+		require.True(t, full.Resync)
+		require.Equal(t, []int{1, 2, 3}, ids(full.Upserts))
+		require.NoError(t, errChanges)
+		require.Equal(t, full.Seq+4, changes.Seq)
+		require.False(t, changes.Resync)
+		require.Equal(t, []int{1, 4}, ids(changes.Upserts))
+		require.Equal(t, "Black", changes.Upserts[0].Color)
+		require.Equal(t, []int{2}, changes.Tombstones)
+		require.NoError(t, errNone)
+		require.Equal(t, changes.Seq, none.Seq)
+		require.False(t, none.Resync)
+		require.Empty(t, none.Upserts)
+		require.Empty(t, none.Tombstones)
+	})
+
+	t.Run("case 02: positions before a reload or an evicted tombstone, and unknown ones, resync", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceChangesVehicleDefault(vehicles(), 1)
+		sv.Publish(internal.NewEvent(internal.EventDatasetReloaded, internal.DefaultFleet, loaded))
+		first, err := sv.Since(0)
+		require.NoError(t, err)
+		sv.Publish(event(internal.EventVehicleDeleted, 1, "Red"))
+		sv.Publish(event(internal.EventVehicleDeleted, 2, "Blue"))
+
+		// act
+		evicted, errEvicted := sv.Since(first.Seq)
+		kept, errKept := sv.Since(first.Seq + 1)
+		unknown, errUnknown := sv.Since(first.Seq + 99)
+		sv.Publish(internal.NewEvent(internal.EventDatasetReloaded, internal.DefaultFleet, loaded))
+		reloaded, errReloaded := sv.Since(first.Seq + 2)
+
+		// assert
+		require.NoError(t, errEvicted)
+		require.True(t, evicted.Resync)
+		require.NoError(t, errKept)
+		require.False(t, kept.Resync)
+		require.Equal(t, []int{2}, kept.Tombstones)
+		require.NoError(t, errUnknown)
+		require.True(t, unknown.Resync)
+		require.NoError(t, errReloaded)
+		require.True(t, reloaded.Resync)
+		require.Equal(t, first.Seq+3, reloaded.Seq)
+	})
+}
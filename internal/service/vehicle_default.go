@@ -1,85 +1,660 @@
-package service
-
-import "app/internal"
-
-// ServiceVehicleDefault is a struct that represents the default service for vehicles
-type ServiceVehicleDefault struct {
-	// rp is the repository that will be used by the service
-	rp internal.RepositoryReadVehicle
-}
-
-// NewServiceVehicleDefault is a function that returns a new instance of ServiceVehicleDefault
-func NewServiceVehicleDefault(rp internal.RepositoryReadVehicle) *ServiceVehicleDefault {
-	return &ServiceVehicleDefault{rp: rp}
-}
-
-// FindByColorAndYear is a method that returns a map of vehicles that match the color and fabrication year
-func (s *ServiceVehicleDefault) FindByColorAndYear(color string, fabricationYear int) (v map[int]internal.Vehicle, err error) {
-	v, err = s.rp.FindByColorAndYear(color, fabricationYear)
-	return
-}
-
-// FindByBrandAndYearRange is a method that returns a map of vehicles that match the brand and a range of fabrication years
-func (s *ServiceVehicleDefault) FindByBrandAndYearRange(brand string, startYear int, endYear int) (v map[int]internal.Vehicle, err error) {
-	v, err = s.rp.FindByBrandAndYearRange(brand, startYear, endYear)
-	return
-}
-
-// AverageMaxSpeedByBrand is a method that returns the average speed of the vehicles by brand
-func (s *ServiceVehicleDefault) AverageMaxSpeedByBrand(brand string) (a float64, err error) {
-	// get vehicles by brand
-	v, err := s.rp.FindByBrand(brand)
-	if err != nil {
-		return
-	}
-
-	// check if there are vehicles
-	if len(v) == 0 {
-		err = internal.ErrServiceNoVehicles
-		return
-	}
-
-	var totalSpeed float64
-	for _, vehicle := range v {
-		totalSpeed += vehicle.MaxSpeed
-	}
-
-	a = totalSpeed / float64(len(v))
-	return
-}
-		
-// AverageCapacityByBrand is a method that returns the average capacity of the vehicles by brand
-func (s *ServiceVehicleDefault) AverageCapacityByBrand(brand string) (a int, err error) {
-	// get vehicles by brand
-	v, err := s.rp.FindByBrand(brand)
-	if err != nil {
-		return
-	}
-	
-	// check if there are vehicles
-	if len(v) == 0 {
-		err = internal.ErrServiceNoVehicles
-		return
-	}
-
-	var totalCapacity int
-	for _, vehicle := range v {
-		totalCapacity += vehicle.Capacity
-	}
-
-	a = totalCapacity / len(v)
-	return
-}
-
-// SearchByWeightRange
-func (s *ServiceVehicleDefault) SearchByWeightRange(query internal.SearchQuery, ok bool) (v map[int]internal.Vehicle, err error) {
-	// check if query is set
-	if !ok {
-		v, err = s.rp.FindAll()
-		return
-	}
-
-	v, err = s.rp.FindByWeightRange(query.FromWeight, query.ToWeight)
-	return
-}
-	
\ No newline at end of file
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ServiceVehicleDefault is a struct that represents the default service for vehicles
+type ServiceVehicleDefault struct {
+	// rp is the repository that will be used by the service
+	rp internal.RepositoryVehicle
+	// rr are the rules of the registrations, used to validate their format (nil: not validated)
+	rr internal.RegistrationRules
+	// nz is the normalizer of the string attributes, used to group the vehicles by brand
+	nz internal.Normalizer
+}
+
+// ConfigServiceVehicleDefault is a struct that represents the configuration for ServiceVehicleDefault
+type ConfigServiceVehicleDefault struct {
+	// RegistrationRules are the rules of the registrations of the jurisdiction (nil: any registration is valid)
+	RegistrationRules internal.RegistrationRules
+	// Normalizer is the normalizer of the string attributes, the same as the one of the repository (nil: compared in lower case)
+	Normalizer internal.Normalizer
+}
+
+// NewServiceVehicleDefault is a function that returns a new instance of ServiceVehicleDefault
+func NewServiceVehicleDefault(rp internal.RepositoryVehicle) *ServiceVehicleDefault {
+	return NewServiceVehicleDefaultWithConfig(rp, nil)
+}
+
+// NewServiceVehicleDefaultWithConfig is a function that returns a new instance of ServiceVehicleDefault with a configuration
+func NewServiceVehicleDefaultWithConfig(rp internal.RepositoryVehicle, cfg *ConfigServiceVehicleDefault) *ServiceVehicleDefault {
+	// default values
+	defaultConfig := &ConfigServiceVehicleDefault{
+		Normalizer: internal.NormalizerNone{},
+	}
+	if cfg != nil {
+		if cfg.RegistrationRules != nil {
+			defaultConfig.RegistrationRules = cfg.RegistrationRules
+		}
+		if cfg.Normalizer != nil {
+			defaultConfig.Normalizer = cfg.Normalizer
+		}
+	}
+
+	return &ServiceVehicleDefault{rp: rp, rr: defaultConfig.RegistrationRules, nz: defaultConfig.Normalizer}
+}
+
+// FindById is a method that returns the vehicle that matches the id
+func (s *ServiceVehicleDefault) FindById(id int) (v internal.Vehicle, err error) {
+	v, err = s.rp.FindById(id)
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// FindByRegistration is a method that returns a map of vehicles that match the registration
+func (s *ServiceVehicleDefault) FindByRegistration(registration string) (v map[int]internal.Vehicle, err error) {
+	v, err = s.rp.FindByRegistration(registration)
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// FindByColorAndYear is a method that returns a map of vehicles that match the color and fabrication year
+func (s *ServiceVehicleDefault) FindByColorAndYear(color string, fabricationYear int) (v map[int]internal.Vehicle, err error) {
+	if err = (internal.VehicleFilter{Color: color}).Validate(); err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrServiceInvalidFilter, err)
+		return
+	}
+	v, err = s.rp.FindByColorAndYear(color, fabricationYear)
+	return
+}
+
+// FindByBrandAndYearRange is a method that returns a map of vehicles that match the brand and a range of fabrication years
+func (s *ServiceVehicleDefault) FindByBrandAndYearRange(brand string, startYear int, endYear int) (v map[int]internal.Vehicle, err error) {
+	if err = (internal.VehicleFilter{Brand: brand}).Validate(); err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrServiceInvalidFilter, err)
+		return
+	}
+	v, err = s.rp.FindByBrandAndYearRange(brand, startYear, endYear)
+	return
+}
+
+// AverageMaxSpeedByBrand is a method that returns the average speed of the vehicles by brand
+func (s *ServiceVehicleDefault) AverageMaxSpeedByBrand(brand string) (a internal.Speed, err error) {
+	// get vehicles by brand
+	v, err := s.brand(brand)
+	if err != nil {
+		return
+	}
+
+	var totalSpeed internal.Speed
+	for _, vehicle := range v {
+		totalSpeed += vehicle.MaxSpeed
+	}
+
+	a = totalSpeed / internal.Speed(len(v))
+	return
+}
+		
+// AverageCapacityByBrand is a method that returns the average capacity of the vehicles by brand
+func (s *ServiceVehicleDefault) AverageCapacityByBrand(brand string) (a int, err error) {
+	// get vehicles by brand
+	v, err := s.brand(brand)
+	if err != nil {
+		return
+	}
+
+	var totalCapacity int
+	for _, vehicle := range v {
+		totalCapacity += vehicle.Capacity
+	}
+
+	a = totalCapacity / len(v)
+	return
+}
+
+// Compare is a method that returns the vehicles that match the ids side by side
+// - the percentiles are over the vehicles of the same brand, grouped as for the averages by brand, and over the fleet
+// - the fleet is read once, the distributions of the fleet and of each brand are sorted once per attribute
+func (s *ServiceVehicleDefault) Compare(ids []int) (c internal.Comparison, err error) {
+	// check ids
+	if len(ids) < 2 {
+		err = fmt.Errorf("%w: at least two ids are required", internal.ErrServiceInvalidCompare)
+		return
+	}
+	if len(ids) > internal.MaxCompareIds {
+		err = fmt.Errorf("%w: at most %d ids are allowed", internal.ErrServiceInvalidCompare, internal.MaxCompareIds)
+		return
+	}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			err = fmt.Errorf("%w: id %d is repeated", internal.ErrServiceInvalidCompare, id)
+			return
+		}
+		seen[id] = true
+	}
+
+	// get vehicles, the vehicles of their brands and the fleet
+	c.Vehicles = make([]internal.Vehicle, 0, len(ids))
+	for _, id := range ids {
+		var v internal.Vehicle
+		v, err = s.rp.FindById(id)
+		if err != nil {
+			err = s.translate(err)
+			return
+		}
+		c.Vehicles = append(c.Vehicles, v)
+	}
+	fleet, err := s.rp.FindAll()
+	if err != nil {
+		return
+	}
+	brands := make(map[string][]internal.Vehicle)
+	for _, v := range c.Vehicles {
+		brands[s.nz.Key("brand", v.Brand)] = nil
+	}
+	all := make([]internal.Vehicle, 0, len(fleet))
+	for _, v := range fleet {
+		all = append(all, v)
+		key := s.nz.Key("brand", v.Brand)
+		if group, ok := brands[key]; ok {
+			brands[key] = append(group, v)
+		}
+	}
+
+	// compare each attribute
+	c.Rows = make([]internal.ComparisonRow, 0, len(internal.VehicleMetrics))
+	for _, m := range internal.VehicleMetrics {
+		row := internal.ComparisonRow{
+			Metric:          m,
+			Values:          make([]float64, 0, len(c.Vehicles)),
+			Best:            make([]int, 0),
+			Worst:           make([]int, 0),
+			PercentileBrand: make([]float64, 0, len(c.Vehicles)),
+			PercentileFleet: make([]float64, 0, len(c.Vehicles)),
+		}
+		better := func(a float64, b float64) bool {
+			if m.HigherIsBetter() {
+				return a > b
+			}
+			return a < b
+		}
+		distributionFleet := distribution(m, all)
+		distributionBrands := make(map[string][]float64, len(brands))
+		for brand, group := range brands {
+			distributionBrands[brand] = distribution(m, group)
+		}
+		best, worst := m.Value(c.Vehicles[0]), m.Value(c.Vehicles[0])
+		for _, v := range c.Vehicles {
+			value := m.Value(v)
+			row.Values = append(row.Values, value)
+			row.PercentileBrand = append(row.PercentileBrand, percentile(value, distributionBrands[s.nz.Key("brand", v.Brand)]))
+			row.PercentileFleet = append(row.PercentileFleet, percentile(value, distributionFleet))
+			if better(value, best) {
+				best = value
+			}
+			if better(worst, value) {
+				worst = value
+			}
+		}
+		for i, v := range c.Vehicles {
+			if row.Values[i] == best {
+				row.Best = append(row.Best, v.Id)
+			}
+			if row.Values[i] == worst {
+				row.Worst = append(row.Worst, v.Id)
+			}
+		}
+		c.Rows = append(c.Rows, row)
+	}
+	return
+}
+
+// FindByFilter is a method that returns a map of vehicles that match every filter
+func (s *ServiceVehicleDefault) FindByFilter(f internal.VehicleFilter) (v map[int]internal.Vehicle, err error) {
+	if err = f.Validate(); err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrServiceInvalidFilter, err)
+		return
+	}
+
+	v, err = s.rp.FindByFilter(f)
+	return
+}
+
+// Average is a method that returns the average of an attribute among the vehicles that match the filter, in the stored units
+func (s *ServiceVehicleDefault) Average(query internal.AverageQuery) (a float64, err error) {
+	if !slices.Contains(internal.VehicleMetrics, query.Metric) {
+		err = fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidFilter, internal.ErrInvalidVehicleMetric, query.Metric)
+		return
+	}
+	v, err := s.FindByFilter(query.VehicleFilter)
+	if err != nil {
+		return
+	}
+
+	// check if there are vehicles
+	if len(v) == 0 {
+		err = internal.ErrServiceNoVehicles
+		return
+	}
+
+	var total float64
+	for _, vehicle := range v {
+		total += query.Metric.Value(vehicle)
+	}
+	a = total / float64(len(v))
+	return
+}
+
+// Histogram is a method that returns the distribution of an attribute among the vehicles that match the filter
+// - numeric buckets: the edges of the query, or bins of the same width between the lowest and the highest value
+// - categorical buckets: one per value of the attribute
+func (s *ServiceVehicleDefault) Histogram(query internal.HistogramQuery) (h internal.Histogram, err error) {
+	if err = s.validateHistogram(query); err != nil {
+		return
+	}
+	v, err := s.FindByFilter(query.VehicleFilter)
+	if err != nil {
+		return
+	}
+
+	// buckets and the bucket of each vehicle (-1: out of the buckets)
+	bucket := make(map[int]int, len(v))
+	if query.Metric != "" {
+		edges := query.Edges
+		if len(edges) == 0 {
+			bins := query.Bins
+			if bins == 0 {
+				bins = 10
+			}
+			edges = equalWidth(query.Metric, bins, v)
+		}
+		for i := 0; i+1 < len(edges); i++ {
+			h.Buckets = append(h.Buckets, internal.HistogramBucket{Lower: edges[i], Upper: edges[i+1]})
+		}
+		for id, vehicle := range v {
+			value := query.Metric.Value(vehicle)
+			bucket[id] = -1
+			if len(edges) < 2 || value < edges[0] || value > edges[len(edges)-1] {
+				continue
+			}
+			// - last edge lower or equal to the value, the last bucket includes its upper bound
+			i := sort.Search(len(edges), func(i int) bool { return edges[i] > value }) - 1
+			bucket[id] = min(i, len(edges)-2)
+		}
+	} else {
+		counts := make(map[string]int)
+		for _, vehicle := range v {
+			counts[query.Category.Value(vehicle)]++
+		}
+		for value := range counts {
+			h.Buckets = append(h.Buckets, internal.HistogramBucket{Value: value})
+		}
+		sort.Slice(h.Buckets, func(i, j int) bool {
+			a, b := h.Buckets[i].Value, h.Buckets[j].Value
+			if counts[a] != counts[b] {
+				return counts[a] > counts[b]
+			}
+			return a < b
+		})
+		index := make(map[string]int, len(h.Buckets))
+		for i, b := range h.Buckets {
+			index[b.Value] = i
+		}
+		for id, vehicle := range v {
+			bucket[id] = index[query.Category.Value(vehicle)]
+		}
+	}
+
+	// series: a single one without group by
+	series := make(map[string]*internal.HistogramSeries)
+	if query.GroupBy == "" {
+		series[""] = &internal.HistogramSeries{Counts: make([]int, len(h.Buckets))}
+	}
+	for id, vehicle := range v {
+		var group string
+		if query.GroupBy != "" {
+			group = query.GroupBy.Value(vehicle)
+		}
+		sr, ok := series[group]
+		if !ok {
+			sr = &internal.HistogramSeries{Group: group, Counts: make([]int, len(h.Buckets))}
+			series[group] = sr
+		}
+		if bucket[id] < 0 {
+			continue
+		}
+		sr.Counts[bucket[id]]++
+		sr.Total++
+	}
+	h.Series = make([]internal.HistogramSeries, 0, len(series))
+	for _, sr := range series {
+		h.Series = append(h.Series, *sr)
+	}
+	sort.Slice(h.Series, func(i, j int) bool { return h.Series[i].Group < h.Series[j].Group })
+	return
+}
+
+// Trends is a method that returns the average of an attribute by fabrication year among the vehicles that match the filter
+// - the years go from the first to the last fabrication year of the vehicles, the same for every series
+func (s *ServiceVehicleDefault) Trends(query internal.TrendQuery) (t internal.Trend, err error) {
+	if err = s.validateTrend(query); err != nil {
+		return
+	}
+	window := query.Window
+	if window == 0 {
+		window = 3
+	}
+	v, err := s.FindByFilter(query.VehicleFilter)
+	if err != nil {
+		return
+	}
+
+	// sum and count of the attribute by group and year
+	type acc struct {
+		sum   float64
+		count int
+	}
+	groups := make(map[string]map[int]acc)
+	if query.GroupBy == "" {
+		groups[""] = make(map[int]acc)
+	}
+	first, last := math.MaxInt, math.MinInt
+	for _, vehicle := range v {
+		var group string
+		if query.GroupBy != "" {
+			group = query.GroupBy.Value(vehicle)
+		}
+		if groups[group] == nil {
+			groups[group] = make(map[int]acc)
+		}
+		a := groups[group][vehicle.FabricationYear]
+		a.sum += query.Metric.Value(vehicle)
+		a.count++
+		groups[group][vehicle.FabricationYear] = a
+		first, last = min(first, vehicle.FabricationYear), max(last, vehicle.FabricationYear)
+	}
+	if len(v) > 0 && last-first+1 > internal.MaxTrendYears {
+		err = fmt.Errorf("%w: the fabrication years span more than %d years, narrow them with the year filters", internal.ErrServiceInvalidTrend, internal.MaxTrendYears)
+		return
+	}
+	for year := first; year <= last; year++ {
+		t.Years = append(t.Years, year)
+	}
+
+	// dense series
+	t.Series = make([]internal.TrendSeries, 0, len(groups))
+	for group, years := range groups {
+		sr := internal.TrendSeries{Group: group, Points: make([]internal.TrendPoint, 0, len(t.Years))}
+		for i, year := range t.Years {
+			p := internal.TrendPoint{Year: year, Count: years[year].count}
+			if p.Count > 0 {
+				average := years[year].sum / float64(p.Count)
+				p.Average = &average
+			}
+			var sum float64
+			var count int
+			for y := year - window + 1; y <= year; y++ {
+				sum, count = sum+years[y].sum, count+years[y].count
+			}
+			if count > 0 {
+				moving := sum / float64(count)
+				p.MovingAverage = &moving
+			}
+			if i > 0 && p.Average != nil && sr.Points[i-1].Average != nil {
+				delta := *p.Average - *sr.Points[i-1].Average
+				p.Delta = &delta
+			}
+			sr.Points = append(sr.Points, p)
+		}
+		t.Series = append(t.Series, sr)
+	}
+	sort.Slice(t.Series, func(i, j int) bool { return t.Series[i].Group < t.Series[j].Group })
+	return
+}
+
+// SearchByWeightRange
+func (s *ServiceVehicleDefault) SearchByWeightRange(query internal.SearchQuery, ok bool) (v map[int]internal.Vehicle, err error) {
+	// check if query is set
+	if !ok {
+		v, err = s.rp.FindAll()
+		return
+	}
+
+	v, err = s.rp.FindByWeightRange(query.FromWeight, query.ToWeight)
+	return
+}
+	
+
+// Create is a method that validates and adds a vehicle. A zero id is replaced by the next free id
+func (s *ServiceVehicleDefault) Create(ctx context.Context, v *internal.Vehicle) (err error) {
+	if err = s.validate(v); err != nil {
+		return
+	}
+
+	if err = s.rp.Save(ctx, v); err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// Update is a method that validates and replaces the vehicle with the same id
+func (s *ServiceVehicleDefault) Update(ctx context.Context, v *internal.Vehicle) (err error) {
+	if err = s.validate(v); err != nil {
+		return
+	}
+
+	if err = s.rp.Update(ctx, v); err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// Delete is a method that removes the vehicle that matches the id
+func (s *ServiceVehicleDefault) Delete(ctx context.Context, id int) (err error) {
+	if err = s.rp.Delete(ctx, id); err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// FindHistory is a method that returns every version of the vehicle, oldest first
+func (s *ServiceVehicleDefault) FindHistory(id int) (h []internal.VehicleVersion, err error) {
+	h, err = s.rp.FindHistory(id)
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// Restore is a method that undeletes the vehicle with its last state
+func (s *ServiceVehicleDefault) Restore(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	v, err = s.rp.Restore(ctx, id)
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// AsOf is a method that returns a read only service over the fleet as it was at the instant
+func (s *ServiceVehicleDefault) AsOf(t time.Time) (sv internal.ServiceVehicle, err error) {
+	rp, err := s.rp.AsOf(t)
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+
+	sv = NewServiceVehicleDefaultWithConfig(rp, &ConfigServiceVehicleDefault{RegistrationRules: s.rr, Normalizer: s.nz})
+	return
+}
+
+// brand is a method that returns the vehicles of the brand, the group of the aggregations by brand
+func (s *ServiceVehicleDefault) brand(brand string) (v map[int]internal.Vehicle, err error) {
+	if err = (internal.VehicleFilter{Brand: brand}).Validate(); err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrServiceInvalidFilter, err)
+		return
+	}
+	v, err = s.rp.FindByBrand(brand)
+	if err != nil {
+		return
+	}
+
+	// check if there are vehicles
+	if len(v) == 0 {
+		err = internal.ErrServiceNoVehicles
+		return
+	}
+	return
+}
+
+// distribution is a function that returns the values of the attribute of the vehicles, ascending
+func distribution(m internal.VehicleMetric, v []internal.Vehicle) (d []float64) {
+	d = make([]float64, 0, len(v))
+	for _, vehicle := range v {
+		d = append(d, m.Value(vehicle))
+	}
+	sort.Float64s(d)
+	return
+}
+
+// percentile is a function that returns the percentage of the values of a distribution lower or equal to the value
+func percentile(value float64, d []float64) float64 {
+	n := sort.Search(len(d), func(i int) bool { return d[i] > value })
+	return 100 * float64(n) / float64(len(d))
+}
+
+// equalWidth is a function that returns the edges of bins of the same width between the lowest and the highest value of the attribute
+// - a single bucket if every value is the same, none without vehicles
+func equalWidth(m internal.VehicleMetric, bins int, v map[int]internal.Vehicle) (edges []float64) {
+	if len(v) == 0 {
+		return
+	}
+	lower, upper := math.Inf(1), math.Inf(-1)
+	for _, vehicle := range v {
+		lower, upper = math.Min(lower, m.Value(vehicle)), math.Max(upper, m.Value(vehicle))
+	}
+	if lower == upper {
+		return []float64{lower, upper}
+	}
+
+	width := (upper - lower) / float64(bins)
+	edges = make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = lower + float64(i)*width
+	}
+	edges[bins] = upper
+	return
+}
+
+// validateHistogram is a method that checks the field, the buckets and the group of a histogram
+func (s *ServiceVehicleDefault) validateHistogram(query internal.HistogramQuery) (err error) {
+	switch {
+	case query.Metric == "" && query.Category == "":
+		return fmt.Errorf("%w: field is required", internal.ErrServiceInvalidHistogram)
+	case query.Metric != "" && query.Category != "":
+		return fmt.Errorf("%w: field is either numeric or categorical", internal.ErrServiceInvalidHistogram)
+	case query.Metric != "" && !slices.Contains(internal.VehicleMetrics, query.Metric):
+		return fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidHistogram, internal.ErrInvalidVehicleMetric, query.Metric)
+	case query.Category != "" && !slices.Contains(internal.VehicleCategories, query.Category):
+		return fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidHistogram, internal.ErrInvalidVehicleCategory, query.Category)
+	case query.GroupBy != "" && !slices.Contains(internal.VehicleCategories, query.GroupBy):
+		return fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidHistogram, internal.ErrInvalidVehicleCategory, query.GroupBy)
+	case query.GroupBy != "" && query.GroupBy == query.Category:
+		return fmt.Errorf("%w: group by the field itself", internal.ErrServiceInvalidHistogram)
+	case query.Category != "" && (query.Bins != 0 || len(query.Edges) > 0):
+		return fmt.Errorf("%w: bins and edges are only for numeric fields", internal.ErrServiceInvalidHistogram)
+	case query.Bins < 0 || query.Bins > 1000:
+		return fmt.Errorf("%w: bins must be between 1 and 1000", internal.ErrServiceInvalidHistogram)
+	case query.Bins != 0 && len(query.Edges) > 0:
+		return fmt.Errorf("%w: bins and edges are exclusive", internal.ErrServiceInvalidHistogram)
+	case len(query.Edges) == 1:
+		return fmt.Errorf("%w: at least two edges are required", internal.ErrServiceInvalidHistogram)
+	}
+	for i := 1; i < len(query.Edges); i++ {
+		if query.Edges[i] <= query.Edges[i-1] {
+			return fmt.Errorf("%w: edges must be strictly increasing", internal.ErrServiceInvalidHistogram)
+		}
+	}
+	return
+}
+
+// validateTrend is a method that checks the metric, the group and the window of a trend
+func (s *ServiceVehicleDefault) validateTrend(query internal.TrendQuery) (err error) {
+	switch {
+	case !slices.Contains(internal.VehicleMetrics, query.Metric):
+		return fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidTrend, internal.ErrInvalidVehicleMetric, query.Metric)
+	case query.GroupBy != "" && !slices.Contains(internal.VehicleCategories, query.GroupBy):
+		return fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidTrend, internal.ErrInvalidVehicleCategory, query.GroupBy)
+	case query.Window < 0 || query.Window > 50:
+		return fmt.Errorf("%w: window must be between 1 and 50 years (0: 3 years)", internal.ErrServiceInvalidTrend)
+	}
+	return
+}
+
+// validate is a method that checks the attributes of a vehicle
+func (s *ServiceVehicleDefault) validate(v *internal.Vehicle) (err error) {
+	switch {
+	case v.Id < 0:
+		err = fmt.Errorf("%w: id must not be negative", internal.ErrServiceInvalidVehicle)
+	case internal.Blank(v.Brand):
+		err = fmt.Errorf("%w: brand is required, with a letter or a digit", internal.ErrServiceInvalidVehicle)
+	case internal.Blank(v.Model):
+		err = fmt.Errorf("%w: model is required, with a letter or a digit", internal.ErrServiceInvalidVehicle)
+	case v.Color != "" && internal.Blank(v.Color):
+		err = fmt.Errorf("%w: color must have a letter or a digit", internal.ErrServiceInvalidVehicle)
+	case v.FabricationYear < internal.MinFabricationYear || v.FabricationYear > time.Now().Year()+1:
+		err = fmt.Errorf("%w: year must be between %d and %d", internal.ErrServiceInvalidVehicle, internal.MinFabricationYear, time.Now().Year()+1)
+	case v.Capacity < 0:
+		err = fmt.Errorf("%w: passengers must not be negative", internal.ErrServiceInvalidVehicle)
+	case v.MaxSpeed < 0 || v.Weight < 0 || v.Height < 0 || v.Length < 0 || v.Width < 0:
+		err = fmt.Errorf("%w: max_speed, weight and dimensions must not be negative", internal.ErrServiceInvalidVehicle)
+	case !v.FuelType.Valid():
+		err = fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidVehicle, internal.ErrInvalidFuelType, v.FuelType)
+	case !v.Transmission.Valid():
+		err = fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidVehicle, internal.ErrInvalidTransmission, v.Transmission)
+	}
+	if err == nil && s.rr != nil {
+		if errFormat := s.rr.Validate(s.rr.Canonical(v.Registration)); errFormat != nil {
+			err = fmt.Errorf("%w: %w", internal.ErrServiceInvalidVehicle, errFormat)
+		}
+	}
+	return
+}
+
+// translate is a method that maps the repository errors to service errors
+func (s *ServiceVehicleDefault) translate(err error) error {
+	switch {
+	case errors.Is(err, internal.ErrRepositoryVehicleNotFound):
+		return fmt.Errorf("%w: %v", internal.ErrServiceVehicleNotFound, err)
+	case errors.Is(err, internal.ErrRepositoryVehicleAlreadyExists):
+		return fmt.Errorf("%w: %v", internal.ErrServiceVehicleAlreadyExists, err)
+	case errors.Is(err, internal.ErrRepositoryVehicleNotDeleted):
+		return fmt.Errorf("%w: %v", internal.ErrServiceVehicleNotDeleted, err)
+	case errors.Is(err, internal.ErrRepositoryRegistrationConflict):
+		return fmt.Errorf("%w: %v", internal.ErrServiceRegistrationConflict, err)
+	case errors.Is(err, internal.ErrRepositoryHistoryTruncated):
+		return fmt.Errorf("%w: %v", internal.ErrServiceHistoryTruncated, err)
+	case errors.Is(err, internal.ErrRepositoryReadOnly):
+		return fmt.Errorf("%w: %v", internal.ErrServiceReadOnly, err)
+	}
+	return err
+}
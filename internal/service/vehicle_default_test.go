@@ -0,0 +1,422 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/normalizer"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ServiceVehicleDefault.Compare method
+func TestServiceVehicleDefault_Compare(t *testing.T) {
+	vehicle := func(id int, brand string, speed float64, capacity int, weight float64) internal.Vehicle {
+		return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+			Brand: brand, MaxSpeed: internal.Speed(speed), Capacity: capacity, Weight: internal.Mass(weight),
+		}}
+	}
+	sv := service.NewServiceVehicleDefault(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+		1: vehicle(1, "Ford", 180, 5, 1200),
+		2: vehicle(2, "Ford", 150, 9, 2000),
+		3: vehicle(3, "Ford", 120, 5, 1500),
+		4: vehicle(4, "Toyota", 160, 5, 1200),
+	}, nil))
+	row := func(c internal.Comparison, m internal.VehicleMetric) internal.ComparisonRow {
+		for _, r := range c.Rows {
+			if r.Metric == m {
+				return r
+			}
+		}
+		return internal.ComparisonRow{}
+	}
+
+	t.Run("case 01: the best and worst values follow the direction of each attribute, with ties", func(t *testing.T) {
+		// arrange
+		ids := []int{4, 1, 3}
+
+		// act
+		c, err := sv.Compare(ids)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, c.Vehicles, 3)
+		require.Equal(t, 4, c.Vehicles[0].Id)
+		require.Len(t, c.Rows, len(internal.VehicleMetrics))
+		speed := row(c, internal.VehicleMetricMaxSpeed)
+		require.Equal(t, []float64{160, 180, 120}, speed.Values)
+		require.Equal(t, []int{1}, speed.Best)
+		require.Equal(t, []int{3}, speed.Worst)
+		capacity := row(c, internal.VehicleMetricCapacity)
+		require.Equal(t, []int{4, 1, 3}, capacity.Best)
+		require.Equal(t, []int{4, 1, 3}, capacity.Worst)
+		weight := row(c, internal.VehicleMetricWeight)
+		require.Equal(t, []int{4, 1}, weight.Best)
+		require.Equal(t, []int{3}, weight.Worst)
+	})
+
+	t.Run("case 02: the percentiles are within the brand and within the fleet", func(t *testing.T) {
+		// arrange
+		ids := []int{3, 4}
+
+		// act
+		c, err := sv.Compare(ids)
+
+		// assert
+		require.NoError(t, err)
+		speed := row(c, internal.VehicleMetricMaxSpeed)
+		require.InDelta(t, 100.0/3, speed.PercentileBrand[0], 0.001)
+		require.Equal(t, 25.0, speed.PercentileFleet[0])
+		require.Equal(t, 100.0, speed.PercentileBrand[1])
+		require.Equal(t, 75.0, speed.PercentileFleet[1])
+	})
+
+	t.Run("case 03: less than two ids, more than the maximum, repeated ids and unknown ids are rejected", func(t *testing.T) {
+		// arrange
+		many := make([]int, internal.MaxCompareIds+1)
+		for i := range many {
+			many[i] = i + 1
+		}
+
+		// act
+		_, errOne := sv.Compare([]int{1})
+		_, errMany := sv.Compare(many)
+		_, errRepeated := sv.Compare([]int{1, 2, 1})
+		_, errUnknown := sv.Compare([]int{1, 99})
+
+		// assert
+		require.ErrorIs(t, errOne, internal.ErrServiceInvalidCompare)
+		require.ErrorIs(t, errMany, internal.ErrServiceInvalidCompare)
+		require.ErrorIs(t, errRepeated, internal.ErrServiceInvalidCompare)
+		require.ErrorIs(t, errUnknown, internal.ErrServiceVehicleNotFound)
+	})
+}
+
+// Tests for ServiceVehicleDefault.Histogram method
+func TestServiceVehicleDefault_Histogram(t *testing.T) {
+	vehicle := func(id int, brand string, color string, fuel internal.FuelType, weight float64, year int) internal.Vehicle {
+		return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+			Brand: brand, Color: color, FuelType: fuel, Transmission: internal.TransmissionManual, Weight: internal.Mass(weight), FabricationYear: year,
+		}}
+	}
+	sv := service.NewServiceVehicleDefault(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+		1: vehicle(1, "Ford", "Red", internal.FuelTypeDiesel, 1000, 2010),
+		2: vehicle(2, "Ford", "Blue", internal.FuelTypeGasoline, 1400, 2012),
+		3: vehicle(3, "Ford", "Red", internal.FuelTypeDiesel, 1500, 2015),
+		4: vehicle(4, "Toyota", "Red", internal.FuelTypeGasoline, 2000, 2018),
+		5: vehicle(5, "Toyota", "Green", internal.FuelTypeDiesel, 3000, 2020),
+	}, nil))
+
+	t.Run("case 01: equal width bins between the lowest and the highest value, grouped and filtered", func(t *testing.T) {
+		// arrange
+		query := internal.HistogramQuery{
+			VehicleFilter: internal.VehicleFilter{ToYear: 2018},
+			Metric:        internal.VehicleMetricWeight,
+			Bins:          2,
+			GroupBy:       internal.VehicleCategoryFuelType,
+		}
+
+		// act
+		h, err := sv.Histogram(query)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []internal.HistogramBucket{{Lower: 1000, Upper: 1500}, {Lower: 1500, Upper: 2000}}, h.Buckets)
+		require.Equal(t, []internal.HistogramSeries{
+			{Group: "diesel", Counts: []int{1, 1}, Total: 2},
+			{Group: "gasoline", Counts: []int{1, 1}, Total: 2},
+		}, h.Series)
+	})
+
+	t.Run("case 02: explicit edges leave out the values beyond them and categories are counted by frequency", func(t *testing.T) {
+		// arrange
+		edges := internal.HistogramQuery{Metric: internal.VehicleMetricWeight, Edges: []float64{1200, 1500, 2500}}
+		colors := internal.HistogramQuery{VehicleFilter: internal.VehicleFilter{Brand: "ford"}, Category: internal.VehicleCategoryColor}
+
+		// act
+		hEdges, errEdges := sv.Histogram(edges)
+		hColors, errColors := sv.Histogram(colors)
+
+		// assert
+		require.NoError(t, errEdges)
+		require.Len(t, hEdges.Buckets, 2)
+		require.Equal(t, []internal.HistogramSeries{{Counts: []int{1, 2}, Total: 3}}, hEdges.Series)
+		require.NoError(t, errColors)
+		require.Equal(t, []internal.HistogramBucket{{Value: "Red"}, {Value: "Blue"}}, hColors.Buckets)
+		require.Equal(t, []internal.HistogramSeries{{Counts: []int{2, 1}, Total: 3}}, hColors.Series)
+	})
+
+	t.Run("case 03: invalid fields, buckets, groups and filters are rejected", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		_, errField := sv.Histogram(internal.HistogramQuery{})
+		_, errEdges := sv.Histogram(internal.HistogramQuery{Metric: internal.VehicleMetricWeight, Edges: []float64{2000, 1000}})
+		_, errBins := sv.Histogram(internal.HistogramQuery{Category: internal.VehicleCategoryColor, Bins: 5})
+		_, errGroup := sv.Histogram(internal.HistogramQuery{Category: internal.VehicleCategoryColor, GroupBy: internal.VehicleCategoryColor})
+		_, errFilter := sv.Histogram(internal.HistogramQuery{VehicleFilter: internal.VehicleFilter{FromYear: 2020, ToYear: 2010}, Metric: internal.VehicleMetricWeight})
+
+		// assert
+		require.ErrorIs(t, errField, internal.ErrServiceInvalidHistogram)
+		require.ErrorIs(t, errEdges, internal.ErrServiceInvalidHistogram)
+		require.ErrorIs(t, errBins, internal.ErrServiceInvalidHistogram)
+		require.ErrorIs(t, errGroup, internal.ErrServiceInvalidHistogram)
+		require.ErrorIs(t, errFilter, internal.ErrServiceInvalidFilter)
+	})
+}
+
+// Tests for ServiceVehicleDefault.Trends method
+func TestServiceVehicleDefault_Trends(t *testing.T) {
+	vehicle := func(id int, brand string, speed float64, year int) internal.Vehicle {
+		return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+			Brand: brand, FuelType: internal.FuelTypeDiesel, Transmission: internal.TransmissionManual, MaxSpeed: internal.Speed(speed), FabricationYear: year,
+		}}
+	}
+	sv := service.NewServiceVehicleDefault(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+		1: vehicle(1, "Ford", 100, 2010),
+		2: vehicle(2, "Ford", 140, 2010),
+		3: vehicle(3, "Ford", 150, 2013),
+		4: vehicle(4, "Toyota", 200, 2011),
+		5: vehicle(5, "Toyota", 210, 2012),
+	}, nil))
+	value := func(p *float64) any {
+		if p == nil {
+			return nil
+		}
+		return *p
+	}
+
+	t.Run("case 01: the series are dense over the years of the fleet, with gaps for the years without vehicles", func(t *testing.T) {
+		// arrange
+		query := internal.TrendQuery{Metric: internal.VehicleMetricMaxSpeed, GroupBy: internal.VehicleCategoryBrand, Window: 2}
+
+		// act
+		tr, err := sv.Trends(query)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []int{2010, 2011, 2012, 2013}, tr.Years)
+		require.Len(t, tr.Series, 2)
+		ford, toyota := tr.Series[0], tr.Series[1]
+		require.Equal(t, "Ford", ford.Group)
+		require.Len(t, ford.Points, 4)
+		require.Equal(t, []any{120.0, nil, nil, 150.0}, []any{value(ford.Points[0].Average), value(ford.Points[1].Average), value(ford.Points[2].Average), value(ford.Points[3].Average)})
+		require.Equal(t, []any{120.0, 120.0, nil, 150.0}, []any{value(ford.Points[0].MovingAverage), value(ford.Points[1].MovingAverage), value(ford.Points[2].MovingAverage), value(ford.Points[3].MovingAverage)})
+		require.Nil(t, ford.Points[3].Delta)
+		require.Equal(t, 0, ford.Points[1].Count)
+		require.Equal(t, "Toyota", toyota.Group)
+		require.Nil(t, toyota.Points[0].Average)
+		require.Equal(t, 10.0, value(toyota.Points[2].Delta))
+		require.Equal(t, 205.0, value(toyota.Points[2].MovingAverage))
+	})
+
+	t.Run("case 02: without group by a single series averages the vehicles of every year that match the filter", func(t *testing.T) {
+		// arrange
+		query := internal.TrendQuery{VehicleFilter: internal.VehicleFilter{FromYear: 2011}, Metric: internal.VehicleMetricMaxSpeed}
+
+		// act
+		tr, err := sv.Trends(query)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []int{2011, 2012, 2013}, tr.Years)
+		require.Len(t, tr.Series, 1)
+		require.Equal(t, "", tr.Series[0].Group)
+		require.Equal(t, -60.0, value(tr.Series[0].Points[2].Delta))
+		require.Equal(t, 186.67, math.Round(*tr.Series[0].Points[2].MovingAverage*100)/100)
+	})
+
+	t.Run("case 03: invalid metrics, groups and windows are rejected, as the years of the vehicles past the maximum span", func(t *testing.T) {
+		// arrange
+		spanned := service.NewServiceVehicleDefault(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: vehicle(1, "Ford", 100, 1),
+			2: vehicle(2, "Ford", 140, 2010),
+		}, nil))
+
+		// act
+		_, errMetric := sv.Trends(internal.TrendQuery{Metric: "speed"})
+		_, errGroup := sv.Trends(internal.TrendQuery{Metric: internal.VehicleMetricMaxSpeed, GroupBy: "model"})
+		_, errWindow := sv.Trends(internal.TrendQuery{Metric: internal.VehicleMetricMaxSpeed, Window: -1})
+		_, errSpan := spanned.Trends(internal.TrendQuery{Metric: internal.VehicleMetricMaxSpeed})
+		narrowed, errNarrowed := spanned.Trends(internal.TrendQuery{VehicleFilter: internal.VehicleFilter{FromYear: internal.MinFabricationYear}, Metric: internal.VehicleMetricMaxSpeed})
+
+		// assert
+		require.ErrorIs(t, errMetric, internal.ErrServiceInvalidTrend)
+		require.ErrorIs(t, errMetric, internal.ErrInvalidVehicleMetric)
+		require.ErrorIs(t, errGroup, internal.ErrServiceInvalidTrend)
+		require.ErrorIs(t, errWindow, internal.ErrServiceInvalidTrend)
+		require.ErrorIs(t, errSpan, internal.ErrServiceInvalidTrend)
+		require.NoError(t, errNarrowed)
+		require.Equal(t, []int{2010}, narrowed.Years)
+	})
+}
+
+// Tests for ServiceVehicleDefault.Average method
+func TestServiceVehicleDefault_Average(t *testing.T) {
+	vehicle := func(id int, brand string, speed float64, capacity int) internal.Vehicle {
+		return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+			Brand: brand, FuelType: internal.FuelTypeDiesel, Transmission: internal.TransmissionManual, MaxSpeed: internal.Speed(speed), Capacity: capacity, FabricationYear: 2010,
+		}}
+	}
+	sv := service.NewServiceVehicleDefault(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+		1: vehicle(1, "Ford", 100, 4),
+		2: vehicle(2, "Ford", 150, 5),
+		3: vehicle(3, "Toyota", 200, 7),
+	}, nil))
+
+	t.Run("case 01: average of the attribute among the vehicles that match the filter", func(t *testing.T) {
+		// arrange
+		query := internal.AverageQuery{VehicleFilter: internal.VehicleFilter{Brand: "Ford"}, Metric: internal.VehicleMetricMaxSpeed}
+
+		// act
+		speed, errSpeed := sv.Average(query)
+		capacity, errCapacity := sv.Average(internal.AverageQuery{Metric: internal.VehicleMetricCapacity})
+
+		// assert
+		require.NoError(t, errSpeed)
+		require.Equal(t, 125.0, speed)
+		require.NoError(t, errCapacity)
+		require.Equal(t, 16.0/3, capacity)
+	})
+
+	t.Run("case 02: no vehicles match the filter or the metric is invalid", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		_, errNone := sv.Average(internal.AverageQuery{VehicleFilter: internal.VehicleFilter{Brand: "Fiat"}, Metric: internal.VehicleMetricMaxSpeed})
+		_, errMetric := sv.Average(internal.AverageQuery{Metric: "speed"})
+
+		// assert
+		require.ErrorIs(t, errNone, internal.ErrServiceNoVehicles)
+		require.ErrorIs(t, errMetric, internal.ErrServiceInvalidFilter)
+		require.ErrorIs(t, errMetric, internal.ErrInvalidVehicleMetric)
+	})
+
+	t.Run("case 03: a brand without letters nor digits is invalid, not a brand without vehicles", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		_, errFilter := sv.Average(internal.AverageQuery{VehicleFilter: internal.VehicleFilter{Brand: "--"}, Metric: internal.VehicleMetricMaxSpeed})
+		_, errBrand := sv.AverageMaxSpeedByBrand("--")
+		errCreate := sv.Create(context.Background(), &internal.Vehicle{Id: 4, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "--", Model: "Fiesta", FuelType: internal.FuelTypeDiesel, Transmission: internal.TransmissionManual, FabricationYear: 2010,
+		}})
+
+		// assert
+		require.ErrorIs(t, errFilter, internal.ErrServiceInvalidFilter)
+		require.ErrorIs(t, errBrand, internal.ErrServiceInvalidFilter)
+		require.ErrorIs(t, errCreate, internal.ErrServiceInvalidVehicle)
+	})
+}
+
+// Tests for the registrations of ServiceVehicleDefault
+func TestServiceVehicleDefault_Registration(t *testing.T) {
+	vehicle := func(id int, registration string) internal.Vehicle {
+		return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", Model: "Focus", Registration: registration, FabricationYear: 2010,
+			FuelType: internal.FuelTypeGasoline, Transmission: internal.TransmissionManual,
+		}}
+	}
+	sv := func() *service.ServiceVehicleDefault {
+		rr, err := normalizer.NewRegistrationRulesRegex(normalizer.RegistrationRules{
+			"ar": {Strip: `[\s-]`, Upper: true, Formats: []string{`^[A-Z]{3}[0-9]{3}$`}},
+		}, "ar")
+		require.NoError(t, err)
+		nz := normalizer.NewNormalizerRegistration(normalizer.NewNormalizerAlias(nil), rr)
+		rp := repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{
+			1: vehicle(1, "ABC123"),
+			2: vehicle(2, "XYZ987"),
+		}, nz)
+		return service.NewServiceVehicleDefaultWithConfig(rp, &service.ConfigServiceVehicleDefault{RegistrationRules: rr})
+	}
+
+	t.Run("case 01: a vehicle is found by its registration in any form of the jurisdiction", func(t *testing.T) {
+		// arrange
+		s := sv()
+
+		// act
+		v, err := s.FindByRegistration("abc-123")
+		_, errNotFound := s.FindByRegistration("XYZ999")
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 1)
+		require.Contains(t, v, 1)
+		require.ErrorIs(t, errNotFound, internal.ErrServiceVehicleNotFound)
+	})
+
+	t.Run("case 02: a registration of another vehicle is a conflict on create, update and restore", func(t *testing.T) {
+		// arrange
+		s := sv()
+		ctx := context.Background()
+		v4 := vehicle(4, "abc 123")
+		v2 := vehicle(2, "ABC-123")
+		v5 := vehicle(5, "DEF456")
+
+		// act
+		errCreate := s.Create(ctx, &v4)
+		errUpdate := s.Update(ctx, &v2)
+		require.NoError(t, s.Create(ctx, &v5))
+		require.NoError(t, s.Delete(ctx, 5))
+		v6 := vehicle(6, "def-456")
+		require.NoError(t, s.Create(ctx, &v6))
+		_, errRestore := s.Restore(ctx, 5)
+
+		// assert
+		require.ErrorIs(t, errCreate, internal.ErrServiceRegistrationConflict)
+		require.ErrorIs(t, errUpdate, internal.ErrServiceRegistrationConflict)
+		require.ErrorIs(t, errRestore, internal.ErrServiceRegistrationConflict)
+		require.Equal(t, "DEF456", v6.Registration)
+	})
+
+	t.Run("case 03: a registration out of the formats of the jurisdiction is invalid", func(t *testing.T) {
+		// arrange
+		s := sv()
+		v := vehicle(4, "12-34")
+
+		// act
+		err := s.Create(context.Background(), &v)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrServiceInvalidVehicle)
+		require.ErrorIs(t, err, internal.ErrInvalidRegistration)
+	})
+}
+
+// Tests for the validation of the vehicles of ServiceVehicleDefault
+func TestServiceVehicleDefault_Validate(t *testing.T) {
+	vehicle := func(id int, year int) *internal.Vehicle {
+		return &internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", Model: "Focus", FabricationYear: year,
+			FuelType: internal.FuelTypeGasoline, Transmission: internal.TransmissionManual,
+		}}
+	}
+
+	t.Run("case 01: the fabrication year goes from the first automobile to the next year", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceVehicleDefault(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{}, nil))
+		ctx := context.Background()
+		next := time.Now().Year() + 1
+
+		// act
+		errFirst := sv.Create(ctx, vehicle(1, internal.MinFabricationYear))
+		errNext := sv.Create(ctx, vehicle(2, next))
+		errBefore := sv.Create(ctx, vehicle(3, internal.MinFabricationYear-1))
+		errAfter := sv.Create(ctx, vehicle(4, next+1))
+		errUpdate := sv.Update(ctx, vehicle(1, 20100))
+
+		// assert
+		require.NoError(t, errFirst)
+		require.NoError(t, errNext)
+		require.ErrorIs(t, errBefore, internal.ErrServiceInvalidVehicle)
+		require.ErrorIs(t, errAfter, internal.ErrServiceInvalidVehicle)
+		require.ErrorIs(t, errUpdate, internal.ErrServiceInvalidVehicle)
+	})
+}
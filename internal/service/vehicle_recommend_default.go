@@ -0,0 +1,139 @@
+package service
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// NewServiceRecommendVehicleDefault is a function that returns a new instance of ServiceRecommendVehicleDefault
+// - ix: similarity index kept in sync with the repository
+func NewServiceRecommendVehicleDefault(rp internal.RepositoryReadVehicle, ix internal.IndexSimilarVehicle) *ServiceRecommendVehicleDefault {
+	return &ServiceRecommendVehicleDefault{rp: rp, ix: ix}
+}
+
+// ServiceRecommendVehicleDefault is a struct that represents the default service that finds vehicles by requirements or by likeness
+type ServiceRecommendVehicleDefault struct {
+	// rp is the repository of the vehicles
+	rp internal.RepositoryReadVehicle
+	// ix is the similarity index of the vehicles
+	ix internal.IndexSimilarVehicle
+}
+
+// Recommend is a method that returns the vehicles that meet the constraints, best score first
+// - the score of a preference is the position of the value in the range of the attribute among the vehicles that meet the constraints
+// - ties are broken by id
+func (s *ServiceRecommendVehicleDefault) Recommend(query internal.RecommendQuery) (r []internal.Recommendation, err error) {
+	if err = s.validate(query); err != nil {
+		return
+	}
+
+	// get the vehicles that meet the constraints
+	v, err := s.rp.FindByFilter(query.VehicleFilter)
+	if err != nil {
+		return
+	}
+	candidates := make([]internal.Vehicle, 0, len(v))
+	for _, vehicle := range v {
+		candidates = append(candidates, vehicle)
+	}
+
+	// ranges of the preferred attributes among the candidates
+	lo, hi := make([]float64, len(query.Preferences)), make([]float64, len(query.Preferences))
+	var total float64
+	for i, p := range query.Preferences {
+		for j, vehicle := range candidates {
+			value := p.Metric.Value(vehicle)
+			if j == 0 || value < lo[i] {
+				lo[i] = value
+			}
+			if j == 0 || value > hi[i] {
+				hi[i] = value
+			}
+		}
+		total += p.Weight
+	}
+
+	// score
+	r = make([]internal.Recommendation, 0, len(candidates))
+	for _, vehicle := range candidates {
+		rc := internal.Recommendation{Vehicle: vehicle, Score: 1, Contributions: make([]internal.RecommendContribution, 0, len(query.Preferences))}
+		if len(query.Preferences) > 0 {
+			rc.Score = 0
+		}
+		for i, p := range query.Preferences {
+			c := internal.RecommendContribution{Preference: p, Value: p.Metric.Value(vehicle), Score: 1}
+			if hi[i] > lo[i] {
+				c.Score = (c.Value - lo[i]) / (hi[i] - lo[i])
+				if !p.Higher {
+					c.Score = 1 - c.Score
+				}
+			}
+			c.Contribution = c.Score * p.Weight / total
+			rc.Score += c.Contribution
+			rc.Contributions = append(rc.Contributions, c)
+		}
+		r = append(r, rc)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Score != r[j].Score {
+			return r[i].Score > r[j].Score
+		}
+		return r[i].Vehicle.Id < r[j].Vehicle.Id
+	})
+	if query.Limit > 0 && len(r) > query.Limit {
+		r = r[:query.Limit]
+	}
+	return
+}
+
+// Similar is a method that returns the k vehicles most similar to the vehicle of the id, most similar first
+func (s *ServiceRecommendVehicleDefault) Similar(id int, k int) (r []internal.SimilarResult, err error) {
+	if k < 1 {
+		err = fmt.Errorf("%w: k must be positive", internal.ErrServiceInvalidRecommendation)
+		return
+	}
+
+	h, ok := s.ix.Nearest(id, k)
+	if !ok {
+		err = fmt.Errorf("%w: id %d", internal.ErrServiceVehicleNotFound, id)
+		return
+	}
+
+	r = make([]internal.SimilarResult, 0, len(h))
+	for _, hit := range h {
+		var v internal.Vehicle
+		v, err = s.rp.FindById(hit.Id)
+		if err != nil {
+			// deleted since the search
+			if errors.Is(err, internal.ErrRepositoryVehicleNotFound) {
+				err = nil
+				continue
+			}
+			return
+		}
+		r = append(r, internal.SimilarResult{Vehicle: v, Distance: hit.Distance})
+	}
+	return
+}
+
+// validate is a method that checks the constraints and the preferences of a query
+func (s *ServiceRecommendVehicleDefault) validate(query internal.RecommendQuery) (err error) {
+	if err := query.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", internal.ErrServiceInvalidRecommendation, err)
+	}
+	for _, p := range query.Preferences {
+		if !slices.Contains(internal.VehicleMetrics, p.Metric) {
+			return fmt.Errorf("%w: %w: %q", internal.ErrServiceInvalidRecommendation, internal.ErrInvalidVehicleMetric, p.Metric)
+		}
+		if p.Weight <= 0 {
+			return fmt.Errorf("%w: weight of %s must be positive", internal.ErrServiceInvalidRecommendation, p.Metric)
+		}
+	}
+	if query.Limit < 0 {
+		return fmt.Errorf("%w: limit must not be negative", internal.ErrServiceInvalidRecommendation)
+	}
+	return
+}
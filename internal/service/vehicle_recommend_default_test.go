@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/index"
+	"app/internal/repository"
+	"app/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ServiceRecommendVehicleDefault
+func TestServiceRecommendVehicleDefault(t *testing.T) {
+	vehicle := func(id int, fuel internal.FuelType, speed float64, capacity int, weight float64) internal.Vehicle {
+		return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", FuelType: fuel, Transmission: internal.TransmissionManual,
+			MaxSpeed: internal.Speed(speed), Capacity: capacity, Weight: internal.Mass(weight),
+		}}
+	}
+	db := map[int]internal.Vehicle{
+		1: vehicle(1, internal.FuelTypeDiesel, 180, 5, 1800),
+		2: vehicle(2, internal.FuelTypeDiesel, 140, 7, 1900),
+		3: vehicle(3, internal.FuelTypeDiesel, 200, 2, 1300),
+		4: vehicle(4, internal.FuelTypeGasoline, 220, 5, 1500),
+		5: vehicle(5, internal.FuelTypeDiesel, 160, 9, 2600),
+	}
+	sv := func() *service.ServiceRecommendVehicleDefault {
+		ix := index.NewIndexVehicleKDTree()
+		rp, err := repository.NewRepositoryVehicleIndexed(repository.NewRepositoryReadVehicleMap(db, nil), ix)
+		require.NoError(t, err)
+		return service.NewServiceRecommendVehicleDefault(rp, ix)
+	}
+
+	t.Run("case 01: the vehicles that meet the constraints are ranked by the weighted preferences", func(t *testing.T) {
+		// arrange
+		query := internal.RecommendQuery{
+			VehicleFilter: internal.VehicleFilter{
+				FuelTypes: []internal.FuelType{internal.FuelTypeDiesel},
+				Min:       map[internal.VehicleMetric]float64{internal.VehicleMetricCapacity: 5},
+				Max:       map[internal.VehicleMetric]float64{internal.VehicleMetricWeight: 2000},
+			},
+			Preferences: []internal.RecommendPreference{
+				{Metric: internal.VehicleMetricMaxSpeed, Weight: 3, Higher: true},
+				{Metric: internal.VehicleMetricWeight, Weight: 1},
+			},
+		}
+
+		// act
+		r, err := sv().Recommend(query)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, r, 2)
+		require.Equal(t, 1, r[0].Vehicle.Id)
+		require.Equal(t, 1.0, r[0].Score)
+		require.Len(t, r[0].Contributions, 2)
+		require.Equal(t, 0.75, r[0].Contributions[0].Contribution)
+		require.Equal(t, 0.25, r[0].Contributions[1].Contribution)
+		require.Equal(t, 2, r[1].Vehicle.Id)
+		require.Equal(t, 0.0, r[1].Score)
+	})
+
+	t.Run("case 02: without preferences every vehicle scores 1 and the limit keeps the lowest ids", func(t *testing.T) {
+		// arrange
+		query := internal.RecommendQuery{Limit: 2}
+
+		// act
+		r, err := sv().Recommend(query)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, r, 2)
+		require.Equal(t, 1, r[0].Vehicle.Id)
+		require.Equal(t, 1.0, r[0].Score)
+		require.Equal(t, 2, r[1].Vehicle.Id)
+	})
+
+	t.Run("case 03: the similar vehicles are the nearest over the normalized attributes", func(t *testing.T) {
+		// arrange
+		s := sv()
+
+		// act
+		r, err := s.Similar(1, 2)
+		_, errUnknown := s.Similar(99, 2)
+		_, errK := s.Similar(1, 0)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, r, 2)
+		require.Equal(t, 4, r[0].Vehicle.Id)
+		require.Equal(t, 2, r[1].Vehicle.Id)
+		require.Less(t, r[0].Distance, r[1].Distance)
+		require.ErrorIs(t, errUnknown, internal.ErrServiceVehicleNotFound)
+		require.ErrorIs(t, errK, internal.ErrServiceInvalidRecommendation)
+	})
+
+	t.Run("case 04: invalid constraints and preferences are rejected", func(t *testing.T) {
+		// arrange
+		s := sv()
+
+		// act
+		_, errFuel := s.Recommend(internal.RecommendQuery{VehicleFilter: internal.VehicleFilter{FuelTypes: []internal.FuelType{"steam"}}})
+		_, errRange := s.Recommend(internal.RecommendQuery{VehicleFilter: internal.VehicleFilter{
+			Min: map[internal.VehicleMetric]float64{internal.VehicleMetricWeight: 2000},
+			Max: map[internal.VehicleMetric]float64{internal.VehicleMetricWeight: 1000},
+		}})
+		_, errWeight := s.Recommend(internal.RecommendQuery{Preferences: []internal.RecommendPreference{{Metric: internal.VehicleMetricMaxSpeed}}})
+
+		// assert
+		require.ErrorIs(t, errFuel, internal.ErrServiceInvalidRecommendation)
+		require.ErrorIs(t, errFuel, internal.ErrInvalidFuelType)
+		require.ErrorIs(t, errRange, internal.ErrServiceInvalidRecommendation)
+		require.ErrorIs(t, errWeight, internal.ErrServiceInvalidRecommendation)
+	})
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// NewServiceReloadVehicleDefault is a function that returns a new instance of ServiceReloadVehicleDefault
+// - ld: loader of the dataset of the fleet, the same as on the first load
+func NewServiceReloadVehicleDefault(ld internal.LoaderVehicle, rp internal.RepositoryReloadVehicle) *ServiceReloadVehicleDefault {
+	return &ServiceReloadVehicleDefault{ld: ld, rp: rp}
+}
+
+// ServiceReloadVehicleDefault is a struct that represents the default service that reloads the vehicles of a fleet from its dataset
+type ServiceReloadVehicleDefault struct {
+	// ld is the loader of the dataset
+	ld internal.LoaderVehicle
+	// rp is the repository of the vehicles
+	rp internal.RepositoryReloadVehicle
+	// mu serializes the reloads, so that the dataset of a reload is not replaced by an older one
+	mu sync.Mutex
+}
+
+// Reload is a method that loads the dataset again and replaces the vehicles of the fleet by its vehicles
+// - a dataset that cannot be loaded leaves the fleet as it was
+func (s *ServiceReloadVehicleDefault) Reload(ctx context.Context) (r internal.VehicleReload, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, err := s.ld.Load()
+	if err != nil {
+		err = fmt.Errorf("%w: %v", internal.ErrServiceDatasetNotLoaded, err)
+		return
+	}
+
+	r, err = s.rp.Reload(ctx, db)
+	return
+}
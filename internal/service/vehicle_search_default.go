@@ -0,0 +1,44 @@
+package service
+
+import (
+	"app/internal"
+	"errors"
+	"strings"
+)
+
+// ServiceSearchVehicleDefault is a struct that represents the default full-text search service for vehicles
+type ServiceSearchVehicleDefault struct {
+	// ix is the index that will be used by the service
+	ix internal.IndexVehicle
+	// rp is the repository where the vehicles found are read
+	rp internal.RepositoryReadVehicle
+}
+
+// NewServiceSearchVehicleDefault is a function that returns a new instance of ServiceSearchVehicleDefault
+func NewServiceSearchVehicleDefault(ix internal.IndexVehicle, rp internal.RepositoryReadVehicle) *ServiceSearchVehicleDefault {
+	return &ServiceSearchVehicleDefault{ix: ix, rp: rp}
+}
+
+// Search is a method that returns the vehicles that match the text, most relevant first
+func (s *ServiceSearchVehicleDefault) Search(text string, limit int) (r []internal.SearchResult, err error) {
+	if strings.TrimSpace(text) == "" {
+		err = internal.ErrServiceInvalidSearch
+		return
+	}
+
+	hits := s.ix.Search(text, limit)
+	r = make([]internal.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		v, errFind := s.rp.FindById(hit.Id)
+		if errFind != nil {
+			// the vehicle was removed between the search and the read
+			if errors.Is(errFind, internal.ErrRepositoryVehicleNotFound) {
+				continue
+			}
+			err = errFind
+			return
+		}
+		r = append(r, internal.SearchResult{Vehicle: v, Score: hit.Score})
+	}
+	return
+}
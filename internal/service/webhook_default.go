@@ -0,0 +1,462 @@
+package service
+
+import (
+	"app/internal"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ConfigServiceWebhookDefault is a struct that represents the configuration for ServiceWebhookDefault
+type ConfigServiceWebhookDefault struct {
+	// Client is the client that posts the events (default: a client that refuses the private addresses, unless AllowPrivateTargets)
+	Client *http.Client
+	// AllowPrivateTargets lets the webhooks target loopback, private, link-local and unspecified addresses (e.g. a receiver on the same host)
+	AllowPrivateTargets bool
+	// Encode is the function that returns the body of an event (default: json.Marshal of the event)
+	Encode func(e internal.Event) (body []byte, err error)
+	// MaxAttempts is the number of attempts after which an event goes to the dead letters
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on every retry
+	Backoff time.Duration
+	// MaxBackoff is the maximum delay between retries
+	MaxBackoff time.Duration
+	// QueueSize is the number of events of each webhook waiting for delivery, the events above it go to the dead letters
+	QueueSize int
+}
+
+// NewServiceWebhookDefault is a function that returns a new instance of ServiceWebhookDefault
+func NewServiceWebhookDefault(rp internal.RepositoryWebhook, rpDelivery internal.RepositoryDelivery, cfg *ConfigServiceWebhookDefault) *ServiceWebhookDefault {
+	// default values
+	defaultConfig := &ConfigServiceWebhookDefault{
+		Encode:      func(e internal.Event) ([]byte, error) { return json.Marshal(e) },
+		MaxAttempts: 5,
+		Backoff:     time.Second,
+		MaxBackoff:  time.Minute,
+		QueueSize:   100,
+	}
+	if cfg != nil {
+		if cfg.Client != nil {
+			defaultConfig.Client = cfg.Client
+		}
+		defaultConfig.AllowPrivateTargets = cfg.AllowPrivateTargets
+		if cfg.Encode != nil {
+			defaultConfig.Encode = cfg.Encode
+		}
+		if cfg.MaxAttempts != 0 {
+			defaultConfig.MaxAttempts = cfg.MaxAttempts
+		}
+		if cfg.Backoff != 0 {
+			defaultConfig.Backoff = cfg.Backoff
+		}
+		if cfg.MaxBackoff != 0 {
+			defaultConfig.MaxBackoff = cfg.MaxBackoff
+		}
+		if cfg.QueueSize != 0 {
+			defaultConfig.QueueSize = cfg.QueueSize
+		}
+	}
+
+	if defaultConfig.Client == nil {
+		defaultConfig.Client = webhookClient(defaultConfig.AllowPrivateTargets)
+	}
+
+	return &ServiceWebhookDefault{
+		rp:           rp,
+		rpDelivery:   rpDelivery,
+		client:       defaultConfig.Client,
+		allowPrivate: defaultConfig.AllowPrivateTargets,
+		lookup:       net.DefaultResolver.LookupIPAddr,
+		encode:       defaultConfig.Encode,
+		maxAttempts:  defaultConfig.MaxAttempts,
+		backoff:      defaultConfig.Backoff,
+		maxBackoff:   defaultConfig.MaxBackoff,
+		queueSize:    defaultConfig.QueueSize,
+		queues:       make(map[int]chan internal.Event),
+		done:         make(chan struct{}),
+		now:          time.Now,
+	}
+}
+
+// ServiceWebhookDefault is a struct that represents the default service for webhooks
+// - each webhook has a bounded queue of events and a worker that delivers them in order,
+// retrying with exponential backoff until each one succeeds or goes to the dead letters
+type ServiceWebhookDefault struct {
+	// rp is the repository of the webhooks
+	rp internal.RepositoryWebhook
+	// rpDelivery is the repository of the delivery attempts and the dead letters
+	rpDelivery internal.RepositoryDelivery
+	// client is the client that posts the events
+	client *http.Client
+	// allowPrivate is true if the webhooks can target loopback, private, link-local and unspecified addresses
+	allowPrivate bool
+	// lookup is the function that resolves the host of the url of a webhook
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+	// encode is the function that returns the body of an event
+	encode func(e internal.Event) (body []byte, err error)
+	// maxAttempts is the number of attempts after which an event goes to the dead letters
+	maxAttempts int
+	// backoff is the delay before the first retry
+	backoff time.Duration
+	// maxBackoff is the maximum delay between retries
+	maxBackoff time.Duration
+	// queueSize is the number of events of each webhook waiting for delivery
+	queueSize int
+	// mu is the lock that guards queues and closed
+	mu sync.Mutex
+	// queues is a map of the queue of each webhook, read by its worker
+	queues map[int]chan internal.Event
+	// closed is true once the service is closed: no event is queued anymore
+	closed bool
+	// done is closed with the service, to stop waiting between retries
+	done chan struct{}
+	// pending tracks the queued events until their delivery ends, retries included
+	pending sync.WaitGroup
+	// workers tracks the workers of the queues
+	workers sync.WaitGroup
+	// now is the clock of the attempts
+	now func() time.Time
+}
+
+// Publish is a method that queues the event for delivery to the subscribed webhooks
+func (s *ServiceWebhookDefault) Publish(e internal.Event) {
+	w, err := s.rp.FindAll()
+	if err != nil {
+		return
+	}
+	for _, webhook := range w {
+		if webhook.Subscribed(e) {
+			s.enqueue(webhook.Id, e)
+		}
+	}
+}
+
+// Wait is a method that blocks until the queued deliveries end, retries included
+func (s *ServiceWebhookDefault) Wait() {
+	s.pending.Wait()
+}
+
+// Close is a method that stops queueing events and drains the queues
+// - the queued events get one more attempt, the ones that fail go to the dead letters without waiting for their retries
+func (s *ServiceWebhookDefault) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.done)
+	for id, queue := range s.queues {
+		close(queue)
+		delete(s.queues, id)
+	}
+	s.mu.Unlock()
+
+	s.workers.Wait()
+}
+
+// enqueue is a method that adds the event to the queue of the webhook, starting its worker on the first event
+// - an event that does not fit in the queue, or that comes after the service is closed, goes to the dead letters
+func (s *ServiceWebhookDefault) enqueue(webhookId int, e internal.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reason := "service closed"
+	if !s.closed {
+		queue, ok := s.queues[webhookId]
+		if !ok {
+			queue = make(chan internal.Event, s.queueSize)
+			s.queues[webhookId] = queue
+			s.workers.Add(1)
+			go s.work(webhookId, queue)
+		}
+		s.pending.Add(1)
+		select {
+		case queue <- e:
+			return
+		default:
+			s.pending.Done()
+			reason = fmt.Sprintf("queue full (%d events)", s.queueSize)
+		}
+	}
+	_ = s.rpDelivery.SaveDeadLetter(&internal.DeadLetter{WebhookId: webhookId, Event: e, LastError: reason, Time: s.now()})
+}
+
+// dequeue is a method that stops the worker of the webhook once its queue is drained
+func (s *ServiceWebhookDefault) dequeue(webhookId int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if queue, ok := s.queues[webhookId]; ok {
+		close(queue)
+		delete(s.queues, webhookId)
+	}
+}
+
+// work is a method that delivers the events of the queue of the webhook, in order, until the queue is closed
+func (s *ServiceWebhookDefault) work(webhookId int, queue chan internal.Event) {
+	defer s.workers.Done()
+
+	for e := range queue {
+		s.deliver(webhookId, e)
+		s.pending.Done()
+	}
+}
+
+// deliver is a method that posts the event to the webhook until it succeeds or the attempts run out
+// - the webhook is read before every attempt: a deleted webhook stops its retries
+// - once the service is closed a failed attempt is not retried
+func (s *ServiceWebhookDefault) deliver(webhookId int, e internal.Event) {
+	body, errEncode := s.encode(e)
+	for attempt := 1; ; attempt++ {
+		webhook, err := s.rp.FindById(webhookId)
+		if err != nil {
+			return
+		}
+
+		d := internal.Delivery{WebhookId: webhookId, EventId: e.Id, EventType: e.Type, Attempt: attempt, Time: s.now()}
+		if errEncode != nil {
+			// an event that cannot be encoded is not retried
+			err = fmt.Errorf("encode: %v", errEncode)
+			attempt = s.maxAttempts
+		} else {
+			d.StatusCode, err = s.post(webhook, e, body, attempt, d.Time)
+		}
+		switch {
+		case err == nil:
+			d.Status = internal.DeliveryStatusDelivered
+		case attempt < s.maxAttempts && !s.isClosed():
+			d.Status, d.Error = internal.DeliveryStatusRetrying, err.Error()
+		default:
+			d.Status, d.Error = internal.DeliveryStatusDead, err.Error()
+		}
+		_ = s.rpDelivery.Append(&d)
+
+		switch d.Status {
+		case internal.DeliveryStatusDelivered:
+			return
+		case internal.DeliveryStatusDead:
+			_ = s.rpDelivery.SaveDeadLetter(&internal.DeadLetter{WebhookId: webhookId, Event: e, Attempts: attempt, LastError: d.Error, Time: d.Time})
+			return
+		}
+		select {
+		case <-time.After(s.delay(attempt)):
+		case <-s.done:
+		}
+	}
+}
+
+// isClosed is a method that returns true once the service is closed
+func (s *ServiceWebhookDefault) isClosed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// post is a method that makes an attempt to deliver the event: a signed POST that succeeds with a 2xx status
+func (s *ServiceWebhookDefault) post(w internal.Webhook, e internal.Event, body []byte, attempt int, at time.Time) (statusCode int, err error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	timestamp := at.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(internal.HeaderWebhookId, e.Id)
+	req.Header.Set(internal.HeaderWebhookEvent, string(e.Type))
+	req.Header.Set(internal.HeaderWebhookAttempt, strconv.Itoa(attempt))
+	req.Header.Set(internal.HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(internal.HeaderWebhookSignature, w.Sign(timestamp, body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	statusCode = res.StatusCode
+	if statusCode < 200 || statusCode > 299 {
+		err = fmt.Errorf("status %d", statusCode)
+	}
+	return
+}
+
+// delay is a method that returns the wait after the attempt: the backoff doubled on every retry, up to the max backoff
+func (s *ServiceWebhookDefault) delay(attempt int) time.Duration {
+	d := s.backoff
+	for i := 1; i < attempt && d < s.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.maxBackoff {
+		d = s.maxBackoff
+	}
+	return d
+}
+
+// FindAll is a method that returns the webhooks, by id
+func (s *ServiceWebhookDefault) FindAll() (w []internal.Webhook, err error) {
+	w, err = s.rp.FindAll()
+	return
+}
+
+// FindById is a method that returns the webhook that matches the id
+func (s *ServiceWebhookDefault) FindById(id int) (w internal.Webhook, err error) {
+	w, err = s.rp.FindById(id)
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+	return
+}
+
+// Create is a method that validates and adds a webhook created by the principal of ctx
+func (s *ServiceWebhookDefault) Create(ctx context.Context, w *internal.Webhook) (err error) {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = fmt.Errorf("%w: url must be an absolute http or https url", internal.ErrServiceInvalidWebhook)
+		return
+	}
+	if !s.allowPrivate {
+		if err = s.public(ctx, u.Hostname()); err != nil {
+			return
+		}
+	}
+	for _, t := range w.Events {
+		if _, err = internal.ParseEventType(string(t)); err != nil {
+			err = fmt.Errorf("%w: %v", internal.ErrServiceInvalidWebhook, err)
+			return
+		}
+	}
+
+	if w.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return
+		}
+		w.Secret = hex.EncodeToString(secret)
+	}
+	w.CreatedBy = "anonymous"
+	if p, ok := internal.PrincipalFromContext(ctx); ok {
+		w.CreatedBy = p.Subject
+	}
+	w.CreatedAt = s.now()
+	err = s.rp.Save(w)
+	return
+}
+
+// Delete is a method that removes the webhook that matches the id
+func (s *ServiceWebhookDefault) Delete(ctx context.Context, id int) (err error) {
+	if err = s.rp.Delete(id); err != nil {
+		err = s.translate(err)
+		return
+	}
+	// - the queued events are dropped by the worker, that reads the webhook before every attempt
+	s.dequeue(id)
+	return
+}
+
+// FindDeliveries is a method that returns the delivery attempts of the webhook, oldest first
+func (s *ServiceWebhookDefault) FindDeliveries(id int) (d []internal.Delivery, err error) {
+	if _, err = s.FindById(id); err != nil {
+		return
+	}
+	d, err = s.rpDelivery.FindByWebhookId(id)
+	return
+}
+
+// FindDeadLetters is a method that returns the events that could not be delivered to the webhook, oldest first
+func (s *ServiceWebhookDefault) FindDeadLetters(id int) (dl []internal.DeadLetter, err error) {
+	if _, err = s.FindById(id); err != nil {
+		return
+	}
+	dl, err = s.rpDelivery.FindDeadLetters(id)
+	return
+}
+
+// Redeliver is a method that removes a dead letter of the webhook and delivers its event again, with new retries
+func (s *ServiceWebhookDefault) Redeliver(ctx context.Context, id int, deadLetterId int) (err error) {
+	if _, err = s.FindById(id); err != nil {
+		return
+	}
+	dl, err := s.rpDelivery.DeleteDeadLetter(id, deadLetterId)
+	if err != nil {
+		err = s.translate(err)
+		return
+	}
+
+	s.enqueue(id, dl.Event)
+	return
+}
+
+// public is a method that checks that the host resolves only to public addresses
+func (s *ServiceWebhookDefault) public(ctx context.Context, host string) (err error) {
+	addrs := []net.IPAddr{{IP: net.ParseIP(host)}}
+	if addrs[0].IP == nil {
+		if addrs, err = s.lookup(ctx, host); err != nil {
+			return fmt.Errorf("%w: url host %q does not resolve", internal.ErrServiceInvalidWebhook, host)
+		}
+	}
+	for _, addr := range addrs {
+		if private(addr.IP) {
+			return fmt.Errorf("%w: url must not target a loopback, private, link-local or unspecified address (%s)", internal.ErrServiceInvalidWebhook, addr.IP)
+		}
+	}
+	return
+}
+
+// private is a function that returns true if the address is loopback, private, link-local, multicast or unspecified
+func private(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// webhookClient is a function that returns the client that posts the events
+// - unless allowPrivate, it refuses to connect to the private addresses, checked once resolved: a host that resolves
+// to another address after the creation of its webhook, or a redirect, is refused too. It does not go through a proxy
+func webhookClient(allowPrivate bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network string, address string, c syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || private(ip) {
+					return fmt.Errorf("webhook target %s is not a public address", host)
+				}
+				return nil
+			},
+		}
+		transport.Proxy = nil
+		transport.DialContext = dialer.DialContext
+	}
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+// translate is a method that maps the repository errors to service errors
+func (s *ServiceWebhookDefault) translate(err error) error {
+	switch {
+	case errors.Is(err, internal.ErrRepositoryWebhookNotFound):
+		return fmt.Errorf("%w: %v", internal.ErrServiceWebhookNotFound, err)
+	case errors.Is(err, internal.ErrRepositoryDeadLetterNotFound):
+		return fmt.Errorf("%w: %v", internal.ErrServiceDeadLetterNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,255 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a webhook endpoint that records the deliveries and answers with the next status of a script
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+// ServeHTTP records the request and answers with the next status (200 when the script is over)
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// Tests for ServiceWebhookDefault
+func TestServiceWebhookDefault(t *testing.T) {
+	newService := func() *service.ServiceWebhookDefault {
+		return service.NewServiceWebhookDefault(repository.NewRepositoryWebhookMap(nil, nil), repository.NewRepositoryDeliveryMap(100), &service.ConfigServiceWebhookDefault{
+			MaxAttempts:         3,
+			Backoff:             time.Millisecond,
+			AllowPrivateTargets: true,
+		})
+	}
+
+	t.Run("case 01: the mutations are delivered signed to the subscribed webhooks", func(t *testing.T) {
+		// arrange
+		rc := &receiver{}
+		srv := httptest.NewServer(rc)
+		defer srv.Close()
+		sv := newService()
+		created := internal.Webhook{URL: srv.URL, Secret: "s3cr3t", Events: []internal.EventType{internal.EventVehicleCreated}}
+		require.NoError(t, sv.Create(context.Background(), &created))
+		otherFleet := internal.Webhook{URL: srv.URL, Fleet: "north"}
+		require.NoError(t, sv.Create(context.Background(), &otherFleet))
+		rp := repository.NewRepositoryVehicleEvents(repository.NewRepositoryReadVehicleMap(map[int]internal.Vehicle{}, nil), sv, internal.DefaultFleet)
+		ctx := internal.ContextWithPrincipal(context.Background(), internal.Principal{Subject: "alice", Role: internal.RoleEditor})
+
+		// act
+		v := internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Transit"}}
+		err := rp.Save(ctx, &v)
+		require.NoError(t, err)
+		v.Model = "Focus"
+		err = rp.Update(ctx, &v)
+		require.NoError(t, err)
+		sv.Wait()
+
+		// assert
+		require.Len(t, rc.requests, 1)
+		r, body := rc.requests[0], rc.bodies[0]
+		require.Equal(t, string(internal.EventVehicleCreated), r.Header.Get(internal.HeaderWebhookEvent))
+		require.Equal(t, "1", r.Header.Get(internal.HeaderWebhookAttempt))
+		timestamp, err := strconv.ParseInt(r.Header.Get(internal.HeaderWebhookTimestamp), 10, 64)
+		require.NoError(t, err)
+		require.Equal(t, created.Sign(timestamp, body), r.Header.Get(internal.HeaderWebhookSignature))
+		var e internal.Event
+		require.NoError(t, json.Unmarshal(body, &e))
+		require.Equal(t, r.Header.Get(internal.HeaderWebhookId), e.Id)
+		require.Equal(t, "alice", e.Actor)
+		require.Equal(t, "Transit", e.Vehicle.Model)
+		d, err := sv.FindDeliveries(created.Id)
+		require.NoError(t, err)
+		require.Len(t, d, 1)
+		require.Equal(t, internal.DeliveryStatusDelivered, d[0].Status)
+	})
+
+	t.Run("case 02: failed deliveries are retried with the same event id", func(t *testing.T) {
+		// arrange
+		rc := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+		srv := httptest.NewServer(rc)
+		defer srv.Close()
+		sv := newService()
+		wh := internal.Webhook{URL: srv.URL}
+		require.NoError(t, sv.Create(context.Background(), &wh))
+
+		// act
+		sv.Publish(internal.NewEvent(internal.EventDatasetReloaded, internal.DefaultFleet, time.Now()))
+		sv.Wait()
+
+		// assert
+		require.Len(t, rc.requests, 3)
+		for i, r := range rc.requests {
+			require.Equal(t, rc.requests[0].Header.Get(internal.HeaderWebhookId), r.Header.Get(internal.HeaderWebhookId))
+			require.Equal(t, strconv.Itoa(i+1), r.Header.Get(internal.HeaderWebhookAttempt))
+		}
+		d, err := sv.FindDeliveries(wh.Id)
+		require.NoError(t, err)
+		require.Len(t, d, 3)
+		require.Equal(t, internal.DeliveryStatusRetrying, d[0].Status)
+		require.Equal(t, http.StatusInternalServerError, d[0].StatusCode)
+		require.Equal(t, internal.DeliveryStatusRetrying, d[1].Status)
+		require.Equal(t, internal.DeliveryStatusDelivered, d[2].Status)
+		dl, err := sv.FindDeadLetters(wh.Id)
+		require.NoError(t, err)
+		require.Empty(t, dl)
+	})
+
+	t.Run("case 03: exhausted deliveries go to the dead letters and can be delivered again", func(t *testing.T) {
+		// arrange
+		rc := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}}
+		srv := httptest.NewServer(rc)
+		defer srv.Close()
+		sv := newService()
+		wh := internal.Webhook{URL: srv.URL}
+		require.NoError(t, sv.Create(context.Background(), &wh))
+		e := internal.NewEvent(internal.EventDatasetReloaded, internal.DefaultFleet, time.Now())
+
+		// act
+		sv.Publish(e)
+		sv.Wait()
+		dead, errDead := sv.FindDeadLetters(wh.Id)
+		errRedeliver := sv.Redeliver(context.Background(), wh.Id, dead[0].Id)
+		sv.Wait()
+
+		// assert
+		require.NoError(t, errDead)
+		require.Len(t, dead, 1)
+		require.Equal(t, e.Id, dead[0].Event.Id)
+		require.Equal(t, 3, dead[0].Attempts)
+		require.Equal(t, "status 500", dead[0].LastError)
+		require.NoError(t, errRedeliver)
+		require.Len(t, rc.requests, 4)
+		dl, err := sv.FindDeadLetters(wh.Id)
+		require.NoError(t, err)
+		require.Empty(t, dl)
+		err = sv.Redeliver(context.Background(), wh.Id, dead[0].Id)
+		require.ErrorIs(t, err, internal.ErrServiceDeadLetterNotFound)
+	})
+
+	t.Run("case 04: invalid webhooks are rejected and an empty secret is generated", func(t *testing.T) {
+		// arrange
+		sv := newService()
+
+		// act
+		relative := internal.Webhook{URL: "/hooks"}
+		errRelative := sv.Create(context.Background(), &relative)
+		unknown := internal.Webhook{URL: "https://example.com/hooks", Events: []internal.EventType{"vehicle.sold"}}
+		errUnknown := sv.Create(context.Background(), &unknown)
+		valid := internal.Webhook{URL: "https://example.com/hooks"}
+		errValid := sv.Create(context.Background(), &valid)
+
+		// assert
+		require.ErrorIs(t, errRelative, internal.ErrServiceInvalidWebhook)
+		require.ErrorIs(t, errUnknown, internal.ErrServiceInvalidWebhook)
+		require.NoError(t, errValid)
+		require.Len(t, valid.Secret, 64)
+	})
+
+	t.Run("case 05: the events above the queue of a webhook go to the dead letters, and closing drains the queue", func(t *testing.T) {
+		// arrange
+		received, release := make(chan struct{}, 10), make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- struct{}{}
+			<-release
+		}))
+		defer srv.Close()
+		sv := service.NewServiceWebhookDefault(repository.NewRepositoryWebhookMap(nil, nil), repository.NewRepositoryDeliveryMap(100), &service.ConfigServiceWebhookDefault{
+			MaxAttempts:         3,
+			Backoff:             time.Millisecond,
+			QueueSize:           2,
+			AllowPrivateTargets: true,
+		})
+		wh := internal.Webhook{URL: srv.URL}
+		require.NoError(t, sv.Create(context.Background(), &wh))
+		event := func() internal.Event {
+			return internal.NewEvent(internal.EventDatasetReloaded, internal.DefaultFleet, time.Now())
+		}
+		sv.Publish(event())
+		<-received
+
+		// act
+		for i := 0; i < 3; i++ {
+			sv.Publish(event())
+		}
+		overflow, errOverflow := sv.FindDeadLetters(wh.Id)
+		close(release)
+		sv.Close()
+		sv.Publish(event())
+		closed, errClosed := sv.FindDeadLetters(wh.Id)
+		d, errDeliveries := sv.FindDeliveries(wh.Id)
+
+		// assert
+		require.NoError(t, errOverflow)
+		require.Len(t, overflow, 1)
+		require.Equal(t, "queue full (2 events)", overflow[0].LastError)
+		require.NoError(t, errClosed)
+		require.Len(t, closed, 2)
+		require.Equal(t, "service closed", closed[1].LastError)
+		require.NoError(t, errDeliveries)
+		require.Len(t, d, 3)
+		for _, delivery := range d {
+			require.Equal(t, internal.DeliveryStatusDelivered, delivery.Status)
+		}
+	})
+
+	t.Run("case 06: without private targets the webhooks to the private addresses are rejected and not delivered", func(t *testing.T) {
+		// arrange
+		rc := &receiver{}
+		srv := httptest.NewServer(rc)
+		defer srv.Close()
+		// - a webhook persisted before the policy, or whose host resolves to another address since its creation
+		rp := repository.NewRepositoryWebhookMap([]internal.Webhook{{Id: 1, URL: srv.URL, Secret: "s3cr3t"}}, nil)
+		sv := service.NewServiceWebhookDefault(rp, repository.NewRepositoryDeliveryMap(100), &service.ConfigServiceWebhookDefault{MaxAttempts: 1})
+
+		// act
+		var errs []error
+		for _, u := range []string{srv.URL, "http://localhost:8080/hooks", "http://169.254.169.254/latest", "http://10.0.0.1/hooks", "http://[::]/hooks"} {
+			wh := internal.Webhook{URL: u}
+			errs = append(errs, sv.Create(context.Background(), &wh))
+		}
+		public := internal.Webhook{URL: "https://93.184.216.34/hooks", Events: []internal.EventType{internal.EventVehicleCreated}}
+		errPublic := sv.Create(context.Background(), &public)
+		sv.Publish(internal.NewEvent(internal.EventDatasetReloaded, internal.DefaultFleet, time.Now()))
+		sv.Close()
+		d, errDeliveries := sv.FindDeliveries(1)
+
+		// assert
+		for _, err := range errs {
+			require.ErrorIs(t, err, internal.ErrServiceInvalidWebhook)
+		}
+		require.NoError(t, errPublic)
+		require.NoError(t, errDeliveries)
+		require.Len(t, d, 1)
+		require.Equal(t, internal.DeliveryStatusDead, d[0].Status)
+		require.Contains(t, d[0].Error, "is not a public address")
+		require.Empty(t, rc.bodies)
+	})
+}
@@ -0,0 +1,77 @@
+package internal
+
+import "time"
+
+// Telemetry is a struct that represents a reading reported by a vehicle
+type Telemetry struct {
+	// VehicleId is the id of the vehicle that reported the reading
+	VehicleId int
+	// Time is the instant of the reading
+	Time time.Time
+	// Latitude is the latitude of the position, in degrees
+	Latitude float64
+	// Longitude is the longitude of the position, in degrees
+	Longitude float64
+	// Speed is the speed of the vehicle
+	Speed Speed
+	// Odometer is the odometer reading
+	Odometer Distance
+}
+
+// AlertKind is a type that represents the rule broken by a reading
+type AlertKind string
+
+const (
+	// AlertKindOverspeed is a reading with a speed above the max speed of the vehicle
+	AlertKindOverspeed AlertKind = "overspeed"
+	// AlertKindImpossibleJump is a reading too far from the previous one to be physically possible
+	AlertKindImpossibleJump AlertKind = "impossible-jump"
+)
+
+// Alert is a struct that represents a reading that broke a rule
+type Alert struct {
+	// Id is the unique identifier of the alert
+	Id int
+	// VehicleId is the id of the vehicle that reported the reading
+	VehicleId int
+	// Kind is the rule broken by the reading
+	Kind AlertKind
+	// Time is the instant of the reading
+	Time time.Time
+	// Message is a description of the reading
+	Message string
+	// AcknowledgedBy is the subject of the principal that acknowledged the alert
+	AcknowledgedBy string
+	// AcknowledgedAt is the instant when the alert was acknowledged (zero if it was not)
+	AcknowledgedAt time.Time
+}
+
+// Acknowledged is a method that returns true if the alert was acknowledged
+func (a Alert) Acknowledged() bool {
+	return !a.AcknowledgedAt.IsZero()
+}
+
+// AlertQuery is a struct that represents a query over the alerts
+// - zero values are not used as filters
+type AlertQuery struct {
+	// VehicleId is the id of the vehicle that reported the reading
+	VehicleId int
+	// Kind is the rule broken by the reading
+	Kind AlertKind
+	// Unacknowledged restricts the alerts to the ones that were not acknowledged
+	Unacknowledged bool
+}
+
+// Match is a method that returns true if the alert satisfies the query
+func (q AlertQuery) Match(a Alert) bool {
+	if q.VehicleId != 0 && a.VehicleId != q.VehicleId {
+		return false
+	}
+	if q.Kind != "" && a.Kind != q.Kind {
+		return false
+	}
+	if q.Unacknowledged && a.Acknowledged() {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRepositoryAlertNotFound is an error that represents an alert that does not exist
+	ErrRepositoryAlertNotFound = errors.New("repository: alert not found")
+)
+
+// RepositoryTelemetry is an interface that represents a time-series repository of the readings of the vehicles
+type RepositoryTelemetry interface {
+	// FindLast is a method that returns the latest reading of the vehicle (ok false if there is none)
+	FindLast(vehicleId int) (t Telemetry, ok bool, err error)
+
+	// FindRange is a method that returns the readings of the vehicle in the interval [from, to), oldest first
+	// - zero bounds are open
+	FindRange(vehicleId int, from time.Time, to time.Time) (t []Telemetry, err error)
+
+	// Append is a method that adds readings of the vehicle, oldest first and after its latest reading
+	// - the readings kept per vehicle are bounded: the oldest ones are dropped first
+	Append(vehicleId int, t []Telemetry) (err error)
+}
+
+// RepositoryAlert is an interface that represents an alert repository
+type RepositoryAlert interface {
+	// Find is a method that returns the alerts that match the query, oldest first
+	Find(query AlertQuery) (a []Alert, err error)
+
+	// Save is a method that adds an alert. The id is replaced by the next free id
+	// - the alerts kept are bounded: the oldest ones are dropped first
+	Save(a *Alert) (err error)
+
+	// Acknowledge is a method that marks the alert that matches the id as acknowledged, if it was not
+	Acknowledge(id int, by string, at time.Time) (a Alert, err error)
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrServiceInvalidTelemetry is an error that represents a reading with invalid attributes
+	ErrServiceInvalidTelemetry = errors.New("service: invalid telemetry")
+	// ErrServiceAlertNotFound is an error that represents an alert that does not exist
+	ErrServiceAlertNotFound = errors.New("service: alert not found")
+)
+
+// TelemetryQuery is a struct that represents a query over the readings of a vehicle
+type TelemetryQuery struct {
+	// From is the instant from which readings are returned (zero for the oldest one)
+	From time.Time
+	// To is the instant until which readings are returned, excluded (zero for the latest one)
+	To time.Time
+	// Step is the width of the buckets the readings are downsampled to (zero for the raw readings)
+	Step time.Duration
+}
+
+// ServiceTelemetry is an interface that represents a telemetry service
+type ServiceTelemetry interface {
+	// Ingest is a method that validates and stores readings of an existing vehicle, and returns the alerts they raised
+	// - the readings must be oldest first and after the latest stored one: a batch is stored whole or not at all
+	// - a reading above the max speed of the vehicle raises an overspeed alert
+	// - a reading that the vehicle could not reach from the previous one raises an impossible-jump alert
+	Ingest(ctx context.Context, vehicleId int, t []Telemetry) (a []Alert, err error)
+
+	// FindRange is a method that returns the readings of the vehicle that match the query, oldest first
+	// - with a step, each bucket [start, start+step) is a reading at its start with the mean speed and the position and odometer of its latest reading
+	FindRange(vehicleId int, query TelemetryQuery) (t []Telemetry, err error)
+
+	// FindAlerts is a method that returns the alerts that match the query, oldest first
+	FindAlerts(query AlertQuery) (a []Alert, err error)
+
+	// Acknowledge is a method that marks the alert as acknowledged by the principal of ctx
+	// - acknowledging an alert again keeps the first acknowledgement
+	Acknowledge(ctx context.Context, id int) (a Alert, err error)
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidUnits is an error that represents an unknown system of units
+	ErrInvalidUnits = errors.New("invalid units")
+)
+
+// MassUnit is a type that represents a unit of mass
+type MassUnit string
+
+const (
+	// MassUnitKilogram is the kilogram
+	MassUnitKilogram MassUnit = "kg"
+	// MassUnitPound is the avoirdupois pound
+	MassUnitPound MassUnit = "lb"
+)
+
+// LengthUnit is a type that represents a unit of length
+type LengthUnit string
+
+const (
+	// LengthUnitCentimeter is the centimeter
+	LengthUnitCentimeter LengthUnit = "cm"
+	// LengthUnitInch is the international inch
+	LengthUnitInch LengthUnit = "in"
+)
+
+// SpeedUnit is a type that represents a unit of speed
+type SpeedUnit string
+
+const (
+	// SpeedUnitKilometersPerHour is the kilometer per hour
+	SpeedUnitKilometersPerHour SpeedUnit = "km/h"
+	// SpeedUnitMilesPerHour is the mile per hour
+	SpeedUnitMilesPerHour SpeedUnit = "mph"
+)
+
+// DistanceUnit is a type that represents a unit of distance
+type DistanceUnit string
+
+const (
+	// DistanceUnitKilometer is the kilometer
+	DistanceUnitKilometer DistanceUnit = "km"
+	// DistanceUnitMile is the international mile
+	DistanceUnitMile DistanceUnit = "mi"
+)
+
+// Units is a struct that represents the units in which quantities are expressed
+type Units struct {
+	// System is the name of the system of units
+	System string
+	// Mass is the unit of the weight
+	Mass MassUnit
+	// Length is the unit of the dimensions
+	Length LengthUnit
+	// Speed is the unit of the max speed
+	Speed SpeedUnit
+	// Distance is the unit of the odometer readings
+	Distance DistanceUnit
+}
+
+var (
+	// UnitsMetric are the metric units. They are the units stored in the domain
+	UnitsMetric = Units{System: "metric", Mass: MassUnitKilogram, Length: LengthUnitCentimeter, Speed: SpeedUnitKilometersPerHour, Distance: DistanceUnitKilometer}
+	// UnitsImperial are the imperial units
+	UnitsImperial = Units{System: "imperial", Mass: MassUnitPound, Length: LengthUnitInch, Speed: SpeedUnitMilesPerHour, Distance: DistanceUnitMile}
+)
+
+// ParseUnits is a function that returns the units of a system of units ("metric" or "imperial")
+func ParseUnits(system string) (u Units, err error) {
+	switch system {
+	case UnitsMetric.System:
+		u = UnitsMetric
+	case UnitsImperial.System:
+		u = UnitsImperial
+	default:
+		err = fmt.Errorf("%w: %q (allowed: metric, imperial)", ErrInvalidUnits, system)
+	}
+	return
+}
+
+// Mass is a type that represents a mass, stored in kilograms
+type Mass float64
+
+// NewMass is a function that returns the mass of a value expressed in the unit
+func NewMass(value float64, u MassUnit) Mass {
+	if u == MassUnitPound {
+		return Mass(value * 0.45359237)
+	}
+	return Mass(value)
+}
+
+// In is a method that returns the mass expressed in the unit
+func (m Mass) In(u MassUnit) float64 {
+	if u == MassUnitPound {
+		return float64(m) / 0.45359237
+	}
+	return float64(m)
+}
+
+// Length is a type that represents a length, stored in centimeters
+type Length float64
+
+// NewLength is a function that returns the length of a value expressed in the unit
+func NewLength(value float64, u LengthUnit) Length {
+	if u == LengthUnitInch {
+		return Length(value * 2.54)
+	}
+	return Length(value)
+}
+
+// In is a method that returns the length expressed in the unit
+func (l Length) In(u LengthUnit) float64 {
+	if u == LengthUnitInch {
+		return float64(l) / 2.54
+	}
+	return float64(l)
+}
+
+// Speed is a type that represents a speed, stored in kilometers per hour
+type Speed float64
+
+// NewSpeed is a function that returns the speed of a value expressed in the unit
+func NewSpeed(value float64, u SpeedUnit) Speed {
+	if u == SpeedUnitMilesPerHour {
+		return Speed(value * 1.609344)
+	}
+	return Speed(value)
+}
+
+// In is a method that returns the speed expressed in the unit
+func (s Speed) In(u SpeedUnit) float64 {
+	if u == SpeedUnitMilesPerHour {
+		return float64(s) / 1.609344
+	}
+	return float64(s)
+}
+
+// Distance is a type that represents a distance, stored in kilometers
+type Distance float64
+
+// NewDistance is a function that returns the distance of a value expressed in the unit
+func NewDistance(value float64, u DistanceUnit) Distance {
+	if u == DistanceUnitMile {
+		return Distance(value * 1.609344)
+	}
+	return Distance(value)
+}
+
+// In is a method that returns the distance expressed in the unit
+func (d Distance) In(u DistanceUnit) float64 {
+	if u == DistanceUnitMile {
+		return float64(d) / 1.609344
+	}
+	return float64(d)
+}
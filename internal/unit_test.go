@@ -0,0 +1,95 @@
+package internal_test
+
+import (
+	"app/internal"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ParseUnits function
+func TestParseUnits(t *testing.T) {
+	cases := []struct {
+		name     string
+		system   string
+		expected internal.Units
+		err      error
+	}{
+		{name: "case 01: metric", system: "metric", expected: internal.UnitsMetric},
+		{name: "case 02: imperial", system: "imperial", expected: internal.UnitsImperial},
+		{name: "case 03: unknown system", system: "nautical", err: internal.ErrInvalidUnits},
+		{name: "case 04: the system is case sensitive", system: "Metric", err: internal.ErrInvalidUnits},
+		{name: "case 05: empty system", system: "", err: internal.ErrInvalidUnits},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// act
+			u, err := internal.ParseUnits(c.system)
+
+			// assert
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, u)
+		})
+	}
+}
+
+// Tests for the conversions of the quantities
+func TestUnitConversions(t *testing.T) {
+	t.Run("case 01: imperial values are stored in metric units", func(t *testing.T) {
+		// act
+		mass := internal.NewMass(1, internal.MassUnitPound)
+		length := internal.NewLength(1, internal.LengthUnitInch)
+		speed := internal.NewSpeed(1, internal.SpeedUnitMilesPerHour)
+		distance := internal.NewDistance(1, internal.DistanceUnitMile)
+
+		// assert
+		require.InDelta(t, 0.45359237, float64(mass), 1e-12)
+		require.InDelta(t, 2.54, float64(length), 1e-12)
+		require.InDelta(t, 1.609344, float64(speed), 1e-12)
+		require.InDelta(t, 1.609344, float64(distance), 1e-12)
+	})
+
+	t.Run("case 02: metric values are stored as they are", func(t *testing.T) {
+		// act
+		mass := internal.NewMass(1500, internal.MassUnitKilogram)
+		length := internal.NewLength(450, internal.LengthUnitCentimeter)
+		speed := internal.NewSpeed(180, internal.SpeedUnitKilometersPerHour)
+		distance := internal.NewDistance(12000, internal.DistanceUnitKilometer)
+
+		// assert
+		require.Equal(t, internal.Mass(1500), mass)
+		require.Equal(t, internal.Length(450), length)
+		require.Equal(t, internal.Speed(180), speed)
+		require.Equal(t, internal.Distance(12000), distance)
+	})
+
+	t.Run("case 03: stored values are expressed in the requested unit", func(t *testing.T) {
+		// act
+		pounds := internal.Mass(1000).In(internal.MassUnitPound)
+		inches := internal.Length(254).In(internal.LengthUnitInch)
+		mph := internal.Speed(160.9344).In(internal.SpeedUnitMilesPerHour)
+		miles := internal.Distance(16.09344).In(internal.DistanceUnitMile)
+		kg := internal.Mass(1000).In(internal.MassUnitKilogram)
+
+		// assert
+		require.InDelta(t, 2204.6226218, pounds, 1e-6)
+		require.InDelta(t, 100, inches, 1e-9)
+		require.InDelta(t, 100, mph, 1e-9)
+		require.InDelta(t, 10, miles, 1e-9)
+		require.Equal(t, 1000.0, kg)
+	})
+
+	t.Run("case 04: a value converted back and forth keeps its value", func(t *testing.T) {
+		for _, value := range []float64{0, 0.5, 1, 73.25, 1e6} {
+			require.InDelta(t, value, internal.NewMass(value, internal.MassUnitPound).In(internal.MassUnitPound), 1e-9)
+			require.InDelta(t, value, internal.NewLength(value, internal.LengthUnitInch).In(internal.LengthUnitInch), 1e-9)
+			require.InDelta(t, value, internal.NewSpeed(value, internal.SpeedUnitMilesPerHour).In(internal.SpeedUnitMilesPerHour), 1e-9)
+			require.InDelta(t, value, internal.NewDistance(value, internal.DistanceUnitMile).In(internal.DistanceUnitMile), 1e-9)
+		}
+	})
+}
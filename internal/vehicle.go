@@ -1,46 +1,50 @@
-package internal
-
-// Dimensions is a struct that represents a dimension in 3d
-type Dimensions struct {
-	// Height is the height of the dimension
-	Height float64
-	// Length is the length of the dimension
-	Length float64
-	// Width is the width of the dimension
-	Width float64
-}
-
-// VehicleAttributes is a struct that represents the attributes of a vehicle
-type VehicleAttributes struct {
-	// Brand is the brand of the vehicle
-	Brand string
-	// Model is the model of the vehicle
-	Model string
-	// Registration is the registration of the vehicle
-	Registration string
-	// Color is the color of the vehicle
-	Color string
-	// FabricationYear is the fabrication year of the vehicle
-	FabricationYear int
-	// Capacity is the capacity of people of the vehicle
-	Capacity int
-	// MaxSpeed is the maximum speed of the vehicle
-	MaxSpeed float64
-	// FuelType is the fuel type of the vehicle
-	FuelType string
-	// Transmission is the transmission of the vehicle
-	Transmission string
-	// Weight is the weight of the vehicle
-	Weight float64
-	// Dimensions is the dimensions of the vehicle
-	Dimensions
-}
-
-// Vehicle is a struct that represents a vehicle
-type Vehicle struct {
-	// Id is the unique identifier of the vehicle
-	Id int
-
-	// VehicleAttribue is the attributes of a vehicle
-	VehicleAttributes
-}
+package internal
+
+// MinFabricationYear is the first fabrication year of a vehicle, the one of the first automobile
+// - the last one is the next year, as the models of the next year are sold from the current one
+const MinFabricationYear = 1886
+
+// Dimensions is a struct that represents a dimension in 3d
+type Dimensions struct {
+	// Height is the height of the dimension
+	Height Length
+	// Length is the length of the dimension
+	Length Length
+	// Width is the width of the dimension
+	Width Length
+}
+
+// VehicleAttributes is a struct that represents the attributes of a vehicle
+type VehicleAttributes struct {
+	// Brand is the brand of the vehicle
+	Brand string
+	// Model is the model of the vehicle
+	Model string
+	// Registration is the registration of the vehicle
+	Registration string
+	// Color is the color of the vehicle
+	Color string
+	// FabricationYear is the fabrication year of the vehicle
+	FabricationYear int
+	// Capacity is the capacity of people of the vehicle
+	Capacity int
+	// MaxSpeed is the maximum speed of the vehicle
+	MaxSpeed Speed
+	// FuelType is the fuel type of the vehicle
+	FuelType FuelType
+	// Transmission is the transmission of the vehicle
+	Transmission Transmission
+	// Weight is the weight of the vehicle
+	Weight Mass
+	// Dimensions is the dimensions of the vehicle
+	Dimensions
+}
+
+// Vehicle is a struct that represents a vehicle
+type Vehicle struct {
+	// Id is the unique identifier of the vehicle
+	Id int
+
+	// VehicleAttribue is the attributes of a vehicle
+	VehicleAttributes
+}
@@ -0,0 +1,26 @@
+package internal
+
+// ChangesVehicle is a struct that represents the changes of the vehicles of a fleet since a position of its sequence
+// - the changes are compacted: a vehicle appears once, with its last state, as an upsert or as a tombstone
+type ChangesVehicle struct {
+	// Seq is the position of the last change included, the since of the next request
+	Seq uint64
+	// Resync is true if the changes are not incremental: the upserts are the whole fleet and the local copy must be replaced
+	Resync bool
+	// Upserts are the vehicles created, updated or restored, by id
+	Upserts []Vehicle
+	// Tombstones are the ids of the vehicles deleted, ascending
+	Tombstones []int
+}
+
+// ServiceChangesVehicle is an interface that represents the feed of the changes of the vehicles of a fleet
+// - it is a publisher: the events of the vehicles advance the sequence of the fleet
+// - the sequence starts over from a higher value when the dataset is reloaded, so every position before is stale
+type ServiceChangesVehicle interface {
+	PublisherEvent
+
+	// Since is a method that returns the changes after the position
+	// - since: the seq of the previous changes (0: the whole fleet)
+	// - a position that is stale, or unknown (e.g. from before a restart), returns the whole fleet with resync
+	Since(since uint64) (c ChangesVehicle, err error)
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrInvalidFuelType is an error that represents an unknown fuel type
+	ErrInvalidFuelType = errors.New("invalid fuel type")
+	// ErrInvalidTransmission is an error that represents an unknown transmission
+	ErrInvalidTransmission = errors.New("invalid transmission")
+)
+
+// FuelType is a type that represents the fuel type of a vehicle
+type FuelType string
+
+const (
+	// FuelTypeGasoline is the fuel type of gasoline vehicles
+	FuelTypeGasoline FuelType = "gasoline"
+	// FuelTypeDiesel is the fuel type of diesel vehicles
+	FuelTypeDiesel FuelType = "diesel"
+	// FuelTypeBiodiesel is the fuel type of biodiesel vehicles
+	FuelTypeBiodiesel FuelType = "biodiesel"
+	// FuelTypeElectric is the fuel type of electric vehicles
+	FuelTypeElectric FuelType = "electric"
+	// FuelTypeHybrid is the fuel type of hybrid vehicles
+	FuelTypeHybrid FuelType = "hybrid"
+)
+
+// FuelTypes are the allowed fuel types
+var FuelTypes = []FuelType{FuelTypeGasoline, FuelTypeDiesel, FuelTypeBiodiesel, FuelTypeElectric, FuelTypeHybrid}
+
+// fuelTypeAliases is a map of the accepted spellings of each fuel type
+var fuelTypeAliases = map[string]FuelType{
+	"gas":    FuelTypeGasoline,
+	"petrol": FuelTypeGasoline,
+	"bio":    FuelTypeBiodiesel,
+	"ev":     FuelTypeElectric,
+}
+
+// ParseFuelType is a function that returns the canonical fuel type of a value (e.g. "Gas" -> gasoline)
+func ParseFuelType(value string) (f FuelType, err error) {
+	key := enumKey(value)
+	for _, fuelType := range FuelTypes {
+		if key == string(fuelType) {
+			f = fuelType
+			return
+		}
+	}
+	if fuelType, ok := fuelTypeAliases[key]; ok {
+		f = fuelType
+		return
+	}
+
+	err = fmt.Errorf("%w: %q (allowed: %s)", ErrInvalidFuelType, value, joinEnum(FuelTypes))
+	return
+}
+
+// Valid is a method that returns true if the fuel type is one of the allowed values
+func (f FuelType) Valid() bool {
+	for _, fuelType := range FuelTypes {
+		if f == fuelType {
+			return true
+		}
+	}
+	return false
+}
+
+// Transmission is a type that represents the transmission of a vehicle
+type Transmission string
+
+const (
+	// TransmissionManual is the transmission of manual vehicles
+	TransmissionManual Transmission = "manual"
+	// TransmissionAutomatic is the transmission of automatic vehicles
+	TransmissionAutomatic Transmission = "automatic"
+	// TransmissionSemiAutomatic is the transmission of semi-automatic vehicles
+	TransmissionSemiAutomatic Transmission = "semi-automatic"
+)
+
+// Transmissions are the allowed transmissions
+var Transmissions = []Transmission{TransmissionManual, TransmissionAutomatic, TransmissionSemiAutomatic}
+
+// transmissionAliases is a map of the accepted spellings of each transmission
+var transmissionAliases = map[string]Transmission{
+	"stick":         TransmissionManual,
+	"auto":          TransmissionAutomatic,
+	"semiautomatic": TransmissionSemiAutomatic,
+	"semi-auto":     TransmissionSemiAutomatic,
+}
+
+// ParseTransmission is a function that returns the canonical transmission of a value (e.g. "Semi Automatic" -> semi-automatic)
+func ParseTransmission(value string) (t Transmission, err error) {
+	key := enumKey(value)
+	for _, transmission := range Transmissions {
+		if key == string(transmission) {
+			t = transmission
+			return
+		}
+	}
+	if transmission, ok := transmissionAliases[key]; ok {
+		t = transmission
+		return
+	}
+
+	err = fmt.Errorf("%w: %q (allowed: %s)", ErrInvalidTransmission, value, joinEnum(Transmissions))
+	return
+}
+
+// Valid is a method that returns true if the transmission is one of the allowed values
+func (t Transmission) Valid() bool {
+	for _, transmission := range Transmissions {
+		if t == transmission {
+			return true
+		}
+	}
+	return false
+}
+
+// enumKey is a function that returns the lookup key of an enum value: lower case words joined by dashes
+func enumKey(value string) string {
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// joinEnum is a function that returns the values of an enum separated by commas
+func joinEnum[T ~string](values []T) string {
+	s := make([]string, len(values))
+	for i, value := range values {
+		s[i] = string(value)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,89 @@
+package internal_test
+
+import (
+	"app/internal"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ParseFuelType function
+func TestParseFuelType(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		expected internal.FuelType
+		err      error
+	}{
+		{name: "case 01: gasoline", value: "gasoline", expected: internal.FuelTypeGasoline},
+		{name: "case 02: diesel", value: "diesel", expected: internal.FuelTypeDiesel},
+		{name: "case 03: biodiesel", value: "biodiesel", expected: internal.FuelTypeBiodiesel},
+		{name: "case 04: electric", value: "electric", expected: internal.FuelTypeElectric},
+		{name: "case 05: hybrid", value: "hybrid", expected: internal.FuelTypeHybrid},
+		{name: "case 06: upper case and surrounding whitespace", value: "  DIESEL ", expected: internal.FuelTypeDiesel},
+		{name: "case 07: alias gas", value: "Gas", expected: internal.FuelTypeGasoline},
+		{name: "case 08: alias petrol", value: "PETROL", expected: internal.FuelTypeGasoline},
+		{name: "case 09: alias bio", value: "bio", expected: internal.FuelTypeBiodiesel},
+		{name: "case 10: alias ev", value: "EV", expected: internal.FuelTypeElectric},
+		{name: "case 11: unknown value", value: "steam", err: internal.ErrInvalidFuelType},
+		{name: "case 12: empty value", value: "", err: internal.ErrInvalidFuelType},
+		{name: "case 13: words that are not a single fuel type", value: "gas diesel", err: internal.ErrInvalidFuelType},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// act
+			f, err := internal.ParseFuelType(c.value)
+
+			// assert
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				require.Empty(t, f)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, f)
+			require.True(t, f.Valid())
+		})
+	}
+}
+
+// Tests for ParseTransmission function
+func TestParseTransmission(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		expected internal.Transmission
+		err      error
+	}{
+		{name: "case 01: manual", value: "manual", expected: internal.TransmissionManual},
+		{name: "case 02: automatic", value: "automatic", expected: internal.TransmissionAutomatic},
+		{name: "case 03: semi-automatic", value: "semi-automatic", expected: internal.TransmissionSemiAutomatic},
+		{name: "case 04: upper case", value: "MANUAL", expected: internal.TransmissionManual},
+		{name: "case 05: words separated by spaces", value: "Semi Automatic", expected: internal.TransmissionSemiAutomatic},
+		{name: "case 06: words separated by underscores", value: "semi_automatic", expected: internal.TransmissionSemiAutomatic},
+		{name: "case 07: alias stick", value: "Stick", expected: internal.TransmissionManual},
+		{name: "case 08: alias auto", value: "AUTO", expected: internal.TransmissionAutomatic},
+		{name: "case 09: alias semiautomatic", value: "SemiAutomatic", expected: internal.TransmissionSemiAutomatic},
+		{name: "case 10: alias semi-auto", value: "semi auto", expected: internal.TransmissionSemiAutomatic},
+		{name: "case 11: unknown value", value: "cvt", err: internal.ErrInvalidTransmission},
+		{name: "case 12: empty value", value: "", err: internal.ErrInvalidTransmission},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// act
+			tr, err := internal.ParseTransmission(c.value)
+
+			// assert
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				require.Empty(t, tr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, tr)
+			require.True(t, tr.Valid())
+		})
+	}
+}
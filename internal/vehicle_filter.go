@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrInvalidVehicleFilter is an error that represents a filter with unknown values or an empty range
+	ErrInvalidVehicleFilter = errors.New("invalid vehicle filter")
+)
+
+// VehicleFilter is a struct that represents the filters shared by the listings and the analytics of the vehicles
+// - zero values are not used as filters, except the bounds of Min and Max, which filter when present, a vehicle must match every filter
+type VehicleFilter struct {
+	// Brand is the brand of the vehicles, case and accent insensitive
+	Brand string
+	// Color is the color of the vehicles, case and accent insensitive
+	Color string
+	// FuelTypes are the allowed fuel types
+	FuelTypes []FuelType
+	// Transmissions are the allowed transmissions
+	Transmissions []Transmission
+	// FromYear is the first fabrication year
+	FromYear int
+	// ToYear is the last fabrication year
+	ToYear int
+	// Min are the minimum values of the attributes, in the stored units
+	Min map[VehicleMetric]float64
+	// Max are the maximum values of the attributes, in the stored units
+	Max map[VehicleMetric]float64
+}
+
+// Validate is a method that checks the brand, the color, the enums, the attributes and the ranges of the filter
+func (f VehicleFilter) Validate() (err error) {
+	if f.Brand != "" && Blank(f.Brand) {
+		return fmt.Errorf("%w: brand must have a letter or a digit", ErrInvalidVehicleFilter)
+	}
+	if f.Color != "" && Blank(f.Color) {
+		return fmt.Errorf("%w: color must have a letter or a digit", ErrInvalidVehicleFilter)
+	}
+	for _, fuel := range f.FuelTypes {
+		if !fuel.Valid() {
+			return fmt.Errorf("%w: %w: %q", ErrInvalidVehicleFilter, ErrInvalidFuelType, fuel)
+		}
+	}
+	for _, t := range f.Transmissions {
+		if !t.Valid() {
+			return fmt.Errorf("%w: %w: %q", ErrInvalidVehicleFilter, ErrInvalidTransmission, t)
+		}
+	}
+	for _, bounds := range []map[VehicleMetric]float64{f.Min, f.Max} {
+		for m := range bounds {
+			if !slices.Contains(VehicleMetrics, m) {
+				return fmt.Errorf("%w: %w: %q", ErrInvalidVehicleFilter, ErrInvalidVehicleMetric, m)
+			}
+		}
+	}
+	for m, lower := range f.Min {
+		if upper, ok := f.Max[m]; ok && lower > upper {
+			return fmt.Errorf("%w: min of %s is greater than its max", ErrInvalidVehicleFilter, m)
+		}
+	}
+	if f.FromYear != 0 && f.ToYear != 0 && f.FromYear > f.ToYear {
+		return fmt.Errorf("%w: from year is greater than to year", ErrInvalidVehicleFilter)
+	}
+	return
+}
+
+// Matcher is a method that returns a function that is true if the vehicle matches every filter
+// - nz folds the brand and the color, the ones of the filter once, before they are compared
+func (f VehicleFilter) Matcher(nz Normalizer) func(v Vehicle) bool {
+	brand, color := nz.Key("brand", f.Brand), nz.Key("color", f.Color)
+	return func(v Vehicle) bool {
+		if f.Brand != "" && nz.Key("brand", v.Brand) != brand {
+			return false
+		}
+		if f.Color != "" && nz.Key("color", v.Color) != color {
+			return false
+		}
+		if len(f.FuelTypes) > 0 && !slices.Contains(f.FuelTypes, v.FuelType) {
+			return false
+		}
+		if len(f.Transmissions) > 0 && !slices.Contains(f.Transmissions, v.Transmission) {
+			return false
+		}
+		if (f.FromYear != 0 && v.FabricationYear < f.FromYear) || (f.ToYear != 0 && v.FabricationYear > f.ToYear) {
+			return false
+		}
+		for m, lower := range f.Min {
+			if m.Value(v) < lower {
+				return false
+			}
+		}
+		for m, upper := range f.Max {
+			if m.Value(v) > upper {
+				return false
+			}
+		}
+		return true
+	}
+}
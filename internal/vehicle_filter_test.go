@@ -0,0 +1,70 @@
+package internal_test
+
+import (
+	"app/internal"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// normalizerLower is a normalizer that folds the values to lower case
+type normalizerLower struct{}
+
+// Key is a method that returns the value in lower case
+func (normalizerLower) Key(field string, value string) string { return strings.ToLower(value) }
+
+// Canonical is a method that returns the value as it is
+func (normalizerLower) Canonical(field string, value string) string { return value }
+
+// Tests for VehicleFilter.Matcher method
+func TestVehicleFilter_Matcher(t *testing.T) {
+	van := internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Color: "White", Capacity: 0, Weight: 2500}}
+	car := internal.Vehicle{Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Color: "Red", Capacity: 5, Weight: 900}}
+
+	cases := []struct {
+		name     string
+		filter   internal.VehicleFilter
+		expected []bool
+	}{
+		{name: "case 01: no filters", filter: internal.VehicleFilter{}, expected: []bool{true, true}},
+		{name: "case 02: brand and color are folded", filter: internal.VehicleFilter{Brand: "FORD", Color: "white"}, expected: []bool{true, false}},
+		{name: "case 03: an explicit max of 0 filters", filter: internal.VehicleFilter{Max: map[internal.VehicleMetric]float64{internal.VehicleMetricCapacity: 0}}, expected: []bool{true, false}},
+		{name: "case 04: an explicit min of 0 keeps the vehicles at 0", filter: internal.VehicleFilter{Min: map[internal.VehicleMetric]float64{internal.VehicleMetricCapacity: 0}}, expected: []bool{true, true}},
+		{name: "case 05: min and max", filter: internal.VehicleFilter{
+			Min: map[internal.VehicleMetric]float64{internal.VehicleMetricWeight: 1000},
+			Max: map[internal.VehicleMetric]float64{internal.VehicleMetricWeight: 3000},
+		}, expected: []bool{true, false}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			matches := c.filter.Matcher(normalizerLower{})
+
+			// act
+			result := []bool{matches(van), matches(car)}
+
+			// assert
+			require.Equal(t, c.expected, result)
+		})
+	}
+}
+
+// Tests for VehicleFilter.Validate method
+func TestVehicleFilter_Validate(t *testing.T) {
+	// act
+	errZero := internal.VehicleFilter{
+		Min: map[internal.VehicleMetric]float64{internal.VehicleMetricCapacity: 2},
+		Max: map[internal.VehicleMetric]float64{internal.VehicleMetricCapacity: 0},
+	}.Validate()
+	errBrand := internal.VehicleFilter{Brand: "--"}.Validate()
+	errColor := internal.VehicleFilter{Color: " . "}.Validate()
+	errNone := internal.VehicleFilter{Brand: "Rolls-Royce", Color: "Red"}.Validate()
+
+	// assert
+	require.ErrorIs(t, errZero, internal.ErrInvalidVehicleFilter)
+	require.ErrorIs(t, errBrand, internal.ErrInvalidVehicleFilter)
+	require.ErrorIs(t, errColor, internal.ErrInvalidVehicleFilter)
+	require.NoError(t, errNone)
+}
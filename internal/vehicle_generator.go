@@ -0,0 +1,26 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrGeneratorInvalidConfig is an error that represents an invalid configuration of a generator
+	ErrGeneratorInvalidConfig = errors.New("generator: invalid config")
+)
+
+// GeneratedKind is a type that represents the kind of a generated vehicle
+type GeneratedKind string
+
+const (
+	// GeneratedValid is the kind of a new vehicle that passes the validation of the service
+	GeneratedValid GeneratedKind = "valid"
+	// GeneratedDuplicate is the kind of a copy of a vehicle generated before (same id and registration)
+	GeneratedDuplicate GeneratedKind = "duplicate"
+	// GeneratedInvalid is the kind of a vehicle that breaks one validation rule of the service
+	GeneratedInvalid GeneratedKind = "invalid"
+)
+
+// GeneratorVehicle is an interface that represents a source of synthetic vehicles
+type GeneratorVehicle interface {
+	// Next is a method that returns the next vehicle and its kind
+	Next() (v Vehicle, kind GeneratedKind)
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidVehicleCategory is an error that represents an unknown categorical attribute
+	ErrInvalidVehicleCategory = errors.New("invalid vehicle category")
+	// ErrServiceInvalidHistogram is an error that represents an invalid field, buckets or group of a histogram
+	ErrServiceInvalidHistogram = errors.New("service: invalid histogram")
+)
+
+// VehicleCategory is a type that represents a categorical attribute of the vehicles, the ones that can be counted and grouped by
+type VehicleCategory string
+
+const (
+	// VehicleCategoryBrand is the brand
+	VehicleCategoryBrand VehicleCategory = "brand"
+	// VehicleCategoryColor is the color
+	VehicleCategoryColor VehicleCategory = "color"
+	// VehicleCategoryFuelType is the fuel type
+	VehicleCategoryFuelType VehicleCategory = "fuel_type"
+	// VehicleCategoryTransmission is the transmission
+	VehicleCategoryTransmission VehicleCategory = "transmission"
+)
+
+// VehicleCategories are the categorical attributes of the vehicles
+var VehicleCategories = []VehicleCategory{VehicleCategoryBrand, VehicleCategoryColor, VehicleCategoryFuelType, VehicleCategoryTransmission}
+
+// ParseVehicleCategory is a function that returns the categorical attribute of a name
+func ParseVehicleCategory(name string) (c VehicleCategory, err error) {
+	for _, category := range VehicleCategories {
+		if string(category) == name {
+			c = category
+			return
+		}
+	}
+	err = fmt.Errorf("%w: %q (allowed: %s)", ErrInvalidVehicleCategory, name, joinEnum(VehicleCategories))
+	return
+}
+
+// Value is a method that returns the attribute of the vehicle, in its canonical form
+func (c VehicleCategory) Value(v Vehicle) string {
+	switch c {
+	case VehicleCategoryBrand:
+		return v.Brand
+	case VehicleCategoryColor:
+		return v.Color
+	case VehicleCategoryFuelType:
+		return string(v.FuelType)
+	case VehicleCategoryTransmission:
+		return string(v.Transmission)
+	}
+	return ""
+}
+
+// HistogramQuery is a struct that represents the distribution of an attribute among the vehicles that match a filter
+// - the field is either a numeric attribute (Metric) or a categorical one (Category)
+type HistogramQuery struct {
+	// VehicleFilter are the filters of the vehicles counted
+	VehicleFilter
+	// Metric is the numeric attribute, counted in buckets of values
+	Metric VehicleMetric
+	// Category is the categorical attribute, counted by value
+	Category VehicleCategory
+	// Bins is the number of buckets of the same width between the lowest and the highest value (0: 10 unless there are edges)
+	Bins int
+	// Edges are the bounds of the buckets, in the stored units and strictly increasing. Values out of them are not counted
+	Edges []float64
+	// GroupBy is the categorical attribute that splits the counts in series (empty: a single series)
+	GroupBy VehicleCategory
+}
+
+// HistogramBucket is a struct that represents a bucket of a histogram
+// - a numeric bucket includes its lower bound and excludes its upper bound, except the last one that includes both
+type HistogramBucket struct {
+	// Value is the value of the categorical attribute (empty for numeric buckets)
+	Value string
+	// Lower is the lower bound of the numeric bucket, in the stored units
+	Lower float64
+	// Upper is the upper bound of the numeric bucket, in the stored units
+	Upper float64
+}
+
+// HistogramSeries is a struct that represents the counts of a group of vehicles
+type HistogramSeries struct {
+	// Group is the value of the group by attribute (empty without group by)
+	Group string
+	// Counts are the number of vehicles of each bucket, aligned with the buckets of the histogram
+	Counts []int
+	// Total is the number of vehicles counted
+	Total int
+}
+
+// Histogram is a struct that represents the distribution of an attribute among vehicles
+type Histogram struct {
+	// Buckets are the buckets: numeric ones by value, categorical ones by count (highest first) and value
+	Buckets []HistogramBucket
+	// Series are the counts of each group, by group value
+	Series []HistogramSeries
+}
@@ -1,7 +1,7 @@
-package internal
-
-// LoaderVehicle is an interface that represents the loader for vehicles
-type LoaderVehicle interface {
-	// Load is a method that loads the vehicles
-	Load() (v map[int]Vehicle, err error)
+package internal
+
+// LoaderVehicle is an interface that represents the loader for vehicles
+type LoaderVehicle interface {
+	// Load is a method that loads the vehicles
+	Load() (v map[int]Vehicle, err error)
 }
\ No newline at end of file
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidVehicleMetric is an error that represents an unknown numeric attribute
+	ErrInvalidVehicleMetric = errors.New("invalid vehicle metric")
+)
+
+// VehicleMetric is a type that represents a numeric attribute of the vehicles, the ones that can be compared and aggregated
+type VehicleMetric string
+
+const (
+	// VehicleMetricMaxSpeed is the maximum speed, higher is better
+	VehicleMetricMaxSpeed VehicleMetric = "max_speed"
+	// VehicleMetricCapacity is the capacity of people, higher is better
+	VehicleMetricCapacity VehicleMetric = "capacity"
+	// VehicleMetricWeight is the weight, lower is better
+	VehicleMetricWeight VehicleMetric = "weight"
+	// VehicleMetricHeight is the height, lower is better
+	VehicleMetricHeight VehicleMetric = "height"
+	// VehicleMetricLength is the length, lower is better
+	VehicleMetricLength VehicleMetric = "length"
+	// VehicleMetricWidth is the width, lower is better
+	VehicleMetricWidth VehicleMetric = "width"
+)
+
+// VehicleMetrics are the numeric attributes of the vehicles
+var VehicleMetrics = []VehicleMetric{VehicleMetricMaxSpeed, VehicleMetricCapacity, VehicleMetricWeight, VehicleMetricHeight, VehicleMetricLength, VehicleMetricWidth}
+
+// ParseVehicleMetric is a function that returns the numeric attribute of a name
+func ParseVehicleMetric(name string) (m VehicleMetric, err error) {
+	for _, metric := range VehicleMetrics {
+		if string(metric) == name {
+			m = metric
+			return
+		}
+	}
+	err = fmt.Errorf("%w: %q (allowed: %s)", ErrInvalidVehicleMetric, name, joinEnum(VehicleMetrics))
+	return
+}
+
+// HigherIsBetter is a method that returns true if a higher value of the attribute is the better one
+// - a faster vehicle that carries more people is better, a lighter and more compact one too
+func (m VehicleMetric) HigherIsBetter() bool {
+	return m == VehicleMetricMaxSpeed || m == VehicleMetricCapacity
+}
+
+// Value is a method that returns the attribute of the vehicle in the stored units (km/h, kg, cm)
+func (m VehicleMetric) Value(v Vehicle) float64 {
+	switch m {
+	case VehicleMetricMaxSpeed:
+		return float64(v.MaxSpeed)
+	case VehicleMetricCapacity:
+		return float64(v.Capacity)
+	case VehicleMetricWeight:
+		return float64(v.Weight)
+	case VehicleMetricHeight:
+		return float64(v.Height)
+	case VehicleMetricLength:
+		return float64(v.Length)
+	case VehicleMetricWidth:
+		return float64(v.Width)
+	}
+	return 0
+}
+
+// From is a method that returns a value of the attribute, expressed in the units, in the stored units
+func (m VehicleMetric) From(value float64, u Units) float64 {
+	switch m {
+	case VehicleMetricMaxSpeed:
+		return float64(NewSpeed(value, u.Speed))
+	case VehicleMetricWeight:
+		return float64(NewMass(value, u.Mass))
+	case VehicleMetricHeight, VehicleMetricLength, VehicleMetricWidth:
+		return float64(NewLength(value, u.Length))
+	}
+	return value
+}
+
+// In is a method that returns a value of the attribute, in the stored units, expressed in the units
+func (m VehicleMetric) In(value float64, u Units) float64 {
+	switch m {
+	case VehicleMetricMaxSpeed:
+		return Speed(value).In(u.Speed)
+	case VehicleMetricWeight:
+		return Mass(value).In(u.Mass)
+	case VehicleMetricHeight, VehicleMetricLength, VehicleMetricWidth:
+		return Length(value).In(u.Length)
+	}
+	return value
+}
+
+// Unit is a method that returns the symbol of the unit of the attribute in the units (empty for counts)
+func (m VehicleMetric) Unit(u Units) string {
+	switch m {
+	case VehicleMetricMaxSpeed:
+		return string(u.Speed)
+	case VehicleMetricWeight:
+		return string(u.Mass)
+	case VehicleMetricHeight, VehicleMetricLength, VehicleMetricWidth:
+		return string(u.Length)
+	}
+	return ""
+}
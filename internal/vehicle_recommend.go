@@ -0,0 +1,87 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrServiceInvalidRecommendation is an error that represents invalid constraints, preferences or neighbours
+	ErrServiceInvalidRecommendation = errors.New("service: invalid recommendation")
+)
+
+// RecommendPreference is a struct that represents a soft preference over a numeric attribute
+type RecommendPreference struct {
+	// Metric is the attribute
+	Metric VehicleMetric
+	// Weight is the importance of the preference relative to the others, positive
+	Weight float64
+	// Higher is true if the higher values are preferred, false for the lower ones
+	Higher bool
+}
+
+// RecommendQuery is a struct that represents what a vehicle is needed for
+// - hard constraints: zero values are not used as filters, a vehicle that fails one is not recommended
+// - soft preferences: they score and rank the vehicles that pass the constraints
+type RecommendQuery struct {
+	// VehicleFilter are the hard constraints
+	VehicleFilter
+	// Preferences are the soft preferences
+	Preferences []RecommendPreference
+	// Limit is the maximum number of recommendations (0: all)
+	Limit int
+}
+
+// RecommendContribution is a struct that represents the part of a preference in the score of a vehicle
+type RecommendContribution struct {
+	// Preference is the preference
+	Preference RecommendPreference
+	// Value is the value of the attribute of the vehicle, in the stored units
+	Value float64
+	// Score is how well the value meets the preference among the recommended vehicles, from 0 (the worst) to 1 (the best)
+	Score float64
+	// Contribution is the score weighted by the share of the preference in the total weight
+	Contribution float64
+}
+
+// Recommendation is a struct that represents a vehicle that meets the constraints, with the explanation of its score
+type Recommendation struct {
+	// Vehicle is the vehicle
+	Vehicle Vehicle
+	// Score is the sum of the contributions, from 0 to 1 (1 without preferences)
+	Score float64
+	// Contributions are the parts of the preferences in the score, in the order of the preferences
+	Contributions []RecommendContribution
+}
+
+// SimilarHit is a struct that represents a neighbour of a vehicle in a similarity index
+type SimilarHit struct {
+	// Id is the id of the neighbour
+	Id int
+	// Distance is the euclidean distance over the normalized numeric attributes
+	Distance float64
+}
+
+// SimilarResult is a struct that represents a vehicle similar to another one
+type SimilarResult struct {
+	// Vehicle is the similar vehicle
+	Vehicle Vehicle
+	// Distance is the euclidean distance over the normalized numeric attributes, 0 for identical attributes
+	Distance float64
+}
+
+// IndexSimilarVehicle is an interface that represents an index of the vehicles by their numeric attributes
+// - each attribute is normalized to [0, 1] by its range in the fleet, so every attribute weighs the same
+type IndexSimilarVehicle interface {
+	IndexerVehicle
+
+	// Nearest is a method that returns the k vehicles closest to the vehicle of the id, closest first, itself excluded
+	// - ok is false if the vehicle is not indexed
+	Nearest(id int, k int) (h []SimilarHit, ok bool)
+}
+
+// ServiceRecommendVehicle is an interface that represents a service that finds vehicles by requirements or by likeness
+type ServiceRecommendVehicle interface {
+	// Recommend is a method that returns the vehicles that meet the constraints, best score first
+	Recommend(query RecommendQuery) (r []Recommendation, err error)
+
+	// Similar is a method that returns the k vehicles most similar to the vehicle of the id, most similar first
+	Similar(id int, k int) (r []SimilarResult, err error)
+}
@@ -0,0 +1,22 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrInvalidRegistration is an error that represents a registration that does not have the format of the jurisdiction
+	ErrInvalidRegistration = errors.New("invalid registration")
+	// ErrDuplicateRegistration is an error that represents a registration of more than one vehicle of a dataset
+	ErrDuplicateRegistration = errors.New("duplicate registration")
+)
+
+// RegistrationField is the name of the registration for the normalizers
+const RegistrationField = "registration"
+
+// RegistrationRules is an interface that represents the registrations of a jurisdiction
+type RegistrationRules interface {
+	// Canonical is a method that returns the canonical form of a registration, used to store, compare and find registrations
+	Canonical(registration string) (c string)
+
+	// Validate is a method that returns an error if the canonical registration does not have a valid format
+	Validate(registration string) (err error)
+}
@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrServiceDatasetNotLoaded is an error that represents a dataset that could not be loaded again, the fleet left as it was
+	ErrServiceDatasetNotLoaded = errors.New("service: dataset not loaded")
+)
+
+// VehicleReload is a struct that represents the changes of a fleet reloaded from its dataset
+type VehicleReload struct {
+	// Created are the ids of the vehicles added or restored by the reload, ascending
+	Created []int
+	// Updated are the ids of the vehicles replaced by the reload, ascending
+	Updated []int
+	// Deleted are the ids of the vehicles removed by the reload, ascending
+	Deleted []int
+}
+
+// ServiceReloadVehicle is an interface that represents the reload of the vehicles of a fleet from its dataset
+type ServiceReloadVehicle interface {
+	// Reload is a method that loads the dataset again and replaces the vehicles of the fleet by its vehicles
+	// - the changes made since the previous load are discarded, every change is kept as a version in history
+	// - the reload is published as a single dataset.reloaded event, not as an event per vehicle
+	Reload(ctx context.Context) (r VehicleReload, err error)
+}
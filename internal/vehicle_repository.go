@@ -1,27 +1,112 @@
-package internal
-
-import "errors"
-
-var (
-	// ErrRepositoryInvalidFind is an error that represents an invalid find
-	ErrRepositoryInvalidFind = errors.New("repository: invalid find")
-)
-
-// RepositoryReadVehicle is an interface that represents a vehicle repository
-// - method: static. All searchs are strong typed, not hybrid or dynamic
-type RepositoryReadVehicle interface {
-	// FindAll is a method that returns a map of all vehicles
-	FindAll() (v map[int]Vehicle, err error)
-
-	// FindByColorAndYear is a method that returns a map of vehicles that match the color and fabrication year
-	FindByColorAndYear(color string, fabricationYear int) (v map[int]Vehicle, err error)
-
-	// FindByBrandAndYearRange is a method that returns a map of vehicles that match the brand and a range of fabrication years
-	FindByBrandAndYearRange(brand string, startYear int, endYear int) (v map[int]Vehicle, err error)
-
-	// FindByBrand is a method that returns a map of vehicles that match the brand
-	FindByBrand(brand string) (v map[int]Vehicle, err error)
-
-	// FindByWeightRange is a method that returns a map of vehicles that match the weight range
-	FindByWeightRange(fromWeight float64, toWeight float64) (v map[int]Vehicle, err error)
-}
\ No newline at end of file
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRepositoryInvalidFind is an error that represents an invalid find
+	ErrRepositoryInvalidFind = errors.New("repository: invalid find")
+	// ErrRepositoryVehicleNotFound is an error that represents a vehicle that does not exist
+	ErrRepositoryVehicleNotFound = errors.New("repository: vehicle not found")
+	// ErrRepositoryVehicleAlreadyExists is an error that represents a vehicle id that is already taken
+	ErrRepositoryVehicleAlreadyExists = errors.New("repository: vehicle already exists")
+	// ErrRepositoryVehicleNotDeleted is an error that represents the restore of a vehicle that is not deleted
+	ErrRepositoryVehicleNotDeleted = errors.New("repository: vehicle not deleted")
+	// ErrRepositoryRegistrationConflict is an error that represents a registration of more than one vehicle
+	ErrRepositoryRegistrationConflict = errors.New("repository: registration conflict")
+	// ErrRepositoryHistoryTruncated is an error that represents an instant whose versions were dropped from history
+	ErrRepositoryHistoryTruncated = errors.New("repository: history truncated")
+	// ErrRepositoryReadOnly is an error that represents a mutation on a read only repository (e.g. a past snapshot)
+	ErrRepositoryReadOnly = errors.New("repository: read only")
+)
+
+// VehicleVersion is a struct that represents a version of a vehicle
+type VehicleVersion struct {
+	// Version is the number of the version, starting at 1
+	Version int
+	// Vehicle is the state of the vehicle in this version
+	Vehicle Vehicle
+	// ValidFrom is the instant from which this version applies (zero for the loaded dataset)
+	ValidFrom time.Time
+	// ValidTo is the instant from which the next version applies (zero for the current version)
+	ValidTo time.Time
+	// Deleted is true if the vehicle was deleted in this version
+	Deleted bool
+}
+
+// RepositoryReadVehicle is an interface that represents a vehicle repository
+// - method: static. All searchs are strong typed, not hybrid or dynamic
+type RepositoryReadVehicle interface {
+	// FindAll is a method that returns a map of all vehicles
+	FindAll() (v map[int]Vehicle, err error)
+
+	// FindById is a method that returns the vehicle that matches the id
+	FindById(id int) (v Vehicle, err error)
+
+	// FindByColorAndYear is a method that returns a map of vehicles that match the color and fabrication year
+	FindByColorAndYear(color string, fabricationYear int) (v map[int]Vehicle, err error)
+
+	// FindByBrandAndYearRange is a method that returns a map of vehicles that match the brand and a range of fabrication years
+	FindByBrandAndYearRange(brand string, startYear int, endYear int) (v map[int]Vehicle, err error)
+
+	// FindByBrand is a method that returns a map of vehicles that match the brand
+	FindByBrand(brand string) (v map[int]Vehicle, err error)
+
+	// FindByWeightRange is a method that returns a map of vehicles that match the weight range
+	FindByWeightRange(fromWeight Mass, toWeight Mass) (v map[int]Vehicle, err error)
+
+	// FindByFilter is a method that returns a map of vehicles that match every filter
+	FindByFilter(f VehicleFilter) (v map[int]Vehicle, err error)
+
+	// FindByRegistration is a method that returns a map of vehicles that match the registration
+	// - more than one vehicle only for the duplicates of a loaded dataset, none is an error
+	FindByRegistration(registration string) (v map[int]Vehicle, err error)
+}
+
+// RepositoryWriteVehicle is an interface that represents the mutations of a vehicle repository
+// - ctx carries the caller of the mutation (principal, request id)
+// - registrations are unique: a mutation that gives a vehicle the registration of another one is rejected
+type RepositoryWriteVehicle interface {
+	// Save is a method that adds a vehicle. A zero id is replaced by the next free id
+	Save(ctx context.Context, v *Vehicle) (err error)
+
+	// Update is a method that replaces the vehicle with the same id
+	Update(ctx context.Context, v *Vehicle) (err error)
+
+	// Delete is a method that removes the vehicle that matches the id
+	Delete(ctx context.Context, id int) (err error)
+}
+
+// RepositoryHistoryVehicle is an interface that represents the version history of a vehicle repository
+// - every mutation adds a version, deletes are soft and can be restored
+type RepositoryHistoryVehicle interface {
+	// FindHistory is a method that returns the kept versions of the vehicle, oldest first
+	// - every version, unless the implementation caps them: the first version is always kept
+	FindHistory(id int) (h []VehicleVersion, err error)
+
+	// AsOf is a method that returns a read only repository with the fleet as it was at the instant
+	// - an instant whose versions were dropped from history is an error (ErrRepositoryHistoryTruncated)
+	AsOf(t time.Time) (rp RepositoryVehicle, err error)
+
+	// Restore is a method that undeletes the vehicle with its last state
+	Restore(ctx context.Context, id int) (v Vehicle, err error)
+}
+
+// RepositoryReloadVehicle is an interface that represents the reload of a vehicle repository from its dataset
+type RepositoryReloadVehicle interface {
+	// Reload is a method that replaces the current vehicles by the ones of the dataset, every change kept as a version in history
+	// - the vehicles are stored as they are loaded, like the ones of the first load: their registrations are not checked
+	// - the vehicles that are not in the dataset are deleted, the deleted ones that are in it are restored
+	Reload(ctx context.Context, db map[int]Vehicle) (r VehicleReload, err error)
+}
+
+// RepositoryVehicle is an interface that represents a vehicle repository that can be read and written
+type RepositoryVehicle interface {
+	RepositoryReadVehicle
+	RepositoryWriteVehicle
+	RepositoryHistoryVehicle
+	RepositoryReloadVehicle
+}
@@ -0,0 +1,40 @@
+package internal
+
+// SearchHit is a struct that represents a vehicle that matches a full-text search
+type SearchHit struct {
+	// Id is the id of the vehicle
+	Id int
+	// Score is the relevance of the vehicle for the search, higher is better
+	Score float64
+}
+
+// SearchResult is a struct that represents a vehicle found by a full-text search
+type SearchResult struct {
+	// Vehicle is the vehicle found
+	Vehicle Vehicle
+	// Score is the relevance of the vehicle for the search, higher is better
+	Score float64
+}
+
+// IndexerVehicle is an interface that represents an index over the vehicles, kept in sync with their mutations
+type IndexerVehicle interface {
+	// Index is a method that adds or replaces the vehicle in the index
+	Index(v Vehicle)
+
+	// Remove is a method that removes the vehicle from the index
+	Remove(id int)
+}
+
+// IndexVehicle is an interface that represents a full-text index over the vehicles
+type IndexVehicle interface {
+	IndexerVehicle
+
+	// Search is a method that returns the vehicles that match the text, most relevant first
+	Search(text string, limit int) (h []SearchHit)
+}
+
+// ServiceSearchVehicle is an interface that represents a full-text search service over the vehicles
+type ServiceSearchVehicle interface {
+	// Search is a method that returns the vehicles that match the text, most relevant first
+	Search(text string, limit int) (r []SearchResult, err error)
+}
@@ -1,42 +1,141 @@
-package internal
-
-import "errors"
-
-var (
-	// ErrServiceInvalidFind is an error that represents an invalid find
-	ErrServiceInvalidFind = errors.New("service: invalid find")
-	// ErrServiceInvalidSearch is an error that represents an invalid search
-	ErrServiceInvalidSearch = errors.New("service: invalid search")
-	// ErrServiceNoVehicles is an error that represents no vehicles
-	ErrServiceNoVehicles = errors.New("service: no vehicles")
-)
-
-// SearchQuery is a struct that represents a search query
-type SearchQuery struct {
-	// FromWeight is the minimum weight
-	FromWeight float64
-	// ToWeight is the maximum weight
-	ToWeight float64
-}
-
-// ServiceVehicle is an interface that represents a vehicle service
-type ServiceVehicle interface {
-	// FindByColorAndYear is a method that returns a map of vehicles that match the color and fabrication year
-	FindByColorAndYear(color string, fabricationYear int) (v map[int]Vehicle, err error)
-
-	// FindByBrandAndYearRange is a method that returns a map of vehicles that match the brand and a range of fabrication years
-	FindByBrandAndYearRange(brand string, startYear int, endYear int) (v map[int]Vehicle, err error)
-
-	// AverageMaxSpeedByBrand is a method that returns the average speed of the vehicles by brand
-	AverageMaxSpeedByBrand(brand string) (a float64, err error)
-
-	// AverageCapacityByBrand is a method that returns the average capacity of the vehicles by brand
-	AverageCapacityByBrand(brand string) (a int, err error)
-
-	// SearchByWeightRange
-	// - method: hybrid. usage of static procedure and static optional (not dynamic types such as maps or slices)
-	// - query:
-	// 	 !ok -> will return all vehicles
-	// 	 ok  -> will return filtered vehicles
-	SearchByWeightRange(query SearchQuery, ok bool) (v map[int]Vehicle, err error)
-}
\ No newline at end of file
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrServiceInvalidFind is an error that represents an invalid find
+	ErrServiceInvalidFind = errors.New("service: invalid find")
+	// ErrServiceInvalidSearch is an error that represents an invalid search
+	ErrServiceInvalidSearch = errors.New("service: invalid search")
+	// ErrServiceNoVehicles is an error that represents no vehicles
+	ErrServiceNoVehicles = errors.New("service: no vehicles")
+	// ErrServiceVehicleNotFound is an error that represents a vehicle that does not exist
+	ErrServiceVehicleNotFound = errors.New("service: vehicle not found")
+	// ErrServiceVehicleAlreadyExists is an error that represents a vehicle id that is already taken
+	ErrServiceVehicleAlreadyExists = errors.New("service: vehicle already exists")
+	// ErrServiceInvalidVehicle is an error that represents a vehicle with invalid attributes
+	ErrServiceInvalidVehicle = errors.New("service: invalid vehicle")
+	// ErrServiceVehicleNotDeleted is an error that represents the restore of a vehicle that is not deleted
+	ErrServiceVehicleNotDeleted = errors.New("service: vehicle not deleted")
+	// ErrServiceRegistrationConflict is an error that represents a registration of more than one vehicle
+	ErrServiceRegistrationConflict = errors.New("service: registration conflict")
+	// ErrServiceHistoryTruncated is an error that represents an instant before the kept history of the fleet
+	ErrServiceHistoryTruncated = errors.New("service: history truncated")
+	// ErrServiceReadOnly is an error that represents a mutation on a past snapshot of the fleet
+	ErrServiceReadOnly = errors.New("service: read only")
+	// ErrServiceInvalidCompare is an error that represents an invalid comparison
+	ErrServiceInvalidCompare = errors.New("service: invalid compare")
+	// ErrServiceInvalidFilter is an error that represents an invalid filter
+	ErrServiceInvalidFilter = errors.New("service: invalid filter")
+)
+
+// MaxCompareIds is the maximum number of vehicles compared side by side
+const MaxCompareIds = 20
+
+// SearchQuery is a struct that represents a search query
+type SearchQuery struct {
+	// FromWeight is the minimum weight
+	FromWeight Mass
+	// ToWeight is the maximum weight
+	ToWeight Mass
+}
+
+// AverageQuery is a struct that represents the average of an attribute among the vehicles that match the filter
+type AverageQuery struct {
+	VehicleFilter
+	// Metric is the averaged attribute
+	Metric VehicleMetric
+}
+
+// ComparisonRow is a struct that represents a numeric attribute of the compared vehicles
+// - the slices are aligned with the vehicles of the comparison
+type ComparisonRow struct {
+	// Metric is the attribute
+	Metric VehicleMetric
+	// Values are the values of the vehicles, in the stored units
+	Values []float64
+	// Best are the ids of the vehicles with the best value (more than one on a tie)
+	Best []int
+	// Worst are the ids of the vehicles with the worst value (more than one on a tie)
+	Worst []int
+	// PercentileBrand are the percentages of the vehicles of the same brand with a value lower or equal
+	PercentileBrand []float64
+	// PercentileFleet are the percentages of the vehicles of the fleet with a value lower or equal
+	PercentileFleet []float64
+}
+
+// Comparison is a struct that represents vehicles side by side, attribute by attribute
+type Comparison struct {
+	// Vehicles are the compared vehicles, in the requested order
+	Vehicles []Vehicle
+	// Rows are the numeric attributes, in the order of VehicleMetrics
+	Rows []ComparisonRow
+}
+
+// ServiceVehicle is an interface that represents a vehicle service
+type ServiceVehicle interface {
+	// FindById is a method that returns the vehicle that matches the id
+	FindById(id int) (v Vehicle, err error)
+
+	// FindByRegistration is a method that returns a map of vehicles that match the registration
+	// - more than one vehicle only for the duplicates of a loaded dataset, none is an error
+	FindByRegistration(registration string) (v map[int]Vehicle, err error)
+
+	// FindByColorAndYear is a method that returns a map of vehicles that match the color and fabrication year
+	FindByColorAndYear(color string, fabricationYear int) (v map[int]Vehicle, err error)
+
+	// FindByBrandAndYearRange is a method that returns a map of vehicles that match the brand and a range of fabrication years
+	FindByBrandAndYearRange(brand string, startYear int, endYear int) (v map[int]Vehicle, err error)
+
+	// AverageMaxSpeedByBrand is a method that returns the average speed of the vehicles by brand
+	AverageMaxSpeedByBrand(brand string) (a Speed, err error)
+
+	// AverageCapacityByBrand is a method that returns the average capacity of the vehicles by brand
+	AverageCapacityByBrand(brand string) (a int, err error)
+
+	// Compare is a method that returns the vehicles that match the ids side by side
+	// - at least two distinct ids, at most MaxCompareIds
+	Compare(ids []int) (c Comparison, err error)
+
+	// FindByFilter is a method that returns a map of vehicles that match every filter
+	FindByFilter(f VehicleFilter) (v map[int]Vehicle, err error)
+
+	// Average is a method that returns the average of an attribute among the vehicles that match the filter, in the stored units
+	Average(query AverageQuery) (a float64, err error)
+
+	// Histogram is a method that returns the distribution of an attribute among the vehicles that match the filter
+	Histogram(query HistogramQuery) (h Histogram, err error)
+
+	// Trends is a method that returns the average of an attribute by fabrication year among the vehicles that match the filter
+	Trends(query TrendQuery) (t Trend, err error)
+
+	// SearchByWeightRange
+	// - method: hybrid. usage of static procedure and static optional (not dynamic types such as maps or slices)
+	// - query:
+	// 	 !ok -> will return all vehicles
+	// 	 ok  -> will return filtered vehicles
+	SearchByWeightRange(query SearchQuery, ok bool) (v map[int]Vehicle, err error)
+
+	// Create is a method that validates and adds a vehicle. A zero id is replaced by the next free id
+	Create(ctx context.Context, v *Vehicle) (err error)
+
+	// Update is a method that validates and replaces the vehicle with the same id
+	Update(ctx context.Context, v *Vehicle) (err error)
+
+	// Delete is a method that removes the vehicle that matches the id
+	Delete(ctx context.Context, id int) (err error)
+
+	// FindHistory is a method that returns every version of the vehicle, oldest first
+	FindHistory(id int) (h []VehicleVersion, err error)
+
+	// Restore is a method that undeletes the vehicle with its last state
+	Restore(ctx context.Context, id int) (v Vehicle, err error)
+
+	// AsOf is a method that returns a read only service over the fleet as it was at the instant
+	// - an instant whose versions were dropped from history is an error (ErrServiceHistoryTruncated)
+	AsOf(t time.Time) (sv ServiceVehicle, err error)
+}
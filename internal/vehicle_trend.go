@@ -0,0 +1,56 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrServiceInvalidTrend is an error that represents an invalid metric, group or window of a trend, or too many years
+	ErrServiceInvalidTrend = errors.New("service: invalid trend")
+)
+
+// MaxTrendYears is the maximum number of years of a trend, from the first to the last fabrication year of the vehicles
+// - more than every valid fabrication year, so that only the years of the vehicles loaded as they were can exceed it
+const MaxTrendYears = 200
+
+// TrendQuery is a struct that represents the evolution of a numeric attribute across the fabrication years
+type TrendQuery struct {
+	// VehicleFilter are the filters of the vehicles averaged
+	VehicleFilter
+	// Metric is the numeric attribute averaged
+	Metric VehicleMetric
+	// GroupBy is the categorical attribute that splits the vehicles in series (empty: a single series)
+	GroupBy VehicleCategory
+	// Window is the number of years of the moving average, the year and the previous ones (0: 3)
+	Window int
+}
+
+// TrendPoint is a struct that represents a fabrication year of a series
+// - the values are nil for the years without vehicles (gaps), in the stored units otherwise
+type TrendPoint struct {
+	// Year is the fabrication year
+	Year int
+	// Count is the number of vehicles of the year
+	Count int
+	// Average is the average of the attribute among the vehicles of the year
+	Average *float64
+	// MovingAverage is the average of the attribute among the vehicles of the years of the window, nil without vehicles in the window
+	MovingAverage *float64
+	// Delta is the difference between the average of the year and the one of the previous year, nil if either is a gap
+	Delta *float64
+}
+
+// TrendSeries is a struct that represents the evolution of a group of vehicles
+type TrendSeries struct {
+	// Group is the value of the group by attribute (empty without group by)
+	Group string
+	// Points are the fabrication years, every year of the trend in order
+	Points []TrendPoint
+}
+
+// Trend is a struct that represents the evolution of a numeric attribute across the fabrication years
+// - every series has a point for every year between the first and the last fabrication year of the vehicles
+type Trend struct {
+	// Years are the fabrication years, in order
+	Years []int
+	// Series are the series, by group value
+	Series []TrendSeries
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderWebhookId is the header of a delivery with the id of the event, the same on every attempt
+	HeaderWebhookId = "X-Webhook-Id"
+	// HeaderWebhookEvent is the header of a delivery with the type of the event
+	HeaderWebhookEvent = "X-Webhook-Event"
+	// HeaderWebhookAttempt is the header of a delivery with the number of the attempt, starting at 1
+	HeaderWebhookAttempt = "X-Webhook-Attempt"
+	// HeaderWebhookTimestamp is the header of a delivery with the unix seconds of the attempt, part of the signature
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	// HeaderWebhookSignature is the header of a delivery with the signature of the timestamp and the body (see Webhook.Sign)
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// Webhook is a struct that represents a subscription of an url to the events
+type Webhook struct {
+	// Id is the unique identifier of the webhook
+	Id int
+	// URL is the endpoint where the events are posted
+	URL string
+	// Secret is the key of the signature of the deliveries
+	Secret string
+	// Events are the event types delivered (empty: every type)
+	Events []EventType
+	// Fleet is the only fleet whose events are delivered (empty: every fleet)
+	Fleet string
+	// CreatedBy is the subject of the principal that created the webhook
+	CreatedBy string
+	// CreatedAt is the instant when the webhook was created
+	CreatedAt time.Time
+}
+
+// Subscribed is a method that returns true if the event is delivered to the webhook
+func (w Webhook) Subscribed(e Event) bool {
+	if w.Fleet != "" && w.Fleet != e.Fleet {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, t := range w.Events {
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign is a method that returns the signature of a delivery: sha256= and the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret
+func (w Webhook) Sign(timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliveryStatus is a type that represents the outcome of a delivery attempt
+type DeliveryStatus string
+
+const (
+	// DeliveryStatusDelivered is an attempt answered with a 2xx status
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	// DeliveryStatusRetrying is a failed attempt that will be retried
+	DeliveryStatusRetrying DeliveryStatus = "retrying"
+	// DeliveryStatusDead is the last failed attempt: the event goes to the dead letters
+	DeliveryStatusDead DeliveryStatus = "dead"
+)
+
+// Delivery is a struct that represents an attempt to post an event to a webhook
+type Delivery struct {
+	// Id is the unique identifier of the attempt
+	Id int
+	// WebhookId is the id of the webhook
+	WebhookId int
+	// EventId is the id of the event
+	EventId string
+	// EventType is the type of the event
+	EventType EventType
+	// Attempt is the number of the attempt, starting at 1
+	Attempt int
+	// Time is the instant of the attempt
+	Time time.Time
+	// StatusCode is the status of the response (0 if there was none)
+	StatusCode int
+	// Error is the reason of the failure (empty if it was delivered)
+	Error string
+	// Status is the outcome of the attempt
+	Status DeliveryStatus
+}
+
+// DeadLetter is a struct that represents an event that could not be delivered to a webhook
+type DeadLetter struct {
+	// Id is the unique identifier of the dead letter
+	Id int
+	// WebhookId is the id of the webhook
+	WebhookId int
+	// Event is the undelivered event
+	Event Event
+	// Attempts is the number of failed attempts
+	Attempts int
+	// LastError is the reason of the last failure
+	LastError string
+	// Time is the instant of the last attempt
+	Time time.Time
+}
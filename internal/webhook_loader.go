@@ -0,0 +1,13 @@
+package internal
+
+// LoaderWebhook is an interface that represents the loader for webhooks
+type LoaderWebhook interface {
+	// Load is a method that loads the webhooks
+	Load() (w []Webhook, err error)
+}
+
+// PersisterWebhook is an interface that represents the storage where the webhooks are persisted
+type PersisterWebhook interface {
+	// Persist is a method that replaces the stored webhooks with the given ones
+	Persist(w []Webhook) (err error)
+}
@@ -0,0 +1,46 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrRepositoryWebhookNotFound is an error that represents a webhook that does not exist
+	ErrRepositoryWebhookNotFound = errors.New("repository: webhook not found")
+	// ErrRepositoryWebhookPersist is an error that represents a failure to persist the webhooks
+	ErrRepositoryWebhookPersist = errors.New("repository: webhook not persisted")
+	// ErrRepositoryDeadLetterNotFound is an error that represents a dead letter that does not exist
+	ErrRepositoryDeadLetterNotFound = errors.New("repository: dead letter not found")
+)
+
+// RepositoryWebhook is an interface that represents a webhook repository
+type RepositoryWebhook interface {
+	// FindAll is a method that returns the webhooks, by id
+	FindAll() (w []Webhook, err error)
+
+	// FindById is a method that returns the webhook that matches the id
+	FindById(id int) (w Webhook, err error)
+
+	// Save is a method that adds a webhook. The id is replaced by the next free id
+	Save(w *Webhook) (err error)
+
+	// Delete is a method that removes the webhook that matches the id
+	Delete(id int) (err error)
+}
+
+// RepositoryDelivery is an interface that represents a repository of the delivery attempts and the dead letters of the webhooks
+// - the attempts and the dead letters kept are bounded: the oldest ones are dropped first
+type RepositoryDelivery interface {
+	// FindByWebhookId is a method that returns the attempts of the webhook, oldest first
+	FindByWebhookId(webhookId int) (d []Delivery, err error)
+
+	// Append is a method that adds an attempt. The id is replaced by the next free id
+	Append(d *Delivery) (err error)
+
+	// FindDeadLetters is a method that returns the dead letters of the webhook, oldest first
+	FindDeadLetters(webhookId int) (dl []DeadLetter, err error)
+
+	// SaveDeadLetter is a method that adds a dead letter. The id is replaced by the next free id
+	SaveDeadLetter(dl *DeadLetter) (err error)
+
+	// DeleteDeadLetter is a method that removes and returns the dead letter of the webhook that matches the id
+	DeleteDeadLetter(webhookId int, id int) (dl DeadLetter, err error)
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrServiceInvalidWebhook is an error that represents a webhook with invalid attributes
+	ErrServiceInvalidWebhook = errors.New("service: invalid webhook")
+	// ErrServiceWebhookNotFound is an error that represents a webhook that does not exist
+	ErrServiceWebhookNotFound = errors.New("service: webhook not found")
+	// ErrServiceDeadLetterNotFound is an error that represents a dead letter that does not exist
+	ErrServiceDeadLetterNotFound = errors.New("service: dead letter not found")
+)
+
+// ServiceWebhook is an interface that represents a webhook service
+// - it is the publisher of the events: each one is posted to the subscribed webhooks in the background
+type ServiceWebhook interface {
+	PublisherEvent
+
+	// FindAll is a method that returns the webhooks, by id
+	FindAll() (w []Webhook, err error)
+
+	// FindById is a method that returns the webhook that matches the id
+	FindById(id int) (w Webhook, err error)
+
+	// Create is a method that validates and adds a webhook created by the principal of ctx
+	// - an empty secret is replaced by a random one
+	Create(ctx context.Context, w *Webhook) (err error)
+
+	// Delete is a method that removes the webhook that matches the id. Its pending retries are dropped
+	Delete(ctx context.Context, id int) (err error)
+
+	// FindDeliveries is a method that returns the delivery attempts of the webhook, oldest first
+	FindDeliveries(id int) (d []Delivery, err error)
+
+	// FindDeadLetters is a method that returns the events that could not be delivered to the webhook, oldest first
+	FindDeadLetters(id int) (dl []DeadLetter, err error)
+
+	// Redeliver is a method that removes a dead letter of the webhook and delivers its event again, with new retries
+	Redeliver(ctx context.Context, id int, deadLetterId int) (err error)
+}
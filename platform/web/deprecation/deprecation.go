@@ -0,0 +1,37 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Policy is a struct that represents the deprecation of a version of an api
+type Policy struct {
+	// Since is the instant from which the version is deprecated
+	Since time.Time
+	// Sunset is the instant after which the version may stop being served (zero: not scheduled)
+	Sunset time.Time
+	// Link is the url of the documentation of the migration (empty: none)
+	Link string
+}
+
+// Headers is a function that returns a middleware that sets the headers of the policy on the responses
+// - Deprecation (RFC 9745): the instant of Since, as @<unix seconds>
+// - Sunset (RFC 8594): the instant of Sunset, as an HTTP date
+// - Link: the documentation, with the relation deprecation
+func Headers(p Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", p.Since.Unix()))
+			if !p.Sunset.IsZero() {
+				w.Header().Set("Sunset", p.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if p.Link != "" {
+				w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"; type=\"text/html\"", p.Link))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
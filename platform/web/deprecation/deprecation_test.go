@@ -0,0 +1,51 @@
+package deprecation_test
+
+import (
+	"app/platform/web/deprecation"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Headers
+func TestHeaders(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("case 01: deprecation, sunset and link of the documentation", func(t *testing.T) {
+		// arrange
+		hd := deprecation.Headers(deprecation.Policy{
+			Since:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Sunset: time.Date(2026, 12, 31, 23, 59, 59, 0, time.FixedZone("ART", -3*60*60)),
+			Link:   "https://example.com/migration",
+		})(next)
+		res := httptest.NewRecorder()
+
+		// act
+		hd.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/v1/vehicles/1", nil))
+
+		// assert
+		require.Equal(t, http.StatusNoContent, res.Code)
+		require.Equal(t, "@1767225600", res.Header().Get("Deprecation"))
+		require.Equal(t, "Fri, 01 Jan 2027 02:59:59 GMT", res.Header().Get("Sunset"))
+		require.Equal(t, `<https://example.com/migration>; rel="deprecation"; type="text/html"`, res.Header().Get("Link"))
+	})
+
+	t.Run("case 02: no sunset scheduled", func(t *testing.T) {
+		// arrange
+		hd := deprecation.Headers(deprecation.Policy{Since: time.Unix(1767225600, 0)})(next)
+		res := httptest.NewRecorder()
+
+		// act
+		hd.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/v1/vehicles/1", nil))
+
+		// assert
+		require.Equal(t, "@1767225600", res.Header().Get("Deprecation"))
+		require.Empty(t, res.Header().Values("Sunset"))
+		require.Empty(t, res.Header().Values("Link"))
+	})
+}
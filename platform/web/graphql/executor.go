@@ -0,0 +1,690 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Request is a struct that represents a GraphQL request
+type Request struct {
+	// Query is the document with the operations and the fragments
+	Query string `json:"query"`
+	// OperationName is the operation to execute, required if the document has several
+	OperationName string `json:"operationName"`
+	// Variables are the values of the variables of the operation
+	Variables map[string]any `json:"variables"`
+}
+
+// Error is a struct that represents an error of the response
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	// Path is the response keys and list indexes of the field that failed
+	Path []any `json:"path,omitempty"`
+}
+
+// Error is a method that returns the message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Result is a struct that represents the response of a request
+type Result struct {
+	// Data is the result of the execution, nil if the request failed before executing
+	Data any
+	// Errors are the syntax, validation and field errors
+	Errors []*Error
+	// executed is true if the execution started, so data is serialized even if it is null
+	executed bool
+}
+
+// Executed is a method that returns true if the operation was executed
+// - false means that the request was rejected (syntax, validation or variables errors)
+func (r *Result) Executed() bool {
+	return r.executed
+}
+
+// MarshalJSON is a method that serializes the result: data is absent if the request was not executed
+func (r *Result) MarshalJSON() ([]byte, error) {
+	body := &orderedObject{values: make(map[string]any, 2)}
+	if r.executed {
+		body.set("data", r.Data)
+	}
+	if len(r.Errors) > 0 {
+		body.set("errors", r.Errors)
+	}
+	return body.MarshalJSON()
+}
+
+// Execute is a method that parses, validates and executes the request
+// - only queries are supported: mutations and subscriptions are rejected
+func (s *Schema) Execute(ctx context.Context, req Request) (res *Result) {
+	res = &Result{}
+
+	// parse
+	doc, err := parse(req.Query)
+	if err != nil {
+		var se *syntaxError
+		errors.As(err, &se)
+		res.Errors = append(res.Errors, &Error{Message: se.Error(), Locations: []Location{se.loc}})
+		return
+	}
+
+	// validate
+	if res.Errors = validate(s, doc); len(res.Errors) > 0 {
+		return
+	}
+
+	// operation
+	var op *operation
+	for _, candidate := range doc.operations {
+		if req.OperationName == "" || candidate.name == req.OperationName {
+			if op != nil {
+				res.Errors = append(res.Errors, &Error{Message: "operationName is required when the document has several operations"})
+				return
+			}
+			op = candidate
+		}
+	}
+	switch {
+	case op == nil:
+		res.Errors = append(res.Errors, &Error{Message: fmt.Sprintf("unknown operation %q", req.OperationName)})
+		return
+	case op.kind != "query":
+		res.Errors = append(res.Errors, &Error{Message: fmt.Sprintf("%s operations are not supported", op.kind), Locations: []Location{op.loc}})
+		return
+	}
+
+	// variables
+	vars, errs := coerceVariables(s, op, req.Variables)
+	if len(errs) > 0 {
+		res.Errors = errs
+		return
+	}
+
+	// execute
+	e := &executor{ctx: ctx, schema: s, doc: doc, vars: vars}
+	data, ok := e.selectionSet(s.Query, nil, op.selections, nil)
+	res.executed = true
+	if ok {
+		res.Data = data
+	}
+	res.Errors = e.errs
+	return
+}
+
+// executor is a struct that holds the state of the execution of an operation
+type executor struct {
+	ctx    context.Context
+	schema *Schema
+	doc    *document
+	vars   map[string]any
+	errs   []*Error
+}
+
+// fail is a method that records a field error
+func (e *executor) fail(node *fieldNode, path []any, message string) {
+	e.errs = append(e.errs, &Error{Message: message, Locations: []Location{node.loc}, Path: path})
+}
+
+// selectionSet is a method that executes the selections on an object
+// - ok is false if a non null field is null: the object is null and the error is already recorded
+func (e *executor) selectionSet(t *Object, source any, selections []selection, path []any) (result *orderedObject, ok bool) {
+	fields := &groupedFields{nodes: make(map[string][]*fieldNode)}
+	collectFields(e.schema, e.doc, e.vars, t, selections, make(map[string]bool), fields)
+
+	result = &orderedObject{values: make(map[string]any, len(fields.keys))}
+	for _, key := range fields.keys {
+		nodes := fields.nodes[key]
+		def := fieldDefinition(e.schema, t, nodes[0].name)
+		fieldPath := appendPath(path, key)
+
+		value, fieldOk := e.field(t, def, source, nodes, fieldPath)
+		if !fieldOk {
+			if _, nonNull := def.Type.(*NonNull); nonNull {
+				return nil, false
+			}
+			value = nil
+		}
+		result.set(key, value)
+	}
+	return result, true
+}
+
+// field is a method that resolves and completes a field
+func (e *executor) field(t *Object, def *Field, source any, nodes []*fieldNode, path []any) (value any, ok bool) {
+	args, err := coerceArguments(def.Args, nodes[0].args, e.vars)
+	if err != nil {
+		e.fail(nodes[0], path, err.Error())
+		return nil, false
+	}
+
+	var result any
+	switch def {
+	case typenameField:
+		result = t.Name
+	case schemaField:
+		result = e.schema
+	case typeField:
+		if named := e.schema.Type(args["name"].(string)); named != nil {
+			result = named
+		}
+	default:
+		resolve := def.Resolve
+		if resolve == nil {
+			resolve = resolveMap(def.Name)
+		}
+		result, err = e.resolve(resolve, ResolveParams{Context: e.ctx, Source: source, Args: args})
+		if err != nil {
+			e.fail(nodes[0], path, err.Error())
+			return nil, false
+		}
+	}
+	return e.complete(def.Type, nodes, result, path)
+}
+
+// resolve is a method that calls a resolver, turning its panics into errors
+func (e *executor) resolve(resolve ResolveFunc, p ResolveParams) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error")
+		}
+	}()
+	return resolve(p)
+}
+
+// resolveMap is a function that returns the default resolver: the value of a map[string]any source by name
+func resolveMap(name string) ResolveFunc {
+	return func(p ResolveParams) (result any, err error) {
+		if m, ok := p.Source.(map[string]any); ok {
+			result = m[name]
+		}
+		return
+	}
+}
+
+// complete is a method that serializes a resolved value according to the type of the field
+// - ok is false if the value is null because of an error already recorded
+func (e *executor) complete(t Type, nodes []*fieldNode, result any, path []any) (value any, ok bool) {
+	if nn, isNonNull := t.(*NonNull); isNonNull {
+		value, ok = e.complete(nn.OfType, nodes, result, path)
+		if !ok {
+			return nil, false
+		}
+		if value == nil {
+			e.fail(nodes[0], path, fmt.Sprintf("cannot return null for non-null field of type %s", t))
+			return nil, false
+		}
+		return value, true
+	}
+	if isNil(result) {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *List:
+		rv := reflect.ValueOf(result)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.fail(nodes[0], path, fmt.Sprintf("expected a list for a field of type %s", t))
+			return nil, false
+		}
+		items := make([]any, rv.Len())
+		for i := range items {
+			item, itemOk := e.complete(t.OfType, nodes, rv.Index(i).Interface(), appendPath(path, i))
+			if !itemOk {
+				if _, nonNull := t.OfType.(*NonNull); nonNull {
+					return nil, false
+				}
+				item = nil
+			}
+			items[i] = item
+		}
+		return items, true
+	case *Scalar:
+		value, err := serializeScalar(t, result)
+		if err != nil {
+			e.fail(nodes[0], path, err.Error())
+			return nil, false
+		}
+		return value, true
+	case *Enum:
+		for _, ev := range t.Values {
+			if ev.Value == result {
+				return ev.Name, true
+			}
+		}
+		e.fail(nodes[0], path, fmt.Sprintf("enum %s cannot represent value %v", t.Name, result))
+		return nil, false
+	case *Object:
+		var selections []selection
+		for _, node := range nodes {
+			selections = append(selections, node.selections...)
+		}
+		return e.selectionSet(t, result, selections, path)
+	}
+	return nil, true
+}
+
+// isNil is a function that returns true for nil and for nil pointers, maps and slices
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// appendPath is a function that returns a copy of the path with the key or index appended
+func appendPath(path []any, key any) []any {
+	p := make([]any, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, key)
+}
+
+// serializeScalar is a function that returns the JSON value of a scalar
+func serializeScalar(s *Scalar, v any) (value any, err error) {
+	rv := reflect.ValueOf(v)
+	switch s {
+	case Int:
+		var n float64
+		switch {
+		case rv.CanInt():
+			n = float64(rv.Int())
+		case rv.CanUint():
+			n = float64(rv.Uint())
+		case rv.CanFloat() && rv.Float() == math.Trunc(rv.Float()):
+			n = rv.Float()
+		default:
+			return nil, fmt.Errorf("Int cannot represent value %v", v)
+		}
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent non 32-bit integer %v", v)
+		}
+		return int(n), nil
+	case Float:
+		switch {
+		case rv.CanFloat():
+			return rv.Float(), nil
+		case rv.CanInt():
+			return float64(rv.Int()), nil
+		case rv.CanUint():
+			return float64(rv.Uint()), nil
+		}
+		return nil, fmt.Errorf("Float cannot represent value %v", v)
+	case Boolean:
+		if rv.Kind() == reflect.Bool {
+			return rv.Bool(), nil
+		}
+		return nil, fmt.Errorf("Boolean cannot represent value %v", v)
+	case String, ID:
+		switch {
+		case rv.Kind() == reflect.String:
+			return rv.String(), nil
+		case s == ID && rv.CanInt():
+			return strconv.FormatInt(rv.Int(), 10), nil
+		}
+		if stringer, ok := v.(fmt.Stringer); ok {
+			return stringer.String(), nil
+		}
+		return nil, fmt.Errorf("%s cannot represent value %v", s.Name, v)
+	}
+	return nil, fmt.Errorf("unsupported scalar %s", s.Name)
+}
+
+// orderedObject is a struct that represents a response object whose keys keep the order of the selections
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// set is a method that adds a key to the object
+func (o *orderedObject) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON is a method that serializes the object with its keys in order
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// groupedFields is a struct that represents the fields of a selection set grouped by response key, in order
+type groupedFields struct {
+	keys  []string
+	nodes map[string][]*fieldNode
+}
+
+// collectFields is a function that flattens the selections on an object, applying fragments and directives
+func collectFields(s *Schema, doc *document, vars map[string]any, t *Object, selections []selection, visited map[string]bool, fields *groupedFields) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *fieldNode:
+			if !included(sel.directives, vars) {
+				continue
+			}
+			key := sel.responseKey()
+			if _, ok := fields.nodes[key]; !ok {
+				fields.keys = append(fields.keys, key)
+			}
+			fields.nodes[key] = append(fields.nodes[key], sel)
+		case *fragmentSpread:
+			if !included(sel.directives, vars) || visited[sel.name] {
+				continue
+			}
+			visited[sel.name] = true
+			f := doc.fragments[sel.name]
+			if f.typeCondition != t.Name {
+				continue
+			}
+			collectFields(s, doc, vars, t, f.selections, visited, fields)
+		case *inlineFragment:
+			if !included(sel.directives, vars) || (sel.typeCondition != "" && sel.typeCondition != t.Name) {
+				continue
+			}
+			collectFields(s, doc, vars, t, sel.selections, visited, fields)
+		}
+	}
+}
+
+// included is a function that evaluates the @skip and @include directives
+func included(directives []*directive, vars map[string]any) bool {
+	for _, d := range directives {
+		var def *Directive
+		switch d.name {
+		case SkipDirective.Name:
+			def = SkipDirective
+		case IncludeDirective.Name:
+			def = IncludeDirective
+		default:
+			continue
+		}
+		args, err := coerceArguments(def.Args, d.args, vars)
+		if err != nil {
+			continue
+		}
+		if args["if"] == (d.name == SkipDirective.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// coerceVariables is a function that returns the values of the variables of the operation
+func coerceVariables(s *Schema, op *operation, inputs map[string]any) (vars map[string]any, errs []*Error) {
+	vars = make(map[string]any, len(op.variables))
+	for _, def := range op.variables {
+		t := inputType(s, def.typ)
+		input, ok := inputs[def.name]
+		switch {
+		case ok:
+			value, err := coerceInput(t, input)
+			if err != nil {
+				errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s: %s", def.name, err), Locations: []Location{def.loc}})
+				continue
+			}
+			vars[def.name] = value
+		case def.defaultValue != nil:
+			value, err := coerceLiteral(t, def.defaultValue, nil)
+			if err != nil {
+				errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s: %s", def.name, err), Locations: []Location{def.loc}})
+				continue
+			}
+			vars[def.name] = value
+		default:
+			if _, nonNull := t.(*NonNull); nonNull {
+				errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s of required type %s was not provided", def.name, t), Locations: []Location{def.loc}})
+			}
+		}
+	}
+	return
+}
+
+// inputType is a function that returns the schema type of a type reference (nil if the named type is unknown)
+func inputType(s *Schema, ref *typeRef) (t Type) {
+	if ref.list != nil {
+		item := inputType(s, ref.list)
+		if item == nil {
+			return nil
+		}
+		t = NewList(item)
+	} else {
+		t = s.Type(ref.name)
+		if t == nil {
+			return nil
+		}
+	}
+	if ref.nonNull {
+		t = NewNonNull(t)
+	}
+	return
+}
+
+// coerceArguments is a function that returns the values of the arguments, defaults included
+func coerceArguments(defs []*Argument, nodes []*argumentNode, vars map[string]any) (args map[string]any, err error) {
+	args = make(map[string]any, len(defs))
+	for _, def := range defs {
+		var node *argumentNode
+		for _, n := range nodes {
+			if n.name == def.Name {
+				node = n
+				break
+			}
+		}
+		_, nonNull := def.Type.(*NonNull)
+
+		var value any
+		var present bool
+		switch {
+		case node == nil:
+		case node.value.kind == valueVariable:
+			value, present = vars[node.value.raw]
+		default:
+			if value, err = coerceLiteral(def.Type, node.value, vars); err != nil {
+				err = fmt.Errorf("argument %q: %w", def.Name, err)
+				return
+			}
+			present = true
+		}
+
+		switch {
+		case !present && def.DefaultValue != nil:
+			args[def.Name] = def.DefaultValue
+		case !present && nonNull:
+			err = fmt.Errorf("argument %q of type %s is required", def.Name, def.Type)
+			return
+		case present && value == nil && nonNull:
+			err = fmt.Errorf("argument %q of type %s cannot be null", def.Name, def.Type)
+			return
+		case present:
+			args[def.Name] = value
+		}
+	}
+	return
+}
+
+// unwrapNonNull is a function that returns the type wrapped by a non null, or the type itself
+func unwrapNonNull(t Type) Type {
+	if nn, ok := t.(*NonNull); ok {
+		return nn.OfType
+	}
+	return t
+}
+
+// coerceInput is a function that returns the Go value of a JSON variable value
+func coerceInput(t Type, v any) (value any, err error) {
+	if nn, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return coerceInput(nn.OfType, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := v.([]any)
+		if !ok {
+			items = []any{v}
+		}
+		values := make([]any, len(items))
+		for i, item := range items {
+			if values[i], err = coerceInput(t.OfType, item); err != nil {
+				return
+			}
+		}
+		return values, nil
+	case *Enum:
+		if name, ok := v.(string); ok {
+			for _, ev := range t.Values {
+				if ev.Name == name {
+					return ev.Value, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("enum %s has no value %v", t.Name, v)
+	case *Scalar:
+		switch t {
+		case Int:
+			if n, ok := v.(float64); ok && n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int(n), nil
+			}
+		case Float:
+			if n, ok := v.(float64); ok {
+				return n, nil
+			}
+		case String:
+			if s, ok := v.(string); ok {
+				return s, nil
+			}
+		case Boolean:
+			if b, ok := v.(bool); ok {
+				return b, nil
+			}
+		case ID:
+			switch id := v.(type) {
+			case string:
+				return id, nil
+			case float64:
+				if id == math.Trunc(id) {
+					return strconv.FormatInt(int64(id), 10), nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("%s cannot represent value %v", t.Name, v)
+	}
+	return nil, fmt.Errorf("type %s is not an input type", t)
+}
+
+// coerceLiteral is a function that returns the Go value of a value literal
+// - variables are read from vars; absent variables are null
+func coerceLiteral(t Type, n *valueNode, vars map[string]any) (value any, err error) {
+	if n.kind == valueVariable {
+		value = vars[n.raw]
+		if _, nonNull := t.(*NonNull); nonNull && value == nil {
+			err = fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return
+	}
+	if nn, ok := t.(*NonNull); ok {
+		if n.kind == valueNull {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return coerceLiteral(nn.OfType, n, vars)
+	}
+	if n.kind == valueNull {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items := n.list
+		if n.kind != valueList {
+			items = []*valueNode{n}
+		}
+		values := make([]any, len(items))
+		for i, item := range items {
+			if values[i], err = coerceLiteral(t.OfType, item, vars); err != nil {
+				return
+			}
+		}
+		return values, nil
+	case *Enum:
+		if n.kind == valueEnum {
+			for _, ev := range t.Values {
+				if ev.Name == n.raw {
+					return ev.Value, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("enum %s has no value %s", t.Name, literal(n))
+	case *Scalar:
+		switch {
+		case t == Int && n.kind == valueInt:
+			v, errInt := strconv.ParseInt(n.raw, 10, 32)
+			if errInt == nil {
+				return int(v), nil
+			}
+		case t == Float && (n.kind == valueInt || n.kind == valueFloat):
+			v, errFloat := strconv.ParseFloat(n.raw, 64)
+			if errFloat == nil {
+				return v, nil
+			}
+		case t == String && n.kind == valueString:
+			return n.raw, nil
+		case t == Boolean && n.kind == valueBoolean:
+			return n.raw == "true", nil
+		case t == ID && (n.kind == valueString || n.kind == valueInt):
+			return n.raw, nil
+		}
+		return nil, fmt.Errorf("%s cannot represent value %s", t.Name, literal(n))
+	}
+	return nil, fmt.Errorf("type %s is not an input type", t)
+}
+
+// literal is a function that returns the GraphQL notation of a value literal, for the error messages
+func literal(n *valueNode) string {
+	switch n.kind {
+	case valueString:
+		return strconv.Quote(n.raw)
+	case valueVariable:
+		return "$" + n.raw
+	case valueList:
+		return "[...]"
+	case valueObject:
+		return "{...}"
+	}
+	return n.raw
+}
@@ -0,0 +1,191 @@
+package graphql_test
+
+import (
+	"app/platform/web/graphql"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newSchema is a function that returns a schema of books for the tests
+func newSchema() *graphql.Schema {
+	genre := &graphql.Enum{Name: "Genre", Values: []*graphql.EnumValue{
+		{Name: "NOVEL", Value: "novel"},
+		{Name: "SCIENCE_FICTION", Value: "science-fiction"},
+	}}
+	book := &graphql.Object{Name: "Book", Fields: []*graphql.Field{
+		{Name: "title", Type: graphql.NewNonNull(graphql.String)},
+		{Name: "year", Type: graphql.Int},
+		{Name: "genre", Type: genre},
+		{Name: "isbn", Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return nil, errors.New("isbn unavailable")
+		}},
+	}}
+	books := []map[string]any{
+		{"title": "Dune", "year": 1965, "genre": "science-fiction"},
+		{"title": "Emma", "year": 1815, "genre": "novel"},
+	}
+	return graphql.NewSchema(&graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{
+			Name: "books",
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(book))),
+			Args: []*graphql.Argument{{Name: "genre", Type: genre}, {Name: "limit", Type: graphql.Int, DefaultValue: 10}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				var result []map[string]any
+				for _, b := range books {
+					if g, ok := p.Args["genre"]; ok && g != b["genre"] {
+						continue
+					}
+					if len(result) < p.Args["limit"].(int) {
+						result = append(result, b)
+					}
+				}
+				return result, nil
+			},
+		},
+		{
+			Name: "book",
+			Type: book,
+			Args: []*graphql.Argument{{Name: "title", Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				for _, b := range books {
+					if b["title"] == p.Args["title"] {
+						return b, nil
+					}
+				}
+				return nil, nil
+			},
+		},
+	}})
+}
+
+// execute is a function that executes a request and returns the JSON of the result
+func execute(t *testing.T, s *graphql.Schema, req graphql.Request) string {
+	res := s.Execute(context.Background(), req)
+	b, err := json.Marshal(res)
+	require.NoError(t, err)
+	return string(b)
+}
+
+// Tests for Schema.Execute
+func TestSchema_Execute(t *testing.T) {
+	s := newSchema()
+
+	t.Run("case 01: query with aliases, arguments and enums keeps the order of the selections", func(t *testing.T) {
+		// act
+		res := execute(t, s, graphql.Request{Query: `{ sf: books(genre: SCIENCE_FICTION) { title genre } all: books(limit: 1) { year title } }`})
+
+		// assert
+		require.Equal(t, `{"data":{"sf":[{"title":"Dune","genre":"SCIENCE_FICTION"}],"all":[{"year":1965,"title":"Dune"}]}}`, res)
+	})
+
+	t.Run("case 02: variables, fragments and directives", func(t *testing.T) {
+		// arrange
+		req := graphql.Request{
+			Query: `
+				query Books($genre: Genre, $withYear: Boolean!) {
+					books(genre: $genre) { ...Fields year @include(if: $withYear) ... on Book { __typename } }
+				}
+				fragment Fields on Book { title }
+			`,
+			Variables: map[string]any{"genre": "NOVEL", "withYear": false},
+		}
+
+		// act
+		res := execute(t, s, req)
+
+		// assert
+		require.Equal(t, `{"data":{"books":[{"title":"Emma","__typename":"Book"}]}}`, res)
+	})
+
+	t.Run("case 03: field errors propagate null to the nearest nullable field", func(t *testing.T) {
+		// act
+		res := execute(t, s, graphql.Request{Query: `{ book(title: "Emma") { title isbn } }`})
+
+		// assert
+		require.JSONEq(t, `{
+			"data": {"book": null},
+			"errors": [{"message": "isbn unavailable", "locations": [{"line": 1, "column": 31}], "path": ["book", "isbn"]}]
+		}`, res)
+	})
+
+	t.Run("case 04: invalid documents are not executed", func(t *testing.T) {
+		// act
+		syntax := s.Execute(context.Background(), graphql.Request{Query: `{ books { title }`})
+		invalid := s.Execute(context.Background(), graphql.Request{Query: `query($g: String) { books(genre: $g, size: 1) { title author } book { title } }`})
+		variables := s.Execute(context.Background(), graphql.Request{Query: `query($l: Int!) { books(limit: $l) { title } }`, Variables: map[string]any{"l": "ten"}})
+		mutation := s.Execute(context.Background(), graphql.Request{Query: `mutation { books { title } }`})
+
+		// assert
+		messages := func(res *graphql.Result) (m []string) {
+			require.False(t, res.Executed())
+			for _, e := range res.Errors {
+				m = append(m, e.Message)
+			}
+			return
+		}
+		require.Equal(t, []string{"syntax error: unexpected end of document (line 1, column 18)"}, messages(syntax))
+		require.Equal(t, []string{
+			"variable $g of type String cannot be used as Genre",
+			"field \"books\" has no argument \"size\"",
+			"cannot query field \"author\" on type \"Book\"",
+			"field \"book\" argument \"title\" of type String! is required",
+		}, messages(invalid))
+		require.Equal(t, []string{"variable $l: Int cannot represent value ten"}, messages(variables))
+		require.Equal(t, []string{"mutation operations are not supported"}, messages(mutation))
+	})
+
+	t.Run("case 05: introspection", func(t *testing.T) {
+		// act
+		res := execute(t, s, graphql.Request{Query: `{
+			__schema { queryType { name } }
+			__type(name: "Query") {
+				kind
+				fields { name args { name defaultValue type { kind name ofType { name } } } }
+			}
+		}`})
+
+		// assert
+		require.JSONEq(t, `{"data": {
+			"__schema": {"queryType": {"name": "Query"}},
+			"__type": {"kind": "OBJECT", "fields": [
+				{"name": "books", "args": [
+					{"name": "genre", "defaultValue": null, "type": {"kind": "ENUM", "name": "Genre", "ofType": null}},
+					{"name": "limit", "defaultValue": "10", "type": {"kind": "SCALAR", "name": "Int", "ofType": null}}
+				]},
+				{"name": "book", "args": [
+					{"name": "title", "defaultValue": null, "type": {"kind": "NON_NULL", "name": null, "ofType": {"name": "String"}}}
+				]}
+			]}
+		}}`, res)
+	})
+
+	t.Run("case 06: documents past the limits of nesting, depth and selections are not executed", func(t *testing.T) {
+		// arrange
+		nested := `{ books(genre: ` + strings.Repeat("[", 101) + strings.Repeat("]", 101) + `) { title } }`
+		deep := `{ __type(name: "Book") { fields { type ` + strings.Repeat("{ ofType ", 12) + `{ name }` + strings.Repeat(" }", 12) + ` } } }`
+		spreads := `{ ...F0 }`
+		for i := 0; i < 10; i++ {
+			spreads += fmt.Sprintf(" fragment F%d on Query { ...F%d ...F%d }", i, i+1, i+1)
+		}
+		spreads += " fragment F10 on Query { __typename }"
+
+		// act
+		resNested := s.Execute(context.Background(), graphql.Request{Query: nested})
+		resDeep := s.Execute(context.Background(), graphql.Request{Query: deep})
+		resSpreads := s.Execute(context.Background(), graphql.Request{Query: spreads})
+
+		// assert
+		require.False(t, resNested.Executed())
+		require.Contains(t, resNested.Errors[0].Message, "document nested deeper than 100 levels")
+		require.False(t, resDeep.Executed())
+		require.Equal(t, "selection deeper than 15 levels", resDeep.Errors[0].Message)
+		require.False(t, resSpreads.Executed())
+		require.Equal(t, "operation with more than 1000 selections", resSpreads.Errors[0].Message)
+	})
+}
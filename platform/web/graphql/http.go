@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"app/platform/web/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxBodyBytes is the size of the largest body of a request
+const maxBodyBytes = 1 << 20
+
+// Handler returns a handler that executes the GraphQL requests on the schema
+// - GET: query, operationName and variables (JSON) in the query string
+// - POST: Request in a JSON body, or the document itself with Content-Type application/graphql
+// - requests rejected before their execution are answered with 400, executed ones with 200, bodies over maxBodyBytes with 413
+func Handler(s *Schema) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		var req Request
+		switch r.Method {
+		case http.MethodGet:
+			q := r.URL.Query()
+			req.Query = q.Get("query")
+			req.OperationName = q.Get("operationName")
+			if vars := q.Get("variables"); vars != "" {
+				if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+					response.JSON(w, http.StatusBadRequest, &Result{Errors: []*Error{{Message: "variables must be a JSON object"}}})
+					return
+				}
+			}
+		case http.MethodPost:
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					if tooLarge(w, err) {
+						return
+					}
+					response.JSON(w, http.StatusBadRequest, &Result{Errors: []*Error{{Message: "body could not be read"}}})
+					return
+				}
+				req.Query = string(body)
+				break
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				if tooLarge(w, err) {
+					return
+				}
+				response.JSON(w, http.StatusBadRequest, &Result{Errors: []*Error{{Message: "body must be a JSON object with a query"}}})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			response.JSON(w, http.StatusMethodNotAllowed, &Result{Errors: []*Error{{Message: "method not allowed"}}})
+			return
+		}
+
+		// process
+		res := s.Execute(r.Context(), req)
+
+		// response
+		code := http.StatusOK
+		if !res.Executed() {
+			code = http.StatusBadRequest
+		}
+		response.JSON(w, code, res)
+	}
+}
+
+// tooLarge is a function that answers with 413 if the error is the one of a body over maxBodyBytes
+func tooLarge(w http.ResponseWriter, err error) bool {
+	var errTooLarge *http.MaxBytesError
+	if !errors.As(err, &errTooLarge) {
+		return false
+	}
+	response.JSON(w, http.StatusRequestEntityTooLarge, &Result{Errors: []*Error{{Message: fmt.Sprintf("body larger than %d bytes", maxBodyBytes)}}})
+	return true
+}
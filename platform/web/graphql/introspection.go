@@ -0,0 +1,291 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Directive is a struct that represents a directive supported by the executor
+type Directive struct {
+	Name        string
+	Description string
+	Locations   []string
+	Args        []*Argument
+}
+
+var (
+	// SkipDirective is the directive that excludes a selection when its argument is true
+	SkipDirective = &Directive{
+		Name:        "skip",
+		Description: "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*Argument{{Name: "if", Description: "Skipped when true.", Type: NewNonNull(Boolean)}},
+	}
+	// IncludeDirective is the directive that includes a selection only when its argument is true
+	IncludeDirective = &Directive{
+		Name:        "include",
+		Description: "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*Argument{{Name: "if", Description: "Included when true.", Type: NewNonNull(Boolean)}},
+	}
+)
+
+// directiveDefinition is a function that returns the directive with the name (nil if it is not supported)
+func directiveDefinition(name string) *Directive {
+	for _, d := range []*Directive{SkipDirective, IncludeDirective} {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// introspection types: they describe the schema and are resolved from the Go values of the schema
+// - __Schema: *Schema, __Type: Type, __Field: *Field, __InputValue: *Argument, __EnumValue: *EnumValue, __Directive: *Directive
+var (
+	metaSchema        = &Object{Name: "__Schema", Description: "A GraphQL Schema defines the capabilities of a GraphQL server."}
+	metaType          = &Object{Name: "__Type", Description: "The fundamental unit of any GraphQL Schema is the type."}
+	metaField         = &Object{Name: "__Field", Description: "Object and Interface types are described by a list of Fields, each of which has a name, potentially a list of arguments, and a return type."}
+	metaInputValue    = &Object{Name: "__InputValue", Description: "Arguments provided to Fields or Directives and the input fields of an InputObject are represented as Input Values."}
+	metaEnumValue     = &Object{Name: "__EnumValue", Description: "One possible value for a given Enum."}
+	metaDirective     = &Object{Name: "__Directive", Description: "A Directive provides a way to describe alternate runtime execution and type validation behavior in a GraphQL document."}
+	metaTypeKind      = enumOfNames("__TypeKind", "An enum describing what kind of type a given `__Type` is.", "SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL")
+	metaDirectiveKind = enumOfNames("__DirectiveLocation", "A Directive can be adjacent to many parts of the GraphQL language.",
+		"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD", "INLINE_FRAGMENT", "VARIABLE_DEFINITION",
+		"SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT", "INPUT_FIELD_DEFINITION")
+
+	// typenameField is the meta field available on every object
+	typenameField = &Field{Name: "__typename", Description: "The name of the current Object type at runtime.", Type: NewNonNull(String)}
+	// schemaField is the meta field of the query root that returns the schema
+	schemaField = &Field{Name: "__schema", Description: "Access the current type schema of this server.", Type: NewNonNull(metaSchema)}
+	// typeField is the meta field of the query root that returns a type by name
+	typeField = &Field{Name: "__type", Description: "Request the type information of a single type.", Type: metaType,
+		Args: []*Argument{{Name: "name", Type: NewNonNull(String)}}}
+)
+
+func init() {
+	includeDeprecated := []*Argument{{Name: "includeDeprecated", Type: Boolean, DefaultValue: false}}
+
+	metaSchema.Fields = []*Field{
+		{Name: "description", Type: String},
+		{Name: "types", Type: NewNonNull(NewList(NewNonNull(metaType))), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Schema).Types(), nil
+		}},
+		{Name: "queryType", Type: NewNonNull(metaType), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Schema).Query, nil
+		}},
+		{Name: "mutationType", Type: metaType},
+		{Name: "subscriptionType", Type: metaType},
+		{Name: "directives", Type: NewNonNull(NewList(NewNonNull(metaDirective))), Resolve: func(p ResolveParams) (any, error) {
+			return []*Directive{IncludeDirective, SkipDirective}, nil
+		}},
+	}
+
+	metaType.Fields = []*Field{
+		{Name: "kind", Type: NewNonNull(metaTypeKind), Resolve: func(p ResolveParams) (any, error) {
+			switch p.Source.(type) {
+			case *Scalar:
+				return "SCALAR", nil
+			case *Enum:
+				return "ENUM", nil
+			case *Object:
+				return "OBJECT", nil
+			case *List:
+				return "LIST", nil
+			case *NonNull:
+				return "NON_NULL", nil
+			}
+			return nil, fmt.Errorf("unknown type %v", p.Source)
+		}},
+		{Name: "name", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			switch t := p.Source.(type) {
+			case *Scalar, *Enum, *Object:
+				return t.(Type).String(), nil
+			}
+			return nil, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			switch t := p.Source.(type) {
+			case *Scalar:
+				return optional(t.Description), nil
+			case *Enum:
+				return optional(t.Description), nil
+			case *Object:
+				return optional(t.Description), nil
+			}
+			return nil, nil
+		}},
+		{Name: "specifiedByURL", Type: String},
+		{Name: "fields", Type: NewList(NewNonNull(metaField)), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			o, ok := p.Source.(*Object)
+			if !ok {
+				return nil, nil
+			}
+			fields := make([]*Field, 0, len(o.Fields))
+			for _, f := range o.Fields {
+				if f.DeprecationReason == "" || p.Args["includeDeprecated"] == true {
+					fields = append(fields, f)
+				}
+			}
+			return fields, nil
+		}},
+		{Name: "interfaces", Type: NewList(NewNonNull(metaType)), Resolve: func(p ResolveParams) (any, error) {
+			if _, ok := p.Source.(*Object); ok {
+				return []Type{}, nil
+			}
+			return nil, nil
+		}},
+		{Name: "possibleTypes", Type: NewList(NewNonNull(metaType))},
+		{Name: "enumValues", Type: NewList(NewNonNull(metaEnumValue)), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			e, ok := p.Source.(*Enum)
+			if !ok {
+				return nil, nil
+			}
+			values := make([]*EnumValue, 0, len(e.Values))
+			for _, ev := range e.Values {
+				if ev.DeprecationReason == "" || p.Args["includeDeprecated"] == true {
+					values = append(values, ev)
+				}
+			}
+			return values, nil
+		}},
+		{Name: "inputFields", Type: NewList(NewNonNull(metaInputValue)), Args: includeDeprecated},
+		{Name: "ofType", Type: metaType, Resolve: func(p ResolveParams) (any, error) {
+			switch t := p.Source.(type) {
+			case *List:
+				return t.OfType, nil
+			case *NonNull:
+				return t.OfType, nil
+			}
+			return nil, nil
+		}},
+	}
+
+	metaField.Fields = []*Field{
+		{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Field).Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*Field).Description), nil
+		}},
+		{Name: "args", Type: NewNonNull(NewList(NewNonNull(metaInputValue))), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			return append([]*Argument{}, p.Source.(*Field).Args...), nil
+		}},
+		{Name: "type", Type: NewNonNull(metaType), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Field).Type, nil
+		}},
+		{Name: "isDeprecated", Type: NewNonNull(Boolean), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Field).DeprecationReason != "", nil
+		}},
+		{Name: "deprecationReason", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*Field).DeprecationReason), nil
+		}},
+	}
+
+	metaInputValue.Fields = []*Field{
+		{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Argument).Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*Argument).Description), nil
+		}},
+		{Name: "type", Type: NewNonNull(metaType), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Argument).Type, nil
+		}},
+		{Name: "defaultValue", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			a := p.Source.(*Argument)
+			if a.DefaultValue == nil {
+				return nil, nil
+			}
+			return printValue(a.Type, a.DefaultValue), nil
+		}},
+		{Name: "isDeprecated", Type: NewNonNull(Boolean), Resolve: func(p ResolveParams) (any, error) {
+			return false, nil
+		}},
+		{Name: "deprecationReason", Type: String},
+	}
+
+	metaEnumValue.Fields = []*Field{
+		{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*EnumValue).Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*EnumValue).Description), nil
+		}},
+		{Name: "isDeprecated", Type: NewNonNull(Boolean), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*EnumValue).DeprecationReason != "", nil
+		}},
+		{Name: "deprecationReason", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*EnumValue).DeprecationReason), nil
+		}},
+	}
+
+	metaDirective.Fields = []*Field{
+		{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Directive).Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*Directive).Description), nil
+		}},
+		{Name: "isRepeatable", Type: NewNonNull(Boolean), Resolve: func(p ResolveParams) (any, error) {
+			return false, nil
+		}},
+		{Name: "locations", Type: NewNonNull(NewList(NewNonNull(metaDirectiveKind))), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Directive).Locations, nil
+		}},
+		{Name: "args", Type: NewNonNull(NewList(NewNonNull(metaInputValue))), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Directive).Args, nil
+		}},
+	}
+}
+
+// enumOfNames is a function that returns an enum whose values are their own names
+func enumOfNames(name string, description string, values ...string) *Enum {
+	e := &Enum{Name: name, Description: description}
+	for _, v := range values {
+		e.Values = append(e.Values, &EnumValue{Name: v, Value: v})
+	}
+	return e
+}
+
+// optional is a function that returns nil for an empty description or reason
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// printValue is a function that returns the GraphQL notation of an input value, for the default values
+func printValue(t Type, v any) string {
+	t = unwrapNonNull(t)
+	if v == nil {
+		return "null"
+	}
+
+	switch t := t.(type) {
+	case *List:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			return printValue(t.OfType, v)
+		}
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = printValue(t.OfType, rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *Enum:
+		for _, ev := range t.Values {
+			if ev.Value == v {
+				return ev.Name
+			}
+		}
+	case *Scalar:
+		if t == String || t == ID {
+			return strconv.Quote(fmt.Sprint(v))
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,859 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind is a type that represents the kind of a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token is a struct that represents a lexical token of a document
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+// Location is a struct that represents a position in a document, 1-based
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// syntaxError is a struct that represents an error of the lexer or the parser
+type syntaxError struct {
+	message string
+	loc     Location
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("syntax error: %s (line %d, column %d)", e.message, e.loc.Line, e.loc.Column)
+}
+
+// lexer is a struct that splits a document into tokens
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+// next is a method that returns the next token, skipping whitespace, commas and comments
+func (l *lexer) next() (t token, err error) {
+	l.skipIgnored()
+	t.loc = Location{Line: l.line, Column: l.col}
+	if l.pos >= len(l.src) {
+		t.kind = tokenEOF
+		return
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.advance(1)
+		t.kind, t.value = tokenPunctuator, string(c)
+	case c == '.':
+		if !strings.HasPrefix(l.src[l.pos:], "...") {
+			err = &syntaxError{"unexpected \".\"", t.loc}
+			return
+		}
+		l.advance(3)
+		t.kind, t.value = tokenPunctuator, "..."
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.advance(1)
+		}
+		t.kind, t.value = tokenName, l.src[start:l.pos]
+	case c == '-' || isDigit(c):
+		t.kind, t.value, err = l.number()
+	case c == '"':
+		t.kind = tokenString
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			t.value, err = l.blockString()
+		} else {
+			t.value, err = l.string()
+		}
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+		err = &syntaxError{fmt.Sprintf("unexpected character %q", r), t.loc}
+	}
+	if e, ok := err.(*syntaxError); ok && e.loc.Line == 0 {
+		e.loc = t.loc
+	}
+	return
+}
+
+// advance is a method that moves the lexer n bytes forward on the current line
+func (l *lexer) advance(n int) {
+	l.pos += n
+	l.col += n
+}
+
+// skipIgnored is a method that skips whitespace, line terminators, commas, comments and the byte order mark
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == ',':
+			l.advance(1)
+		case c == '\n':
+			l.pos++
+			l.line, l.col = l.line+1, 1
+		case c == '\r':
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.line, l.col = l.line+1, 1
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+// number is a method that reads an int or a float
+func (l *lexer) number() (kind tokenKind, value string, err error) {
+	start := l.pos
+	kind = tokenInt
+	if l.src[l.pos] == '-' {
+		l.advance(1)
+	}
+	if !l.digits() {
+		err = &syntaxError{message: "invalid number"}
+		return
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokenFloat
+		l.advance(1)
+		if !l.digits() {
+			err = &syntaxError{message: "invalid number"}
+			return
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokenFloat
+		l.advance(1)
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.advance(1)
+		}
+		if !l.digits() {
+			err = &syntaxError{message: "invalid number"}
+			return
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == '_' || l.src[l.pos] == '.' || isLetter(l.src[l.pos])) {
+		err = &syntaxError{message: "invalid number"}
+		return
+	}
+	value = l.src[start:l.pos]
+	return
+}
+
+// digits is a method that reads a sequence of digits and returns false if it is empty
+func (l *lexer) digits() bool {
+	start := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.advance(1)
+	}
+	return l.pos > start
+}
+
+// string is a method that reads a quoted string with its escape sequences
+func (l *lexer) string() (value string, err error) {
+	var b strings.Builder
+	l.advance(1)
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.advance(1)
+			value = b.String()
+			return
+		case c == '\n' || c == '\r':
+			err = &syntaxError{message: "unterminated string"}
+			return
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				err = &syntaxError{message: "unterminated string"}
+				return
+			}
+			escapes := map[byte]string{'"': "\"", '\\': "\\", '/': "/", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t"}
+			e := l.src[l.pos+1]
+			if s, ok := escapes[e]; ok {
+				b.WriteString(s)
+				l.advance(2)
+				continue
+			}
+			if e != 'u' || l.pos+6 > len(l.src) {
+				err = &syntaxError{message: "invalid escape sequence"}
+				return
+			}
+			code, errHex := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 32)
+			if errHex != nil {
+				err = &syntaxError{message: "invalid escape sequence"}
+				return
+			}
+			b.WriteRune(rune(code))
+			l.advance(6)
+		default:
+			_, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			b.WriteString(l.src[l.pos : l.pos+size])
+			l.advance(size)
+		}
+	}
+	err = &syntaxError{message: "unterminated string"}
+	return
+}
+
+// blockString is a method that reads a triple quoted string, removing the common indentation
+func (l *lexer) blockString() (value string, err error) {
+	l.advance(3)
+	end := strings.Index(l.src[l.pos:], `"""`)
+	for end > 0 && l.src[l.pos+end-1] == '\\' {
+		next := strings.Index(l.src[l.pos+end+3:], `"""`)
+		if next < 0 {
+			end = -1
+			break
+		}
+		end += 3 + next
+	}
+	if end < 0 {
+		err = &syntaxError{message: "unterminated string"}
+		return
+	}
+	raw := strings.ReplaceAll(l.src[l.pos:l.pos+end], `\"""`, `"""`)
+	for _, c := range l.src[l.pos : l.pos+end+3] {
+		if c == '\n' {
+			l.line, l.col = l.line+1, 1
+		} else {
+			l.col++
+		}
+	}
+	l.pos += end + 3
+
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	value = strings.Join(lines, "\n")
+	return
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// document is a struct that represents a parsed executable document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is a struct that represents an operation definition
+type operation struct {
+	// kind is query, mutation or subscription
+	kind       string
+	name       string
+	variables  []*variableDefinition
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+// variableDefinition is a struct that represents a variable of an operation
+type variableDefinition struct {
+	name         string
+	typ          *typeRef
+	defaultValue *valueNode
+	loc          Location
+}
+
+// typeRef is a struct that represents a type in a variable definition
+type typeRef struct {
+	// name is the named type, empty for lists
+	name    string
+	list    *typeRef
+	nonNull bool
+}
+
+// String is a method that returns the type in GraphQL notation
+func (t *typeRef) String() string {
+	s := t.name
+	if t.list != nil {
+		s = "[" + t.list.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// fragment is a struct that represents a named fragment definition
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           Location
+}
+
+// selection is an interface that represents a selection: *fieldNode, *fragmentSpread or *inlineFragment
+type selection interface {
+	location() Location
+}
+
+// fieldNode is a struct that represents a selected field
+type fieldNode struct {
+	alias      string
+	name       string
+	args       []*argumentNode
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+func (f *fieldNode) location() Location { return f.loc }
+
+// responseKey is a method that returns the key of the field in the response
+func (f *fieldNode) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// fragmentSpread is a struct that represents a spread of a named fragment
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+func (f *fragmentSpread) location() Location { return f.loc }
+
+// inlineFragment is a struct that represents an inline fragment, with an optional type condition
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           Location
+}
+
+func (f *inlineFragment) location() Location { return f.loc }
+
+// argumentNode is a struct that represents an argument of a field or a directive
+type argumentNode struct {
+	name  string
+	value *valueNode
+	loc   Location
+}
+
+// directive is a struct that represents a directive, e.g. @include(if: $flag)
+type directive struct {
+	name string
+	args []*argumentNode
+	loc  Location
+}
+
+// valueKind is a type that represents the kind of a value literal
+type valueKind int
+
+const (
+	valueVariable valueKind = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueNull
+	valueEnum
+	valueList
+	valueObject
+)
+
+// valueNode is a struct that represents a value literal
+type valueNode struct {
+	kind valueKind
+	// raw is the name of variables and enums, the text of numbers and the content of strings and booleans
+	raw    string
+	list   []*valueNode
+	fields []*argumentNode
+	loc    Location
+}
+
+// maxNesting is the maximum nesting of the selection sets, the lists and the objects of a document
+// - it bounds the recursion of the parser, the validation bounds the depth of the fields further
+const maxNesting = 100
+
+// parser is a struct that builds a document from the tokens of the lexer
+type parser struct {
+	lx  *lexer
+	tok token
+	// nesting is the number of selection sets, lists and objects the current token is within
+	nesting int
+}
+
+// parse is a function that parses an executable document
+func parse(src string) (doc *document, err error) {
+	p := &parser{lx: &lexer{src: src, line: 1, col: 1}}
+	if err = p.advance(); err != nil {
+		return
+	}
+
+	doc = &document{fragments: make(map[string]*fragment)}
+	if p.tok.kind == tokenEOF {
+		err = &syntaxError{"document without definitions", p.tok.loc}
+		return
+	}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunctuator, "{"), p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			var op *operation
+			if op, err = p.operation(); err != nil {
+				return
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek(tokenName, "fragment"):
+			var f *fragment
+			if f, err = p.fragment(); err != nil {
+				return
+			}
+			if _, ok := doc.fragments[f.name]; ok {
+				err = &syntaxError{fmt.Sprintf("duplicate fragment %q", f.name), f.loc}
+				return
+			}
+			doc.fragments[f.name] = f
+		default:
+			err = p.unexpected()
+			return
+		}
+	}
+	return
+}
+
+// advance is a method that reads the next token
+func (p *parser) advance() (err error) {
+	p.tok, err = p.lx.next()
+	return
+}
+
+// nest is a method that enters a selection set, a list or an object, the caller must call unnest when it leaves it
+func (p *parser) nest() (err error) {
+	p.nesting++
+	if p.nesting > maxNesting {
+		err = &syntaxError{fmt.Sprintf("document nested deeper than %d levels", maxNesting), p.tok.loc}
+	}
+	return
+}
+
+// unnest is a method that leaves a selection set, a list or an object
+func (p *parser) unnest() {
+	p.nesting--
+}
+
+// peek is a method that returns true if the current token is of the kind and value
+func (p *parser) peek(kind tokenKind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// expect is a method that consumes the current token if it is of the kind and value
+func (p *parser) expect(kind tokenKind, value string) (err error) {
+	if !p.peek(kind, value) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+// skip is a method that consumes the current token if it is the punctuator and reports if it did
+func (p *parser) skip(value string) (ok bool, err error) {
+	if !p.peek(tokenPunctuator, value) {
+		return
+	}
+	ok = true
+	err = p.advance()
+	return
+}
+
+// name is a method that consumes a name token
+func (p *parser) name() (name string, err error) {
+	if p.tok.kind != tokenName {
+		err = p.unexpected()
+		return
+	}
+	name = p.tok.value
+	err = p.advance()
+	return
+}
+
+// unexpected is a method that returns the error of the current token
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return &syntaxError{"unexpected end of document", p.tok.loc}
+	}
+	return &syntaxError{fmt.Sprintf("unexpected %q", p.tok.value), p.tok.loc}
+}
+
+// operation is a method that parses an operation definition
+func (p *parser) operation() (op *operation, err error) {
+	op = &operation{kind: "query", loc: p.tok.loc}
+	if p.peek(tokenPunctuator, "{") {
+		op.selections, err = p.selectionSet()
+		return
+	}
+
+	op.kind = p.tok.value
+	if err = p.advance(); err != nil {
+		return
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err = p.advance(); err != nil {
+			return
+		}
+	}
+	if p.peek(tokenPunctuator, "(") {
+		if op.variables, err = p.variableDefinitions(); err != nil {
+			return
+		}
+	}
+	if op.directives, err = p.directives(); err != nil {
+		return
+	}
+	op.selections, err = p.selectionSet()
+	return
+}
+
+// variableDefinitions is a method that parses the variables of an operation
+func (p *parser) variableDefinitions() (vars []*variableDefinition, err error) {
+	if err = p.expect(tokenPunctuator, "("); err != nil {
+		return
+	}
+	for !p.peek(tokenPunctuator, ")") {
+		v := &variableDefinition{loc: p.tok.loc}
+		if err = p.expect(tokenPunctuator, "$"); err != nil {
+			return
+		}
+		if v.name, err = p.name(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, ":"); err != nil {
+			return
+		}
+		if v.typ, err = p.typeRef(); err != nil {
+			return
+		}
+		var ok bool
+		if ok, err = p.skip("="); err != nil {
+			return
+		}
+		if ok {
+			if v.defaultValue, err = p.value(true); err != nil {
+				return
+			}
+		}
+		if _, err = p.directives(); err != nil {
+			return
+		}
+		vars = append(vars, v)
+	}
+	err = p.advance()
+	return
+}
+
+// typeRef is a method that parses a type reference
+func (p *parser) typeRef() (t *typeRef, err error) {
+	t = &typeRef{}
+	var ok bool
+	if ok, err = p.skip("["); err != nil {
+		return
+	}
+	if ok {
+		if err = p.nest(); err != nil {
+			return
+		}
+		defer p.unnest()
+		if t.list, err = p.typeRef(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, "]"); err != nil {
+			return
+		}
+	} else if t.name, err = p.name(); err != nil {
+		return
+	}
+	t.nonNull, err = p.skip("!")
+	return
+}
+
+// fragment is a method that parses a named fragment definition
+func (p *parser) fragment() (f *fragment, err error) {
+	f = &fragment{loc: p.tok.loc}
+	if err = p.advance(); err != nil {
+		return
+	}
+	if f.name, err = p.name(); err != nil {
+		return
+	}
+	if f.name == "on" {
+		err = &syntaxError{"fragment cannot be named \"on\"", f.loc}
+		return
+	}
+	if err = p.expect(tokenName, "on"); err != nil {
+		return
+	}
+	if f.typeCondition, err = p.name(); err != nil {
+		return
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return
+	}
+	f.selections, err = p.selectionSet()
+	return
+}
+
+// selectionSet is a method that parses a selection set
+func (p *parser) selectionSet() (selections []selection, err error) {
+	if err = p.nest(); err != nil {
+		return
+	}
+	defer p.unnest()
+	if err = p.expect(tokenPunctuator, "{"); err != nil {
+		return
+	}
+	for !p.peek(tokenPunctuator, "}") {
+		var s selection
+		if s, err = p.selection(); err != nil {
+			return
+		}
+		selections = append(selections, s)
+	}
+	err = p.advance()
+	return
+}
+
+// selection is a method that parses a field, a fragment spread or an inline fragment
+func (p *parser) selection() (s selection, err error) {
+	loc := p.tok.loc
+	var ok bool
+	if ok, err = p.skip("..."); err != nil || !ok {
+		if err == nil {
+			s, err = p.field()
+		}
+		return
+	}
+
+	// fragment spread
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &fragmentSpread{loc: loc}
+		if spread.name, err = p.name(); err != nil {
+			return
+		}
+		spread.directives, err = p.directives()
+		s = spread
+		return
+	}
+
+	// inline fragment
+	inline := &inlineFragment{loc: loc}
+	if p.peek(tokenName, "on") {
+		if err = p.advance(); err != nil {
+			return
+		}
+		if inline.typeCondition, err = p.name(); err != nil {
+			return
+		}
+	}
+	if inline.directives, err = p.directives(); err != nil {
+		return
+	}
+	inline.selections, err = p.selectionSet()
+	s = inline
+	return
+}
+
+// field is a method that parses a field with its alias, arguments, directives and selections
+func (p *parser) field() (f *fieldNode, err error) {
+	f = &fieldNode{loc: p.tok.loc}
+	if f.name, err = p.name(); err != nil {
+		return
+	}
+	var ok bool
+	if ok, err = p.skip(":"); err != nil {
+		return
+	}
+	if ok {
+		f.alias = f.name
+		if f.name, err = p.name(); err != nil {
+			return
+		}
+	}
+	if p.peek(tokenPunctuator, "(") {
+		if f.args, err = p.arguments(false); err != nil {
+			return
+		}
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return
+	}
+	if p.peek(tokenPunctuator, "{") {
+		f.selections, err = p.selectionSet()
+	}
+	return
+}
+
+// arguments is a method that parses a list of arguments
+func (p *parser) arguments(constant bool) (args []*argumentNode, err error) {
+	if err = p.expect(tokenPunctuator, "("); err != nil {
+		return
+	}
+	for !p.peek(tokenPunctuator, ")") {
+		a := &argumentNode{loc: p.tok.loc}
+		if a.name, err = p.name(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, ":"); err != nil {
+			return
+		}
+		if a.value, err = p.value(constant); err != nil {
+			return
+		}
+		args = append(args, a)
+	}
+	err = p.advance()
+	return
+}
+
+// directives is a method that parses the directives applied to a definition or a selection
+func (p *parser) directives() (directives []*directive, err error) {
+	for p.peek(tokenPunctuator, "@") {
+		d := &directive{loc: p.tok.loc}
+		if err = p.advance(); err != nil {
+			return
+		}
+		if d.name, err = p.name(); err != nil {
+			return
+		}
+		if p.peek(tokenPunctuator, "(") {
+			if d.args, err = p.arguments(false); err != nil {
+				return
+			}
+		}
+		directives = append(directives, d)
+	}
+	return
+}
+
+// value is a method that parses a value literal (without variables if constant)
+func (p *parser) value(constant bool) (v *valueNode, err error) {
+	v = &valueNode{loc: p.tok.loc, raw: p.tok.value}
+	switch p.tok.kind {
+	case tokenInt:
+		v.kind = valueInt
+	case tokenFloat:
+		v.kind = valueFloat
+	case tokenString:
+		v.kind = valueString
+	case tokenName:
+		switch p.tok.value {
+		case "true", "false":
+			v.kind = valueBoolean
+		case "null":
+			v.kind = valueNull
+		default:
+			v.kind = valueEnum
+		}
+	case tokenPunctuator:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			if err = p.advance(); err != nil {
+				return
+			}
+			v.kind = valueVariable
+			v.raw, err = p.name()
+			return
+		case "[":
+			v.kind = valueList
+			if err = p.nest(); err != nil {
+				return
+			}
+			defer p.unnest()
+			if err = p.advance(); err != nil {
+				return
+			}
+			for !p.peek(tokenPunctuator, "]") {
+				var item *valueNode
+				if item, err = p.value(constant); err != nil {
+					return
+				}
+				v.list = append(v.list, item)
+			}
+			err = p.advance()
+			return
+		case "{":
+			v.kind = valueObject
+			if err = p.nest(); err != nil {
+				return
+			}
+			defer p.unnest()
+			if err = p.advance(); err != nil {
+				return
+			}
+			for !p.peek(tokenPunctuator, "}") {
+				f := &argumentNode{loc: p.tok.loc}
+				if f.name, err = p.name(); err != nil {
+					return
+				}
+				if err = p.expect(tokenPunctuator, ":"); err != nil {
+					return
+				}
+				if f.value, err = p.value(constant); err != nil {
+					return
+				}
+				v.fields = append(v.fields, f)
+			}
+			err = p.advance()
+			return
+		default:
+			return nil, p.unexpected()
+		}
+	default:
+		return nil, p.unexpected()
+	}
+	err = p.advance()
+	return
+}
@@ -0,0 +1,255 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Type is an interface that represents a GraphQL type: a named type (*Scalar, *Enum, *Object) or a wrapper (*List, *NonNull)
+type Type interface {
+	// String is a method that returns the type in GraphQL notation, e.g. [Vehicle!]!
+	String() string
+}
+
+// Scalar is a struct that represents a leaf type
+// - only the built-in scalars are supported: Int, Float, String, Boolean and ID
+type Scalar struct {
+	Name        string
+	Description string
+}
+
+// String is a method that returns the name of the scalar
+func (s *Scalar) String() string { return s.Name }
+
+var (
+	// Int is the scalar of the signed 32 bit integers
+	Int = &Scalar{Name: "Int", Description: "The `Int` scalar type represents non-fractional signed whole numeric values."}
+	// Float is the scalar of the double precision numbers
+	Float = &Scalar{Name: "Float", Description: "The `Float` scalar type represents signed double-precision fractional values."}
+	// String is the scalar of the UTF-8 texts
+	String = &Scalar{Name: "String", Description: "The `String` scalar type represents textual data."}
+	// Boolean is the scalar of true and false
+	Boolean = &Scalar{Name: "Boolean", Description: "The `Boolean` scalar type represents `true` or `false`."}
+	// ID is the scalar of the unique identifiers, serialized as a string
+	ID = &Scalar{Name: "ID", Description: "The `ID` scalar type represents a unique identifier."}
+)
+
+// Enum is a struct that represents a leaf type with a finite set of values
+type Enum struct {
+	Name        string
+	Description string
+	Values      []*EnumValue
+}
+
+// String is a method that returns the name of the enum
+func (e *Enum) String() string { return e.Name }
+
+// EnumValue is a struct that represents a value of an enum
+type EnumValue struct {
+	// Name is the name of the value in the queries and the responses, e.g. SEMI_AUTOMATIC
+	Name        string
+	Description string
+	// Value is the Go value the name stands for in arguments and results, e.g. internal.TransmissionSemiAutomatic
+	Value             any
+	DeprecationReason string
+}
+
+// Object is a struct that represents a type with fields
+type Object struct {
+	Name        string
+	Description string
+	// Fields are the fields of the object, in the order they are introspected
+	Fields []*Field
+}
+
+// String is a method that returns the name of the object
+func (o *Object) String() string { return o.Name }
+
+// Field is a method that returns the field with the name (nil if there is none)
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field is a struct that represents a field of an object
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	// Resolve is the function that returns the value of the field
+	// - nil resolves the field from a map[string]any source by name
+	Resolve           ResolveFunc
+	DeprecationReason string
+}
+
+// Argument is a struct that represents an argument of a field or a directive
+type Argument struct {
+	Name        string
+	Description string
+	// Type is the input type of the argument: a scalar, an enum or a list or non null of them
+	Type Type
+	// DefaultValue is the Go value used when the argument is not given (nil: no default)
+	DefaultValue any
+}
+
+// ResolveParams is a struct that represents the input of a resolver
+type ResolveParams struct {
+	// Context is the context of the request
+	Context context.Context
+	// Source is the value of the parent object
+	Source any
+	// Args are the coerced arguments, defaults included
+	Args map[string]any
+}
+
+// ResolveFunc is a function that returns the value of a field
+type ResolveFunc func(p ResolveParams) (result any, err error)
+
+// List is a struct that represents a list of values of a type
+type List struct {
+	OfType Type
+}
+
+// String is a method that returns the type in GraphQL notation
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+// NewList is a function that returns a list of the type
+func NewList(t Type) *List { return &List{OfType: t} }
+
+// NonNull is a struct that represents a type whose values cannot be null
+type NonNull struct {
+	OfType Type
+}
+
+// String is a method that returns the type in GraphQL notation
+func (n *NonNull) String() string { return n.OfType.String() + "!" }
+
+// NewNonNull is a function that returns the non null version of the type
+func NewNonNull(t Type) *NonNull { return &NonNull{OfType: t} }
+
+// Schema is a struct that represents a GraphQL schema with a query root
+// - mutations and subscriptions are not supported
+// - documents whose selections are deeper than maxDepth or more than maxSelections are rejected by the validation
+type Schema struct {
+	// Query is the root type of the queries
+	Query *Object
+	// types are the named types reachable from the query root, introspection types included
+	types map[string]Type
+	// maxDepth is the maximum depth of the fields of an operation, the fields of the query root are at depth 1
+	maxDepth int
+	// maxSelections is the maximum number of selections of an operation, fragments spread as many times as they are
+	maxSelections int
+}
+
+// ConfigSchema is a struct that represents the limits of the documents executed on a Schema
+type ConfigSchema struct {
+	// MaxDepth is the maximum depth of the fields of an operation
+	MaxDepth int
+	// MaxSelections is the maximum number of selections of an operation
+	MaxSelections int
+}
+
+// NewSchema is a function that returns the schema of the query root
+// - it panics if two different types share a name, a programming error like an invalid template
+func NewSchema(query *Object) *Schema {
+	return NewSchemaWithConfig(query, nil)
+}
+
+// NewSchemaWithConfig is a function that returns the schema of the query root with the given limits
+func NewSchemaWithConfig(query *Object, cfg *ConfigSchema) *Schema {
+	// default values
+	// - the introspection query of the usual tools is 13 levels deep
+	defaultConfig := &ConfigSchema{
+		MaxDepth:      15,
+		MaxSelections: 1000,
+	}
+	if cfg != nil {
+		if cfg.MaxDepth != 0 {
+			defaultConfig.MaxDepth = cfg.MaxDepth
+		}
+		if cfg.MaxSelections != 0 {
+			defaultConfig.MaxSelections = cfg.MaxSelections
+		}
+	}
+
+	s := &Schema{
+		Query:         query,
+		types:         make(map[string]Type),
+		maxDepth:      defaultConfig.MaxDepth,
+		maxSelections: defaultConfig.MaxSelections,
+	}
+	for _, t := range []Type{Int, Float, String, Boolean, ID, query, metaSchema} {
+		s.collect(t)
+	}
+	return s
+}
+
+// collect is a method that adds the named types reachable from the type
+func (s *Schema) collect(t Type) {
+	t = named(t)
+	var name string
+	switch t := t.(type) {
+	case *Scalar:
+		name = t.Name
+	case *Enum:
+		name = t.Name
+	case *Object:
+		name = t.Name
+	}
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("graphql: duplicate type %s", name))
+		}
+		return
+	}
+	s.types[name] = t
+
+	if o, ok := t.(*Object); ok {
+		for _, f := range o.Fields {
+			s.collect(f.Type)
+			for _, a := range f.Args {
+				s.collect(a.Type)
+			}
+		}
+	}
+}
+
+// Type is a method that returns the named type with the name (nil if there is none)
+func (s *Schema) Type(name string) Type {
+	return s.types[name]
+}
+
+// Types is a method that returns the named types sorted by name
+func (s *Schema) Types() []Type {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	types := make([]Type, 0, len(names))
+	for _, name := range names {
+		types = append(types, s.types[name])
+	}
+	return types
+}
+
+// named is a function that returns the named type wrapped by lists and non nulls
+func named(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.OfType
+		case *NonNull:
+			t = w.OfType
+		default:
+			return t
+		}
+	}
+}
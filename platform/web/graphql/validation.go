@@ -0,0 +1,312 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// validator is a struct that checks a document against a schema before its execution
+type validator struct {
+	schema *Schema
+	doc    *document
+	errs   []*Error
+	// vars are the variables of the operation being validated
+	vars map[string]*variableDefinition
+	// used are the variables referenced by the operation being validated
+	used map[string]bool
+	// spreading are the fragments being validated, to detect cycles
+	spreading map[string]bool
+	// depth is the depth of the fields of the selections being validated
+	depth int
+	// count is the number of selections validated in the operation or the fragment
+	count int
+	// exceeded is true once a selection is past the limits of the schema, the rest of the document is not validated
+	exceeded bool
+}
+
+// validate is a function that returns the validation errors of a document
+// - fields, arguments, fragments, directives and variables are checked against the schema
+// - the depth and the number of the selections are checked against the limits of the schema
+func validate(s *Schema, doc *document) []*Error {
+	v := &validator{schema: s, doc: doc}
+
+	names := make(map[string]bool)
+	for _, op := range doc.operations {
+		if op.name == "" && len(doc.operations) > 1 {
+			v.fail(op.loc, "anonymous operation must be the only operation of the document")
+		}
+		if op.name != "" && names[op.name] {
+			v.fail(op.loc, fmt.Sprintf("duplicate operation %q", op.name))
+		}
+		names[op.name] = true
+		v.operation(op)
+	}
+
+	for _, f := range doc.fragments {
+		if t, ok := s.Type(f.typeCondition).(*Object); !ok {
+			v.fail(f.loc, fmt.Sprintf("fragment %q cannot condition on unknown or non object type %q", f.name, f.typeCondition))
+		} else {
+			v.vars, v.used, v.spreading = nil, make(map[string]bool), map[string]bool{f.name: true}
+			v.depth, v.count = 1, 0
+			v.selections(t, f.selections)
+		}
+	}
+	return v.errs
+}
+
+// fail is a method that records a validation error
+func (v *validator) fail(loc Location, message string) {
+	v.errs = append(v.errs, &Error{Message: message, Locations: []Location{loc}})
+}
+
+// operation is a method that validates an operation and its variables
+func (v *validator) operation(op *operation) {
+	v.vars, v.used, v.spreading = make(map[string]*variableDefinition), make(map[string]bool), make(map[string]bool)
+	for _, def := range op.variables {
+		if _, ok := v.vars[def.name]; ok {
+			v.fail(def.loc, fmt.Sprintf("duplicate variable $%s", def.name))
+			continue
+		}
+		v.vars[def.name] = def
+
+		t := inputType(v.schema, def.typ)
+		if t == nil {
+			v.fail(def.loc, fmt.Sprintf("variable $%s has unknown type %s", def.name, def.typ))
+			continue
+		}
+		if _, ok := named(t).(*Object); ok {
+			v.fail(def.loc, fmt.Sprintf("variable $%s cannot be of non input type %s", def.name, def.typ))
+			continue
+		}
+		if def.defaultValue != nil {
+			if _, err := coerceLiteral(t, def.defaultValue, nil); err != nil {
+				v.fail(def.loc, fmt.Sprintf("variable $%s has invalid default value: %s", def.name, err))
+			}
+		}
+	}
+
+	v.directives(op.directives)
+	v.depth, v.count = 1, 0
+	v.selections(v.schema.Query, op.selections)
+
+	for _, def := range op.variables {
+		if !v.used[def.name] {
+			v.fail(def.loc, fmt.Sprintf("variable $%s is never used", def.name))
+		}
+	}
+}
+
+// selections is a method that validates the selections on an object
+func (v *validator) selections(t *Object, selections []selection) {
+	for _, sel := range selections {
+		if v.limit(sel.location()) {
+			return
+		}
+		switch sel := sel.(type) {
+		case *fieldNode:
+			v.directives(sel.directives)
+			v.field(t, sel)
+		case *fragmentSpread:
+			v.directives(sel.directives)
+			f, ok := v.doc.fragments[sel.name]
+			if !ok {
+				v.fail(sel.loc, fmt.Sprintf("unknown fragment %q", sel.name))
+				continue
+			}
+			if v.spreading[sel.name] {
+				v.fail(sel.loc, fmt.Sprintf("fragment %q spreads itself", sel.name))
+				continue
+			}
+			if f.typeCondition != t.Name {
+				v.fail(sel.loc, fmt.Sprintf("fragment %q on %s cannot be spread on %s", sel.name, f.typeCondition, t.Name))
+				continue
+			}
+			v.spreading[sel.name] = true
+			v.selections(t, f.selections)
+			delete(v.spreading, sel.name)
+		case *inlineFragment:
+			v.directives(sel.directives)
+			if sel.typeCondition != "" && sel.typeCondition != t.Name {
+				v.fail(sel.loc, fmt.Sprintf("inline fragment on %s cannot be spread on %s", sel.typeCondition, t.Name))
+				continue
+			}
+			v.selections(t, sel.selections)
+		}
+	}
+}
+
+// field is a method that validates a field, its arguments and its selections
+func (v *validator) field(t *Object, node *fieldNode) {
+	def := fieldDefinition(v.schema, t, node.name)
+	if def == nil {
+		v.fail(node.loc, fmt.Sprintf("cannot query field %q on type %q", node.name, t.Name))
+		return
+	}
+	v.arguments(node.loc, fmt.Sprintf("field %q", node.name), def.Args, node.args)
+
+	object, composite := named(def.Type).(*Object)
+	switch {
+	case composite && len(node.selections) == 0:
+		v.fail(node.loc, fmt.Sprintf("field %q of type %s must have a selection of subfields", node.name, def.Type))
+	case !composite && len(node.selections) > 0:
+		v.fail(node.loc, fmt.Sprintf("field %q of type %s must not have a selection of subfields", node.name, def.Type))
+	case composite:
+		v.depth++
+		v.selections(object, node.selections)
+		v.depth--
+	}
+}
+
+// limit is a method that counts a selection and fails the first one past the limits of the schema
+// - it stops the validation: the walk of the selections grows with the spreads of the fragments, as their execution
+func (v *validator) limit(loc Location) bool {
+	if v.exceeded {
+		return true
+	}
+	v.count++
+	switch {
+	case v.depth > v.schema.maxDepth:
+		v.fail(loc, fmt.Sprintf("selection deeper than %d levels", v.schema.maxDepth))
+	case v.count > v.schema.maxSelections:
+		v.fail(loc, fmt.Sprintf("operation with more than %d selections", v.schema.maxSelections))
+	default:
+		return false
+	}
+	v.exceeded = true
+	return true
+}
+
+// directives is a method that validates the @skip and @include directives
+func (v *validator) directives(directives []*directive) {
+	for _, d := range directives {
+		def := directiveDefinition(d.name)
+		if def == nil {
+			v.fail(d.loc, fmt.Sprintf("unknown directive @%s", d.name))
+			continue
+		}
+		v.arguments(d.loc, "directive @"+d.name, def.Args, d.args)
+	}
+}
+
+// arguments is a method that validates the arguments of a field or a directive
+func (v *validator) arguments(loc Location, owner string, defs []*Argument, nodes []*argumentNode) {
+	given := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		if given[node.name] {
+			v.fail(node.loc, fmt.Sprintf("%s has duplicate argument %q", owner, node.name))
+			continue
+		}
+		given[node.name] = true
+
+		var def *Argument
+		for _, d := range defs {
+			if d.Name == node.name {
+				def = d
+			}
+		}
+		if def == nil {
+			v.fail(node.loc, fmt.Sprintf("%s has no argument %q", owner, node.name))
+			continue
+		}
+		v.value(node.loc, owner, def, node.value)
+	}
+
+	for _, def := range defs {
+		if _, nonNull := def.Type.(*NonNull); nonNull && def.DefaultValue == nil && !given[def.Name] {
+			v.fail(loc, fmt.Sprintf("%s argument %q of type %s is required", owner, def.Name, def.Type))
+		}
+	}
+}
+
+// value is a method that validates the value of an argument: literals are coerced, variables must be defined
+func (v *validator) value(loc Location, owner string, def *Argument, n *valueNode) {
+	var variables func(location Type, n *valueNode) bool
+	variables = func(location Type, n *valueNode) (ok bool) {
+		ok = true
+		switch n.kind {
+		case valueVariable:
+			v.used[n.raw] = true
+			if v.vars == nil {
+				// fragments are validated on their own: their variables are checked by the operations
+				return
+			}
+			vd, defined := v.vars[n.raw]
+			if !defined {
+				v.fail(n.loc, fmt.Sprintf("variable $%s is not defined", n.raw))
+				return false
+			}
+			t := inputType(v.schema, vd.typ)
+			if t != nil && !usable(t, vd.defaultValue != nil || def.DefaultValue != nil, location) {
+				v.fail(n.loc, fmt.Sprintf("variable $%s of type %s cannot be used as %s", n.raw, t, location))
+				return false
+			}
+		case valueList:
+			item := location
+			if l, isList := unwrapNonNull(location).(*List); isList {
+				item = l.OfType
+			}
+			for _, i := range n.list {
+				ok = variables(item, i) && ok
+			}
+		}
+		return
+	}
+	if !variables(def.Type, n) {
+		return
+	}
+
+	// variables are checked against their definition when the operation is executed
+	if _, err := coerceLiteral(def.Type, n, placeholders(n)); err != nil {
+		v.fail(loc, fmt.Sprintf("%s argument %q: %s", owner, def.Name, err))
+	}
+}
+
+// usable is a function that returns true if a variable of the type can be used where the location type is expected
+// - a nullable variable can be used as a non null if it or the argument has a default value
+func usable(variable Type, hasDefault bool, location Type) bool {
+	if nn, ok := location.(*NonNull); ok {
+		if vn, ok := variable.(*NonNull); ok {
+			return usable(vn.OfType, false, nn.OfType)
+		}
+		return hasDefault && usable(variable, false, nn.OfType)
+	}
+	if vn, ok := variable.(*NonNull); ok {
+		return usable(vn.OfType, false, location)
+	}
+	if l, ok := location.(*List); ok {
+		vl, ok := variable.(*List)
+		return ok && usable(vl.OfType, false, l.OfType)
+	}
+	if _, ok := variable.(*List); ok {
+		return false
+	}
+	return variable == location
+}
+
+// placeholders is a function that returns a non null value for every variable of a literal, for its validation
+func placeholders(n *valueNode) map[string]any {
+	vars := make(map[string]any)
+	var walk func(n *valueNode)
+	walk = func(n *valueNode) {
+		if n.kind == valueVariable {
+			vars[n.raw] = struct{}{}
+		}
+		for _, item := range n.list {
+			walk(item)
+		}
+	}
+	walk(n)
+	return vars
+}
+
+// fieldDefinition is a function that returns the definition of a field of an object, meta fields included
+func fieldDefinition(s *Schema, t *Object, name string) *Field {
+	switch {
+	case name == typenameField.Name:
+		return typenameField
+	case t == s.Query && name == schemaField.Name:
+		return schemaField
+	case t == s.Query && name == typeField.Name:
+		return typeField
+	}
+	return t.Field(name)
+}
@@ -0,0 +1,298 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Version is the version of the protocol, required in every request and response
+const Version = "2.0"
+
+const (
+	// CodeParseError is the code of a request that is not valid JSON
+	CodeParseError = -32700
+	// CodeInvalidRequest is the code of a request that is not a valid request object
+	CodeInvalidRequest = -32600
+	// CodeMethodNotFound is the code of a request for a method that is not registered
+	CodeMethodNotFound = -32601
+	// CodeInvalidParams is the code of a request with invalid method parameters
+	CodeInvalidParams = -32602
+	// CodeInternalError is the code of an unexpected error
+	CodeInternalError = -32603
+)
+
+// Error is a struct that represents the error object of a response
+// - the range -32000 to -32099 is reserved for the application errors
+type Error struct {
+	// Code is the number that indicates the error type
+	Code int `json:"code"`
+	// Message is a short description of the error
+	Message string `json:"message"`
+	// Data is additional information about the error
+	Data any `json:"data,omitempty"`
+}
+
+// Error is a method that returns the message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError is a function that returns an error object
+func NewError(code int, message string, data any) *Error {
+	return &Error{Code: code, Message: message, Data: data}
+}
+
+// Request is a struct that represents a request object
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	// Id is the identifier of the call (string, number or null). Absent for notifications
+	Id json.RawMessage `json:"id,omitempty"`
+}
+
+// Response is a struct that represents a response object
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	Id      json.RawMessage `json:"id"`
+}
+
+// Method is a function that handles the calls of a method
+// - errors that are not *Error are translated by the error mapper of the server
+type Method func(ctx context.Context, params json.RawMessage) (result any, err error)
+
+// ErrorMapper is a function that translates the errors of the methods into error objects
+type ErrorMapper func(err error) *Error
+
+// Server is a struct that dispatches JSON-RPC 2.0 requests over HTTP to the registered methods
+// - single and batch requests are supported, notifications (requests without id) get no response
+// - bodies over maxBytes and batches over maxBatch are invalid requests
+type Server struct {
+	// methods is the map of method name to handler
+	methods map[string]Method
+	// mapError translates the errors of the methods
+	mapError ErrorMapper
+	// maxBytes is the maximum size of the body of a request
+	maxBytes int64
+	// maxBatch is the maximum number of requests of a batch
+	maxBatch int
+}
+
+// ConfigServer is a struct that represents the limits of a Server
+type ConfigServer struct {
+	// MaxBytes is the maximum size of the body of a request
+	MaxBytes int64
+	// MaxBatch is the maximum number of requests of a batch
+	MaxBatch int
+}
+
+// NewServer is a function that returns a new instance of Server
+// - a nil mapError translates every error into an internal error
+func NewServer(mapError ErrorMapper) *Server {
+	return NewServerWithConfig(mapError, nil)
+}
+
+// NewServerWithConfig is a function that returns a new instance of Server with the given limits
+func NewServerWithConfig(mapError ErrorMapper, cfg *ConfigServer) *Server {
+	// default values
+	defaultConfig := &ConfigServer{
+		MaxBytes: 1 << 20,
+		MaxBatch: 100,
+	}
+	if cfg != nil {
+		if cfg.MaxBytes != 0 {
+			defaultConfig.MaxBytes = cfg.MaxBytes
+		}
+		if cfg.MaxBatch != 0 {
+			defaultConfig.MaxBatch = cfg.MaxBatch
+		}
+	}
+
+	if mapError == nil {
+		mapError = func(err error) *Error { return NewError(CodeInternalError, "internal error", nil) }
+	}
+	return &Server{
+		methods:  make(map[string]Method),
+		mapError: mapError,
+		maxBytes: defaultConfig.MaxBytes,
+		maxBatch: defaultConfig.MaxBatch,
+	}
+}
+
+// Register is a method that adds a method to the server
+func (s *Server) Register(name string, m Method) {
+	s.methods[name] = m
+}
+
+// ServeHTTP is a method that handles a single or a batch request in the body of a POST request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBytes))
+	if err != nil {
+		var errTooLarge *http.MaxBytesError
+		if errors.As(err, &errTooLarge) {
+			write(w, Response{JSONRPC: Version, Error: NewError(CodeInvalidRequest, "invalid request", "request too large"), Id: null})
+			return
+		}
+		write(w, Response{JSONRPC: Version, Error: NewError(CodeParseError, "parse error", nil), Id: null})
+		return
+	}
+	body = bytes.TrimSpace(body)
+
+	// batch
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			write(w, Response{JSONRPC: Version, Error: NewError(CodeParseError, "parse error", nil), Id: null})
+			return
+		}
+		if len(batch) == 0 {
+			write(w, Response{JSONRPC: Version, Error: NewError(CodeInvalidRequest, "invalid request", "empty batch"), Id: null})
+			return
+		}
+		if len(batch) > s.maxBatch {
+			write(w, Response{JSONRPC: Version, Error: NewError(CodeInvalidRequest, "invalid request", "batch too large"), Id: null})
+			return
+		}
+
+		responses := make([]Response, 0, len(batch))
+		for _, raw := range batch {
+			if res, ok := s.call(r.Context(), raw); ok {
+				responses = append(responses, res)
+			}
+		}
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		write(w, responses)
+		return
+	}
+
+	// single
+	if !json.Valid(body) {
+		write(w, Response{JSONRPC: Version, Error: NewError(CodeParseError, "parse error", nil), Id: null})
+		return
+	}
+	res, ok := s.call(r.Context(), body)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	write(w, res)
+}
+
+// null is the id of the responses to requests whose id could not be determined
+var null = json.RawMessage("null")
+
+// call is a method that handles a request object
+// - ok is false for notifications, which get no response
+func (s *Server) call(ctx context.Context, raw json.RawMessage) (res Response, ok bool) {
+	res = Response{JSONRPC: Version, Id: null}
+
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != Version || req.Method == "" || !validId(req.Id) {
+		res.Error = NewError(CodeInvalidRequest, "invalid request", nil)
+		return res, true
+	}
+	notification := req.Id == nil
+	if !notification {
+		res.Id = req.Id
+	}
+
+	m, found := s.methods[req.Method]
+	if !found {
+		res.Error = NewError(CodeMethodNotFound, "method not found", req.Method)
+		return res, !notification
+	}
+
+	result, err := m(ctx, req.Params)
+	if err != nil {
+		var e *Error
+		if !errors.As(err, &e) {
+			e = s.mapError(err)
+		}
+		res.Error = e
+		return res, !notification
+	}
+	res.Result, err = json.Marshal(result)
+	if err != nil {
+		res.Result = nil
+		res.Error = NewError(CodeInternalError, "internal error", nil)
+	}
+	return res, !notification
+}
+
+// validId is a function that returns true if the id is absent, a string, a number or null
+func validId(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+	var v any
+	if err := json.Unmarshal(id, &v); err != nil {
+		return false
+	}
+	switch v.(type) {
+	case nil, string, float64:
+		return true
+	}
+	return false
+}
+
+// write is a function that writes a response or a batch of responses
+func write(w http.ResponseWriter, body any) {
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(bytes)
+}
+
+// DecodeParams is a function that decodes the params of a call into ptr, a pointer to a struct with json tags
+// - by-name params (object) are decoded as is
+// - by-position params (array) are matched with the given names, in order
+// - unknown params are rejected
+func DecodeParams(params json.RawMessage, ptr any, names ...string) (err error) {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+
+	if params[0] == '[' {
+		var positional []json.RawMessage
+		if err = json.Unmarshal(params, &positional); err != nil {
+			return NewError(CodeInvalidParams, "invalid params", err.Error())
+		}
+		if len(positional) > len(names) {
+			return NewError(CodeInvalidParams, "invalid params", "too many params")
+		}
+		named := make(map[string]json.RawMessage, len(positional))
+		for i, p := range positional {
+			named[names[i]] = p
+		}
+		if params, err = json.Marshal(named); err != nil {
+			return NewError(CodeInvalidParams, "invalid params", err.Error())
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(ptr); err != nil {
+		return NewError(CodeInvalidParams, "invalid params", err.Error())
+	}
+	return
+}
@@ -0,0 +1,144 @@
+package jsonrpc_test
+
+import (
+	"app/platform/web/jsonrpc"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Server.ServeHTTP
+func TestServer_ServeHTTP(t *testing.T) {
+	errNotFound := errors.New("not found")
+	server := jsonrpc.NewServer(func(err error) *jsonrpc.Error {
+		if errors.Is(err, errNotFound) {
+			return jsonrpc.NewError(-32004, "not found", nil)
+		}
+		return jsonrpc.NewError(jsonrpc.CodeInternalError, "internal error", nil)
+	})
+	server.Register("sum", func(ctx context.Context, params json.RawMessage) (result any, err error) {
+		var p struct {
+			A int `json:"a"`
+			B int `json:"b"`
+		}
+		if err = jsonrpc.DecodeParams(params, &p, "a", "b"); err != nil {
+			return
+		}
+		result = p.A + p.B
+		return
+	})
+	server.Register("find", func(ctx context.Context, params json.RawMessage) (result any, err error) {
+		err = errNotFound
+		return
+	})
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+		res := httptest.NewRecorder()
+		server.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("case 01: call with named and positional params", func(t *testing.T) {
+		// act
+		named := serve(`{"jsonrpc": "2.0", "method": "sum", "params": {"a": 1, "b": 2}, "id": 1}`)
+		positional := serve(`{"jsonrpc": "2.0", "method": "sum", "params": [0, 0], "id": "x"}`)
+
+		// assert
+		require.Equal(t, http.StatusOK, named.Code)
+		require.JSONEq(t, `{"jsonrpc": "2.0", "result": 3, "id": 1}`, named.Body.String())
+		require.JSONEq(t, `{"jsonrpc": "2.0", "result": 0, "id": "x"}`, positional.Body.String())
+	})
+
+	t.Run("case 02: standard and mapped errors", func(t *testing.T) {
+		// act
+		parse := serve(`{"jsonrpc": "2.0", "method"`)
+		invalid := serve(`{"jsonrpc": "1.0", "method": "sum", "id": 1}`)
+		method := serve(`{"jsonrpc": "2.0", "method": "div", "id": 2}`)
+		params := serve(`{"jsonrpc": "2.0", "method": "sum", "params": {"c": 1}, "id": 3}`)
+		mapped := serve(`{"jsonrpc": "2.0", "method": "find", "id": 4}`)
+
+		// assert
+		code := func(res *httptest.ResponseRecorder) int {
+			var body struct {
+				Error jsonrpc.Error `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+			return body.Error.Code
+		}
+		require.Equal(t, jsonrpc.CodeParseError, code(parse))
+		require.Equal(t, jsonrpc.CodeInvalidRequest, code(invalid))
+		require.Equal(t, jsonrpc.CodeMethodNotFound, code(method))
+		require.Equal(t, jsonrpc.CodeInvalidParams, code(params))
+		require.Equal(t, -32004, code(mapped))
+	})
+
+	t.Run("case 03: batch with notifications and invalid entries", func(t *testing.T) {
+		// act
+		res := serve(`[
+			{"jsonrpc": "2.0", "method": "sum", "params": [1, 1], "id": 1},
+			{"jsonrpc": "2.0", "method": "sum", "params": [1, 2]},
+			1,
+			{"jsonrpc": "2.0", "method": "find", "id": 2}
+		]`)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, `[
+			{"jsonrpc": "2.0", "result": 2, "id": 1},
+			{"jsonrpc": "2.0", "error": {"code": -32600, "message": "invalid request"}, "id": null},
+			{"jsonrpc": "2.0", "error": {"code": -32004, "message": "not found"}, "id": 2}
+		]`, res.Body.String())
+	})
+
+	t.Run("case 04: only notifications get no response", func(t *testing.T) {
+		// act
+		single := serve(`{"jsonrpc": "2.0", "method": "sum", "params": [1, 2]}`)
+		batch := serve(`[{"jsonrpc": "2.0", "method": "find"}]`)
+
+		// assert
+		require.Equal(t, http.StatusNoContent, single.Code)
+		require.Empty(t, single.Body.String())
+		require.Equal(t, http.StatusNoContent, batch.Code)
+	})
+
+	t.Run("case 05: empty batch is an invalid request", func(t *testing.T) {
+		// act
+		res := serve(`[]`)
+
+		// assert
+		require.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "invalid request", "data": "empty batch"}, "id": null}`, res.Body.String())
+	})
+
+	t.Run("case 06: batches and bodies over the limits are invalid requests", func(t *testing.T) {
+		// arrange
+		limited := jsonrpc.NewServerWithConfig(nil, &jsonrpc.ConfigServer{MaxBytes: 256, MaxBatch: 2})
+		limited.Register("ping", func(ctx context.Context, params json.RawMessage) (result any, err error) {
+			result = "pong"
+			return
+		})
+		call := `{"jsonrpc": "2.0", "method": "ping", "id": 1}`
+		serve := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+			res := httptest.NewRecorder()
+			limited.ServeHTTP(res, req)
+			return res
+		}
+
+		// act
+		within := serve("[" + call + "," + call + "]")
+		batch := serve("[" + call + "," + call + "," + call + "]")
+		large := serve(`{"jsonrpc": "2.0", "method": "ping", "params": "` + strings.Repeat("x", 256) + `", "id": 1}`)
+
+		// assert
+		require.JSONEq(t, `[{"jsonrpc": "2.0", "result": "pong", "id": 1}, {"jsonrpc": "2.0", "result": "pong", "id": 1}]`, within.Body.String())
+		require.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "invalid request", "data": "batch too large"}, "id": null}`, batch.Body.String())
+		require.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "invalid request", "data": "request too large"}, "id": null}`, large.Body.String())
+	})
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Counter is a struct that counts events by the values of its labels, safe for concurrent use
+type Counter struct {
+	// name is the name of the metric
+	name string
+	// help is the description of the metric
+	help string
+	// labels are the names of the labels
+	labels []string
+	// mu guards the counts
+	mu sync.Mutex
+	// counts are the counts by the values of the labels, joined by a separator that is not valid UTF-8
+	counts map[string]uint64
+}
+
+// separator is the separator of the values of the labels in the keys of the counts
+const separator = "\xff"
+
+// escaper escapes the values of the labels in the Prometheus text format
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// NewCounter is a function that returns a new instance of Counter
+func NewCounter(name string, help string, labels ...string) *Counter {
+	return &Counter{name: name, help: help, labels: labels, counts: make(map[string]uint64)}
+}
+
+// Inc is a method that adds one to the count of the values of the labels
+// - one value per label, in the order of the labels
+func (c *Counter) Inc(values ...string) {
+	if len(values) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: counter %s: %d values for %d labels", c.name, len(values), len(c.labels)))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[strings.Join(values, separator)]++
+}
+
+// Value is a method that returns the count of the values of the labels
+func (c *Counter) Value(values ...string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[strings.Join(values, separator)]
+}
+
+// WriteText is a method that writes the counts in the Prometheus text format, sorted by the values of the labels
+func (c *Counter) WriteText(w io.Writer) (err error) {
+	// snapshot: the writer can be slow, the counts are not locked while writing
+	c.mu.Lock()
+	counts := make(map[string]uint64, len(c.counts))
+	for key, n := range c.counts {
+		counts[key] = n
+	}
+	c.mu.Unlock()
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if _, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return
+	}
+	for _, key := range keys {
+		var pairs []string
+		if len(c.labels) > 0 {
+			for i, value := range strings.Split(key, separator) {
+				pairs = append(pairs, c.labels[i]+`="`+escaper.Replace(value)+`"`)
+			}
+		}
+		labels := ""
+		if len(pairs) > 0 {
+			labels = "{" + strings.Join(pairs, ",") + "}"
+		}
+		if _, err = fmt.Fprintf(w, "%s%s %d\n", c.name, labels, counts[key]); err != nil {
+			return
+		}
+	}
+	return
+}
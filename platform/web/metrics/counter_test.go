@@ -0,0 +1,61 @@
+package metrics_test
+
+import (
+	"app/platform/web/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Counter
+func TestCounter(t *testing.T) {
+	t.Run("case 01: counts by the values of the labels, written sorted and escaped", func(t *testing.T) {
+		// arrange
+		c := metrics.NewCounter("requests_total", "Requests.", "version", "path")
+		c.Inc("v2", "/a")
+		c.Inc("v1", `/"b"`)
+		c.Inc("v2", "/a")
+
+		// act
+		var sb strings.Builder
+		err := c.WriteText(&sb)
+
+		// assert
+		expected := "# HELP requests_total Requests.\n" +
+			"# TYPE requests_total counter\n" +
+			`requests_total{version="v1",path="/\"b\""} 1` + "\n" +
+			`requests_total{version="v2",path="/a"} 2` + "\n"
+		require.NoError(t, err)
+		require.Equal(t, expected, sb.String())
+		require.Equal(t, uint64(2), c.Value("v2", "/a"))
+		require.Equal(t, uint64(0), c.Value("v3", "/a"))
+	})
+
+	t.Run("case 02: the middleware counts the method and the status code of the responses", func(t *testing.T) {
+		// arrange
+		c := metrics.NewCounter("requests_total", "Requests.", "version", "method", "code")
+		hd := metrics.Count(c, "v1")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+
+		// act
+		for _, path := range []string{"/found", "/missing", "/found"} {
+			hd.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+		res := httptest.NewRecorder()
+		metrics.Handler(c)(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		// assert
+		require.Equal(t, uint64(2), c.Value("v1", http.MethodGet, "200"))
+		require.Equal(t, uint64(1), c.Value("v1", http.MethodGet, "404"))
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Contains(t, res.Body.String(), `requests_total{version="v1",method="GET",code="404"} 1`)
+	})
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Handler is a function that returns a handler that writes the counters in the Prometheus text format
+func Handler(counters ...*Counter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		for _, c := range counters {
+			if err := c.WriteText(w); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Count is a function that returns a middleware that counts the requests with the counter
+// - the labels of the counter are the values, followed by the method of the request and the status code of the response
+func Count(c *Counter, values ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			defer func() {
+				// a handler that does not write a status code responds 200
+				code := ww.Status()
+				if code == 0 {
+					code = http.StatusOK
+				}
+				c.Inc(append(values[:len(values):len(values)], r.Method, strconv.Itoa(code))...)
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
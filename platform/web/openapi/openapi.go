@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Document is a struct that represents an OpenAPI 3.1 document
+// - only the subset of the specification used by the services is modeled
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info is a struct that represents the metadata of the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a struct that represents a server of the API
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components is a struct that represents the reusable objects of the document
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a struct that represents an authentication method
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+}
+
+// PathItem is a struct that represents the operations of a path
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+// Operation is a method that returns the operation of the path for the http method (nil if there is none)
+func (p *PathItem) Operation(method string) *Operation {
+	switch method {
+	case http.MethodGet:
+		return p.Get
+	case http.MethodPost:
+		return p.Post
+	case http.MethodPut:
+		return p.Put
+	case http.MethodPatch:
+		return p.Patch
+	case http.MethodDelete:
+		return p.Delete
+	}
+	return nil
+}
+
+// Methods is a method that returns the http methods that have an operation
+func (p *PathItem) Methods() (m []string) {
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		if p.Operation(method) != nil {
+			m = append(m, method)
+		}
+	}
+	return
+}
+
+// Operation is a struct that represents an operation of the API
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	// Role is the minimum role required to call the operation (extension x-role)
+	Role string `json:"x-role,omitempty"`
+}
+
+// Parameter is a struct that represents a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is a struct that represents the body of a request
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// Response is a struct that represents a response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType is a struct that represents the schema of a content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a struct that represents a JSON schema
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"-"`
+	Nullable             bool               `json:"-"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// MarshalJSON is a method that serializes the schema
+// - nullable schemas are serialized with the OpenAPI 3.1 type array: ["string", "null"]
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type schemaJSON Schema
+	body := struct {
+		*schemaJSON
+		Type any `json:"type,omitempty"`
+	}{schemaJSON: (*schemaJSON)(s)}
+
+	switch {
+	case s.Type != "" && s.Nullable:
+		body.Type = []string{s.Type, "null"}
+	case s.Type != "":
+		body.Type = s.Type
+	}
+	return json.Marshal(body)
+}
+
+// Ref is a function that returns a schema that references a component schema
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Resolve is a method that returns the component schema referenced by the schema, or the schema itself
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// Find is a method that returns the path template and the operation that match a request path and method
+// - templates are matched segment by segment, {name} matches any non empty segment
+func (d *Document) Find(method string, path string) (template string, op *Operation, params map[string]string) {
+	segments := splitPath(path)
+	for candidate, item := range d.Paths {
+		op = item.Operation(method)
+		if op == nil {
+			continue
+		}
+
+		params = matchPath(splitPath(candidate), segments)
+		if params == nil {
+			continue
+		}
+
+		// static segments win over parameters: prefer the template with fewer parameters
+		if template == "" || strings.Count(candidate, "{") < strings.Count(template, "{") {
+			template = candidate
+		}
+	}
+	if template == "" {
+		return "", nil, nil
+	}
+
+	op = d.Paths[template].Operation(method)
+	params = matchPath(splitPath(template), segments)
+	return
+}
+
+// splitPath is a function that returns the segments of a path without empty segments
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
+
+// matchPath is a function that returns the parameters of the path if it matches the template (nil otherwise)
+func matchPath(template []string, path []string) map[string]string {
+	if len(template) != len(path) {
+		return nil
+	}
+
+	params := make(map[string]string)
+	for i, segment := range template {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[strings.Trim(segment, "{}")] = path[i]
+			continue
+		}
+		if segment != path[i] {
+			return nil
+		}
+	}
+	return params
+}
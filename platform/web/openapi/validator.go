@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	"app/platform/web/response"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validator is a middleware that rejects the requests that do not conform to the document with 400
+// - path and query parameters are checked against their schema, JSON bodies against the request body schema
+// - requests without operation in the document are let through, so the router answers them
+func Validator(doc *Document) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, op, params := doc.Find(r.Method, r.URL.Path)
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			errs := validateParameters(doc, op, params, r)
+
+			// body
+			if op.RequestBody != nil {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					response.Problem(w, http.StatusBadRequest, "body could not be read")
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				errs = append(errs, validateBody(doc, op.RequestBody, r.Header.Get("Content-Type"), body)...)
+			}
+
+			if len(errs) > 0 {
+				response.Problem(w, http.StatusBadRequest, strings.Join(errs, "; "))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validateParameters is a function that returns the errors of the path and query parameters of a request
+func validateParameters(doc *Document, op *Operation, params map[string]string, r *http.Request) (errs []string) {
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var value string
+		var ok bool
+		switch p.In {
+		case "path":
+			value, ok = params[p.Name]
+		case "query":
+			ok = query.Has(p.Name)
+			value = query.Get(p.Name)
+		default:
+			continue
+		}
+
+		if !ok {
+			if p.Required {
+				errs = append(errs, fmt.Sprintf("%s parameter %s is required", p.In, p.Name))
+			}
+			continue
+		}
+		if err := validateString(doc.Resolve(p.Schema), value); err != "" {
+			errs = append(errs, fmt.Sprintf("%s parameter %s %s", p.In, p.Name, err))
+		}
+	}
+	return
+}
+
+// validateBody is a function that returns the errors of the body of a request
+func validateBody(doc *Document, rb *RequestBody, contentType string, body []byte) (errs []string) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		if rb.Required {
+			errs = append(errs, "body is required")
+		}
+		return
+	}
+
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	media, ok := rb.Content[mediaType]
+	if !ok {
+		errs = append(errs, fmt.Sprintf("content type %q is not supported", mediaType))
+		return
+	}
+	if mediaType != "application/json" || media.Schema == nil {
+		return
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		errs = append(errs, "body is not valid JSON")
+		return
+	}
+	errs = validateValue(doc, media.Schema, value, "body")
+	return
+}
+
+// validateString is a function that returns the error of a parameter value ("" if it is valid)
+func validateString(s *Schema, value string) string {
+	if s == nil {
+		return ""
+	}
+
+	var typed any = value
+	switch s.Type {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "must be an integer"
+		}
+		typed = float64(n)
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return "must be a number"
+		}
+		typed = n
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "must be a boolean"
+		}
+		typed = b
+	}
+	return validateConstraints(s, typed)
+}
+
+// validateValue is a function that returns the errors of a decoded JSON value
+func validateValue(doc *Document, s *Schema, value any, path string) (errs []string) {
+	s = doc.Resolve(s)
+	if s == nil {
+		return
+	}
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			errs = append(errs, path+" must not be null")
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return append(errs, path+" must be an object")
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				errs = append(errs, path+"."+name+" is required")
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				property = s.AdditionalProperties
+			}
+			errs = append(errs, validateValue(doc, property, object[name], path+"."+name)...)
+		}
+		return
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return append(errs, path+" must be an array")
+		}
+		for i, item := range array {
+			errs = append(errs, validateValue(doc, s.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return append(errs, path+" must be an integer")
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return append(errs, path+" must be a number")
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return append(errs, path+" must be a string")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(errs, path+" must be a boolean")
+		}
+	}
+
+	if err := validateConstraints(s, value); err != "" {
+		errs = append(errs, path+" "+err)
+	}
+	return
+}
+
+// validateConstraints is a function that returns the error of the enum and range constraints ("" if they hold)
+func validateConstraints(s *Schema, value any) string {
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("must be one of %v", s.Enum)
+		}
+	}
+
+	if n, ok := value.(float64); ok {
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Sprintf("must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Sprintf("must be <= %v", *s.Maximum)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package openapi_test
+
+import (
+	"app/platform/web/openapi"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Validator
+func TestValidator(t *testing.T) {
+	min := 1.0
+	doc := &openapi.Document{
+		Paths: map[string]*openapi.PathItem{
+			"/items/{id}": {
+				Put: &openapi.Operation{
+					Parameters: []*openapi.Parameter{
+						{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+						{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []any{"metric", "imperial"}}},
+					},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  map[string]*openapi.MediaType{"application/json": {Schema: openapi.Ref("Item")}},
+					},
+				},
+			},
+		},
+		Components: openapi.Components{
+			Schemas: map[string]*openapi.Schema{
+				"Item": {
+					Type:     "object",
+					Required: []string{"name"},
+					Properties: map[string]*openapi.Schema{
+						"name":     {Type: "string"},
+						"quantity": {Type: "integer", Minimum: &min},
+					},
+				},
+			},
+		},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	hd := openapi.Validator(doc)(next)
+
+	t.Run("case 01: conforming request is let through", func(t *testing.T) {
+		// arrange
+		req := httptest.NewRequest(http.MethodPut, "/items/1?units=metric", strings.NewReader(`{"name": "a", "quantity": 2}`))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		// act
+		hd.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusNoContent, res.Code)
+	})
+
+	t.Run("case 02: invalid parameters and body are rejected with every error", func(t *testing.T) {
+		// arrange
+		req := httptest.NewRequest(http.MethodPut, "/items/one?units=parsecs", strings.NewReader(`{"quantity": 0}`))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		// act
+		hd.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+		var problem map[string]any
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &problem))
+		body := problem["detail"].(string)
+		require.Contains(t, body, "path parameter id must be an integer")
+		require.Contains(t, body, "query parameter units must be one of [metric imperial]")
+		require.Contains(t, body, "body.name is required")
+		require.Contains(t, body, "body.quantity must be >= 1")
+	})
+
+	t.Run("case 03: request without operation is let through", func(t *testing.T) {
+		// arrange
+		req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+		res := httptest.NewRecorder()
+
+		// act
+		hd.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusNoContent, res.Code)
+	})
+}
@@ -0,0 +1,30 @@
+package openapi
+
+import (
+	"app/platform/web/response"
+	_ "embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed viewer.html
+var viewerHTML string
+
+// viewerTemplate is the template of the documentation page
+var viewerTemplate = template.Must(template.New("viewer").Parse(viewerHTML))
+
+// Handler returns a handler that serves the document in JSON format
+func Handler(doc *Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, doc)
+	}
+}
+
+// Viewer returns a handler that serves a self-contained HTML page that renders the document served at specURL
+func Viewer(title string, specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		viewerTemplate.Execute(w, map[string]string{"Title": title, "SpecURL": specURL})
+	}
+}
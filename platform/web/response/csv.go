@@ -0,0 +1,20 @@
+package response
+
+import (
+	"encoding/csv"
+	"net/http"
+)
+
+// CSV writes csv response
+// - records: the first one is the header
+func CSV(w http.ResponseWriter, code int, records [][]string) {
+	// set header
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+	// set status code
+	w.WriteHeader(code)
+
+	// write body
+	cw := csv.NewWriter(w)
+	cw.WriteAll(records)
+}
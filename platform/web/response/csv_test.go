@@ -0,0 +1,32 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for CSV function
+func TestCSV(t *testing.T) {
+	t.Run("records", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		code := http.StatusOK
+		records := [][]string{{"bucket", "count"}, {"red, dark", "2"}}
+		response.CSV(rr, code, records)
+
+		// assert
+		expectedHeader := http.Header{"Content-Type": []string{"text/csv; charset=utf-8"}}
+		expectedCode := http.StatusOK
+		expectedBody := "bucket,count\n\"red, dark\",2\n"
+		require.Equal(t, expectedHeader, rr.Header())
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+	})
+}
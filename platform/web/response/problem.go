@@ -0,0 +1,42 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// problemResponse is a struct that represents a problem details body (RFC 9457)
+type problemResponse struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Problem writes a problem details response
+func Problem(w http.ResponseWriter, statusCode int, detail string) {
+	// default status code
+	defaultStatusCode := http.StatusInternalServerError
+	// check if status code is valid
+	if statusCode > 399 && statusCode < 600 {
+		defaultStatusCode = statusCode
+	}
+
+	// response
+	body := problemResponse{
+		Type:   "about:blank",
+		Title:  http.StatusText(defaultStatusCode),
+		Status: defaultStatusCode,
+		Detail: detail,
+	}
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// write response
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(defaultStatusCode)
+	w.Write(bytes)
+}
@@ -0,0 +1,51 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Problem function
+func TestProblem(t *testing.T) {
+	t.Run("401 - status unauthorized", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		code := http.StatusUnauthorized
+		detail := "missing credentials"
+		response.Problem(rr, code, detail)
+
+		// assert
+		expectedHeader := http.Header{"Content-Type": []string{"application/problem+json"}}
+		expectedCode := http.StatusUnauthorized
+		expectedBody := `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"missing credentials"}`
+		require.Equal(t, expectedHeader, rr.Header())
+		require.Equal(t, expectedCode, rr.Code)
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+
+	t.Run("500 - invalid status code", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		code := http.StatusOK
+		detail := ""
+		response.Problem(rr, code, detail)
+
+		// assert
+		expectedHeader := http.Header{"Content-Type": []string{"application/problem+json"}}
+		expectedCode := http.StatusInternalServerError
+		expectedBody := `{"type":"about:blank","title":"Internal Server Error","status":500}`
+		require.Equal(t, expectedHeader, rr.Header())
+		require.Equal(t, expectedCode, rr.Code)
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+}